@@ -0,0 +1,232 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// consensus-replay inspects the consensus message traces written by a node
+// started with --consensus.tracefile and replays Sport and Tendermint traces
+// against the core state machine to reproduce round changes deterministically.
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
+	"go-smilo/src/blockchain/smilobft/consensus/sport"
+	"go-smilo/src/blockchain/smilobft/consensus/sport/smilobftcore"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	tendermintCore "go-smilo/src/blockchain/smilobft/consensus/tendermint/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+var (
+	traceFlag = cli.StringFlag{
+		Name:  "trace",
+		Usage: "Consensus message trace file written with --consensus.tracefile",
+	}
+	validatorsFlag = cli.StringFlag{
+		Name:  "validators",
+		Usage: "Comma separated validator or fullnode addresses (default: every sender found in the trace)",
+	}
+	heightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "Height or sequence to start replaying from (default: lowest one found in the trace)",
+	}
+	lastProposerFlag = cli.StringFlag{
+		Name:  "lastproposer",
+		Usage: "Proposer or speaker of the block preceding the start height",
+	}
+	stickyFlag = cli.BoolFlag{
+		Name:  "sticky",
+		Usage: "Use the sticky proposer policy instead of round robin",
+	}
+	verbosityFlag = cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: 1,
+	}
+
+	dumpCommand = cli.Command{
+		Action:    dump,
+		Name:      "dump",
+		Usage:     "Print the records of a Sport or Tendermint message trace",
+		ArgsUsage: "--trace <file>",
+		Flags: []cli.Flag{
+			traceFlag,
+		},
+	}
+	sportCommand = cli.Command{
+		Action:    replaySport,
+		Name:      "sport",
+		Usage:     "Replay a Sport message trace against the core state machine",
+		ArgsUsage: "--trace <file>",
+		Flags: []cli.Flag{
+			traceFlag,
+			validatorsFlag,
+			heightFlag,
+			lastProposerFlag,
+			verbosityFlag,
+		},
+		Description: `
+Replays every inbound message of the trace, plus the messages the recording node
+delivered to itself, into a smilobft core running as an observer outside of the
+fullnode set against a stub backend. Proposals are accepted as valid and round
+change timeouts are not replayed, so sequence, round and state transitions only
+depend on the trace.`,
+	}
+	tendermintCommand = cli.Command{
+		Action:    replayTendermint,
+		Name:      "tendermint",
+		Usage:     "Replay a Tendermint message trace against the core state machine",
+		ArgsUsage: "--trace <file>",
+		Flags: []cli.Flag{
+			traceFlag,
+			validatorsFlag,
+			heightFlag,
+			lastProposerFlag,
+			stickyFlag,
+			verbosityFlag,
+		},
+		Description: `
+Replays every inbound message of the trace, plus the messages the recording node
+delivered to itself, into a tendermint core running as a non-validating observer
+against a stub backend. Proposals are accepted as valid and timeouts are not
+replayed, so height, round and step transitions only depend on the trace.`,
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "consensus-replay"
+	app.Usage = "The Smilo consensus message trace replay tool"
+
+	app.Commands = []cli.Command{
+		dumpCommand,
+		sportCommand,
+		tendermintCommand,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func readTrace(ctx *cli.Context) ([]*msgtrace.Record, error) {
+	if !ctx.IsSet(traceFlag.Name) {
+		return nil, cli.NewExitError("--trace is required", 1)
+	}
+	return msgtrace.ReadFile(ctx.String(traceFlag.Name))
+}
+
+func dump(ctx *cli.Context) error {
+	records, err := readTrace(ctx)
+	if err != nil {
+		return err
+	}
+	for i, r := range records {
+		fmt.Printf("#%d %s %-3s peer=%s code=%#x size=%d hash=%s\n", i,
+			time.Unix(0, r.Time).UTC().Format(time.RFC3339Nano), r.Direction, r.Peer.Hex(), r.Code,
+			len(r.Payload), types.RLPHash([]byte(r.Payload)).Hex())
+	}
+	return nil
+}
+
+func replaySport(ctx *cli.Context) error {
+	setupLogging(ctx)
+
+	records, err := readTrace(ctx)
+	if err != nil {
+		return err
+	}
+	senders, lowest := smilobftcore.ScanTrace(records)
+	fullnodes, height, err := replayStart(ctx, senders, lowest)
+	if err != nil {
+		return err
+	}
+
+	last := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height - 1)})
+	replayer := smilobftcore.NewReplayer(fullnodes, sport.RoundRobin, last, common.HexToAddress(ctx.String(lastProposerFlag.Name)))
+
+	fmt.Printf("Replaying %d records from sequence %d with %d fullnodes\n", len(records), height, len(fullnodes))
+	for _, event := range replayer.Replay(records) {
+		fmt.Println(event.String())
+	}
+	return nil
+}
+
+func replayTendermint(ctx *cli.Context) error {
+	setupLogging(ctx)
+
+	records, err := readTrace(ctx)
+	if err != nil {
+		return err
+	}
+	senders, lowest := tendermintCore.ScanTrace(records)
+	validators, height, err := replayStart(ctx, senders, lowest)
+	if err != nil {
+		return err
+	}
+	policy := config.RoundRobin
+	if ctx.Bool(stickyFlag.Name) {
+		policy = config.Sticky
+	}
+
+	last := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height - 1)})
+	replayer := tendermintCore.NewReplayer(validators, policy, last, common.HexToAddress(ctx.String(lastProposerFlag.Name)))
+
+	fmt.Printf("Replaying %d records from height %d with %d validators\n", len(records), height, len(validators))
+	for _, event := range replayer.Replay(records) {
+		fmt.Println(event.String())
+	}
+	return nil
+}
+
+func setupLogging(ctx *cli.Context) {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.Int(verbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+}
+
+// replayStart returns the committee and the start height of a replay, from the
+// flags or else from the senders and the lowest height found in the trace.
+func replayStart(ctx *cli.Context, senders []common.Address, lowest uint64) ([]common.Address, uint64, error) {
+	committee := senders
+	if ctx.IsSet(validatorsFlag.Name) {
+		committee = nil
+		for _, v := range strings.Split(ctx.String(validatorsFlag.Name), ",") {
+			committee = append(committee, common.HexToAddress(strings.TrimSpace(v)))
+		}
+	}
+	if len(committee) == 0 {
+		return nil, 0, cli.NewExitError("no validators given or found in the trace", 1)
+	}
+
+	height := lowest
+	if ctx.IsSet(heightFlag.Name) {
+		height = ctx.Uint64(heightFlag.Name)
+	}
+	if height == 0 {
+		return nil, 0, cli.NewExitError("start height must be above zero", 1)
+	}
+	return committee, height, nil
+}
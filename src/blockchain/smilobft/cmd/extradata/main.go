@@ -51,6 +51,11 @@ var (
 		Value: "0x00",
 	}
 
+	checkpointFlag = cli.BoolFlag{
+		Name:  "checkpoint",
+		Usage: "Commit the extraData of an epoch checkpoint header to the fullnodes",
+	}
+
 	stringToHashFlag = cli.StringFlag{
 		Name:  "string",
 		Usage: "string to be hashed",
@@ -75,12 +80,14 @@ var (
 				Action:    Encode,
 				Name:      "encode",
 				Usage:     "Encode Sport extraData",
-				ArgsUsage: "--fullnodes 0x7cB791430,0x2f65A895 --vanity 0x00",
+				ArgsUsage: "--fullnodes 0x7cB791430,0x2f65A895 --vanity 0x00 [--checkpoint]",
 				Flags: []cli.Flag{
 					fullnodesFlag,
 					vanityFlag,
+					checkpointFlag,
 				},
-				Description: `Encode vanity / fullnodes to extraData.`,
+				Description: `Encode vanity / fullnodes to extraData, with the digest of the fullnodes for
+the headers of the epoch checkpoints.`,
 			},
 			{
 				Action:    MixHash,
@@ -92,6 +99,27 @@ var (
 				},
 				Description: `Generate sport mixhash`,
 			},
+			{
+				Action:    Verify,
+				Name:      "verify",
+				Usage:     "Verify the seals of a BFT header",
+				ArgsUsage: "--header <file> | --rpc <url> [--block <number or hash>]",
+				Flags: []cli.Flag{
+					headerFlag,
+					rpcFlag,
+					blockFlag,
+					engineFlag,
+					validatorsFlag,
+					genesisFlag,
+					jsonFlag,
+				},
+				Description: `Decode a Sport, SportDAO, Istanbul or Tendermint header, recover its proposer
+seal and its committed seals, and check them against the validators of its
+parent block, from the node or from --validators. Exits with status 2 if the
+committed seals don't reach the quorum of the engine at the header number. The
+Sport and SportDAO quorum depends on the SixtySixPercentBlock of the chain
+config, from the admin API of the node or from --genesis.`,
+			},
 		},
 	}
 )
@@ -102,7 +130,7 @@ func Encode(ctx *cli.Context) error {
 		return cli.NewExitError("Fullnodes are required", 1)
 	}
 
-	extraData, err := GenerateExtraFromFullnodes(ctx.String(vanityFlag.Name), fullnodes)
+	extraData, err := GenerateExtraFromFullnodes(ctx.String(vanityFlag.Name), fullnodes, ctx.Bool(checkpointFlag.Name))
 	if err != nil {
 		return cli.NewExitError("Failed generate extraData from fullnodes", 0)
 	}
@@ -158,10 +186,15 @@ func Decode(ctx *cli.Context) error {
 		fmt.Println("committed seal: ", "0x"+common.Bytes2Hex(seal))
 	}
 
+	if smiloExtra.Checkpoint != (common.Hash{}) {
+		valid := types.ValidatorsDigest(smiloExtra.Fullnodes) == smiloExtra.Checkpoint
+		fmt.Println("checkpoint: ", smiloExtra.Checkpoint.Hex(), "valid:", valid)
+	}
+
 	return nil
 }
 
-func GenerateExtraFromFullnodes(vanity string, fullnodesStr string) (string, error) {
+func GenerateExtraFromFullnodes(vanity string, fullnodesStr string, checkpoint bool) (string, error) {
 	result := strings.Split(fullnodesStr, ",")
 	for i, r := range result {
 		result[i] = strings.TrimSpace(r)
@@ -187,6 +220,9 @@ func GenerateExtraFromFullnodes(vanity string, fullnodesStr string) (string, err
 		Seal:          make([]byte, types.BFTExtraSeal),
 		CommittedSeal: [][]byte{},
 	}
+	if checkpoint {
+		ist.Checkpoint = types.ValidatorsDigest(fullnodes)
+	}
 
 	payload, err := rlp.EncodeToBytes(&ist)
 	if err != nil {
@@ -0,0 +1,476 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/ethclient"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+// commitCode is the code of the message signed by the committed seals, after
+// the block hash: commit for Sport, SportDAO and Istanbul, precommit for
+// Tendermint.
+const commitCode = 2
+
+var (
+	headerFlag = cli.StringFlag{
+		Name:  "header",
+		Usage: "File holding the header in RLP, raw or hex encoded, or in JSON",
+	}
+
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "Node to get the header and the validators from, eg: --rpc=http://localhost:22000",
+	}
+
+	blockFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "Number or hash of the block of the header to get from the node",
+		Value: "latest",
+	}
+
+	engineFlag = cli.StringFlag{
+		Name:  "engine",
+		Usage: "Consensus engine of the header: sport, sportdao, istanbul or tendermint (default = detected)",
+	}
+
+	validatorsFlag = cli.StringFlag{
+		Name:  "validators",
+		Usage: "Validators of the parent block, instead of asking the node",
+	}
+
+	genesisFlag = cli.StringFlag{
+		Name:  "genesis",
+		Usage: "Genesis file holding the chain config of the Sport quorum, instead of asking the node",
+	}
+
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report in JSON",
+	}
+)
+
+// quorumFunc returns the number of committed seals needed out of n validators
+// for the header of block number.
+type quorumFunc func(n int, number *big.Int, config *params.ChainConfig) int
+
+// engineRules are the seal rules of a BFT consensus engine.
+type engineRules struct {
+	name       string
+	namespace  string // Namespace of the engine API
+	validators string // Method returning the validators at a block hash
+	quorum     quorumFunc
+	forked     bool // Whether the quorum depends on the chain config
+}
+
+// twoThirds is the quorum of Tendermint.
+func twoThirds(n int) int {
+	return int(math.Ceil(float64(2*n) / 3))
+}
+
+// twoFaultyPlusOne is the quorum of Istanbul, more than twice the tolerated
+// faulty validators.
+func twoFaultyPlusOne(n int) int {
+	return 2*(int(math.Ceil(float64(n)/3))-1) + 1
+}
+
+// sportQuorum is the quorum of Sport and SportDAO, two thirds of the fullnodes
+// from the SixtySixPercentBlock fork on and one seal less up to it.
+func sportQuorum(n int, number *big.Int, config *params.ChainConfig) int {
+	quorum := twoThirds(n)
+	if fork := config.SixtySixPercentBlock; fork == nil || number.Cmp(fork) <= 0 {
+		quorum--
+	}
+	return quorum
+}
+
+// fixedQuorum returns the quorum of an engine without forks.
+func fixedQuorum(quorum func(n int) int) quorumFunc {
+	return func(n int, number *big.Int, config *params.ChainConfig) int {
+		return quorum(n)
+	}
+}
+
+var engines = []engineRules{
+	{name: "sport", namespace: "smilobft", validators: "smilobft_getFullnodesByHash", quorum: sportQuorum, forked: true},
+	{name: "sportdao", namespace: "smilobftdao", validators: "smilobftdao_getValidatorsAtHash", quorum: sportQuorum, forked: true},
+	{name: "istanbul", namespace: "istanbul", validators: "istanbul_getValidatorsAtHash", quorum: fixedQuorum(twoFaultyPlusOne)},
+	{name: "tendermint", namespace: "tendermint", validators: "tendermint_getValidatorsAtHash", quorum: fixedQuorum(twoThirds)},
+}
+
+func engineByName(name string) (*engineRules, error) {
+	for i := range engines {
+		if engines[i].name == name {
+			return &engines[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown engine %q", name)
+}
+
+// committedSeal is a committed seal of a header and its signer.
+type committedSeal struct {
+	Seal      hexutil.Bytes  `json:"seal"`
+	Signer    common.Address `json:"signer"`
+	Validator bool           `json:"validator"` // Whether the signer is a validator of the parent block
+	Duplicate bool           `json:"duplicate"` // Whether the signer sealed the header before
+	Error     string         `json:"error,omitempty"`
+}
+
+// sealReport is the verification of the seals of a header.
+type sealReport struct {
+	Engine     string           `json:"engine"`
+	Number     uint64           `json:"number"`
+	Hash       common.Hash      `json:"hash"`
+	Vanity     hexutil.Bytes    `json:"vanity"`
+	Validators []common.Address `json:"validators"` // Validators of the parent block
+
+	Proposer          common.Address `json:"proposer"`
+	ProposerValidator bool           `json:"proposerValidator"`
+	ProposerError     string         `json:"proposerError,omitempty"`
+
+	Seals  []committedSeal `json:"committedSeals"`
+	Valid  int             `json:"valid"` // Number of the seals of distinct validators
+	Quorum int             `json:"quorum"`
+
+	Checkpoint      *common.Hash `json:"checkpoint,omitempty"`
+	CheckpointValid bool         `json:"checkpointValid,omitempty"` // Whether the checkpoint commits to the validators
+
+	Extra *types.BFTExtra `json:"-"`
+}
+
+// Reached returns whether the valid committed seals reach the quorum, and the
+// proposer is a validator.
+func (r *sealReport) Reached() bool {
+	return r.ProposerValidator && r.Valid >= r.Quorum
+}
+
+// verifySeals recovers the proposer seal and the committed seals of the header,
+// and checks them against the validators of its parent block. The extra-data
+// layout is shared by all the BFT engines, which only differ by their quorum.
+// The chain config is only needed by the engines whose quorum forked.
+func verifySeals(header *types.Header, validators []common.Address, engine *engineRules, config *params.ChainConfig) (*sealReport, error) {
+	if engine.forked && config == nil {
+		return nil, fmt.Errorf("the %s quorum depends on the chain config", engine.name)
+	}
+	extra, err := types.ExtractBFTHeaderExtra(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the extra-data: %v", err)
+	}
+	report := &sealReport{
+		Engine:     engine.name,
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		Vanity:     header.Extra[:types.BFTExtraVanity],
+		Validators: validators,
+		Quorum:     engine.quorum(len(validators), header.Number, config),
+		Extra:      extra,
+	}
+	set := make(map[common.Address]bool, len(validators))
+	for _, v := range validators {
+		set[v] = true
+	}
+
+	if proposer, err := types.GetSignatureAddress(types.SigHash(header).Bytes(), extra.Seal); err != nil {
+		report.ProposerError = err.Error()
+	} else {
+		report.Proposer, report.ProposerValidator = proposer, set[proposer]
+	}
+
+	commit := append(report.Hash.Bytes(), byte(commitCode))
+	sealed := make(map[common.Address]bool)
+	for _, seal := range extra.CommittedSeal {
+		cs := committedSeal{Seal: seal}
+		signer, err := types.GetSignatureAddress(commit, seal)
+		if err != nil {
+			cs.Error = err.Error()
+			report.Seals = append(report.Seals, cs)
+			continue
+		}
+		cs.Signer, cs.Validator, cs.Duplicate = signer, set[signer], sealed[signer]
+		if cs.Validator && !cs.Duplicate {
+			report.Valid++
+		}
+		sealed[signer] = true
+		report.Seals = append(report.Seals, cs)
+	}
+
+	if extra.Checkpoint != (common.Hash{}) {
+		checkpoint := extra.Checkpoint
+		report.Checkpoint = &checkpoint
+		report.CheckpointValid = types.VerifyCheckpointValidators(header, validators) == nil
+	}
+	return report, nil
+}
+
+// readHeader decodes a header in RLP, raw or hex encoded, or in JSON.
+func readHeader(blob []byte) (*types.Header, error) {
+	header := new(types.Header)
+	text := bytes.TrimSpace(blob)
+	if len(text) > 0 && text[0] == '{' {
+		if err := json.Unmarshal(text, header); err != nil {
+			return nil, fmt.Errorf("invalid JSON header: %v", err)
+		}
+		return header, nil
+	}
+	if raw, err := hexutil.Decode(string(text)); err == nil {
+		blob = raw
+	} else if raw, err := hexutil.Decode("0x" + string(text)); err == nil {
+		blob = raw
+	}
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, fmt.Errorf("invalid RLP header: %v", err)
+	}
+	return header, nil
+}
+
+// fetchHeader gets the header of a block number or hash from the node.
+func fetchHeader(ctx context.Context, client *ethclient.Client, block string) (*types.Header, error) {
+	switch {
+	case block == "latest":
+		return client.HeaderByNumber(ctx, nil)
+	case len(block) == 2+2*common.HashLength && strings.HasPrefix(block, "0x"):
+		return client.HeaderByHash(ctx, common.HexToHash(block))
+	default:
+		n, err := strconv.ParseUint(block, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block %q", block)
+		}
+		return client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	}
+}
+
+// detectEngine returns the engine of the header, from the engine API enabled
+// on the node if any, or else from its mix digest.
+func detectEngine(client *rpc.Client, header *types.Header) (*engineRules, error) {
+	if client != nil {
+		modules, err := client.SupportedModules()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the modules of the node: %v", err)
+		}
+		for i := range engines {
+			if _, ok := modules[engines[i].namespace]; ok {
+				return &engines[i], nil
+			}
+		}
+	}
+	switch header.MixDigest {
+	case types.SportDigest:
+		return engineByName("sport")
+	case types.BFTDigest:
+		return nil, errors.New("the engine of the header is required, istanbul or tendermint")
+	}
+	return nil, fmt.Errorf("not a BFT header, mix digest %s", header.MixDigest.Hex())
+}
+
+// readChainConfig reads the chain config of a genesis file.
+func readChainConfig(path string) (*params.ChainConfig, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var genesis struct {
+		Config *params.ChainConfig `json:"config"`
+	}
+	if err := json.Unmarshal(blob, &genesis); err != nil {
+		return nil, fmt.Errorf("invalid genesis file: %v", err)
+	}
+	if genesis.Config == nil {
+		return nil, errors.New("no chain config in the genesis file")
+	}
+	return genesis.Config, nil
+}
+
+// fetchChainConfig gets the chain config of the node from its admin API.
+func fetchChainConfig(ctx context.Context, client *rpc.Client) (*params.ChainConfig, error) {
+	var info struct {
+		Protocols map[string]json.RawMessage `json:"protocols"`
+	}
+	if err := client.CallContext(ctx, &info, "admin_nodeInfo"); err != nil {
+		return nil, err
+	}
+	var eth struct {
+		Config *params.ChainConfig `json:"config"`
+	}
+	if err := json.Unmarshal(info.Protocols["eth"], &eth); err != nil || eth.Config == nil {
+		return nil, errors.New("no chain config in the node info")
+	}
+	return eth.Config, nil
+}
+
+func parseAddresses(list string) []common.Address {
+	var addresses []common.Address
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			addresses = append(addresses, common.HexToAddress(s))
+		}
+	}
+	return addresses
+}
+
+// Verify verifies the seals of a header against the validators of its parent.
+func Verify(ctx *cli.Context) error {
+	var (
+		client *rpc.Client
+		header *types.Header
+		err    error
+	)
+	callctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if url := ctx.String(rpcFlag.Name); url != "" {
+		if client, err = rpc.DialContext(callctx, url); err != nil {
+			return fmt.Errorf("could not dial to Smilo node: %v", err)
+		}
+		defer client.Close()
+	}
+	switch {
+	case ctx.IsSet(headerFlag.Name):
+		blob, err := ioutil.ReadFile(ctx.String(headerFlag.Name))
+		if err != nil {
+			return err
+		}
+		if header, err = readHeader(blob); err != nil {
+			return err
+		}
+	case client != nil:
+		if header, err = fetchHeader(callctx, ethclient.NewClient(client), ctx.String(blockFlag.Name)); err != nil {
+			return fmt.Errorf("failed to get the header: %v", err)
+		}
+	default:
+		return cli.NewExitError("header or rpc is required", 1)
+	}
+
+	var engine *engineRules
+	if name := ctx.String(engineFlag.Name); name != "" {
+		engine, err = engineByName(name)
+	} else {
+		engine, err = detectEngine(client, header)
+	}
+	if err != nil {
+		return err
+	}
+
+	var validators []common.Address
+	switch {
+	case ctx.IsSet(validatorsFlag.Name):
+		validators = parseAddresses(ctx.String(validatorsFlag.Name))
+	case header.Number.Sign() == 0:
+		// The genesis validators are the ones of its extra-data
+		extra, err := types.ExtractBFTHeaderExtra(header)
+		if err != nil {
+			return err
+		}
+		validators = extra.Validators
+	case client != nil:
+		if err := client.CallContext(callctx, &validators, engine.validators, header.ParentHash); err != nil {
+			return fmt.Errorf("failed to get the validators of the parent block: %v", err)
+		}
+	default:
+		return cli.NewExitError("validators or rpc is required", 1)
+	}
+
+	var config *params.ChainConfig
+	switch {
+	case !engine.forked:
+	case ctx.IsSet(genesisFlag.Name):
+		if config, err = readChainConfig(ctx.String(genesisFlag.Name)); err != nil {
+			return err
+		}
+	case client != nil:
+		if config, err = fetchChainConfig(callctx, client); err != nil {
+			return fmt.Errorf("failed to get the chain config of the node, use --genesis: %v", err)
+		}
+	default:
+		return cli.NewExitError(fmt.Sprintf("genesis or rpc is required by the %s quorum", engine.name), 1)
+	}
+
+	report, err := verifySeals(header, validators, engine, config)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		report.print(os.Stdout)
+	}
+	if header.Number.Sign() != 0 && !report.Reached() {
+		return cli.NewExitError("", 2)
+	}
+	return nil
+}
+
+func (r *sealReport) print(w io.Writer) {
+	fmt.Fprintf(w, "%s header %d %s\n", r.Engine, r.Number, r.Hash.Hex())
+	fmt.Fprintln(w, "vanity:", r.Vanity.String())
+	for _, v := range r.Extra.Validators {
+		fmt.Fprintln(w, "extra-data validator:", v.Hex())
+	}
+	for _, v := range r.Validators {
+		fmt.Fprintln(w, "parent validator:", v.Hex())
+	}
+	if r.Checkpoint != nil {
+		fmt.Fprintln(w, "checkpoint:", r.Checkpoint.Hex(), "valid:", r.CheckpointValid)
+	}
+	if r.Number == 0 {
+		return
+	}
+	if r.ProposerError != "" {
+		fmt.Fprintln(w, "proposer seal: invalid:", r.ProposerError)
+	} else {
+		fmt.Fprintln(w, "proposer:", r.Proposer.Hex(), "validator:", r.ProposerValidator)
+	}
+	for _, seal := range r.Seals {
+		switch {
+		case seal.Error != "":
+			fmt.Fprintln(w, "committed seal: invalid:", seal.Error)
+		case seal.Duplicate:
+			fmt.Fprintln(w, "committed seal:", seal.Signer.Hex(), "duplicate")
+		default:
+			fmt.Fprintln(w, "committed seal:", seal.Signer.Hex(), "validator:", seal.Validator)
+		}
+	}
+	status := "reached"
+	if !r.Reached() {
+		status = "NOT reached"
+	}
+	fmt.Fprintf(w, "quorum %s: %d valid committed seals of %d validators, %d needed\n", status, r.Valid, len(r.Validators), r.Quorum)
+}
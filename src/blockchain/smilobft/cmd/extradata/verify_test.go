@@ -0,0 +1,189 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// sealedHeader returns a header proposed by the first key and committed by
+// the keys of the signers.
+func sealedHeader(t *testing.T, digest common.Hash, keys []*ecdsa.PrivateKey, signers []int) *types.Header {
+	validators := make([]common.Address, len(keys))
+	for i, key := range keys {
+		validators[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	extra, err := types.PrepareExtra(nil, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(1), MixDigest: digest, Extra: extra}
+
+	sign := func(data []byte, key *ecdsa.PrivateKey) []byte {
+		sig, err := crypto.Sign(crypto.Keccak256(data), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	bft, _ := types.ExtractBFTHeaderExtra(header)
+	bft.Seal = sign(types.SigHash(header).Bytes(), keys[0])
+	header.Extra = encodeExtra(t, header.Extra, bft)
+
+	commit := append(header.Hash().Bytes(), byte(commitCode))
+	for _, i := range signers {
+		bft.CommittedSeal = append(bft.CommittedSeal, sign(commit, keys[i]))
+	}
+	header.Extra = encodeExtra(t, header.Extra, bft)
+	return header
+}
+
+func encodeExtra(t *testing.T, extra []byte, bft *types.BFTExtra) []byte {
+	payload, err := rlp.EncodeToBytes(bft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(append([]byte{}, extra[:types.BFTExtraVanity]...), payload...)
+}
+
+func TestVerifySeals(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	outsider, _ := crypto.GenerateKey()
+	keys = append(keys, outsider)
+
+	// The sealed headers are block 10
+	var (
+		forked   = &params.ChainConfig{SixtySixPercentBlock: big.NewInt(9)}
+		unforked = &params.ChainConfig{SixtySixPercentBlock: big.NewInt(10)}
+	)
+	tests := []struct {
+		engine  string
+		digest  common.Hash
+		signers []int
+		config  *params.ChainConfig
+		valid   int
+		quorum  int
+		reached bool
+	}{
+		{"istanbul", types.BFTDigest, []int{0, 1, 2}, nil, 3, 3, true},
+		{"tendermint", types.BFTDigest, []int{0, 1}, nil, 2, 3, false},
+		{"sport", types.SportDigest, []int{0, 1, 1, 3}, forked, 3, 3, true},
+		{"sportdao", types.SportDigest, []int{0, 4, 2}, forked, 2, 3, false},
+		{"sport", types.SportDigest, []int{0, 1}, unforked, 2, 2, true},
+		{"sportdao", types.SportDigest, []int{0, 4, 2}, unforked, 2, 2, true},
+		{"sport", types.SportDigest, []int{0, 1}, &params.ChainConfig{}, 2, 2, true},
+	}
+	for _, tt := range tests {
+		engine, err := engineByName(tt.engine)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := sealedHeader(t, tt.digest, keys, tt.signers)
+		report, err := verifySeals(header, validators, engine, tt.config)
+		if err != nil {
+			t.Fatalf("%s: failed to verify: %v", tt.engine, err)
+		}
+		if report.Proposer != validators[0] || !report.ProposerValidator {
+			t.Errorf("%s: proposer mismatch: have %x, want %x", tt.engine, report.Proposer, validators[0])
+		}
+		if report.Valid != tt.valid || report.Quorum != tt.quorum || report.Reached() != tt.reached {
+			t.Errorf("%s: report mismatch: have %d/%d reached %v, want %d/%d reached %v",
+				tt.engine, report.Valid, report.Quorum, report.Reached(), tt.valid, tt.quorum, tt.reached)
+		}
+	}
+}
+
+func TestVerifySealsWithoutConfig(t *testing.T) {
+	engine, err := engineByName("sport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	header := sealedHeader(t, types.SportDigest, []*ecdsa.PrivateKey{key}, []int{0})
+	if _, err := verifySeals(header, []common.Address{crypto.PubkeyToAddress(key.PublicKey)}, engine, nil); err == nil {
+		t.Error("sport seals verified without a chain config")
+	}
+}
+
+func TestReadChainConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "genesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(`{"config": {"chainId": 10, "sixtySixPercentBlock": 310000}, "alloc": {}}`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	config, err := readChainConfig(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := config.SixtySixPercentBlock, big.NewInt(310000); have == nil || have.Cmp(want) != 0 {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestQuorums(t *testing.T) {
+	for n, want := range map[int][2]int{1: {1, 1}, 4: {3, 3}, 6: {3, 4}, 7: {5, 5}, 10: {7, 7}} {
+		if have := twoFaultyPlusOne(n); have != want[0] {
+			t.Errorf("istanbul quorum of %d: have %d, want %d", n, have, want[0])
+		}
+		if have := twoThirds(n); have != want[1] {
+			t.Errorf("two thirds quorum of %d: have %d, want %d", n, have, want[1])
+		}
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(3), Difficulty: big.NewInt(1), Extra: make([]byte, 32)}
+	blob, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json, err := header.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range [][]byte{blob, []byte(hexutil.Encode(blob) + "\n"), []byte(common.Bytes2Hex(blob)), json} {
+		have, err := readHeader(input)
+		if err != nil {
+			t.Fatalf("failed to read header %q: %v", input, err)
+		}
+		if have.Hash() != header.Hash() {
+			t.Errorf("header hash mismatch: have %x, want %x", have.Hash(), header.Hash())
+		}
+	}
+}
@@ -46,6 +46,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/core/state"
 	"go-smilo/src/blockchain/smilobft/core/types"
 	"go-smilo/src/blockchain/smilobft/eth/downloader"
+	"go-smilo/src/blockchain/smilobft/ethdb"
 	"go-smilo/src/blockchain/smilobft/trie"
 )
 
@@ -57,6 +58,7 @@ var (
 		ArgsUsage: "<genesisPath>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -73,6 +75,7 @@ It expects the genesis file as argument.`,
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
@@ -94,6 +97,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 		},
@@ -112,6 +116,7 @@ be gzipped.`,
 		ArgsUsage: "<datafile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 		},
@@ -126,6 +131,7 @@ be gzipped.`,
 		ArgsUsage: "<dumpfile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 		},
@@ -140,6 +146,7 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		ArgsUsage: "<sourceChaindataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
@@ -169,6 +176,7 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.IterativeOutputFlag,
@@ -188,6 +196,7 @@ Use "ethereum dump 0" to dump the genesis block.`,
 		ArgsUsage: " ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DatabaseEngineFlag,
 			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.TestnetFlag,
@@ -374,17 +383,8 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	stats, err := db.Stat("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
-
-	ioStats, err := db.Stat("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
-	}
-	fmt.Println(ioStats)
+	engine := rawdb.PreexistingDatabaseEngine(stack.ResolvePath("chaindata"))
+	printDatabaseStats(db, engine)
 
 	// Print the memory statistics used by the importing
 	mem := new(runtime.MemStats)
@@ -402,23 +402,29 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.Compact(nil, nil); err != nil {
+	if err := db.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.Stat("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
+	printDatabaseStats(db, engine)
+	return nil
+}
 
-	ioStats, err = db.Stat("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
+// printDatabaseStats prints the internal stats of the chain database, which
+// depend on its storage engine.
+func printDatabaseStats(db ethdb.Database, engine string) {
+	properties := []string{"leveldb.stats", "leveldb.iostats"}
+	if engine == rawdb.BoltDBEngine {
+		properties = []string{"boltdb.stats"}
+	}
+	for _, property := range properties {
+		stats, err := db.Stat(property)
+		if err != nil {
+			utils.Fatalf("Failed to read database %s: %v", property, err)
+		}
+		fmt.Println(stats)
 	}
-	fmt.Println(ioStats)
-	return nil
 }
 
 func exportChain(ctx *cli.Context) error {
@@ -513,7 +519,7 @@ func copyDb(ctx *cli.Context) error {
 	dl := downloader.New(0, chainDb, syncBloom, new(cmn.TypeMux), chain, nil, nil)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := rawdb.NewLevelDBDatabaseWithFreezer(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name)/2, 256, ctx.Args().Get(1), "")
+	db, err := rawdb.NewDiskDatabaseWithFreezer("", ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name)/2, 256, ctx.Args().Get(1), "")
 	if err != nil {
 		return err
 	}
@@ -120,20 +120,7 @@ func remoteConsole(ctx *cli.Context) error {
 	// Attach to a remotely running geth instance and start the JavaScript console
 	endpoint := ctx.Args().First()
 	if endpoint == "" {
-		path := node.DefaultDataDir()
-		if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
-			path = ctx.GlobalString(utils.DataDirFlag.Name)
-		}
-		if path != "" {
-			if ctx.GlobalBool(utils.TestnetFlag.Name) {
-				path = filepath.Join(path, "testnet")
-			} else if ctx.GlobalBool(utils.RinkebyFlag.Name) {
-				path = filepath.Join(path, "rinkeby")
-			} else if ctx.GlobalBool(utils.SportFlag.Name) {
-				path = filepath.Join(path, "sport")
-			}
-		}
-		endpoint = fmt.Sprintf("%s/geth.ipc", path)
+		endpoint = dataDirIPCEndpoint(ctx)
 	}
 	client, err := dialRPC(endpoint)
 	if err != nil {
@@ -164,6 +151,25 @@ func remoteConsole(ctx *cli.Context) error {
 	return nil
 }
 
+// dataDirIPCEndpoint returns the IPC endpoint of the node running in the data
+// directory set by the command line flags.
+func dataDirIPCEndpoint(ctx *cli.Context) string {
+	path := node.DefaultDataDir()
+	if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
+		path = ctx.GlobalString(utils.DataDirFlag.Name)
+	}
+	if path != "" {
+		if ctx.GlobalBool(utils.TestnetFlag.Name) {
+			path = filepath.Join(path, "testnet")
+		} else if ctx.GlobalBool(utils.RinkebyFlag.Name) {
+			path = filepath.Join(path, "rinkeby")
+		} else if ctx.GlobalBool(utils.SportFlag.Name) {
+			path = filepath.Join(path, "sport")
+		}
+	}
+	return fmt.Sprintf("%s/geth.ipc", path)
+}
+
 // dialRPC returns a RPC client which connects to the given endpoint.
 // The check for empty endpoint implements the defaulting logic
 // for "geth attach" and "geth monitor" with no argument.
@@ -0,0 +1,209 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of go-smilo.
+//
+// go-smilo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-smilo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-smilo. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/cmd/utils"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Manage the databases of the node",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "migrate",
+				Usage:     "Copy databases to another storage engine",
+				ArgsUsage: "[<name> ...]",
+				Action:    utils.MigrateFlags(migrateDatabases),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.DatabaseEngineFlag,
+					utils.CacheFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.SyncModeFlag,
+				},
+				Description: `
+    geth db migrate --db.engine boltdb
+
+Copies the databases of the data directory to the storage engine of --db.engine,
+by default the chain database (lightchaindata with --syncmode light). Other
+databases of the node, like the economic history, are migrated by naming them.
+The node must be stopped.
+
+Each database is copied next to itself, then swapped with its copy, keeping the
+former one in <name>.<engine>: remove it once the node runs on the new engine.
+The ancient chain segments are moved into the new chain database, unless they
+are stored elsewhere with --datadir.ancient. An interrupted migration starts
+over by running the command again.`,
+			},
+		},
+	}
+)
+
+func migrateDatabases(ctx *cli.Context) error {
+	target := ctx.GlobalString(utils.DatabaseEngineFlag.Name)
+	if target == "" {
+		utils.Fatalf("The target storage engine must be set with --%s", utils.DatabaseEngineFlag.Name)
+	}
+	names := []string(ctx.Args())
+	if len(names) == 0 {
+		names = []string{"chaindata"}
+		if ctx.GlobalString(utils.SyncModeFlag.Name) == "light" {
+			names = []string{"lightchaindata"}
+		}
+	}
+	node, _ := makeConfigNode(ctx)
+	defer node.Close()
+
+	for _, name := range names {
+		path := node.ResolvePath(name)
+		source := rawdb.PreexistingDatabaseEngine(path)
+		switch source {
+		case "":
+			utils.Fatalf("No database at %s", path)
+		case target:
+			fmt.Printf("Database %s already uses the %s engine\n", name, target)
+			continue
+		}
+		if err := migrateDatabase(ctx, path, source, target); err != nil {
+			utils.Fatalf("Failed to migrate database %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// migrateDatabase copies the database at path from the source storage engine
+// to the target one, and swaps the database with its copy.
+func migrateDatabase(ctx *cli.Context, path string, source, target string) error {
+	var (
+		cache   = ctx.GlobalInt(utils.CacheFlag.Name) / 2
+		copied  = path + ".migrating"
+		former  = path + "." + source
+		ancient = filepath.Join(path, "ancient")
+	)
+	if _, err := os.Stat(former); err == nil {
+		return fmt.Errorf("%s already exists, remove it first", former)
+	}
+	// Start over an interrupted migration
+	if err := os.RemoveAll(copied); err != nil {
+		return err
+	}
+	srcDb, err := rawdb.NewDiskDatabase(source, path, cache, 256, "")
+	if err != nil {
+		return err
+	}
+	dstDb, err := rawdb.NewDiskDatabase(target, copied, cache, 256, "")
+	if err != nil {
+		srcDb.Close()
+		return err
+	}
+	start := time.Now()
+	entries, size, err := copyDatabase(srcDb, dstDb)
+	if err == nil {
+		err = verifyDatabaseCopy(dstDb, entries)
+	}
+	srcDb.Close()
+	dstDb.Close()
+	if err != nil {
+		return err
+	}
+	// Swap the database with its copy, then move the ancient chain segments
+	if err := os.Rename(path, former); err != nil {
+		return err
+	}
+	if err := os.Rename(copied, path); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(former, "ancient")); err == nil {
+		if err := os.Rename(filepath.Join(former, "ancient"), ancient); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Migrated %s from %s to %s: %d entries, %v, in %v\n", path, source, target, entries, size, common.PrettyDuration(time.Since(start)))
+	fmt.Printf("The former database is kept in %s\n", former)
+	return nil
+}
+
+// copyDatabase copies all the key-value pairs of the source database into the
+// destination one.
+func copyDatabase(srcDb, dstDb ethdb.Database) (int, common.StorageSize, error) {
+	it := srcDb.NewIterator()
+	defer it.Release()
+
+	var (
+		batch   = dstDb.NewBatch()
+		entries int
+		size    common.StorageSize
+		start   = time.Now()
+		logged  = time.Now()
+	)
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return 0, 0, err
+		}
+		entries++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, 0, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying database", "entries", entries, "size", size, "key", common.Bytes2Hex(it.Key()), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return 0, 0, err
+	}
+	return entries, size, batch.Write()
+}
+
+// verifyDatabaseCopy checks the copy of a database has all the entries copied.
+func verifyDatabaseCopy(db ethdb.Database, entries int) error {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		count++
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if count != entries {
+		return fmt.Errorf("copy has %d entries, want %d", count, entries)
+	}
+	return nil
+}
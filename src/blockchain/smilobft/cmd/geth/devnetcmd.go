@@ -0,0 +1,210 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of go-smilo.
+//
+// go-smilo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-smilo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-smilo. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/cmd/utils"
+	"go-smilo/src/blockchain/smilobft/devnet"
+)
+
+var (
+	devnetDirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory of the devnet",
+		Value: "devnet",
+	}
+	devnetEngineFlag = cli.StringFlag{
+		Name:  "engine",
+		Usage: "Consensus engine of the devnet (" + strings.Join(devnet.Engines, ", ") + ")",
+		Value: devnet.DefaultConfig.Engine,
+	}
+	devnetValidatorsFlag = cli.IntFlag{
+		Name:  "validators",
+		Usage: "Number of validators of the devnet",
+		Value: devnet.DefaultConfig.Validators,
+	}
+	devnetChainIDFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "Chain and network id of the devnet",
+		Value: devnet.DefaultConfig.ChainID,
+	}
+	devnetPortFlag = cli.IntFlag{
+		Name:  "port",
+		Usage: "P2P port of the first node, the next nodes using the following ports",
+		Value: devnet.DefaultConfig.Port,
+	}
+	devnetRPCPortFlag = cli.IntFlag{
+		Name:  "rpcport",
+		Usage: "HTTP RPC port of the first node, the next nodes using the following ports",
+		Value: devnet.DefaultConfig.RPCPort,
+	}
+	devnetVaultFlag = cli.BoolFlag{
+		Name:  "vault",
+		Usage: "Serve a stand-in vault to each node for private transactions",
+	}
+	devnetPermissionedFlag = cli.BoolFlag{
+		Name:  "permissioned",
+		Usage: "Only allow the validators to connect to each other",
+	}
+	devnetInProcessFlag = cli.BoolFlag{
+		Name:  "inprocess",
+		Usage: "Run all the nodes in this process instead of child geth processes",
+	}
+
+	devnetCommand = cli.Command{
+		Name:     "devnet",
+		Usage:    "Run a local network of validators",
+		Category: "DEVNET COMMANDS",
+		Description: `
+Generates and runs a local network of BFT validators for development, with the
+keys, the genesis and the data directory of each node kept in one directory.
+Private transactions can be tested with stand-in vaults, which keep the
+payloads in memory instead of running Blackbox.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "init",
+				Usage:     "Generate a devnet",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(devnetInit),
+				Flags: []cli.Flag{
+					devnetDirFlag,
+					devnetEngineFlag,
+					devnetValidatorsFlag,
+					devnetChainIDFlag,
+					devnetPortFlag,
+					devnetRPCPortFlag,
+					devnetVaultFlag,
+					devnetPermissionedFlag,
+				},
+				Description: `
+    geth devnet init --engine tendermint --validators 4 --vault
+
+Generates the node keys of the validators, a genesis sealed by them with all
+the forks enabled and the data directory of each node, with its static and
+permissioned nodes and its vault configuration. The devnet directory must be
+missing or empty.`,
+			},
+			{
+				Name:      "start",
+				Usage:     "Run a devnet until interrupted",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(devnetStart),
+				Flags:     []cli.Flag{devnetDirFlag, devnetInProcessFlag},
+				Description: `
+    geth devnet start
+
+Starts the validators of the devnet, as child processes of this geth binary or
+in this process with --inprocess, and their stand-in vaults. The nodes are
+stopped on interrupt. In process, the nodes share the vault of the first node.`,
+			},
+			{
+				Name:      "reset",
+				Usage:     "Drop the chain of a devnet",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(devnetReset),
+				Flags:     []cli.Flag{devnetDirFlag},
+				Description: `
+    geth devnet reset
+
+Drops the chain of every node, keeping the keys and the genesis, so that the
+devnet restarts from its genesis block.`,
+			},
+			{
+				Name:      "teardown",
+				Usage:     "Delete a devnet",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(devnetTeardown),
+				Flags:     []cli.Flag{devnetDirFlag},
+				Description: `
+    geth devnet teardown
+
+Deletes the directory of the devnet, refusing directories without a devnet.`,
+			},
+		},
+	}
+)
+
+func devnetInit(ctx *cli.Context) error {
+	network, err := devnet.Generate(ctx.String(devnetDirFlag.Name), devnet.Config{
+		Engine:       ctx.String(devnetEngineFlag.Name),
+		Validators:   ctx.Int(devnetValidatorsFlag.Name),
+		ChainID:      ctx.Uint64(devnetChainIDFlag.Name),
+		Port:         ctx.Int(devnetPortFlag.Name),
+		RPCPort:      ctx.Int(devnetRPCPortFlag.Name),
+		Vault:        ctx.Bool(devnetVaultFlag.Name),
+		Permissioned: ctx.Bool(devnetPermissionedFlag.Name),
+	})
+	if err != nil {
+		utils.Fatalf("Failed to generate devnet: %v", err)
+	}
+	for _, node := range network.Nodes {
+		fmt.Printf("%s: address %s, rpc http://127.0.0.1:%d\n", node.Name, node.Address.Hex(), node.RPCPort)
+	}
+	return nil
+}
+
+func devnetStart(ctx *cli.Context) error {
+	network, err := devnet.Load(ctx.String(devnetDirFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to load devnet: %v", err)
+	}
+	var instance *devnet.Instance
+	if ctx.Bool(devnetInProcessFlag.Name) {
+		instance, err = network.StartInProcess()
+	} else {
+		var geth string
+		if geth, err = os.Executable(); err == nil {
+			instance, err = network.StartChildren(geth, os.Stderr)
+		}
+	}
+	if err != nil {
+		utils.Fatalf("Failed to start devnet: %v", err)
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	<-sigc
+
+	instance.Stop()
+	return nil
+}
+
+func devnetReset(ctx *cli.Context) error {
+	network, err := devnet.Load(ctx.String(devnetDirFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to load devnet: %v", err)
+	}
+	if err := network.Reset(); err != nil {
+		utils.Fatalf("Failed to reset devnet: %v", err)
+	}
+	return nil
+}
+
+func devnetTeardown(ctx *cli.Context) error {
+	if err := devnet.Teardown(ctx.String(devnetDirFlag.Name)); err != nil {
+		utils.Fatalf("Failed to tear down devnet: %v", err)
+	}
+	return nil
+}
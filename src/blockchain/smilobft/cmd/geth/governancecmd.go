@@ -0,0 +1,269 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of go-smilo.
+//
+// go-smilo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-smilo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-smilo. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/cmd/utils"
+	"go-smilo/src/blockchain/smilobft/contracts/autonity"
+)
+
+var (
+	governanceEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node (defaults to the IPC endpoint of the data directory)",
+	}
+	governanceFlags = []cli.Flag{governanceEndpointFlag, utils.DataDirFlag}
+
+	governanceCommand = cli.Command{
+		Name:     "governance",
+		Usage:    "Manage the Autonity contract",
+		Category: "GOVERNANCE COMMANDS",
+		Description: `
+Sends the governance operator transactions of the Autonity contract through the
+autonity RPC API of a running node, and reads the contract state. The node must
+have the governance operator account unlocked and expose the autonity API.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "addValidator",
+				Usage:     "Add a validator",
+				ArgsUsage: "<address> <stake> <enode>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_addValidator", "address", "big", "string")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "addStakeholder",
+				Usage:     "Add a stakeholder",
+				ArgsUsage: "<address> <enode> <stake>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_addStakeholder", "address", "string", "big")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "removeUser",
+				Usage:     "Remove a user",
+				ArgsUsage: "<address>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_removeUser", "address")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "mintStake",
+				Usage:     "Create stake for a stakeholder",
+				ArgsUsage: "<address> <amount>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_mintStake", "address", "big")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "redeemStake",
+				Usage:     "Destroy stake of a stakeholder",
+				ArgsUsage: "<address> <amount>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_redeemStake", "address", "big")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "setMinimumGasPrice",
+				Usage:     "Set the minimum gas price of the transactions",
+				ArgsUsage: "<price>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_setMinimumGasPrice", "big")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "setCommitteeSize",
+				Usage:     "Set the maximum size of the consensus committee",
+				ArgsUsage: "<size>",
+				Action:    utils.MigrateFlags(governanceSend("autonity_setCommitteeSize", "big")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "getCommittee",
+				Usage:     "Print the consensus committee",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(governanceCall("autonity_getCommittee", printJSON)),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "getAccountStake",
+				Usage:     "Print the stake of a stakeholder",
+				ArgsUsage: "<address>",
+				Action:    utils.MigrateFlags(governanceCall("autonity_getAccountStake", printBig, "address")),
+				Flags:     governanceFlags,
+			},
+			{
+				Name:      "exportEconomics",
+				Usage:     "Export the recorded economic history as CSV",
+				ArgsUsage: "<from> <to> <file> [<address>]",
+				Action:    utils.MigrateFlags(exportEconomics),
+				Flags:     governanceFlags,
+				Description: `
+Writes the economic snapshots of the Autonity contract recorded by a node running
+with --autonity.economichistory for the blocks from and to to a CSV file, a row
+per user and block. The rows can be restricted to the user of address.`,
+			},
+		},
+	}
+)
+
+// governanceSend returns the action of a command which sends an operator
+// transaction and prints its hash.
+func governanceSend(method string, kinds ...string) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		var hash common.Hash
+		if err := governanceRequest(ctx, &hash, method, kinds); err != nil {
+			utils.Fatalf("%v", err)
+		}
+		fmt.Println(hash.Hex())
+		return nil
+	}
+}
+
+// governanceCall returns the action of a command which calls a read only method
+// and prints its result.
+func governanceCall(method string, print func(json.RawMessage) error, kinds ...string) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		var result json.RawMessage
+		if err := governanceRequest(ctx, &result, method, kinds); err != nil {
+			utils.Fatalf("%v", err)
+		}
+		if err := print(result); err != nil {
+			utils.Fatalf("Invalid result: %v", err)
+		}
+		return nil
+	}
+}
+
+func printJSON(result json.RawMessage) error {
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func printBig(result json.RawMessage) error {
+	var n hexutil.Big
+	if err := json.Unmarshal(result, &n); err != nil {
+		return err
+	}
+	fmt.Println(n.ToInt())
+	return nil
+}
+
+// exportEconomics writes the economic history of a block range to a CSV file.
+func exportEconomics(ctx *cli.Context) error {
+	if ctx.NArg() != 3 && ctx.NArg() != 4 {
+		utils.Fatalf("This command requires 3 or 4 arguments.")
+	}
+	kinds := []string{"block", "block"}
+	if ctx.NArg() == 4 {
+		kinds = append(kinds, "address")
+	}
+	argv := append(ctx.Args()[:2:2], ctx.Args()[3:]...)
+	args, err := parseGovernanceArgs(argv, kinds)
+	if err != nil {
+		utils.Fatalf("%v", err)
+	}
+	var snapshots []*autonity.EconomicSnapshot
+	if err := governanceRPC(ctx, &snapshots, "autonity_getEconomicHistory", args...); err != nil {
+		utils.Fatalf("%v", err)
+	}
+
+	f, err := os.Create(ctx.Args().Get(2))
+	if err != nil {
+		utils.Fatalf("Could not create the export file: %v", err)
+	}
+	defer f.Close()
+	if err := autonity.WriteEconomicCSV(f, snapshots); err != nil {
+		utils.Fatalf("Export error: %v", err)
+	}
+	fmt.Printf("Exported %d blocks\n", len(snapshots))
+	return nil
+}
+
+// governanceRequest parses the command arguments of the given kinds and calls
+// method on the node.
+func governanceRequest(ctx *cli.Context, result interface{}, method string, kinds []string) error {
+	if ctx.NArg() != len(kinds) {
+		return fmt.Errorf("expected %d arguments, have %d", len(kinds), ctx.NArg())
+	}
+	args, err := parseGovernanceArgs(ctx.Args(), kinds)
+	if err != nil {
+		return err
+	}
+	return governanceRPC(ctx, result, method, args...)
+}
+
+// parseGovernanceArgs converts the command line arguments to the RPC arguments
+// of the given kinds.
+func parseGovernanceArgs(argv []string, kinds []string) ([]interface{}, error) {
+	args := make([]interface{}, len(kinds))
+	for i, kind := range kinds {
+		arg := argv[i]
+		switch kind {
+		case "address":
+			if !common.IsHexAddress(arg) {
+				return nil, fmt.Errorf("invalid address %q", arg)
+			}
+			args[i] = common.HexToAddress(arg)
+		case "big":
+			n, ok := math.ParseBig256(arg)
+			if !ok {
+				return nil, fmt.Errorf("invalid number %q", arg)
+			}
+			args[i] = (*hexutil.Big)(n)
+		case "block":
+			if arg == "latest" {
+				args[i] = arg
+				break
+			}
+			n, ok := math.ParseUint64(arg)
+			if !ok {
+				return nil, fmt.Errorf("invalid block number %q", arg)
+			}
+			args[i] = hexutil.Uint64(n)
+		default:
+			args[i] = arg
+		}
+	}
+	return args, nil
+}
+
+// governanceRPC calls method on the node.
+func governanceRPC(ctx *cli.Context, result interface{}, method string, args ...interface{}) error {
+	endpoint := ctx.String(governanceEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = dataDirIPCEndpoint(ctx)
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		return fmt.Errorf("unable to attach to the node: %v", err)
+	}
+	defer client.Close()
+
+	timeout, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return client.CallContext(timeout, result, method, args...)
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of go-smilo.
+//
+// go-smilo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-smilo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-smilo. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"go-smilo/src/blockchain/smilobft/contracts/autonity"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+var governanceTestHash = common.HexToHash("0x3e6a0c13a6b49cbb35a7f05b4b11e4e0e7c4e45d0a0d2a6b91e3b0d1e5c2f7a1")
+
+// governanceTestService is an autonity RPC API recording the calls of the
+// governance command.
+type governanceTestService struct {
+	mu        sync.Mutex
+	calls     []string
+	snapshots []*autonity.EconomicSnapshot
+}
+
+func (s *governanceTestService) record(method string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, fmt.Sprint(append([]interface{}{method}, args...)...))
+}
+
+func (s *governanceTestService) AddValidator(address common.Address, stake hexutil.Big, enode string) common.Hash {
+	s.record("addValidator", address.Hex(), " ", stake.ToInt(), " ", enode)
+	return governanceTestHash
+}
+
+func (s *governanceTestService) AddStakeholder(address common.Address, enode string, stake hexutil.Big) common.Hash {
+	s.record("addStakeholder", address.Hex(), " ", enode, " ", stake.ToInt())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) RemoveUser(address common.Address) common.Hash {
+	s.record("removeUser", address.Hex())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) MintStake(account common.Address, amount hexutil.Big) common.Hash {
+	s.record("mintStake", account.Hex(), " ", amount.ToInt())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) RedeemStake(account common.Address, amount hexutil.Big) common.Hash {
+	s.record("redeemStake", account.Hex(), " ", amount.ToInt())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) SetMinimumGasPrice(price hexutil.Big) common.Hash {
+	s.record("setMinimumGasPrice", price.ToInt())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) SetCommitteeSize(size hexutil.Big) common.Hash {
+	s.record("setCommitteeSize", size.ToInt())
+	return governanceTestHash
+}
+
+func (s *governanceTestService) GetCommittee(blockNr *rpc.BlockNumber) []map[string]string {
+	s.record("getCommittee")
+	return []map[string]string{{"address": "0x1000000000000000000000000000000000000001"}}
+}
+
+func (s *governanceTestService) GetAccountStake(account common.Address, blockNr *rpc.BlockNumber) *hexutil.Big {
+	s.record("getAccountStake", account.Hex())
+	return (*hexutil.Big)(big.NewInt(1234))
+}
+
+func (s *governanceTestService) GetEconomicHistory(from, to rpc.BlockNumber, address *common.Address) []*autonity.EconomicSnapshot {
+	if address != nil {
+		s.record("getEconomicHistory", from.Int64(), " ", to.Int64(), " ", address.Hex())
+	} else {
+		s.record("getEconomicHistory", from.Int64(), " ", to.Int64())
+	}
+	return s.snapshots
+}
+
+// startGovernanceTestServer serves the autonity API of service on an IPC
+// endpoint in dir and returns the endpoint and a function stopping the server.
+func startGovernanceTestServer(t *testing.T, dir string, service *governanceTestService) (string, func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported")
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("autonity", service); err != nil {
+		t.Fatal(err)
+	}
+	endpoint := filepath.Join(dir, "autonity.ipc")
+	listener, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.ServeListener(listener)
+	return endpoint, func() {
+		listener.Close()
+		server.Stop()
+	}
+}
+
+func TestGovernanceCommands(t *testing.T) {
+	address := "0x1000000000000000000000000000000000000001"
+	enode := "enode://1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439@127.0.0.1:30303"
+	tests := []struct {
+		args   []string
+		output string
+		call   string
+	}{
+		{
+			args:   []string{"addValidator", address, "100", enode},
+			output: governanceTestHash.Hex(),
+			call:   "addValidator" + address + " 100 " + enode,
+		},
+		{
+			args:   []string{"addStakeholder", address, enode, "50"},
+			output: governanceTestHash.Hex(),
+			call:   "addStakeholder" + address + " " + enode + " 50",
+		},
+		{
+			args:   []string{"removeUser", address},
+			output: governanceTestHash.Hex(),
+			call:   "removeUser" + address,
+		},
+		{
+			args:   []string{"mintStake", address, "7"},
+			output: governanceTestHash.Hex(),
+			call:   "mintStake" + address + " 7",
+		},
+		{
+			args:   []string{"redeemStake", address, "0x10"},
+			output: governanceTestHash.Hex(),
+			call:   "redeemStake" + address + " 16",
+		},
+		{
+			args:   []string{"setMinimumGasPrice", "5000"},
+			output: governanceTestHash.Hex(),
+			call:   "setMinimumGasPrice5000",
+		},
+		{
+			args:   []string{"setCommitteeSize", "4"},
+			output: governanceTestHash.Hex(),
+			call:   "setCommitteeSize4",
+		},
+		{
+			args:   []string{"getCommittee"},
+			output: "[\n  {\n    \"address\": \"" + address + "\"\n  }\n]",
+			call:   "getCommittee",
+		},
+		{
+			args:   []string{"getAccountStake", address},
+			output: "1234",
+			call:   "getAccountStake" + address,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.args[0], func(t *testing.T) {
+			dir := tmpdir(t)
+			defer os.RemoveAll(dir)
+			service := new(governanceTestService)
+			endpoint, stop := startGovernanceTestServer(t, dir, service)
+			defer stop()
+
+			args := []string{"governance", test.args[0], "--endpoint", endpoint}
+			geth := runGeth(t, append(args, test.args[1:]...)...)
+			geth.Expect(test.output + "\n")
+			geth.ExpectExit()
+
+			if want := []string{test.call}; !reflect.DeepEqual(service.calls, want) {
+				t.Errorf("have %v, want %v", service.calls, want)
+			}
+		})
+	}
+}
+
+func TestGovernanceExportEconomics(t *testing.T) {
+	address := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	snapshots := []*autonity.EconomicSnapshot{
+		{
+			Number:          1,
+			MinGasPrice:     big.NewInt(5000),
+			StakeSupply:     big.NewInt(150),
+			OperatorBalance: big.NewInt(10),
+			BlockReward:     big.NewInt(3),
+			Users: []autonity.EconomicUserSnapshot{
+				{Address: address, Type: 2, Stake: big.NewInt(100), Balance: big.NewInt(20), CommissionRate: big.NewInt(0), Reward: big.NewInt(2)},
+			},
+		},
+		{
+			Number:          2,
+			MinGasPrice:     big.NewInt(5000),
+			StakeSupply:     big.NewInt(150),
+			OperatorBalance: big.NewInt(10),
+			BlockReward:     big.NewInt(0),
+			Users: []autonity.EconomicUserSnapshot{
+				{Address: address, Type: 2, Stake: big.NewInt(100), Balance: big.NewInt(22), CommissionRate: big.NewInt(0), Reward: big.NewInt(0)},
+			},
+		},
+	}
+	var want bytes.Buffer
+	if err := autonity.WriteEconomicCSV(&want, snapshots); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		call string
+	}{
+		{[]string{"1", "2"}, "getEconomicHistory1 2"},
+		{[]string{"1", "latest"}, "getEconomicHistory1 -1"},
+		{[]string{"1", "2", address.Hex()}, "getEconomicHistory1 2 " + address.Hex()},
+	}
+	for _, test := range tests {
+		dir := tmpdir(t)
+		defer os.RemoveAll(dir)
+		service := &governanceTestService{snapshots: snapshots}
+		endpoint, stop := startGovernanceTestServer(t, dir, service)
+		defer stop()
+		file := filepath.Join(dir, "economics.csv")
+
+		args := []string{"governance", "exportEconomics", "--endpoint", endpoint, test.args[0], test.args[1], file}
+		geth := runGeth(t, append(args, test.args[2:]...)...)
+		geth.Expect("Exported 2 blocks\n")
+		geth.ExpectExit()
+
+		if wantCalls := []string{test.call}; !reflect.DeepEqual(service.calls, wantCalls) {
+			t.Errorf("%v: have %v, want %v", test.args, service.calls, wantCalls)
+		}
+		have, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(have, want.Bytes()) {
+			t.Errorf("%v: have %s, want %s", test.args, have, want.Bytes())
+		}
+	}
+}
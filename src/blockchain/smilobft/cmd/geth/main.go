@@ -74,6 +74,7 @@ var (
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.DatabaseEngineFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
@@ -137,6 +138,7 @@ var (
 		utils.MinerLegacyExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
+		utils.MinerContractAnalysisFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -169,6 +171,9 @@ var (
 		utils.SportDAORequestTimeoutFlag,
 		utils.SportDAOBlockPeriodFlag,
 		utils.EnableNodePermissionFlag,
+		utils.ConsensusTraceFileFlag,
+		utils.EconomicHistoryFlag,
+		utils.EconomicHistoryDBFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -243,6 +248,14 @@ func init() {
 		licenseCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See governancecmd.go
+		governanceCommand,
+		// See devnetcmd.go
+		devnetCommand,
+		// See dbcmd.go
+		dbCommand,
+		// See prunecmd.go
+		pruneStateCommand,
 		// See retesteth.go
 		//retestethCommand,
 	}
@@ -0,0 +1,113 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of go-smilo.
+//
+// go-smilo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-smilo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-smilo. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/cmd/utils"
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+)
+
+var (
+	pruneKeepFlag = cli.Uint64Flag{
+		Name:  "keep",
+		Usage: "Number of recent blocks whose public and vault states are kept",
+		Value: core.TriesInMemory,
+	}
+	pruneDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Report the reclaimable space without deleting anything",
+	}
+
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Delete the public and vault states of old blocks",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DatabaseEngineFlag,
+			utils.CacheFlag,
+			utils.TestnetFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.SyncModeFlag,
+			pruneKeepFlag,
+			pruneDryRunFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command deletes the trie nodes of the public and vault states
+which aren't reachable from the states of the last --keep blocks, or from the
+genesis state, and compacts the database. The node must be stopped.
+
+Only the states present in the database are kept: a full node only flushes a
+few recent states, an archive node all of them.
+
+An interrupted pruning is resumed by running the command again, whatever its
+flags. With --dry-run, the command prints the database statistics of inspect
+and the space the pruning would reclaim.`,
+	}
+)
+
+func pruneState(ctx *cli.Context) error {
+	keep := ctx.Uint64(pruneKeepFlag.Name)
+	if keep == 0 {
+		utils.Fatalf("At least one block state must be kept")
+	}
+	dryRun := ctx.Bool(pruneDryRunFlag.Name)
+
+	node, _ := makeConfigNode(ctx)
+	defer node.Close()
+
+	chainDb := utils.MakeChainDatabase(ctx, node)
+	defer chainDb.Close()
+
+	if dryRun {
+		if err := rawdb.InspectDatabase(chainDb); err != nil {
+			utils.Fatalf("Failed to inspect the database: %v", err)
+		}
+	}
+	report, err := core.PruneState(chainDb, keep, dryRun)
+	if err != nil {
+		utils.Fatalf("Failed to prune the state: %v", err)
+	}
+	action := "Deleted"
+	if dryRun {
+		action = "Reclaimable"
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"State", "Nodes", "Size"})
+	table.AppendBulk([][]string{
+		{"Trie nodes and codes", fmt.Sprint(report.Nodes), report.Size.String()},
+		{"Reachable from the kept states", fmt.Sprint(report.Nodes - report.Unreachable), (report.Size - report.Reclaimable).String()},
+		{action, fmt.Sprint(report.Unreachable), report.Reclaimable.String()},
+	})
+	table.Render()
+	if report.Resumed {
+		fmt.Println("Resumed an interrupted pruning, the figures only cover the rest of it")
+	}
+	fmt.Printf("Kept %d public and vault state roots\n", len(report.Roots))
+	return nil
+}
@@ -72,6 +72,7 @@ var AppHelpFlagGroups = []flagGroup{
 			configFileFlag,
 			utils.DataDirFlag,
 			utils.AncientFlag,
+			utils.DatabaseEngineFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.SmartCardDaemonPathFlag,
@@ -229,6 +230,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerfiyFlag,
+			utils.MinerContractAnalysisFlag,
 		},
 	},
 	{
@@ -281,6 +283,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.SportRequestTimeoutFlag,
 			utils.SportBlockPeriodFlag,
+			utils.ConsensusTraceFileFlag,
 		},
 	},
 	{
@@ -305,6 +308,13 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MinBlocksEmptyMiningFlag,
 		},
 	},
+	{
+		Name: "AUTONITY",
+		Flags: []cli.Flag{
+			utils.EconomicHistoryFlag,
+			utils.EconomicHistoryDBFlag,
+		},
+	},
 }
 
 // byCategory sorts an array of flagGroup by Name in the order
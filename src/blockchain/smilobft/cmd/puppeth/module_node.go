@@ -27,6 +27,7 @@ import (
 	"text/template"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -38,11 +39,19 @@ ADD genesis.json /genesis.json
 {{if .Unlock}}
 	ADD signer.json /signer.json
 	ADD signer.pass /signer.pass
+{{end}}{{if .NodeKey}}
+	ADD nodekey /nodekey
+{{end}}{{if .Permissioned}}
+	ADD permissioned-nodes.json /permissioned-nodes.json
+{{end}}{{if .Vault}}
+	ADD blackbox.conf /blackbox.conf
+	ENV VAULT_IPC /blackbox.conf
 {{end}}
 RUN \
   echo 'geth --cache 512 init /genesis.json' > geth.sh && \{{if .Unlock}}
-	echo 'mkdir -p /root/.ethereum/keystore/ && cp /signer.json /root/.ethereum/keystore/' >> geth.sh && \{{end}}
-	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}}' >> geth.sh
+	echo 'mkdir -p /root/.ethereum/keystore/ && cp /signer.json /root/.ethereum/keystore/' >> geth.sh && \{{end}}{{if .Permissioned}}
+	echo 'cp /permissioned-nodes.json /root/.ethereum/' >> geth.sh && \{{end}}
+	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} {{if .NodeKey}}--nodekey /nodekey --mine{{end}} {{if .Permissioned}}--permissioned{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}}' >> geth.sh
 
 ENTRYPOINT ["/bin/sh", "geth.sh"]
 `
@@ -79,12 +88,18 @@ services:
     restart: always
 `
 
+// nodeBlackboxConfig is the configuration pointing the node to the socket of its
+// Blackbox vault, which runs next to it and shares its data directory.
+var nodeBlackboxConfig = `socket = "blackbox.ipc"
+workdir = "/root/.ethereum/blackbox"
+`
+
 // deployNode deploys a new Ethereum node container to a remote machine via SSH,
 // docker and docker-compose. If an instance with the specified network name
 // already exists there, it will be overwritten!
 func deployNode(client *sshClient, network string, bootnodes []string, config *nodeInfos, nocache bool) ([]byte, error) {
 	kind := "sealnode"
-	if config.keyJSON == "" && config.etherbase == "" {
+	if config.keyJSON == "" && config.etherbase == "" && config.nodeKey == "" {
 		kind = "bootnode"
 		bootnodes = make([]string, 0)
 	}
@@ -98,18 +113,21 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 	}
 	dockerfile := new(bytes.Buffer)
 	template.Must(template.New("").Parse(nodeDockerfile)).Execute(dockerfile, map[string]interface{}{
-		"NetworkID": config.network,
-		"Port":      config.port,
-		"IP":        client.address,
-		"Peers":     config.peersTotal,
-		"LightFlag": lightFlag,
-		"Bootnodes": strings.Join(bootnodes, ","),
-		"Ethstats":  config.ethstats,
-		"Etherbase": config.etherbase,
-		"GasTarget": uint64(1000000 * config.gasTarget),
-		"GasLimit":  uint64(1000000 * config.gasLimit),
-		"GasPrice":  uint64(1000000000 * config.gasPrice),
-		"Unlock":    config.keyJSON != "",
+		"NetworkID":    config.network,
+		"Port":         config.port,
+		"IP":           client.address,
+		"Peers":        config.peersTotal,
+		"LightFlag":    lightFlag,
+		"Bootnodes":    strings.Join(bootnodes, ","),
+		"Ethstats":     config.ethstats,
+		"Etherbase":    config.etherbase,
+		"GasTarget":    uint64(1000000 * config.gasTarget),
+		"GasLimit":     uint64(1000000 * config.gasLimit),
+		"GasPrice":     uint64(1000000000 * config.gasPrice),
+		"Unlock":       config.keyJSON != "",
+		"NodeKey":      config.nodeKey != "",
+		"Permissioned": len(config.permissioned) > 0,
+		"Vault":        config.vault,
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
 
@@ -136,6 +154,16 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 		files[filepath.Join(workdir, "signer.json")] = []byte(config.keyJSON)
 		files[filepath.Join(workdir, "signer.pass")] = []byte(config.keyPass)
 	}
+	if config.nodeKey != "" {
+		files[filepath.Join(workdir, "nodekey")] = []byte(config.nodeKey)
+	}
+	if len(config.permissioned) > 0 {
+		permissioned, _ := json.MarshalIndent(config.permissioned, "", "  ")
+		files[filepath.Join(workdir, "permissioned-nodes.json")] = permissioned
+	}
+	if config.vault {
+		files[filepath.Join(workdir, "blackbox.conf")] = []byte(nodeBlackboxConfig)
+	}
 	// Upload the deployment files to the remote server (and clean up afterwards)
 	if out, err := client.Upload(files); err != nil {
 		return out, err
@@ -167,6 +195,10 @@ type nodeInfos struct {
 	gasTarget  float64
 	gasLimit   float64
 	gasPrice   float64
+
+	nodeKey      string   // Hex node key of a BFT validator
+	permissioned []string // Enodes allowed to connect, any if empty
+	vault        bool     // Whether private transactions go through a Blackbox vault
 }
 
 // Report converts the typed struct into a plain string->string map, containing
@@ -179,6 +211,12 @@ func (info *nodeInfos) Report() map[string]string {
 		"Peer count (light nodes)": strconv.Itoa(info.peersLight),
 		"Ethstats username":        info.ethstats,
 	}
+	if len(info.permissioned) > 0 {
+		report["Permissioned nodes"] = strconv.Itoa(len(info.permissioned))
+	}
+	if info.vault {
+		report["Blackbox vault"] = "enabled"
+	}
 	if info.gasTarget > 0 {
 		// Miner or signer node
 		report["Gas price (minimum accepted)"] = fmt.Sprintf("%0.3f GWei", info.gasPrice)
@@ -190,6 +228,14 @@ func (info *nodeInfos) Report() map[string]string {
 			report["Ethash directory"] = info.ethashdir
 			report["Miner account"] = info.etherbase
 		}
+		if info.nodeKey != "" {
+			// BFT validator, identified by its node key
+			if key, err := crypto.HexToECDSA(info.nodeKey); err == nil {
+				report["Validator enode ID"] = fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:])
+			} else {
+				log.Error("Failed to retrieve validator node ID", "err", err)
+			}
+		}
 		if info.keyJSON != "" {
 			// Clique proof-of-authority signer
 			var key struct {
@@ -246,6 +292,16 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /signer.pass", network, kind)); err == nil {
 		keyPass = string(bytes.TrimSpace(out))
 	}
+	nodeKey := ""
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /nodekey", network, kind)); err == nil {
+		nodeKey = string(bytes.TrimSpace(out))
+	}
+	var permissioned []string
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /permissioned-nodes.json", network, kind)); err == nil {
+		if err := json.Unmarshal(out, &permissioned); err != nil {
+			log.Warn("Failed to parse permissioned nodes", "err", err)
+		}
+	}
 	// Run a sanity check to see if the devp2p is reachable
 	port := infos.portmap[infos.envvars["PORT"]]
 	if err = checkPort(client.server, port); err != nil {
@@ -266,6 +322,10 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 		gasTarget:  gasTarget,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
+
+		nodeKey:      nodeKey,
+		permissioned: permissioned,
+		vault:        infos.envvars["VAULT_IPC"] != "",
 	}
 	stats.enode = string(enode)
 
@@ -59,6 +59,10 @@ func (w *wizard) makeGenesis() {
 	fmt.Println("Which consensus engine to use? (default = clique)")
 	fmt.Println(" 1. Ethash - proof-of-work")
 	fmt.Println(" 2. Clique - proof-of-authority")
+	fmt.Println(" 3. Sport - BFT proof-of-authority")
+	fmt.Println(" 4. SportDAO - BFT governed by the Autonity contract")
+	fmt.Println(" 5. Istanbul - BFT governed by the Autonity contract")
+	fmt.Println(" 6. Tendermint - BFT governed by the Autonity contract")
 
 	choice := w.read()
 	switch {
@@ -105,6 +109,18 @@ func (w *wizard) makeGenesis() {
 			copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
 		}
 
+	case choice == "3":
+		w.makeBFTGenesis(genesis, engineSport)
+
+	case choice == "4":
+		w.makeBFTGenesis(genesis, engineSportDAO)
+
+	case choice == "5":
+		w.makeBFTGenesis(genesis, engineIstanbul)
+
+	case choice == "6":
+		w.makeBFTGenesis(genesis, engineTendermint)
+
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
 	}
@@ -0,0 +1,234 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// BFT consensus engines puppeth can create a genesis for.
+const (
+	engineSport      = "sport"
+	engineSportDAO   = "sportdao"
+	engineIstanbul   = "istanbul"
+	engineTendermint = "tendermint"
+)
+
+// makeBFTGenesis configures the genesis of one of the BFT consensus engines:
+// the engine parameters, the initial validators embedded in the extra-data,
+// the Autonity contract users of the engines governed by it and the Smilo
+// chain parameters.
+func (w *wizard) makeBFTGenesis(genesis *core.Genesis, engine string) {
+	// We have to use '1' to have TD == BlockNumber for BFT consensus
+	genesis.Difficulty = big.NewInt(1)
+	genesis.Mixhash = types.BFTDigest
+
+	fmt.Println()
+	fmt.Println("How many blocks should an epoch last? (default = 30000)")
+	epoch := uint64(w.readDefaultInt(30000))
+
+	fmt.Println()
+	fmt.Println("Which policy should select the block proposers? (default = 0)")
+	fmt.Println(" 0. Round robin - next proposer after each block")
+	fmt.Println(" 1. Sticky - same proposer until a round change")
+	policy := uint64(w.readDefaultInt(0))
+	if policy > 1 {
+		log.Crit("Invalid proposer policy choice", "choice", policy)
+	}
+
+	switch engine {
+	case engineSport, engineSportDAO:
+		genesis.Mixhash = types.SportDigest
+
+		fmt.Println()
+		fmt.Println("What minimum funds should a fullnode hold to seal? (default = 1)")
+		minFunds := int64(w.readDefaultInt(1))
+
+		if engine == engineSport {
+			genesis.Config.Sport = &params.SportConfig{Epoch: epoch, SpeakerPolicy: policy, MinFunds: minFunds}
+		} else {
+			genesis.Config.SportDAO = &params.SportDAOConfig{Epoch: epoch, SpeakerPolicy: policy, MinFunds: minFunds}
+		}
+
+	case engineIstanbul, engineTendermint:
+		fmt.Println()
+		fmt.Println("How many seconds should blocks take? (default = 1)")
+		period := uint64(w.readDefaultInt(1))
+
+		fmt.Println()
+		fmt.Println("How many milliseconds should a round last before changing? (default = 10000)")
+		timeout := uint64(w.readDefaultInt(10000))
+
+		if engine == engineIstanbul {
+			genesis.Config.Istanbul = &params.IstanbulConfig{Epoch: epoch, ProposerPolicy: policy, BlockPeriod: period, RequestTimeout: timeout}
+		} else {
+			genesis.Config.Tendermint = &params.TendermintConfig{Epoch: epoch, ProposerPolicy: policy, BlockPeriod: period, RequestTimeout: timeout}
+		}
+	}
+
+	// Sport fullnodes are plain accounts, the other engines are governed by the
+	// Autonity contract and their validators are identified by their enodes
+	var validators []common.Address
+	if engine == engineSport {
+		fmt.Println()
+		fmt.Println("Which accounts are allowed to seal? (mandatory at least one)")
+		for {
+			if address := w.readAddress(); address != nil {
+				validators = append(validators, *address)
+				continue
+			}
+			if len(validators) > 0 {
+				break
+			}
+		}
+	} else {
+		contract := w.readAutonityContract()
+		for _, user := range contract.Users {
+			validators = append(validators, user.Address)
+		}
+		genesis.Config.AutonityContractConfig = contract
+	}
+	extra, err := bftExtraData(validators)
+	if err != nil {
+		log.Crit("Failed to encode the validators", "err", err)
+	}
+	genesis.ExtraData = extra
+
+	w.readSmiloConfig(genesis.Config)
+}
+
+// readAutonityContract reads the validators, the governance operator and the
+// minimum gas price of the Autonity contract.
+func (w *wizard) readAutonityContract() *params.AutonityContractGenesis {
+	contract := new(params.AutonityContractGenesis)
+
+	fmt.Println()
+	fmt.Println("What stake should each validator hold? (default = 1)")
+	stake := uint64(w.readDefaultInt(1))
+
+	fmt.Println()
+	fmt.Println("Which enodes are allowed to validate? (mandatory at least one)")
+	for {
+		if node := w.readEnode(); node != nil {
+			contract.Users = append(contract.Users, params.User{
+				Address: params.EnodeToAddress(node),
+				Enode:   node.String(),
+				Type:    params.UserValidator,
+				Stake:   stake,
+			})
+			continue
+		}
+		if len(contract.Users) > 0 {
+			break
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Which account should operate the governance? (default = %s)\n", params.DefaultGovernance.Hex())
+	contract.Operator = w.readDefaultAddress(params.DefaultGovernance)
+
+	fmt.Println()
+	fmt.Printf("Which account should deploy the contract? (default = %s)\n", params.DefaultDeployer.Hex())
+	contract.Deployer = w.readDefaultAddress(params.DefaultDeployer)
+
+	fmt.Println()
+	fmt.Println("What minimum gas price should the contract require (wei)? (default = 0)")
+	contract.MinGasPrice = uint64(w.readDefaultInt(0))
+
+	// Validate against the default contract, which is left out of the genesis
+	// file and filled in when the genesis is committed
+	check := *contract
+	if err := check.AddDefault().Validate(); err != nil {
+		log.Crit("Invalid Autonity contract configuration", "err", err)
+	}
+	return contract
+}
+
+// readSmiloConfig reads the Smilo specific chain parameters.
+func (w *wizard) readSmiloConfig(config *params.ChainConfig) {
+	config.IsSmilo = true
+
+	fmt.Println()
+	fmt.Println("Should transactions pay gas? (default = yes)")
+	config.IsGas = w.readDefaultYesNo(true)
+
+	if config.IsGas {
+		fmt.Println()
+		fmt.Println("Should the unused gas be refunded? (default = yes)")
+		config.IsGasRefunded = w.readDefaultYesNo(true)
+	}
+	fmt.Println()
+	fmt.Println("What minimum funds should an account hold to transact? (default = 1)")
+	config.RequiredMinFunds = int64(w.readDefaultInt(1))
+
+	for {
+		fmt.Println()
+		fmt.Println("What maximum size should transactions have (KB, 32 to 128)? (default = 32)")
+		config.CustomTransactionSizeLimit = uint64(w.readDefaultInt(32))
+		if err := config.IsValid(); err != nil {
+			log.Error("Invalid transaction size limit", "err", err)
+			continue
+		}
+		break
+	}
+}
+
+// readEnode reads a single line from stdin, trimming it from spaces and
+// converting it to an enode. An empty line returns nil.
+func (w *wizard) readEnode() *enode.Node {
+	for {
+		text := w.read()
+		if text == "" {
+			return nil
+		}
+		node, err := enode.ParseV4(text)
+		if err != nil {
+			log.Error("Invalid enode", "err", err)
+			continue
+		}
+		return node
+	}
+}
+
+// bftExtraData returns the genesis extra-data embedding the initial validators,
+// in the layout shared by the BFT engines.
+func bftExtraData(validators []common.Address) ([]byte, error) {
+	return types.PrepareExtra(nil, validators)
+}
+
+// bftEngine returns the BFT consensus engine of the chain, empty if none.
+func bftEngine(config *params.ChainConfig) string {
+	switch {
+	case config.Sport != nil:
+		return engineSport
+	case config.SportDAO != nil:
+		return engineSportDAO
+	case config.Istanbul != nil:
+		return engineIstanbul
+	case config.Tendermint != nil:
+		return engineTendermint
+	}
+	return ""
+}
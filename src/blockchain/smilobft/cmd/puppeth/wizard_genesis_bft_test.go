@@ -0,0 +1,95 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// Tests that the genesis extra-data decodes to the validators for all the BFT
+// engines, and matches the extra-data set when committing the genesis.
+func TestBFTExtraData(t *testing.T) {
+	validators := []common.Address{
+		common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		common.HexToAddress("0x0000000000000000000000000000000000000001"),
+	}
+	extra, err := bftExtraData(validators)
+	if err != nil {
+		t.Fatalf("failed to encode extra-data: %v", err)
+	}
+	bft, err := types.ExtractBFTExtra(extra)
+	if err != nil {
+		t.Fatalf("failed to decode BFT extra-data: %v", err)
+	}
+	if !reflect.DeepEqual(bft.Validators, validators) {
+		t.Errorf("BFT validators mismatch: have %v, want %v", bft.Validators, validators)
+	}
+	sport, err := types.ExtractSportExtra(&types.Header{Extra: extra})
+	if err != nil {
+		t.Fatalf("failed to decode sport extra-data: %v", err)
+	}
+	if !reflect.DeepEqual(sport.Fullnodes, validators) {
+		t.Errorf("sport fullnodes mismatch: have %v, want %v", sport.Fullnodes, validators)
+	}
+
+	genesis := &core.Genesis{
+		ExtraData: extra,
+		Config: &params.ChainConfig{
+			Istanbul: &params.IstanbulConfig{},
+			AutonityContractConfig: &params.AutonityContractGenesis{
+				Users: []params.User{
+					{Address: validators[0], Type: params.UserValidator},
+					{Address: validators[1], Type: params.UserValidator},
+				},
+			},
+		},
+	}
+	if err := genesis.SetBFT(); err != nil {
+		t.Fatalf("failed to set BFT genesis: %v", err)
+	}
+	if !bytes.Equal(genesis.ExtraData, extra) {
+		t.Errorf("committed extra-data mismatch: have %x, want %x", genesis.ExtraData, extra)
+	}
+}
+
+// Tests that the permissioned nodes hold the validators and the bootnodes once.
+func TestPermissionedNodes(t *testing.T) {
+	const (
+		validator = "enode://3f1d12044546b76342d59d4a05532c14b85aa669704bfe1f864fe079415aa2c02d743e03218e57a33fb94523adb54032871a6c51b2cc5514cb7c7e35b3ed0a99@127.0.0.1:30303"
+		bootnode  = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@127.0.0.1:30304"
+	)
+	config := &params.ChainConfig{
+		AutonityContractConfig: &params.AutonityContractGenesis{
+			Users: []params.User{
+				{Enode: validator, Type: params.UserValidator},
+				{Address: common.HexToAddress("0x01"), Type: params.UserStakeHolder},
+			},
+		},
+	}
+	nodes := permissionedNodes(config, []string{bootnode, validator})
+	if want := []string{validator, bootnode}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("permissioned nodes mismatch: have %v, want %v", nodes, want)
+	}
+}
@@ -22,9 +22,11 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
 	"go-smilo/src/blockchain/smilobft/accounts/keystore"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
 // deployNode creates a new node configuration based on some user input.
@@ -147,6 +149,29 @@ func (w *wizard) deployNode(boot bool) {
 					return
 				}
 			}
+		} else if bftEngine(w.conf.Genesis.Config) != "" {
+			// BFT validators are identified by their node key, offer to reuse it
+			if infos.nodeKey != "" {
+				if key, err := crypto.HexToECDSA(infos.nodeKey); err != nil {
+					infos.nodeKey = ""
+				} else {
+					fmt.Println()
+					fmt.Printf("Reuse previous (%x) validator node key (y/n)? (default = yes)\n", crypto.FromECDSAPub(&key.PublicKey)[1:])
+					if !w.readDefaultYesNo(true) {
+						infos.nodeKey = ""
+					}
+				}
+			}
+			if infos.nodeKey == "" {
+				fmt.Println()
+				fmt.Println("What's the hex node key of the validator? (won't be echoed)")
+				infos.nodeKey = w.readPassword()
+
+				if _, err := crypto.HexToECDSA(infos.nodeKey); err != nil {
+					log.Error("Invalid validator node key", "err", err)
+					return
+				}
+			}
 		}
 		// Establish the gas dynamics to be enforced by the signer
 		fmt.Println()
@@ -161,6 +186,10 @@ func (w *wizard) deployNode(boot bool) {
 		fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", infos.gasPrice)
 		infos.gasPrice = w.readDefaultFloat(infos.gasPrice)
 	}
+	// BFT networks restrict their peers to the known nodes and may run a vault
+	if bftEngine(w.conf.Genesis.Config) != "" {
+		w.readNodePermissions(infos)
+	}
 	// Try to deploy the full node on the host
 	nocache := false
 	if existed {
@@ -181,3 +210,64 @@ func (w *wizard) deployNode(boot bool) {
 
 	w.networkStats()
 }
+
+// readNodePermissions reads whether the node only accepts the permissioned
+// nodes, the validators of the genesis and the bootnodes, and whether it runs
+// with a Blackbox vault.
+func (w *wizard) readNodePermissions(infos *nodeInfos) {
+	fmt.Println()
+	fmt.Printf("Should the node only accept the validators and the bootnodes as peers (y/n)? (default = %s)\n", yesNo(len(infos.permissioned) > 0 || infos.nodeKey != ""))
+	if w.readDefaultYesNo(len(infos.permissioned) > 0 || infos.nodeKey != "") {
+		infos.permissioned = permissionedNodes(w.conf.Genesis.Config, w.conf.bootnodes)
+
+		fmt.Println()
+		fmt.Println("Which other enodes should be allowed? (advisable the other sealnodes)")
+		for {
+			node := w.readEnode()
+			if node == nil {
+				break
+			}
+			infos.permissioned = append(infos.permissioned, node.String())
+		}
+		if len(infos.permissioned) == 0 {
+			log.Warn("No permissioned nodes, the node will accept any peer")
+		}
+	} else {
+		infos.permissioned = nil
+	}
+	fmt.Println()
+	fmt.Printf("Should private transactions go through a Blackbox vault (y/n)? (default = %s)\n", yesNo(infos.vault))
+	infos.vault = w.readDefaultYesNo(infos.vault)
+	if infos.vault {
+		log.Info("The vault must listen on blackbox/blackbox.ipc of the node's data directory")
+	}
+}
+
+// permissionedNodes returns the enodes of the validators of the genesis and of
+// the bootnodes, without duplicates.
+func permissionedNodes(config *params.ChainConfig, bootnodes []string) []string {
+	var nodes []string
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			nodes = append(nodes, url)
+		}
+	}
+	if config.AutonityContractConfig != nil {
+		for _, user := range config.AutonityContractConfig.GetValidatorUsers() {
+			add(user.Enode)
+		}
+	}
+	for _, url := range bootnodes {
+		add(url)
+	}
+	return nodes
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
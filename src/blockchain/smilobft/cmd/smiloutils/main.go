@@ -32,6 +32,11 @@ func main() {
 
 	app.Commands = []cli.Command{
 		src.TransactionCommand,
+		src.NonceCommand,
+		src.SmiloPayCommand,
+		src.VaultCommand,
+		src.ValidatorsCommand,
+		src.WhitelistCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
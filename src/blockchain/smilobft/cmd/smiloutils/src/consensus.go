@@ -0,0 +1,127 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// engineAPI is the API of a consensus engine, found by its namespace.
+type engineAPI struct {
+	namespace  string
+	validators string // Method returning the validators at a block
+	whitelist  string // Method returning the enode whitelist, empty if none
+}
+
+var engineAPIs = []engineAPI{
+	{namespace: "istanbul", validators: "istanbul_getValidators", whitelist: "istanbul_getWhitelist"},
+	{namespace: "tendermint", validators: "tendermint_getValidators", whitelist: "tendermint_getWhitelist"},
+	{namespace: "smilobftdao", validators: "smilobftdao_getValidators", whitelist: "smilobftdao_getWhitelist"},
+	{namespace: "smilobft", validators: "smilobft_getFullnodes"},
+	{namespace: "clique", validators: "clique_getSigners"},
+}
+
+// engineAPI returns the API of the consensus engine of the node.
+func (s *session) engineAPI() (*engineAPI, error) {
+	modules, err := s.rpc.SupportedModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the modules of the node: %v", err)
+	}
+	for i := range engineAPIs {
+		if _, ok := modules[engineAPIs[i].namespace]; ok {
+			return &engineAPIs[i], nil
+		}
+	}
+	return nil, errors.New("no consensus engine API enabled on the connection")
+}
+
+// validatorSet are the validators at a block.
+type validatorSet struct {
+	Engine     string           `json:"engine"`
+	Block      string           `json:"block"`
+	Validators []common.Address `json:"validators"`
+}
+
+// ShowValidators shows the validators of the consensus engine.
+func ShowValidators(ctx *cli.Context) error {
+	block, err := blockArg(ctx)
+	if err != nil {
+		return err
+	}
+	s, err := newSession(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	api, err := s.engineAPI()
+	if err != nil {
+		return err
+	}
+	set := &validatorSet{Engine: api.namespace, Block: block}
+	callctx, cancel := s.context()
+	defer cancel()
+	if err := s.rpc.CallContext(callctx, &set.Validators, api.validators, block); err != nil {
+		return fmt.Errorf("failed to get the validators: %v", err)
+	}
+	return printResult(ctx, set, func(w io.Writer) {
+		fmt.Fprintf(w, "%d validators of %s at block %s\n", len(set.Validators), set.Engine, set.Block)
+		for _, validator := range set.Validators {
+			fmt.Fprintln(w, validator.Hex())
+		}
+	})
+}
+
+// enodeWhitelist is the enode whitelist of the consensus engine.
+type enodeWhitelist struct {
+	Engine string   `json:"engine"`
+	Enodes []string `json:"enodes"`
+}
+
+// ShowWhitelist shows the enode whitelist of the consensus engine.
+func ShowWhitelist(ctx *cli.Context) error {
+	s, err := newSession(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	api, err := s.engineAPI()
+	if err != nil {
+		return err
+	}
+	if api.whitelist == "" {
+		return fmt.Errorf("the %s engine has no enode whitelist", api.namespace)
+	}
+	list := &enodeWhitelist{Engine: api.namespace}
+	callctx, cancel := s.context()
+	defer cancel()
+	if err := s.rpc.CallContext(callctx, &list.Enodes, api.whitelist); err != nil {
+		return fmt.Errorf("failed to get the whitelist: %v", err)
+	}
+	return printResult(ctx, list, func(w io.Writer) {
+		fmt.Fprintf(w, "%d enodes whitelisted by %s\n", len(list.Enodes), list.Engine)
+		for _, enode := range list.Enodes {
+			fmt.Fprintln(w, enode)
+		}
+	})
+}
@@ -17,24 +17,46 @@
 package src
 
 import (
-	"context"
 	"fmt"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common"
-
-	"go-smilo/src/blockchain/smilobft"
+	"github.com/orinocopay/go-etherutils"
+	"gopkg.in/urfave/cli.v1"
 )
 
-// EstimateGas will estimate the gas cost
-func EstimateGas(fromAddress common.Address, toAddress *common.Address, amount *big.Int, data []byte) (gas uint64, err error) {
-	msg := smilobft.CallMsg{From: fromAddress, To: toAddress, Value: amount, Data: data}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// minReplacementPrice returns the lowest gas price replacing a pooled
+// transaction of gas price price, above the 10% bump required by the pool.
+func minReplacementPrice(price *big.Int) *big.Int {
+	min := new(big.Int).Div(price, big.NewInt(10))
+	min.Add(min, price)
+	return min.Add(min, big.NewInt(10))
+}
+
+// replacementPrice returns the gas price replacing a pooled transaction of gas
+// price price, given by --gasprice or else the minimum accepted by the pool.
+func replacementPrice(ctx *cli.Context, price *big.Int) (*big.Int, error) {
+	min := minReplacementPrice(price)
+	if ctx.Uint64(gaspriceFlag.Name) == 0 {
+		return min, nil
+	}
+	gasPrice := new(big.Int).SetUint64(ctx.Uint64(gaspriceFlag.Name))
+	if gasPrice.Cmp(min) < 0 {
+		return nil, fmt.Errorf("gas price must be at least %s", etherutils.WeiToString(min, true))
+	}
+	return gasPrice, nil
+}
+
+// sendPrice returns the gas price of a new transaction, given by --gasprice or
+// else suggested by the node.
+func (s *session) sendPrice(ctx *cli.Context) (*big.Int, error) {
+	if price := ctx.Uint64(gaspriceFlag.Name); price != 0 {
+		return new(big.Int).SetUint64(price), nil
+	}
+	callctx, cancel := s.context()
 	defer cancel()
-	gas, err = client.EstimateGas(ctx, msg)
+	price, err := s.client.SuggestGasPrice(callctx)
 	if err != nil {
-		fmt.Println("estimateGas, client.EstimateGas, ", err)
-		return gas, err
+		return nil, fmt.Errorf("failed to get the gas price: %v", err)
 	}
-	return gas, err
+	return price, nil
 }
@@ -17,40 +17,92 @@
 package src
 
 import (
-	"context"
 	"fmt"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
 )
 
-//GetNextNonce Will get the next nonce for the account
-func GetNextNonce(address common.Address) (nextNonce uint64, err error) {
-	if nonce == -1 {
-		_, err = PendingNonceAt(address)
-		if err != nil {
-			return nextNonce, err
+// maxNonceGaps is the number of missing nonces above which the pooled
+// transactions are considered unreachable rather than repaired.
+const maxNonceGaps = 1024
+
+// accountNonces are the nonces of an account.
+type accountNonces struct {
+	Account common.Address `json:"account"`
+	Nonce   uint64         `json:"nonce"`   // Nonce at the head
+	Pending uint64         `json:"pending"` // Next nonce after the pending transactions
+	Pooled  []uint64       `json:"pooled"`  // Nonces of the pooled transactions
+	Gaps    []uint64       `json:"gaps"`    // Nonces missing below the pooled transactions
+}
+
+// poolTransactions returns the pending and queued transactions of the account,
+// sorted by nonce.
+func (s *session) poolTransactions(account common.Address) (types.Transactions, error) {
+	var content map[string]map[string]map[string]*types.Transaction
+	ctx, cancel := s.context()
+	defer cancel()
+	if err := s.rpc.CallContext(ctx, &content, "txpool_content"); err != nil {
+		return nil, fmt.Errorf("failed to get the transaction pool content: %v", err)
+	}
+	var txs types.Transactions
+	for _, accounts := range content {
+		for addr, pooled := range accounts {
+			if common.HexToAddress(addr) != account {
+				continue
+			}
+			for _, tx := range pooled {
+				txs = append(txs, tx)
+			}
 		}
 	}
-	nonce++
-	nextNonce = uint64(nonce)
-	return nextNonce, err
+	sort.Sort(types.TxByNonce(txs))
+	return txs, nil
 }
 
-//PendingNonceAt will get the next pending nonce
-func PendingNonceAt(address common.Address) (currentNonce uint64, err error) {
-	if nonce == -1 {
-		var tmpNonce uint64
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-		tmpNonce, err = client.PendingNonceAt(ctx, address)
-		if err != nil {
-			err = fmt.Errorf("failed to obtain nonce for %s: %v", address.Hex(), err)
-			return currentNonce, err
+// nonces returns the nonces of the account.
+func (s *session) nonces(account common.Address) (*accountNonces, error) {
+	nonces := &accountNonces{Account: account, Pooled: []uint64{}}
+
+	ctx, cancel := s.context()
+	defer cancel()
+	var err error
+	if nonces.Nonce, err = s.client.NonceAt(ctx, account, nil); err != nil {
+		return nil, fmt.Errorf("failed to get the nonce of %s: %v", account.Hex(), err)
+	}
+	if nonces.Pending, err = s.client.PendingNonceAt(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to get the pending nonce of %s: %v", account.Hex(), err)
+	}
+	txs, err := s.poolTransactions(account)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		nonces.Pooled = append(nonces.Pooled, tx.Nonce())
+	}
+	if nonces.Gaps, err = nonceGaps(nonces.Nonce, nonces.Pooled); err != nil {
+		return nil, err
+	}
+	return nonces, nil
+}
+
+// nonceGaps returns the nonces missing from next, the nonce at the head, up to
+// the highest of the sorted pooled nonces.
+func nonceGaps(next uint64, pooled []uint64) ([]uint64, error) {
+	gaps := []uint64{}
+	for _, nonce := range pooled {
+		if nonce < next {
+			continue
+		}
+		if nonce-next+uint64(len(gaps)) > maxNonceGaps {
+			return nil, fmt.Errorf("more than %d nonces missing below nonce %d", maxNonceGaps, nonce)
+		}
+		for ; next < nonce; next++ {
+			gaps = append(gaps, next)
 		}
-		nonce = int64(tmpNonce)
-		currentNonce = uint64(nonce)
-	} else {
-		currentNonce = uint64(nonce)
+		next = nonce + 1
 	}
-	return currentNonce, err
+	return gaps, nil
 }
@@ -0,0 +1,47 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+package src
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNonceGaps(t *testing.T) {
+	tests := []struct {
+		next   uint64
+		pooled []uint64
+		gaps   []uint64
+	}{
+		{next: 5, pooled: nil, gaps: []uint64{}},
+		{next: 5, pooled: []uint64{5, 6, 7}, gaps: []uint64{}},
+		{next: 5, pooled: []uint64{7}, gaps: []uint64{5, 6}},
+		{next: 5, pooled: []uint64{5, 8, 10}, gaps: []uint64{6, 7, 9}},
+		{next: 5, pooled: []uint64{3, 4, 6}, gaps: []uint64{5}},
+	}
+	for i, test := range tests {
+		gaps, err := nonceGaps(test.next, test.pooled)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(gaps, test.gaps) {
+			t.Errorf("test %d: gaps mismatch: got %v, want %v", i, gaps, test.gaps)
+		}
+	}
+	if _, err := nonceGaps(0, []uint64{maxNonceGaps + 1}); err == nil {
+		t.Error("too many gaps accepted")
+	}
+}
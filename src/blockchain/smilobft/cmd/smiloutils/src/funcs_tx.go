@@ -18,74 +18,40 @@ package src
 
 import (
 	"errors"
-	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
-// NewTransactionSigned creates a new transaction and signs it
-func NewTransactionSigned(fromAddress common.Address, toAddress *common.Address, amount *big.Int, data []byte) (signedTx *types.Transaction, err error) {
-	tx, err := NewTransaction(fromAddress, toAddress, amount, gasLimit, data)
-	if err != nil {
-		err = fmt.Errorf("failed to createSignedTransaction, createTransaction transaction: %v", err)
-		return tx, err
-	}
-
-	key, err := crypto.HexToECDSA(privatekey)
-	if err != nil {
-		fmt.Println("crypto.HexToECDSA, Invalid private key", err)
-		return nil, err
-	}
-
-	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
-	if fromAddress != keyAddr {
-		fmt.Println("crypto.PubkeyToAddress, The address of this PK does not match with the desired fromAddress")
-		return nil, errors.New("not correct PK for the account")
-	}
-
-	signer := types.NewEIP155Signer(chainID)
+// errVaultReprice is returned when upping a vault transaction, whose gas price
+// must stay zero.
+var errVaultReprice = errors.New("vault transactions can't be repriced, cancel them instead")
 
-	signedTx, err = types.SignTx(tx, signer, key)
-	if err != nil {
-		err = fmt.Errorf("failed to createSignedTransaction, signTransaction : %v", err)
-		return tx, err
-	}
-
-	GetNextNonce(fromAddress)
-
-	return signedTx, err
+// emptyTransfer returns the transfer of nothing from the account to itself,
+// used to cancel a transaction or to fill a nonce gap.
+func emptyTransfer(nonce uint64, from common.Address, gasPrice *big.Int) *types.Transaction {
+	return types.NewTransaction(nonce, from, new(big.Int), params.TxGas, gasPrice, nil)
 }
 
-// NewTransaction will create a unsigned transaction
-func NewTransaction(fromAddress common.Address, toAddress *common.Address, amount *big.Int, gasLimit uint64, data []byte) (tx *types.Transaction, err error) {
-	var txNonce uint64
-	txNonce, err = PendingNonceAt(fromAddress)
-	if err != nil {
-		fmt.Println("Error: createTransaction, currentNonce, ", err)
-		return tx, err
+// replacement returns the unsigned transaction replacing tx sent by from: an
+// empty transfer if cancel is set, or else tx itself at the new gas price.
+func replacement(tx *types.Transaction, from common.Address, gasPrice *big.Int, cancel bool) (*types.Transaction, error) {
+	if cancel {
+		return emptyTransfer(tx.Nonce(), from, gasPrice), nil
 	}
-
-	if gasLimit == 0 {
-		gasLimit, err = EstimateGas(fromAddress, toAddress, amount, data)
-		if err != nil {
-			fmt.Println("Error: createTransaction, estimateGas, ", err)
-			return tx, err
-		}
+	if tx.IsVault() {
+		return nil, errVaultReprice
 	}
-
-	if toAddress == nil {
-		tx = types.NewContractCreation(txNonce, amount, gasLimit, gasPrice, data)
-	} else {
-		tx = types.NewTransaction(txNonce, *toAddress, amount, gasLimit, gasPrice, data)
+	if tx.To() == nil {
+		return types.NewContractCreation(tx.Nonce(), tx.Value(), tx.Gas(), gasPrice, tx.Data()), nil
 	}
-
-	return tx, err
+	return types.NewTransaction(tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), gasPrice, tx.Data()), nil
 }
 
+// TxFrom returns the sender of a signed transaction.
 func TxFrom(tx *types.Transaction) (address common.Address, err error) {
 	V, _, _ := tx.RawSignatureValues()
 	signer := deriveSigner(V)
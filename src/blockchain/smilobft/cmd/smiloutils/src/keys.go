@@ -0,0 +1,79 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/accounts/keystore"
+)
+
+// loadKey loads the signing key, given in hex by --privatekey or in the
+// --keystore file unlocked with the passphrase.
+func loadKey(ctx *cli.Context) (*ecdsa.PrivateKey, error) {
+	hexkey, keyfile := ctx.String(privatekeyFlag.Name), ctx.String(keystoreFlag.Name)
+	switch {
+	case hexkey != "" && keyfile != "":
+		return nil, errors.New("--privatekey and --keystore are mutually exclusive")
+	case hexkey != "":
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(hexkey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %v", err)
+		}
+		return key, nil
+	case keyfile != "":
+		passphrase, err := readPassphrase(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return loadKeystoreKey(keyfile, passphrase)
+	}
+	return nil, errors.New("privatekey or keystore is required")
+}
+
+// readPassphrase returns the passphrase given by --passphrase or by the first
+// line of the --passwordfile.
+func readPassphrase(ctx *cli.Context) (string, error) {
+	if file := ctx.String(passwordFileFlag.Name); file != "" {
+		text, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %v", err)
+		}
+		return strings.TrimRight(strings.SplitN(string(text), "\n", 2)[0], "\r"), nil
+	}
+	return ctx.String(passphraseFlag.Name), nil
+}
+
+// loadKeystoreKey decrypts the key of a keystore file.
+func loadKeystoreKey(file, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyjson, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %v", err)
+	}
+	key, err := keystore.DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file: %v", err)
+	}
+	return key.PrivateKey, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+package src
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/accounts/keystore"
+)
+
+func keyContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range []cli.Flag{privatekeyFlag, keystoreFlag, passphraseFlag, passwordFileFlag} {
+		f.Apply(set)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smiloutils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	priv, _ := crypto.GenerateKey()
+	want := crypto.PubkeyToAddress(priv.PublicKey)
+
+	key := &keystore.Key{Id: uuid.NewRandom(), Address: want, PrivateKey: priv}
+	keyjson, err := keystore.EncryptKey(key, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyfile, passfile := filepath.Join(dir, "key.json"), filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(keyfile, keyjson, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(passfile, []byte("secret\nignored\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	valid := [][]string{
+		{"--privatekey", hexutil.Encode(crypto.FromECDSA(priv))},
+		{"--keystore", keyfile, "--passphrase", "secret"},
+		{"--keystore", keyfile, "--passwordfile", passfile},
+	}
+	for _, args := range valid {
+		loaded, err := loadKey(keyContext(t, args...))
+		if err != nil {
+			t.Errorf("%v: %v", args, err)
+			continue
+		}
+		if got := crypto.PubkeyToAddress(loaded.PublicKey); got != want {
+			t.Errorf("%v: address mismatch: got %x, want %x", args, got, want)
+		}
+	}
+	invalid := [][]string{
+		{},
+		{"--keystore", keyfile, "--passphrase", "wrong"},
+		{"--keystore", keyfile, "--privatekey", hexutil.Encode(crypto.FromECDSA(priv))},
+	}
+	for _, args := range invalid {
+		if _, err := loadKey(keyContext(t, args...)); err == nil {
+			t.Errorf("%v: invalid key accepted", args)
+		}
+	}
+}
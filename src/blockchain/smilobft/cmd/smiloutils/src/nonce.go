@@ -0,0 +1,108 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// filledNonce is a nonce gap filled with an empty transfer.
+type filledNonce struct {
+	Nonce uint64       `json:"nonce"`
+	Hash  *common.Hash `json:"hash,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// ShowNonce shows the nonces and the nonce gaps of an account.
+func ShowNonce(ctx *cli.Context) error {
+	account, err := accountArg(ctx)
+	if err != nil {
+		return err
+	}
+	s, err := newSession(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	nonces, err := s.nonces(account)
+	if err != nil {
+		return err
+	}
+	return printResult(ctx, nonces, func(w io.Writer) {
+		fmt.Fprintf(w, "Account: %s\n", nonces.Account.Hex())
+		fmt.Fprintf(w, "Nonce:   %d\n", nonces.Nonce)
+		fmt.Fprintf(w, "Pending: %d\n", nonces.Pending)
+		fmt.Fprintf(w, "Pooled:  %v\n", nonces.Pooled)
+		fmt.Fprintf(w, "Gaps:    %v\n", nonces.Gaps)
+	})
+}
+
+// RepairNonce fills the nonce gaps of the signing account with empty transfers.
+func RepairNonce(ctx *cli.Context) error {
+	s, err := newSession(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	nonces, err := s.nonces(s.from())
+	if err != nil {
+		return err
+	}
+	filled := []*filledNonce{}
+	if len(nonces.Gaps) > 0 {
+		gasPrice, err := s.sendPrice(ctx)
+		if err != nil {
+			return err
+		}
+		for _, nonce := range nonces.Gaps {
+			fill := &filledNonce{Nonce: nonce}
+			filled = append(filled, fill)
+
+			tx, err := s.send(emptyTransfer(nonce, s.from(), gasPrice))
+			if err != nil {
+				fill.Error = err.Error()
+				break
+			}
+			hash := tx.Hash()
+			fill.Hash = &hash
+		}
+	}
+	if err := printResult(ctx, filled, func(w io.Writer) {
+		if len(filled) == 0 {
+			fmt.Fprintf(w, "No nonce gap for %s\n", nonces.Account.Hex())
+		}
+		for _, fill := range filled {
+			if fill.Error != "" {
+				fmt.Fprintf(w, "nonce %d: not filled: %s\n", fill.Nonce, fill.Error)
+			} else {
+				fmt.Fprintf(w, "nonce %d: filled by %s\n", fill.Nonce, fill.Hash.Hex())
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if n := len(filled); n > 0 && filled[n-1].Error != "" {
+		return fmt.Errorf("%d of %d nonce gaps not filled", len(nonces.Gaps)-n+1, len(nonces.Gaps))
+	}
+	return nil
+}
@@ -17,19 +17,15 @@
 package src
 
 import (
-	"context"
-	"math/big"
-	"time"
+	"os"
 
 	"gopkg.in/urfave/cli.v1"
-
-	"go-smilo/src/blockchain/smilobft/ethclient"
 )
 
 var (
 	transactionFlag = cli.StringFlag{
 		Name:  "transaction",
-		Usage: "Hex string for Sport extraData",
+		Usage: "Hash of the transaction, instead of the argument",
 	}
 
 	connectionFlag = cli.StringFlag{
@@ -45,68 +41,199 @@ var (
 
 	passphraseFlag = cli.StringFlag{
 		Name:  "passphrase",
-		Usage: "passphrase",
+		Usage: "Passphrase of the keystore file",
 		Value: "",
 	}
 
+	passwordFileFlag = cli.StringFlag{
+		Name:  "passwordfile",
+		Usage: "File holding the passphrase of the keystore file on its first line",
+	}
+
 	privatekeyFlag = cli.StringFlag{
 		Name:  "privatekey",
-		Usage: "privatekey",
+		Usage: "Hex encoded private key signing the transactions",
 		Value: "",
 	}
 
+	keystoreFlag = cli.StringFlag{
+		Name:  "keystore",
+		Usage: "Keystore file of the key signing the transactions, instead of --privatekey",
+	}
+
 	gaspriceFlag = cli.Uint64Flag{
 		Name:  "gasprice",
-		Usage: "gasprice",
+		Usage: "Gas price in wei of the sent transactions (default = minimum accepted)",
 		Value: 0x00,
 	}
 
+	accountFlag = cli.StringFlag{
+		Name:  "account",
+		Usage: "Address of the account (default = address of the signing key)",
+	}
+
+	blockFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "Block number, or latest or pending",
+		Value: "latest",
+	}
+
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the result in JSON, for scripting",
+	}
+
+	vaultFlag = cli.StringFlag{
+		Name:  "vault",
+		Usage: "Socket or configuration file of the vault",
+		Value: os.Getenv("VAULT_IPC"),
+	}
+
+	vaultFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Base64 public key of the vault sender (default = vault's own key)",
+	}
+
+	vaultToFlag = cli.StringSliceFlag{
+		Name:  "to",
+		Usage: "Base64 public key of a vault recipient, may be repeated",
+	}
+
+	nodeFlags = []cli.Flag{
+		connectionFlag,
+		timeoutFlag,
+		jsonFlag,
+	}
+
+	signingFlags = append([]cli.Flag{
+		passphraseFlag,
+		passwordFileFlag,
+		privatekeyFlag,
+		keystoreFlag,
+		gaspriceFlag,
+	}, nodeFlags...)
+
 	TransactionCommand = cli.Command{
 		Name:  "transaction",
 		Usage: "do things with a transaction",
 		Subcommands: []cli.Command{
 			{
-				Action:    CancelTransaction,
-				Name:      "cancel",
-				Usage:     "cancel txid",
-				ArgsUsage: "<tx id>",
-				Flags: []cli.Flag{
-					connectionFlag,
-					transactionFlag,
-					timeoutFlag,
-					passphraseFlag,
-					privatekeyFlag,
-					gaspriceFlag,
-				},
-				Description: `Cancel a transaction.`,
+				Action:      CancelTransaction,
+				Name:        "cancel",
+				Usage:       "cancel txid",
+				ArgsUsage:   "<tx id>",
+				Flags:       append([]cli.Flag{transactionFlag}, signingFlags...),
+				Description: `Cancel a pending transaction, replacing it with an empty transfer to the sender.`,
+			},
+			{
+				Action:      UpTransaction,
+				Name:        "up",
+				Usage:       "up gas for a txid",
+				ArgsUsage:   "<tx id>",
+				Flags:       append([]cli.Flag{transactionFlag}, signingFlags...),
+				Description: `Up a transaction, sending it again with a higher gas price.`,
+			},
+			{
+				Action:      CancelAllTransactions,
+				Name:        "cancel-all",
+				Usage:       "cancel all the pooled transactions of the account",
+				Flags:       signingFlags,
+				Description: `Cancel all the pending and queued transactions of the signing account.`,
 			},
 			{
-				Action:    UpTransaction,
-				Name:      "up",
-				Usage:     "up gas for a txid",
-				ArgsUsage: "<tx id>",
-				Flags: []cli.Flag{
-					connectionFlag,
-					transactionFlag,
-					timeoutFlag,
+				Action:      UpAllTransactions,
+				Name:        "up-all",
+				Usage:       "up gas for all the pooled transactions of the account",
+				Flags:       signingFlags,
+				Description: `Send all the pending and queued transactions of the signing account again with a higher gas price.`,
+			},
+		},
+	}
+
+	NonceCommand = cli.Command{
+		Name:  "nonce",
+		Usage: "inspect and repair the nonces of an account",
+		Subcommands: []cli.Command{
+			{
+				Action: ShowNonce,
+				Name:   "show",
+				Usage:  "show the nonces and the nonce gaps of an account",
+				Flags: append([]cli.Flag{
+					accountFlag,
 					passphraseFlag,
+					passwordFileFlag,
 					privatekeyFlag,
-					gaspriceFlag,
-				},
-				Description: `Up a transaction.`,
+					keystoreFlag,
+				}, nodeFlags...),
+				Description: `Show the nonce of the account at the head, its pending nonce and the
+nonces missing below its queued transactions.`,
+			},
+			{
+				Action: RepairNonce,
+				Name:   "repair",
+				Usage:  "fill the nonce gaps of the account",
+				Flags:  signingFlags,
+				Description: `Fill the nonces missing below the queued transactions of the signing
+account with empty transfers, so that the queued transactions can be mined.`,
 			},
 		},
 	}
 
-	client     *ethclient.Client
-	chainID    *big.Int
-	timeout    = 30 * time.Second
-	gasPrice   *big.Int
-	gasLimit   uint64
-	nonce      int64
-	privatekey string
-)
+	SmiloPayCommand = cli.Command{
+		Name:  "smilopay",
+		Usage: "inspect the SmiloPay of an account",
+		Subcommands: []cli.Command{
+			{
+				Action:      ShowSmiloPay,
+				Name:        "show",
+				Usage:       "show the SmiloPay and the balance of an address",
+				ArgsUsage:   "<address>",
+				Flags:       append([]cli.Flag{blockFlag}, nodeFlags...),
+				Description: `Show the SmiloPay and the balance of an address at a block.`,
+			},
+		},
+	}
 
-func LocalContext() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), timeout)
-}
+	VaultCommand = cli.Command{
+		Name:  "vault",
+		Usage: "send and get private payloads",
+		Subcommands: []cli.Command{
+			{
+				Action:      SendVaultPayload,
+				Name:        "send",
+				Usage:       "store a private payload in the vault",
+				ArgsUsage:   "<hex payload>",
+				Flags:       []cli.Flag{vaultFlag, vaultFromFlag, vaultToFlag, jsonFlag},
+				Description: `Store a private payload in the vault, shared with the recipients, and print its digest.`,
+			},
+			{
+				Action:      GetVaultPayload,
+				Name:        "get",
+				Usage:       "get a private payload from the vault",
+				ArgsUsage:   "<digest>",
+				Flags:       []cli.Flag{vaultFlag, jsonFlag},
+				Description: `Get the private payload of a digest from the vault.`,
+			},
+		},
+	}
+
+	ValidatorsCommand = cli.Command{
+		Action:    ShowValidators,
+		Name:      "validators",
+		Usage:     "show the validators of the consensus engine",
+		ArgsUsage: "",
+		Flags:     append([]cli.Flag{blockFlag}, nodeFlags...),
+		Description: `Show the validators at a block, as reported by the API of the consensus
+engine of the node (istanbul, tendermint, sportdao, sport or clique).`,
+	}
+
+	WhitelistCommand = cli.Command{
+		Action:    ShowWhitelist,
+		Name:      "whitelist",
+		Usage:     "show the enode whitelist of the consensus engine",
+		ArgsUsage: "",
+		Flags:     nodeFlags,
+		Description: `Show the enode whitelist, as reported by the API of the consensus engine
+of the node (istanbul, tendermint or sportdao).`,
+	}
+)
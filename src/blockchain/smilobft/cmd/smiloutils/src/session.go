@@ -0,0 +1,162 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/ethclient"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+// session is the connection of a command to a node, with the key signing the
+// transactions if the command sends any.
+type session struct {
+	rpc     *rpc.Client
+	client  *ethclient.Client
+	chainID *big.Int
+	timeout time.Duration
+	key     *ecdsa.PrivateKey // Signing key, nil if not loaded
+}
+
+// newSession connects to the node of the command, loading the signing key if
+// withKey is set.
+func newSession(ctx *cli.Context, withKey bool) (*session, error) {
+	connection := ctx.String(connectionFlag.Name)
+	if connection == "" {
+		return nil, errors.New("connection is required")
+	}
+	s := &session{timeout: time.Duration(ctx.Int(timeoutFlag.Name)) * time.Second}
+	if withKey {
+		key, err := loadKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+	}
+	dialctx, cancel := s.context()
+	defer cancel()
+	client, err := rpc.DialContext(dialctx, connection)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial to Smilo node: %v", err)
+	}
+	s.rpc, s.client = client, ethclient.NewClient(client)
+
+	if withKey {
+		callctx, cancel := s.context()
+		defer cancel()
+		if s.chainID, err = s.client.ChainID(callctx); err != nil {
+			// Nodes without eth_chainId use the network id as the chain id
+			if s.chainID, err = s.client.NetworkID(callctx); err != nil {
+				s.close()
+				return nil, fmt.Errorf("could not get the chain id of Smilo node: %v", err)
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *session) close() {
+	s.rpc.Close()
+}
+
+// context returns the context of a call to the node.
+func (s *session) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+// from returns the address of the signing key.
+func (s *session) from() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+// accountArg returns the account of a read-only command, given by --account or
+// by the signing key.
+func accountArg(ctx *cli.Context) (common.Address, error) {
+	if account := ctx.String(accountFlag.Name); account != "" {
+		if !common.IsHexAddress(account) {
+			return common.Address{}, fmt.Errorf("invalid account %q", account)
+		}
+		return common.HexToAddress(account), nil
+	}
+	if ctx.String(privatekeyFlag.Name) == "" && ctx.String(keystoreFlag.Name) == "" {
+		return common.Address{}, errors.New("account is required")
+	}
+	key, err := loadKey(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(key.PublicKey), nil
+}
+
+// send signs the transaction and sends it to the node.
+func (s *session) send(tx *types.Transaction) (*types.Transaction, error) {
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	ctx, cancel := s.context()
+	defer cancel()
+	if err := s.client.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("failed to send transaction with nonce %d: %v", signed.Nonce(), err)
+	}
+	return signed, nil
+}
+
+// blockArg returns the block of the --block flag as an RPC argument.
+func blockArg(ctx *cli.Context) (string, error) {
+	switch block := ctx.String(blockFlag.Name); block {
+	case "", "latest", "pending", "earliest":
+		if block == "" {
+			block = "latest"
+		}
+		return block, nil
+	default:
+		n, err := strconv.ParseUint(block, 0, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid block %q", block)
+		}
+		return hexutil.EncodeUint64(n), nil
+	}
+}
+
+// printResult prints v in JSON if --json is set, or else calls text with the
+// standard output.
+func printResult(ctx *cli.Context, v interface{}, text func(w io.Writer)) error {
+	if ctx.Bool(jsonFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(os.Stdout)
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// smiloPay is the SmiloPay and the balance of an address.
+type smiloPay struct {
+	Address  common.Address `json:"address"`
+	Block    string         `json:"block"`
+	SmiloPay *hexutil.Big   `json:"smiloPay"`
+	Balance  *hexutil.Big   `json:"balance"`
+}
+
+// ShowSmiloPay shows the SmiloPay and the balance of an address.
+func ShowSmiloPay(ctx *cli.Context) error {
+	address := ctx.Args().First()
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	block, err := blockArg(ctx)
+	if err != nil {
+		return err
+	}
+	s, err := newSession(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	pay := &smiloPay{Address: common.HexToAddress(address), Block: block}
+	callctx, cancel := s.context()
+	defer cancel()
+	if err := s.rpc.CallContext(callctx, &pay.SmiloPay, "eth_getSmiloPay", pay.Address, block); err != nil {
+		return fmt.Errorf("failed to get the SmiloPay of %s: %v", address, err)
+	}
+	if err := s.rpc.CallContext(callctx, &pay.Balance, "eth_getBalance", pay.Address, block); err != nil {
+		return fmt.Errorf("failed to get the balance of %s: %v", address, err)
+	}
+	return printResult(ctx, pay, func(w io.Writer) {
+		fmt.Fprintf(w, "Address:  %s\n", pay.Address.Hex())
+		fmt.Fprintf(w, "Block:    %s\n", pay.Block)
+		fmt.Fprintf(w, "SmiloPay: %s\n", pay.SmiloPay.ToInt())
+		fmt.Fprintf(w, "Balance:  %s\n", pay.Balance.ToInt())
+	})
+}
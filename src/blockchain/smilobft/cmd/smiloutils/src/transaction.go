@@ -17,153 +17,161 @@
 package src
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"math/big"
+	"io"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/orinocopay/go-etherutils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"gopkg.in/urfave/cli.v1"
 
 	"go-smilo/src/blockchain/smilobft/core/types"
-	"go-smilo/src/blockchain/smilobft/ethclient"
 )
 
-func GetValidTXGasPrice(ctx *cli.Context) (validTX *types.Transaction, gasprice int64, err error) {
-	transaction := ctx.String(transactionFlag.Name)
-	if len(transaction) == 0 {
-		return validTX, gasprice, cli.NewExitError("transaction is required", 1)
-	}
+// replacedTransaction is the outcome of the replacement of a pooled transaction.
+type replacedTransaction struct {
+	Nonce    uint64       `json:"nonce"`
+	Replaced common.Hash  `json:"replaced"`           // Hash of the pooled transaction
+	Hash     *common.Hash `json:"hash,omitempty"`     // Hash of the replacement, if sent
+	GasPrice *hexutil.Big `json:"gasPrice,omitempty"` // Gas price of the replacement, if sent
+	Error    string       `json:"error,omitempty"`
+}
 
-	connection := ctx.String(connectionFlag.Name)
-	if len(connection) == 0 {
-		return validTX, gasprice, cli.NewExitError("connection is required", 1)
+// pendingTransaction returns the pooled transaction given by the argument or
+// by --transaction, checking that it was sent by the signing account.
+func (s *session) pendingTransaction(ctx *cli.Context) (*types.Transaction, error) {
+	hash := ctx.Args().First()
+	if hash == "" {
+		hash = ctx.String(transactionFlag.Name)
 	}
-	gasprice = ctx.Int64(gaspriceFlag.Name)
-
-	privatekey = ctx.String(privatekeyFlag.Name)
-
-	fmt.Println("Will cancel the transaction ", "transaction", transaction, "connection", connection)
-
-	client, err = ethclient.Dial(connection)
-	if err != nil {
-		return validTX, gasprice, cli.NewExitError("Could not dial to Smilo node", 1)
+	if hash == "" {
+		return nil, errors.New("transaction is required")
 	}
-
-	thisctx, cancel := context.WithTimeout(context.Background(), timeout)
+	callctx, cancel := s.context()
 	defer cancel()
-	chainID, err = client.NetworkID(thisctx)
+	tx, pending, err := s.client.TransactionByHash(callctx, common.HexToHash(hash))
 	if err != nil {
-		return validTX, gasprice, cli.NewExitError("Could not get NetworkID of Smilo node", 1)
+		return nil, fmt.Errorf("failed to obtain transaction %s: %v", hash, err)
 	}
-
-	fmt.Println("Connection to Smilo node OK", "chainID", chainID, "transaction", transaction, "connection", connection)
-
-	tx := common.HexToHash(transaction)
-	thisctx, cancel = LocalContext()
-	defer cancel()
-
-	avalidTX, pending, err := client.TransactionByHash(thisctx, tx)
-
-	if avalidTX == nil || avalidTX.Hash() != tx || err != nil {
-		fmt.Println("failed to obtain transaction", tx.Hex(), err)
-		return validTX, gasprice, cli.NewExitError("Failed to obtain transaction", 1)
-	} else if !pending {
-		fmt.Printf("Transaction %s has already been mined \n", tx.Hex())
-		return validTX, gasprice, cli.NewExitError("Transaction %s has already been mined", 1)
+	if !pending {
+		return nil, fmt.Errorf("transaction %s has already been mined", tx.Hash().Hex())
 	}
-
-	//set it back
-	validTX = avalidTX
-
-	fmt.Println("validTX, ", validTX)
-
-	return validTX, gasprice, nil
+	from, err := TxFrom(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain the sender of %s: %v", tx.Hash().Hex(), err)
+	}
+	if from != s.from() {
+		return nil, fmt.Errorf("transaction %s was sent by %s, not by the signing account %s", tx.Hash().Hex(), from.Hex(), s.from().Hex())
+	}
+	return tx, nil
 }
 
-func ProcessValidTXAndGas(validTX *types.Transaction, gasprice int64, minGasPrice *big.Int, cmdStr string) error {
-	if gasprice == 0 {
-		// No gas price supplied; use the calculated minimum
-		gasPrice = minGasPrice
-	} else {
-		gasPrice = big.NewInt(gasprice)
-		// Gas price supplied; ensure it is at least 10% more than the current gas price
-		if gasPrice.Cmp(minGasPrice) >= 0 {
-			fmt.Printf("Gas price must be at least %s", etherutils.WeiToString(minGasPrice, true))
-			return cli.NewExitError(fmt.Sprintf("Gas price must be at least %s", etherutils.WeiToString(minGasPrice, true)), 1)
+// replace sends the replacements of the pooled transactions, cancelling them if
+// cancel is set. It stops at the first failure unless all is set.
+func (s *session) replace(ctx *cli.Context, txs types.Transactions, cancel, all bool) ([]*replacedTransaction, error) {
+	var (
+		results []*replacedTransaction
+		failed  int
+	)
+	for _, tx := range txs {
+		result := &replacedTransaction{Nonce: tx.Nonce(), Replaced: tx.Hash()}
+		results = append(results, result)
+
+		sent, err := s.replaceOne(ctx, tx, cancel)
+		if err != nil {
+			result.Error = err.Error()
+			if !all {
+				return results, err
+			}
+			failed++
+			continue
 		}
+		hash := sent.Hash()
+		result.Hash, result.GasPrice = &hash, (*hexutil.Big)(sent.GasPrice())
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d transactions not replaced", failed, len(txs))
 	}
+	return results, nil
+}
 
-	// Create and sign the transaction
-	fromAddress, err := TxFrom(validTX)
+func (s *session) replaceOne(ctx *cli.Context, tx *types.Transaction, cancel bool) (*types.Transaction, error) {
+	gasPrice, err := replacementPrice(ctx, tx.GasPrice())
 	if err != nil {
-		fmt.Println("Failed to obtain from address")
-		return cli.NewExitError("Failed to obtain from address", 1)
-
+		return nil, err
 	}
-
-	nonce = int64(validTX.Nonce())
-
-	signedTx, err := NewTransactionSigned(fromAddress, &fromAddress, nil, nil)
+	replaced, err := replacement(tx, s.from(), gasPrice, cancel)
 	if err != nil {
-		fmt.Printf("Failed to createSignedTransaction %s", validTX.Hash().Hex())
-		return cli.NewExitError("Failed to createSignedTransaction", 1)
+		return nil, err
 	}
+	return s.send(replaced)
+}
 
-	thisctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	fmt.Println("signedTx, ", signedTx)
-	err = client.SendTransaction(thisctx, signedTx)
+func printReplaced(ctx *cli.Context, results []*replacedTransaction, err error) error {
+	perr := printResult(ctx, results, func(w io.Writer) {
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Fprintf(w, "nonce %d: %s not replaced: %s\n", r.Nonce, r.Replaced.Hex(), r.Error)
+			} else {
+				fmt.Fprintf(w, "nonce %d: %s replaced by %s at gas price %s\n", r.Nonce, r.Replaced.Hex(), r.Hash.Hex(), r.GasPrice.ToInt())
+			}
+		}
+	})
 	if err != nil {
-		fmt.Println("Failed to send transaction, ", validTX.Hash().Hex(), err)
-		return cli.NewExitError("Signed transaction failed SendTransaction, ", 1)
+		return err
 	}
-
-	fmt.Println("cmd", "transaction",
-		"Command", cmdStr,
-		"Address", fromAddress.Hex(),
-		"NetworkID", chainID,
-		"Gas", signedTx.Gas(),
-		"Gas Price", signedTx.GasPrice().String(),
-		"Transaction Hash", signedTx.Hash().Hex(),
-	)
-
-	return nil
+	return perr
 }
 
 // CancelTransaction will cancel a transaction based on the tx and pk
 func CancelTransaction(ctx *cli.Context) error {
+	return replaceTransaction(ctx, true)
+}
+
+// UpTransaction will up the gas for transaction based on the tx and pk
+func UpTransaction(ctx *cli.Context) error {
+	return replaceTransaction(ctx, false)
+}
 
-	validTX, gasprice, err := GetValidTXGasPrice(ctx)
+func replaceTransaction(ctx *cli.Context, cancel bool) error {
+	s, err := newSession(ctx, true)
 	if err != nil {
-		return cli.NewExitError("Could not get GetStuff with Smilo node", 1)
+		return err
 	}
+	defer s.close()
 
-	minGasPrice := big.NewInt(0).Add(big.NewInt(0).Add(validTX.GasPrice(), big.NewInt(0).Div(validTX.GasPrice(), big.NewInt(10))), big.NewInt(10))
-
-	err = ProcessValidTXAndGas(validTX, gasprice, minGasPrice, "cancel")
+	tx, err := s.pendingTransaction(ctx)
 	if err != nil {
-		return cli.NewExitError("Could not get DoMoreSuff with Smilo node", 1)
+		return err
 	}
+	results, err := s.replace(ctx, types.Transactions{tx}, cancel, false)
+	return printReplaced(ctx, results, err)
+}
 
-	return nil
+// CancelAllTransactions cancels all the pooled transactions of the signing account.
+func CancelAllTransactions(ctx *cli.Context) error {
+	return replaceAllTransactions(ctx, true)
 }
 
-// UpTransaction will up the gas for transaction based on the tx and pk
-func UpTransaction(ctx *cli.Context) error {
+// UpAllTransactions ups the gas of all the pooled transactions of the signing account.
+func UpAllTransactions(ctx *cli.Context) error {
+	return replaceAllTransactions(ctx, false)
+}
 
-	validTX, gasprice, err := GetValidTXGasPrice(ctx)
+func replaceAllTransactions(ctx *cli.Context, cancel bool) error {
+	s, err := newSession(ctx, true)
 	if err != nil {
-		return cli.NewExitError("Could not get GetValidTXGasPrice with Smilo node", 1)
+		return err
 	}
+	defer s.close()
 
-	minGasPrice := big.NewInt(0).Add(big.NewInt(0).Add(validTX.GasPrice(), big.NewInt(0).Div(validTX.GasPrice(), big.NewInt(10))), big.NewInt(10))
-
-	err = ProcessValidTXAndGas(validTX, gasprice, minGasPrice, "up")
+	txs, err := s.poolTransactions(s.from())
 	if err != nil {
-		return cli.NewExitError("Could not ProcessValidTXAndGas with Smilo node", 1)
+		return err
 	}
-
-	return nil
+	results, err := s.replace(ctx, txs, cancel, true)
+	if results == nil {
+		results = []*replacedTransaction{}
+	}
+	return printReplaced(ctx, results, err)
 }
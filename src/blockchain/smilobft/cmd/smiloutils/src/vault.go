@@ -0,0 +1,96 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package src
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/urfave/cli.v1"
+
+	"go-smilo/src/blockchain/smilobft/vault/blackbox"
+)
+
+// vaultPayload is a private payload and its digest in the vault.
+type vaultPayload struct {
+	Digest  hexutil.Bytes `json:"digest"`
+	Payload hexutil.Bytes `json:"payload,omitempty"`
+}
+
+// openVault connects to the vault given by --vault.
+func openVault(ctx *cli.Context) (*blackbox.Blackbox, error) {
+	path := ctx.String(vaultFlag.Name)
+	if path == "" {
+		return nil, errors.New("vault is required")
+	}
+	vault, err := blackbox.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the vault: %v", err)
+	}
+	return vault, nil
+}
+
+// SendVaultPayload stores a private payload in the vault and prints its digest.
+func SendVaultPayload(ctx *cli.Context) error {
+	payload, err := hexutil.Decode(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	if len(payload) == 0 {
+		return errors.New("payload is required")
+	}
+	vault, err := openVault(ctx)
+	if err != nil {
+		return err
+	}
+	digest, err := vault.Post(payload, ctx.String(vaultFromFlag.Name), ctx.StringSlice(vaultToFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to send the payload to the vault: %v", err)
+	}
+	result := &vaultPayload{Digest: digest}
+	return printResult(ctx, result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Digest)
+	})
+}
+
+// GetVaultPayload prints the private payload of a digest.
+func GetVaultPayload(ctx *cli.Context) error {
+	digest, err := hexutil.Decode(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("invalid digest: %v", err)
+	}
+	if len(digest) != 64 {
+		return fmt.Errorf("expected a digest of length 64, but got %d", len(digest))
+	}
+	vault, err := openVault(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := vault.Get(digest)
+	if err != nil {
+		return fmt.Errorf("failed to get the payload from the vault: %v", err)
+	}
+	if len(payload) == 0 {
+		return errors.New("payload not found, or not shared with the vault")
+	}
+	result := &vaultPayload{Digest: digest, Payload: payload}
+	return printResult(ctx, result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Payload)
+	})
+}
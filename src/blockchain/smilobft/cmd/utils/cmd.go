@@ -86,6 +86,18 @@ func StartNode(stack *node.Node) {
 		debug.Exit() // ensure trace and CPU profile data is flushed.
 		debug.LoudPanic("boom")
 	}()
+	if permissions := stack.Server().Permissions(); permissions != nil {
+		go func() {
+			sighup := make(chan os.Signal, 1)
+			signal.Notify(sighup, syscall.SIGHUP)
+			for range sighup {
+				log.Info("Got hangup, reloading the permissioned nodes...")
+				if err := permissions.Reload(); err != nil {
+					log.Error("Could not reload the permissioned nodes", "err", err)
+				}
+			}
+		}()
+	}
 }
 
 func ImportChain(chain *core.BlockChain, fn string) error {
@@ -51,6 +51,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/consensus/clique"
 	"go-smilo/src/blockchain/smilobft/consensus/ethash"
 	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
 	"go-smilo/src/blockchain/smilobft/core/vm"
 	"go-smilo/src/blockchain/smilobft/dashboard"
 	"go-smilo/src/blockchain/smilobft/eth"
@@ -128,6 +129,10 @@ var (
 		Name:  "datadir.ancient",
 		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
 	}
+	DatabaseEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: `Storage engine of the databases ("leveldb" or "boltdb", default = engine of the existing database or leveldb)`,
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -467,6 +472,10 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerContractAnalysisFlag = cli.BoolFlag{
+		Name:  "miner.contractanalysis",
+		Usage: "Skip the contract creations refused by the transaction pool's code analysis rules",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -802,7 +811,7 @@ var (
 	}
 	SmiloCodeAnalysisPathFlag = cli.StringFlag{
 		Name:  "smilocodeanalysispath",
-		Usage: "path to smilo code analysis executable, if provided, enables eth.codeAnalysis web3",
+		Usage: "path to smilo code analysis, if provided, enables eth.codeAnalysis web3 (the analysis runs in process)",
 		Value: "",
 	}
 	MinBlocksEmptyMiningFlag = BigFlag{
@@ -814,6 +823,18 @@ var (
 		Name:  "permissioned",
 		Usage: "If enabled, the node will allow only a defined list of nodes to connect",
 	}
+	ConsensusTraceFileFlag = cli.StringFlag{
+		Name:  "consensus.tracefile",
+		Usage: "File to record every inbound and outbound Sport/Tendermint consensus message to",
+	}
+	EconomicHistoryFlag = cli.BoolFlag{
+		Name:  "autonity.economichistory",
+		Usage: "Record the economic state of the Autonity contract for every block",
+	}
+	EconomicHistoryDBFlag = cli.StringFlag{
+		Name:  "autonity.economichistory.db",
+		Usage: "Name of a separate database in the data directory for the economic history (default = chain database)",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -1266,6 +1287,14 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 
 	cfg.EnableNodePermissionFlag = ctx.GlobalBool(EnableNodePermissionFlag.Name)
 
+	if ctx.GlobalIsSet(DatabaseEngineFlag.Name) {
+		switch engine := ctx.GlobalString(DatabaseEngineFlag.Name); engine {
+		case rawdb.LevelDBEngine, rawdb.BoltDBEngine:
+			cfg.DatabaseEngine = engine
+		default:
+			Fatalf("Invalid database engine %q, want %q or %q", engine, rawdb.LevelDBEngine, rawdb.BoltDBEngine)
+		}
+	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -1447,6 +1476,9 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.Bool(MinerNoVerfiyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerContractAnalysisFlag.Name) {
+		cfg.ContractAnalysis = ctx.GlobalBool(MinerContractAnalysisFlag.Name)
+	}
 	log.Info("********* MINER ****** ", "ExtraData", cfg.ExtraData)
 }
 
@@ -1477,6 +1509,16 @@ func setBFT(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(EnableNodePermissionFlag.Name) {
 		cfg.EnableNodePermissionFlag = ctx.GlobalBool(EnableNodePermissionFlag.Name)
 	}
+	if ctx.GlobalIsSet(ConsensusTraceFileFlag.Name) {
+		cfg.Sport.MessageTraceFile = ctx.GlobalString(ConsensusTraceFileFlag.Name)
+		cfg.Tendermint.MessageTraceFile = ctx.GlobalString(ConsensusTraceFileFlag.Name)
+	}
+	if ctx.GlobalIsSet(EconomicHistoryFlag.Name) {
+		cfg.EconomicHistory = ctx.GlobalBool(EconomicHistoryFlag.Name)
+	}
+	if ctx.GlobalIsSet(EconomicHistoryDBFlag.Name) {
+		cfg.EconomicHistoryDB = ctx.GlobalString(EconomicHistoryDBFlag.Name)
+	}
 }
 
 func setSport(ctx *cli.Context, cfg *eth.Config) {
@@ -0,0 +1,198 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package clock provides the timers of the BFT cores, on the system clock or on
+// a virtual clock which only moves when its owner runs the scheduled callbacks.
+package clock
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Timer is a scheduled callback which can be cancelled.
+type Timer interface {
+	// Stop prevents the callback from running. It returns false if the callback
+	// already ran or the timer was already stopped.
+	Stop() bool
+}
+
+// Clock is the source of time of the BFT cores. Production code runs on the
+// system clock, the simulator replaces it with a VirtualClock.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// VirtualClock is a Clock whose time only moves when the owner runs the
+// scheduled callbacks. Callbacks run on the goroutine calling Step, one at a
+// time, ordered by deadline and then by scheduling order, so that a run is
+// fully reproducible.
+type VirtualClock struct {
+	now   time.Time
+	seq   uint64
+	queue timerQueue
+	mu    sync.Mutex
+}
+
+// NewVirtualClock creates a virtual clock set to start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to run once the virtual time advanced by d.
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) Timer {
+	if d < 0 {
+		d = 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &virtualTimer{
+		clock: c,
+		at:    c.now.Add(d),
+		seq:   c.seq,
+		f:     f,
+	}
+	c.seq++
+	heap.Push(&c.queue, t)
+	return t
+}
+
+// Pending returns the number of scheduled callbacks.
+func (c *VirtualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue)
+}
+
+// Next returns the deadline of the next scheduled callback.
+func (c *VirtualClock) Next() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queue) == 0 {
+		return time.Time{}, false
+	}
+	return c.queue[0].at, true
+}
+
+// Step advances the clock to the deadline of the next callback and runs it.
+// It reports false if nothing is scheduled.
+func (c *VirtualClock) Step() bool {
+	c.mu.Lock()
+	if len(c.queue) == 0 {
+		c.mu.Unlock()
+		return false
+	}
+	t := heap.Pop(&c.queue).(*virtualTimer)
+	if t.at.After(c.now) {
+		c.now = t.at
+	}
+	c.mu.Unlock()
+
+	// The callback may schedule or stop timers, so it runs without the lock
+	t.f()
+	return true
+}
+
+// RunUntil runs every callback scheduled up to deadline and then moves the
+// clock to deadline. It returns the number of callbacks run.
+func (c *VirtualClock) RunUntil(deadline time.Time) int {
+	var n int
+	for {
+		next, ok := c.Next()
+		if !ok || next.After(deadline) {
+			break
+		}
+		c.Step()
+		n++
+	}
+	c.mu.Lock()
+	if deadline.After(c.now) {
+		c.now = deadline
+	}
+	c.mu.Unlock()
+	return n
+}
+
+type virtualTimer struct {
+	clock *VirtualClock
+	at    time.Time
+	seq   uint64
+	f     func()
+	index int // position in the clock queue, -1 once run or stopped
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&t.clock.queue, t.index)
+	return true
+}
+
+// timerQueue implements heap.Interface ordered by deadline and sequence.
+type timerQueue []*virtualTimer
+
+func (q timerQueue) Len() int { return len(q) }
+
+func (q timerQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q timerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *timerQueue) Push(x interface{}) {
+	t := x.(*virtualTimer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package clock
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVirtualClockOrder(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewVirtualClock(start)
+
+	var order []int
+	clock.AfterFunc(2*time.Second, func() { order = append(order, 3) })
+	clock.AfterFunc(time.Second, func() { order = append(order, 1) })
+	clock.AfterFunc(time.Second, func() {
+		order = append(order, 2)
+		// Timers scheduled by a callback run after the ones already due
+		clock.AfterFunc(0, func() { order = append(order, 4) })
+	})
+
+	if n := clock.RunUntil(start.Add(time.Second)); n != 3 {
+		t.Errorf("callbacks run: have %d, want 3", n)
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order: have %v, want %v", order, want)
+	}
+	if now := clock.Now(); !now.Equal(start.Add(time.Second)) {
+		t.Errorf("now: have %v, want %v", now, start.Add(time.Second))
+	}
+
+	clock.RunUntil(start.Add(time.Minute))
+	if want := []int{1, 2, 4, 3}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order: have %v, want %v", order, want)
+	}
+	if now := clock.Now(); !now.Equal(start.Add(time.Minute)) {
+		t.Errorf("now: have %v, want %v", now, start.Add(time.Minute))
+	}
+	if clock.Step() {
+		t.Error("step ran a callback on an empty clock")
+	}
+}
+
+func TestVirtualClockStop(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+
+	var ran bool
+	timer := clock.AfterFunc(time.Second, func() { ran = true })
+	clock.AfterFunc(2*time.Second, func() {})
+
+	if !timer.Stop() {
+		t.Error("stop of a pending timer failed")
+	}
+	if timer.Stop() {
+		t.Error("stop of a stopped timer succeeded")
+	}
+	if n := clock.Pending(); n != 1 {
+		t.Errorf("pending: have %d, want 1", n)
+	}
+	for clock.Step() {
+	}
+	if ran {
+		t.Error("stopped timer ran")
+	}
+
+	done := clock.AfterFunc(0, func() {})
+	clock.Step()
+	if done.Stop() {
+		t.Error("stop of a timer which already ran succeeded")
+	}
+}
@@ -158,3 +158,18 @@ type Syncer interface {
 
 	ResetPeerCache(address common.Address)
 }
+
+// Checkpointer is implemented by the BFT engines sealing epoch checkpoint
+// headers, which commit to the validator set that sealed them.
+type Checkpointer interface {
+	// CheckpointRules returns the seal rules of the checkpoint headers of the
+	// chain of config.
+	CheckpointRules(config *params.ChainConfig) *types.CheckpointRules
+}
+
+// PendingChanges is implemented by the engines which vote consensus changes,
+// such as updates of the validator set, in the blocks they seal.
+type PendingChanges interface {
+	// HasPendingChanges returns whether changes wait to be voted in a block.
+	HasPendingChanges() bool
+}
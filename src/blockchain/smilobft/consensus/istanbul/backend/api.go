@@ -56,7 +56,7 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 
 // Get Autonity contract ABI
 func (api *API) GetContractABI() string {
-	return api.chain.Config().AutonityContractConfig.ABI
+	return api.istanbul.blockchain.GetAutonityContract().ABI()
 }
 
 // Get Autonity contract address
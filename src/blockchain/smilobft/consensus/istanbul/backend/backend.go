@@ -118,8 +118,7 @@ type Backend struct {
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
 
-	autonityContractAddress common.Address // Ethereum address of the autonity contract
-	vmConfig                *vm.Config
+	vmConfig *vm.Config
 }
 
 // Address implements istanbul.Backend.Address
@@ -412,6 +411,14 @@ func (sb *Backend) Close() error {
 	return nil
 }
 
+// HasPendingChanges implements consensus.PendingChanges, returning whether
+// candidates wait to be voted.
+func (sb *Backend) HasPendingChanges() bool {
+	sb.candidatesLock.RLock()
+	defer sb.candidatesLock.RUnlock()
+	return len(sb.candidates) > 0
+}
+
 // Whitelist for the current block
 func (sb *Backend) WhiteList() []string {
 	state, vaultstate, err := sb.blockchain.State()
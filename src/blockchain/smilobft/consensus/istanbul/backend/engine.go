@@ -23,7 +23,6 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/orinocopay/go-etherutils"
 
 	"go-smilo/src/blockchain/smilobft/core"
@@ -39,6 +38,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/consensus/istanbul/validator"
 	"go-smilo/src/blockchain/smilobft/core/state"
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
 const (
@@ -77,7 +77,6 @@ var (
 	//errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transactions hashes")
-	errWaitTransactions = errors.New("waiting for transactions")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -256,6 +255,12 @@ func (sb *Backend) verifySigner(chain consensus.ChainReader, header *types.Heade
 	return errUnauthorized
 }
 
+// CheckpointRules returns the seal rules of the Istanbul epoch checkpoint headers,
+// which fast and light sync verify without the headers in between.
+func (sb *Backend) CheckpointRules(config *params.ChainConfig) *types.CheckpointRules {
+	return istanbulCore.CheckpointRules()
+}
+
 // verifyCommittedSeals checks whether every committed seal is signed by one of the parent's validators
 func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
 	number := header.Number.Uint64()
@@ -269,6 +274,12 @@ func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 		return err
 	}
 	validators := validator.NewSet(validatorAddresses, sb.config.GetProposerPolicy())
+	// Epoch checkpoint headers must commit to the validators of their height
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.VerifyCheckpointValidators(header, validatorAddresses); err != nil {
+			return err
+		}
+	}
 
 	extra, err := types.ExtractBFTHeaderExtra(header)
 	if err != nil {
@@ -374,6 +385,13 @@ func (sb *Backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	}
 	header.Extra = extra
 
+	// Epoch checkpoint headers commit to the validators which seal them
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.WriteCheckpoint(header); err != nil {
+			return err
+		}
+	}
+
 	// set header's timestamp
 	header.Time = parent.Time + sb.config.BlockPeriod
 	if int64(header.Time) < time.Now().Unix() {
@@ -467,21 +485,21 @@ func (sb *Backend) getValidators(header *types.Header, chain consensus.ChainRead
 			chain := chain
 			return sb.retrieveSavedValidators(i, chain)
 		}
-		contractAddress, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
+		_, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
 		if err != nil {
 			log.Error("Deploy autonity contract error", "error", err)
 			return nil, err
 		}
-		sb.autonityContractAddress = contractAddress
 		validators, err = sb.retrieveSavedValidators(1, chain)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		if sb.autonityContractAddress == common.HexToAddress("0000000000000000000000000000000000000000") {
-			sb.autonityContractAddress = crypto.CreateAddress(chain.Config().AutonityContractConfig.Deployer, 0)
-		}
 		var err error
+		if err = sb.blockchain.GetAutonityContract().ApplyUpgrade(header, state); err != nil {
+			log.Error("ApplyUpgrade error", "error", err)
+			return nil, err
+		}
 		validators, err = sb.blockchain.GetAutonityContract().ContractGetValidators(chain, header, state)
 		if err != nil {
 			log.Error("ContractGetValidators error", "error", err)
@@ -525,14 +543,6 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, stop <-
 		return nil, nil
 	}
 
-	log.Debug("will crash ?? ", "sb.config.MinBlocksEmptyMining", sb.config.MinBlocksEmptyMining)
-	log.Debug("will crash ?? ", "block.Number()", block.Number())
-	log.Trace("If we're mining, but nothing is being processed, wake on new transactions ? ", "MinBlocksEmptyMining", sb.config.MinBlocksEmptyMining, "BlockNum", block.Number(), "BlockNum Cmp MinBlocksMining", block.Number().Cmp(sb.config.MinBlocksEmptyMining))
-	if len(block.Transactions()) == 0 && block.Number().Cmp(sb.config.MinBlocksEmptyMining) >= 0 {
-		log.Debug("Seal, Bail out errWaitTransactions")
-		return nil, errWaitTransactions
-	}
-
 	// get the proposed block hash and clear it if the seal() is completed.
 	sb.sealMu.Lock()
 	sb.proposedBlockHash = block.Hash()
@@ -17,6 +17,9 @@
 package core
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/ethereum/go-ethereum/log"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
@@ -95,6 +98,7 @@ func (c *core) storeBacklog(msg *message, src istanbul.Validator) {
 	defer c.backlogsMu.Unlock()
 
 	logger.Debug("Retrieving backlog queue", "for", src.Address(), "backlogs_size", len(c.backlogs))
+	src = c.backlogSource(src)
 	backlog := c.backlogs[src]
 	if backlog == nil {
 		backlog = prque.New()
@@ -117,11 +121,36 @@ func (c *core) storeBacklog(msg *message, src istanbul.Validator) {
 	c.backlogs[src] = backlog
 }
 
+// backlogSource returns the key of the backlog of src, as the copies of the
+// validator set hold distinct instances of the same validator
+func (c *core) backlogSource(src istanbul.Validator) istanbul.Validator {
+	for s := range c.backlogs {
+		if s.Address() == src.Address() {
+			return s
+		}
+	}
+	return src
+}
+
+// backlogSources returns the senders of the backlogs ordered by address, so
+// that the backlog events are always posted in the same order
+func (c *core) backlogSources() []istanbul.Validator {
+	srcs := make([]istanbul.Validator, 0, len(c.backlogs))
+	for src := range c.backlogs {
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return bytes.Compare(srcs[i].Address().Bytes(), srcs[j].Address().Bytes()) < 0
+	})
+	return srcs
+}
+
 func (c *core) processBacklog() {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	for src, backlog := range c.backlogs {
+	for _, src := range c.backlogSources() {
+		backlog := c.backlogs[src]
 		if backlog == nil {
 			continue
 		}
@@ -179,7 +208,7 @@ func (c *core) processBacklog() {
 			}
 			logger.Trace("Post backlog event", "msg", msg)
 
-			go c.sendEvent(backlogEvent{
+			c.sendEventAsync(backlogEvent{
 				src: src,
 				msg: msg,
 			})
@@ -28,6 +28,7 @@ import (
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
 	"go-smilo/src/blockchain/smilobft/cmn"
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/istanbul"
 	"go-smilo/src/blockchain/smilobft/consensus/istanbul/validator"
 )
@@ -322,6 +323,50 @@ func TestProcessBacklog(t *testing.T) {
 	}
 }
 
+func TestProcessBacklogOrder(t *testing.T) {
+	start := time.Unix(0, 0)
+	vclock := clock.NewVirtualClock(start)
+
+	var posted []common.Address
+	c := &core{
+		logger:     log.New("backend", "test", "id", 0),
+		backlogs:   make(map[istanbul.Validator]*prque.Prque),
+		backlogsMu: new(sync.Mutex),
+		state:      StatePrepared,
+		current: newRoundState(&istanbul.View{
+			Sequence: big.NewInt(1),
+			Round:    big.NewInt(0),
+		}, newTestValidatorSet(4), common.Hash{}, nil, nil, nil),
+		clock: vclock,
+		postEvent: func(ev interface{}) {
+			posted = append(posted, ev.(backlogEvent).src.Address())
+		},
+	}
+
+	subject := &istanbul.Subject{
+		View:   c.currentView(),
+		Digest: cmn.StringToHash("1234567890"),
+	}
+	subjectPayload, _ := Encode(subject)
+
+	// the copies of a validator set hold distinct instances of the same validator
+	addrs := []common.Address{common.HexToAddress("0x03"), common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x01")}
+	for _, addr := range addrs {
+		c.storeBacklog(&message{Code: msgCommit, Msg: subjectPayload}, validator.New(addr))
+	}
+	if len(c.backlogs) != 3 {
+		t.Fatalf("backlogs mismatch: have %v, want 3", len(c.backlogs))
+	}
+
+	c.processBacklog()
+	vclock.RunUntil(start)
+
+	want := []common.Address{addrs[1], addrs[1], addrs[2], addrs[0]}
+	if !reflect.DeepEqual(posted, want) {
+		t.Errorf("events mismatch: have %v, want %v", posted, want)
+	}
+}
+
 func testProcessBacklog(t *testing.T, msg *message) {
 	vset := newTestValidatorSet(1)
 	backend := &testSystemBackend{
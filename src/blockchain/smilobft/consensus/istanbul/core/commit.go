@@ -77,6 +77,12 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
 		c.current.LockHash()
 		c.commit()
+	} else if c.current.GetPrepareOrCommitSize() > 2*c.valSet.F() && c.state.Cmp(StatePrepared) < 0 {
+		// A COMMIT counts as a PREPARE too, as in handlePrepare. Locked validators only send COMMIT
+		// messages, without this the validators that are not locked could never reach the Prepared state.
+		c.current.LockHash()
+		c.setState(StatePrepared)
+		c.sendCommit()
 	}
 
 	return nil
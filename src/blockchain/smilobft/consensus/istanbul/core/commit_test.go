@@ -325,3 +325,98 @@ func TestVerifyCommit(t *testing.T) {
 		}
 	}
 }
+
+// A COMMIT counts as a PREPARE, so a validator that is not locked reaches the
+// Prepared state with PREPARE and COMMIT messages of 2F+1 distinct validators.
+func TestHandleCommitAsPrepare(t *testing.T) {
+	N := uint64(4)
+	F := uint64(1)
+
+	testCases := []struct {
+		prepares      []int
+		commits       []int
+		expectedState State
+	}{
+		{
+			// one PREPARE and two COMMIT messages
+			[]int{1},
+			[]int{2, 3},
+			StatePrepared,
+		},
+		{
+			// the PREPARE and the COMMIT of the same validator count once
+			[]int{2},
+			[]int{2, 3},
+			StatePreprepared,
+		},
+		{
+			// not enough COMMIT messages
+			[]int{},
+			[]int{2, 3},
+			StatePreprepared,
+		},
+	}
+
+	for i, test := range testCases {
+		sys := NewTestSystemWithBackend(N, F)
+		sys.Run(false)
+
+		v0 := sys.backends[0]
+		r0 := v0.engine.(*core)
+		r0.valSet = v0.peers
+		r0.current = newTestRoundState(
+			&istanbul.View{
+				Round:    big.NewInt(0),
+				Sequence: newTestProposal().Number(),
+			},
+			r0.valSet,
+		)
+		r0.state = StatePreprepared
+
+		m, _ := Encode(r0.current.Subject())
+		for _, j := range test.prepares {
+			validator := r0.valSet.GetByIndex(uint64(j))
+			if err := r0.current.Prepares.Add(&message{
+				Code:    msgPrepare,
+				Msg:     m,
+				Address: validator.Address(),
+			}); err != nil {
+				t.Fatalf("test %d: failed to add PREPARE: %v", i, err)
+			}
+		}
+		for _, j := range test.commits {
+			validator := r0.valSet.GetByIndex(uint64(j))
+			if err := r0.handleCommit(&message{
+				Code:          msgCommit,
+				Msg:           m,
+				Address:       validator.Address(),
+				Signature:     []byte{},
+				CommittedSeal: validator.Address().Bytes(), // small hack
+			}, validator); err != nil {
+				t.Fatalf("test %d: error mismatch: have %v, want nil", i, err)
+			}
+		}
+
+		if r0.state != test.expectedState {
+			t.Errorf("test %d: state mismatch: have %v, want %v", i, r0.state, test.expectedState)
+		}
+		if locked := r0.current.IsHashLocked(); locked != (test.expectedState == StatePrepared) {
+			t.Errorf("test %d: locked mismatch: have %v, want %v", i, locked, !locked)
+		}
+		if test.expectedState != StatePrepared {
+			continue
+		}
+
+		// the validator sends its own COMMIT once prepared
+		if len(v0.sentMsgs) != 1 {
+			t.Fatalf("test %d: the Send() should be called once: times %v", i, len(v0.sentMsgs))
+		}
+		decodedMsg := new(message)
+		if err := decodedMsg.FromPayload(v0.sentMsgs[0], nil); err != nil {
+			t.Fatalf("test %d: error mismatch: have %v, want nil", i, err)
+		}
+		if decodedMsg.Code != msgCommit {
+			t.Errorf("test %d: message code mismatch: have %v, want %v", i, decodedMsg.Code, msgCommit)
+		}
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/istanbul"
 	"go-smilo/src/blockchain/smilobft/core/types"
 )
@@ -68,7 +69,7 @@ type core struct {
 	events                *cmn.TypeMuxSubscription
 	finalCommittedSub     *cmn.TypeMuxSubscription
 	timeoutSub            *cmn.TypeMuxSubscription
-	futurePreprepareTimer *time.Timer
+	futurePreprepareTimer clock.Timer
 
 	valSet                istanbul.ValidatorSet
 	waitingForRoundChange bool
@@ -81,12 +82,18 @@ type core struct {
 	handlerStopCh chan struct{}
 
 	roundChangeSet     *roundChangeSet
-	roundChangeTimer   *time.Timer
+	roundChangeTimer   clock.Timer
 	roundChangeTimerMu sync.RWMutex
 
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	// clock schedules the timers of the core, the system clock is used if nil
+	clock clock.Clock
+	// postEvent receives the events the core sends to itself instead of the
+	// backend event mux if set, so that a simulation can dispatch them in order
+	postEvent func(ev interface{})
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -317,6 +324,14 @@ func (c *core) stopFuturePreprepareTimer() {
 	}
 }
 
+// afterFunc schedules f on the clock of the core
+func (c *core) afterFunc(d time.Duration, f func()) clock.Timer {
+	if c.clock == nil {
+		return time.AfterFunc(d, f)
+	}
+	return c.clock.AfterFunc(d, f)
+}
+
 func (c *core) stopTimer() {
 	c.stopFuturePreprepareTimer()
 
@@ -347,7 +362,7 @@ func (c *core) newRoundChangeTimer() {
 
 	c.roundChangeTimerMu.Lock()
 	defer c.roundChangeTimerMu.Unlock()
-	c.roundChangeTimer = time.AfterFunc(timeout, func() {
+	c.roundChangeTimer = c.afterFunc(timeout, func() {
 		c.logger.Debug("newRoundChangeTimer, Timeout for round !", "round", round, "timeout", timeout, "timeoutOriginal", time.Duration(c.config.RequestTimeout)*time.Millisecond)
 		c.sendEvent(timeoutEvent{})
 	})
@@ -364,3 +379,14 @@ func PrepareCommittedSeal(hash common.Hash) []byte {
 	buf.Write([]byte{byte(msgCommit)})
 	return buf.Bytes()
 }
+
+// CheckpointRules returns the seal rules of the Istanbul epoch checkpoint
+// headers: more than twice the faulty validators must commit them.
+func CheckpointRules() *types.CheckpointRules {
+	return &types.CheckpointRules{
+		CommitCode: byte(msgCommit),
+		Quorum: func(n int, number *big.Int) int {
+			return 2*(int(math.Ceil(float64(n)/3))-1) + 1
+		},
+	}
+}
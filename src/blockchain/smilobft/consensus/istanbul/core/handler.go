@@ -85,68 +85,85 @@ func (c *core) handleEvents() {
 				return
 			}
 			// A real event arrived, process interesting content
-			switch ev := event.Data.(type) {
-			case istanbul.RequestEvent:
-				r := &istanbul.Request{
-					Proposal: ev.Proposal,
-				}
-				err := c.handleRequest(r)
-				if err == errFutureMessage {
-					c.logger.Debug("$$$ istanbul, handleEvents, RequestEvent arrived, errFutureMessage", "Proposal", ev.Proposal.Hash().Hex())
-					c.storeRequestMsg(r)
-				}
-			case istanbul.MessageEvent:
-				if err := c.handleMsg(ev.Payload); err == nil {
-					c.logger.Debug("$$$ istanbul, handleEvents, MessageEvent arrived, will send Gossip to fullnodeSet")
-					err = c.backend.Gossip(c.valSet, ev.Payload)
-					if err != nil {
-						c.logger.Error("$$$ istanbul, handleEvents, handleMsg, failed to backend.Gossip", "err", err)
-					}
-				} else {
-					c.logger.Error("$$$ istanbul, handleEvents, istanbul.MessageEvent", "err", err)
-				}
-			case backlogEvent:
-				// No need to check signature for internal messages
-				if err := c.handleCheckedMsg(ev.msg, ev.src); err == nil {
-					p, err := ev.msg.Payload()
-					if err != nil {
-						c.logger.Warn("handleEvents, Get message payload failed", "err", err)
-						continue
-					}
-					err = c.backend.Gossip(c.valSet, p)
-					if err != nil {
-						c.logger.Error("$$$ istanbul, handleEvents, handleCheckedMsg, backend.Gossip ", "err", err)
-					}
-				}
-			}
-		case _, ok := <-c.timeoutSub.Chan():
+			c.handleEvent(event.Data)
+		case event, ok := <-c.timeoutSub.Chan():
 			if !ok {
 				return
 			}
-			c.handleTimeoutMsg()
+			c.handleEvent(event.Data)
 		case event, ok := <-c.finalCommittedSub.Chan():
 			if !ok {
 				return
 			}
-			switch event.Data.(type) {
-			case istanbul.FinalCommittedEvent:
-				err := c.handleFinalCommitted()
-				if err != nil {
-					c.logger.Error("$$$ istanbul, handleEvents, FinalCommittedEvent, handleFinalCommitted", "err", err)
-				}
+			c.handleEvent(event.Data)
+		}
+	}
+}
+
+// handleEvent processes a single external or internal event
+func (c *core) handleEvent(data interface{}) {
+	switch ev := data.(type) {
+	case istanbul.RequestEvent:
+		r := &istanbul.Request{
+			Proposal: ev.Proposal,
+		}
+		err := c.handleRequest(r)
+		if err == errFutureMessage {
+			c.logger.Debug("$$$ istanbul, handleEvents, RequestEvent arrived, errFutureMessage", "Proposal", ev.Proposal.Hash().Hex())
+			c.storeRequestMsg(r)
+		}
+	case istanbul.MessageEvent:
+		if err := c.handleMsg(ev.Payload); err == nil {
+			c.logger.Debug("$$$ istanbul, handleEvents, MessageEvent arrived, will send Gossip to fullnodeSet")
+			err = c.backend.Gossip(c.valSet, ev.Payload)
+			if err != nil {
+				c.logger.Error("$$$ istanbul, handleEvents, handleMsg, failed to backend.Gossip", "err", err)
+			}
+		} else {
+			c.logger.Error("$$$ istanbul, handleEvents, istanbul.MessageEvent", "err", err)
+		}
+	case backlogEvent:
+		// No need to check signature for internal messages
+		if err := c.handleCheckedMsg(ev.msg, ev.src); err == nil {
+			p, err := ev.msg.Payload()
+			if err != nil {
+				c.logger.Warn("handleEvents, Get message payload failed", "err", err)
+				return
+			}
+			err = c.backend.Gossip(c.valSet, p)
+			if err != nil {
+				c.logger.Error("$$$ istanbul, handleEvents, handleCheckedMsg, backend.Gossip ", "err", err)
 			}
 		}
+	case timeoutEvent:
+		c.handleTimeoutMsg()
+	case istanbul.FinalCommittedEvent:
+		err := c.handleFinalCommitted()
+		if err != nil {
+			c.logger.Error("$$$ istanbul, handleEvents, FinalCommittedEvent, handleFinalCommitted", "err", err)
+		}
 	}
 }
 
 // sendEvent sends events to mux
 func (c *core) sendEvent(ev interface{}) {
+	if c.postEvent != nil {
+		c.postEvent(ev)
+		return
+	}
 	err := c.backend.EventMux().Post(ev)
 	if err != nil {
 		c.logger.Error("$$$ istanbul, sendEvent", "err", err)
 	}
 }
 
+// sendEventAsync sends events to mux without blocking the caller
+func (c *core) sendEventAsync(ev interface{}) {
+	c.afterFunc(0, func() {
+		c.sendEvent(ev)
+	})
+}
+
 func (c *core) handleMsg(payload []byte) error {
 	logger := c.logger.New()
 
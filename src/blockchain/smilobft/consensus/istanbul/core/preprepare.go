@@ -92,7 +92,7 @@ func (c *core) handlePreprepare(msg *message, src istanbul.Validator) error {
 		if err == consensus.ErrFutureBlock {
 			logger.Info("Proposed block will be handled in the future", "err", err, "duration", duration)
 			c.stopFuturePreprepareTimer()
-			c.futurePreprepareTimer = time.AfterFunc(duration, func() {
+			c.futurePreprepareTimer = c.afterFunc(duration, func() {
 				c.sendEvent(backlogEvent{
 					src: src,
 					msg: msg,
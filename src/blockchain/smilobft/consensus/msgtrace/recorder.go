@@ -0,0 +1,159 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package msgtrace records the consensus messages exchanged by a BFT engine so
+// that a stalled network can be investigated and replayed offline.
+package msgtrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Direction tells whether a recorded message was received or sent.
+type Direction string
+
+const (
+	Inbound  Direction = "in"
+	Outbound Direction = "out"
+)
+
+var (
+	// errClosedRecorder is returned when writing to a closed recorder
+	errClosedRecorder = errors.New("recorder is closed")
+)
+
+// Record is a single consensus message as seen by the recording node.
+type Record struct {
+	Time      int64          `json:"time"`      // Unix time in nanoseconds when the message was seen
+	Node      common.Address `json:"node"`      // Address of the recording node
+	Direction Direction      `json:"direction"` // Whether the message was received or sent
+	Peer      common.Address `json:"peer"`      // Sender of an inbound or target of an outbound message
+	Code      uint64         `json:"code"`      // p2p message code
+	Payload   hexutil.Bytes  `json:"payload"`   // Raw consensus payload
+}
+
+// Loopback reports whether the record is a message the node delivered to itself.
+func (r *Record) Loopback() bool {
+	return r.Direction == Outbound && r.Peer == r.Node
+}
+
+// Recorder appends consensus message records to a file, one JSON object per
+// line. A nil Recorder is valid and discards everything, so engines can call it
+// unconditionally.
+type Recorder struct {
+	node common.Address
+	file *os.File
+	enc  *json.Encoder
+	mu   sync.Mutex
+}
+
+// NewRecorder opens (or creates) the trace file at path in append mode.
+func NewRecorder(path string, node common.Address) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		node: node,
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+// Inbound records a message received from a peer.
+func (r *Recorder) Inbound(from common.Address, code uint64, payload []byte) {
+	r.write(Inbound, from, code, payload)
+}
+
+// Outbound records a message sent to a peer. Messages the node delivers to
+// itself are recorded with its own address as the peer.
+func (r *Recorder) Outbound(to common.Address, code uint64, payload []byte) {
+	r.write(Outbound, to, code, payload)
+}
+
+func (r *Recorder) write(dir Direction, peer common.Address, code uint64, payload []byte) {
+	if r == nil {
+		return
+	}
+	record := &Record{
+		Time:      time.Now().UnixNano(),
+		Node:      r.node,
+		Direction: dir,
+		Peer:      peer,
+		Code:      code,
+		Payload:   common.CopyBytes(payload),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		log.Debug("Dropping consensus trace record", "err", errClosedRecorder)
+		return
+	}
+	if err := r.enc.Encode(record); err != nil {
+		log.Warn("Failed to write consensus trace record", "err", err)
+	}
+}
+
+// Close flushes and closes the underlying trace file.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return errClosedRecorder
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// ReadFile loads every record stored in the trace file at path.
+func ReadFile(path string) ([]*Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read decodes records from r until EOF.
+func Read(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		record := new(Record)
+		if err := dec.Decode(record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Partition splits the network into isolated groups of nodes between two
+// offsets from the start of the simulation. Nodes listed in different groups
+// cannot reach each other, nodes not listed in any group form one more group.
+type Partition struct {
+	From   time.Duration
+	Until  time.Duration
+	Groups [][]int
+}
+
+func (p *Partition) active(elapsed time.Duration) bool {
+	return elapsed >= p.From && elapsed < p.Until
+}
+
+func (p *Partition) group(node int) int {
+	for i, group := range p.Groups {
+		for _, n := range group {
+			if n == node {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Conditions describes how the simulated network treats the messages sent
+// between two distinct nodes. Messages a node sends to itself are always
+// delivered immediately.
+type Conditions struct {
+	MinDelay time.Duration // Minimum delivery delay of a message
+	MaxDelay time.Duration // Maximum delivery delay, messages are reordered by the random delay in between
+	DropRate float64       // Probability of a message being lost
+
+	Partitions []Partition
+
+	// StableAfter is the offset after which no message is dropped or
+	// partitioned anymore, zero meaning the network never stabilises. Liveness
+	// can only be expected once the network is stable.
+	StableAfter time.Duration
+}
+
+// route decides the fate of a message sent at elapsed from one node to another
+// and returns its delivery delay.
+func (c *Conditions) route(rng *rand.Rand, elapsed time.Duration, from, to int) (time.Duration, bool) {
+	delay := c.MinDelay
+	if c.MaxDelay > c.MinDelay {
+		delay += time.Duration(rng.Int63n(int64(c.MaxDelay - c.MinDelay)))
+	}
+	// Draw the drop decision unconditionally so that the random sequence does not
+	// depend on the partitions
+	dropped := rng.Float64() < c.DropRate
+
+	if c.StableAfter > 0 && elapsed >= c.StableAfter {
+		return delay, true
+	}
+	for i := range c.Partitions {
+		p := &c.Partitions[i]
+		if p.active(elapsed) && p.group(from) != p.group(to) {
+			return 0, false
+		}
+	}
+	return delay, !dropped
+}
+
+func (c Conditions) String() string {
+	return fmt.Sprintf("delay=%v-%v drop=%.2f partitions=%v stable=%v", c.MinDelay, c.MaxDelay, c.DropRate, c.Partitions, c.StableAfter)
+}
+
+// RandomConditions derives hostile but eventually stable network conditions
+// for n nodes from rng: random delays and losses, and up to two partitions,
+// all of them ending at the returned StableAfter offset.
+func RandomConditions(rng *rand.Rand, n int) Conditions {
+	stable := time.Duration(10+rng.Intn(50)) * time.Second
+	c := Conditions{
+		MinDelay:    time.Duration(rng.Intn(50)) * time.Millisecond,
+		DropRate:    rng.Float64() * 0.3,
+		StableAfter: stable,
+	}
+	c.MaxDelay = c.MinDelay + time.Duration(1+rng.Intn(1000))*time.Millisecond
+
+	for i := rng.Intn(3); i > 0 && n > 1; i-- {
+		from := time.Duration(rng.Int63n(int64(stable)))
+		until := from + time.Duration(rng.Int63n(int64(stable-from)+1))
+
+		// Split a random permutation of the nodes at a random point
+		perm := rng.Perm(n)
+		cut := 1 + rng.Intn(n-1)
+		c.Partitions = append(c.Partitions, Partition{
+			From:   from,
+			Until:  until,
+			Groups: [][]int{perm[:cut], perm[cut:]},
+		})
+	}
+	return c
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConditionsPartition(t *testing.T) {
+	c := Conditions{
+		MinDelay: 10 * time.Millisecond,
+		MaxDelay: 20 * time.Millisecond,
+		Partitions: []Partition{{
+			From:   time.Second,
+			Until:  2 * time.Second,
+			Groups: [][]int{{0, 1}},
+		}},
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		elapsed  time.Duration
+		from, to int
+		ok       bool
+	}{
+		{0, 0, 2, true},
+		{time.Second, 0, 1, true},
+		{time.Second, 2, 3, true}, // Unlisted nodes form their own group
+		{time.Second, 0, 2, false},
+		{time.Second, 3, 1, false},
+		{2 * time.Second, 0, 2, true},
+	}
+	for i, tt := range tests {
+		delay, ok := c.route(rng, tt.elapsed, tt.from, tt.to)
+		if ok != tt.ok {
+			t.Errorf("test %d: delivered: have %v, want %v", i, ok, tt.ok)
+		}
+		if ok && (delay < c.MinDelay || delay >= c.MaxDelay) {
+			t.Errorf("test %d: delay %v out of [%v, %v)", i, delay, c.MinDelay, c.MaxDelay)
+		}
+	}
+}
+
+func TestConditionsStableAfter(t *testing.T) {
+	c := Conditions{
+		DropRate:    1,
+		Partitions:  []Partition{{Until: time.Hour, Groups: [][]int{{0}}}},
+		StableAfter: time.Minute,
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	if _, ok := c.route(rng, time.Second, 0, 1); ok {
+		t.Error("message delivered before the network is stable")
+	}
+	if _, ok := c.route(rng, time.Minute, 0, 1); !ok {
+		t.Error("message dropped after the network is stable")
+	}
+}
+
+func TestRandomConditions(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		c := RandomConditions(rand.New(rand.NewSource(seed)), 4)
+		if !reflect.DeepEqual(c, RandomConditions(rand.New(rand.NewSource(seed)), 4)) {
+			t.Fatalf("seed %d: conditions are not reproducible", seed)
+		}
+		if c.StableAfter <= 0 || c.MaxDelay <= c.MinDelay || c.DropRate >= 1 {
+			t.Fatalf("seed %d: invalid conditions %v", seed, c)
+		}
+		for _, p := range c.Partitions {
+			if p.From > p.Until || p.Until > c.StableAfter {
+				t.Fatalf("seed %d: partition %v does not end before %v", seed, p, c.StableAfter)
+			}
+		}
+	}
+}
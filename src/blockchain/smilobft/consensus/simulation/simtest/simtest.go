@@ -0,0 +1,119 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simtest runs the network simulations of a BFT core from its tests.
+// It is only imported by tests, so its flags are only registered in test
+// binaries:
+//
+//	go test ./consensus/tendermint/core -run TestSimulation -simulation.runs=100000
+//	go test ./consensus/tendermint/core -run TestSimulation -simulation.seed=1234 -simulation.runs=1
+//
+// The first one searches more seeds than the default, the second one reproduces
+// the run of a failing seed.
+package simtest
+
+import (
+	"flag"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"go-smilo/src/blockchain/smilobft/consensus/simulation"
+)
+
+var (
+	runs = flag.Int("simulation.runs", 2000, "Number of randomized network simulations to run, 20 in short mode")
+	seed = flag.Int64("simulation.seed", 0, "First seed of the randomized network simulations")
+)
+
+// shortRuns is the number of simulations run in short mode
+const shortRuns = 20
+
+// Config is the network every core is simulated on
+var Config = simulation.Config{
+	Nodes:            4,
+	Heights:          5,
+	Timeout:          time.Hour,
+	SyncInterval:     10 * time.Second,
+	RandomConditions: true,
+}
+
+// Run checks that the nodes of factory are safe and live on randomized
+// networks, and that a run is fully determined by its seed.
+func Run(t *testing.T, factory simulation.NodeFactory) {
+	t.Run("SafetyAndLiveness", func(t *testing.T) {
+		n := *runs
+		if testing.Short() && n > shortRuns {
+			n = shortRuns
+		}
+		if err := runSeeds(factory, *seed, n); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Determinism", func(t *testing.T) {
+		first := simulation.NewSimulator(Config, 42, factory).Run()
+		second := simulation.NewSimulator(Config, 42, factory).Run()
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("runs with the same seed differ:\n%v\n%v", first, second)
+		}
+	})
+}
+
+// runSeeds runs the simulations of the n seeds from first on every CPU, and
+// returns the error of the lowest failing seed.
+func runSeeds(factory simulation.NodeFactory, first int64, n int) error {
+	var (
+		seeds  = make(chan int64)
+		mu     sync.Mutex
+		failed *simulation.Result
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range seeds {
+				result := simulation.NewSimulator(Config, s, factory).Run()
+				if result.Err() == nil {
+					continue
+				}
+				mu.Lock()
+				if failed == nil || s < failed.Seed {
+					failed = result
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for s := first; s < first+int64(n); s++ {
+		mu.Lock()
+		stop := failed != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+		seeds <- s
+	}
+	close(seeds)
+	wg.Wait()
+
+	if failed != nil {
+		return failed.Err()
+	}
+	return nil
+}
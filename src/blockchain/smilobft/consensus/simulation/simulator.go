@@ -0,0 +1,462 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulation runs BFT consensus cores in a single process over a
+// simulated network driven by a virtual clock. Message delays, losses,
+// reordering and partitions are derived from a seed, so that any run, and in
+// particular a failing one, can be reproduced exactly.
+package simulation
+
+import (
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
+
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+// defaultMaxEvents bounds the callbacks of a run when Config.MaxEvents is unset
+const defaultMaxEvents = 1000000
+
+// Node is a consensus engine instance driven by the simulator. All the methods
+// are called from the goroutine running the simulation.
+type Node interface {
+	// Start starts the engine on top of the genesis block.
+	Start()
+
+	// Deliver hands a consensus message received from the network to the engine.
+	Deliver(payload []byte)
+
+	// Import notifies the engine that the block, committed by the rest of the
+	// network, was inserted into its chain by the downloader.
+	Import(block *types.Block)
+
+	// Stop stops the engine.
+	Stop()
+}
+
+// NodeFactory creates the engine of the node at index.
+type NodeFactory func(sim *Simulator, index int) Node
+
+// Config describes a simulation run.
+type Config struct {
+	Nodes        int           // Number of validators
+	Heights      uint64        // Height every node must reach for the run to be live
+	Timeout      time.Duration // Virtual time after which a run that did not reach Heights is not live
+	SyncInterval time.Duration // Interval at which lagging nodes import blocks from their peers, zero disables syncing
+	MaxEvents    int           // Bound on the callbacks run, to catch livelocks
+
+	Conditions Conditions
+	// RandomConditions replaces Conditions with conditions derived from the seed
+	RandomConditions bool
+}
+
+// Result is the outcome of a simulation run.
+type Result struct {
+	Seed       int64
+	Conditions Conditions
+	Heights    []uint64      // Head of every node at the end of the run
+	Elapsed    time.Duration // Virtual time elapsed
+	Events     int           // Callbacks run
+	Sent       int           // Messages sent, including the ones sent by a node to itself
+	Dropped    int           // Messages lost by the network
+	Violations []error       // Safety violations
+	Live       bool          // Whether every node reached the configured height in time
+}
+
+// Err returns the first safety violation of the run, or an error if the run
+// was not live.
+func (r *Result) Err() error {
+	if len(r.Violations) > 0 {
+		return fmt.Errorf("seed %d: %v", r.Seed, r.Violations[0])
+	}
+	if !r.Live {
+		return fmt.Errorf("seed %d: not live, heights %v after %v and %d events (%v)", r.Seed, r.Heights, r.Elapsed, r.Events, r.Conditions)
+	}
+	return nil
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("seed=%d heights=%v elapsed=%v events=%d sent=%d dropped=%d violations=%d live=%v",
+		r.Seed, r.Heights, r.Elapsed, r.Events, r.Sent, r.Dropped, len(r.Violations), r.Live)
+}
+
+// Simulator connects a set of nodes through a simulated network and checks
+// the blocks they commit.
+type Simulator struct {
+	config Config
+	rng    *rand.Rand
+	clock  *clock.VirtualClock
+	start  time.Time
+
+	keys    []*ecdsa.PrivateKey
+	addrs   []common.Address
+	nodes   []Node
+	stopped []bool
+
+	genesis   *types.Block
+	heads     []*types.Block
+	canonical map[uint64]*types.Block
+
+	delivered []map[common.Hash]struct{}     // messages delivered to each node
+	gossiped  []map[common.Hash]map[int]bool // messages sent by each node, per target
+
+	result *Result
+}
+
+// NewSimulator creates the nodes of a run. Every random choice of the run,
+// including the validator keys, is derived from the seed.
+func NewSimulator(config Config, seed int64, factory NodeFactory) *Simulator {
+	s := &Simulator{
+		config:    config,
+		rng:       rand.New(rand.NewSource(seed)),
+		start:     time.Unix(1546300800, 0),
+		canonical: make(map[uint64]*types.Block),
+		result:    &Result{Seed: seed},
+	}
+	s.clock = clock.NewVirtualClock(s.start)
+	if config.RandomConditions {
+		s.config.Conditions = RandomConditions(s.rng, config.Nodes)
+	}
+	s.result.Conditions = s.config.Conditions
+
+	// Derive the keys from the seed and index the nodes in the order the
+	// validator sets sort their addresses
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	for i := uint64(0); len(s.keys) < config.Nodes; i++ {
+		binary.BigEndian.PutUint64(buf[8:], i)
+		key, err := crypto.ToECDSA(crypto.Keccak256(buf[:]))
+		if err != nil {
+			continue
+		}
+		s.keys = append(s.keys, key)
+	}
+	sort.Slice(s.keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(s.keys[i].PublicKey), crypto.PubkeyToAddress(s.keys[j].PublicKey)
+		return bytes.Compare(a.Bytes(), b.Bytes()) < 0
+	})
+	for _, key := range s.keys {
+		s.addrs = append(s.addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	s.genesis = types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		Time:       uint64(s.start.Unix()),
+	})
+	s.canonical[0] = s.genesis
+
+	s.nodes = make([]Node, config.Nodes)
+	s.stopped = make([]bool, config.Nodes)
+	s.heads = make([]*types.Block, config.Nodes)
+	s.delivered = make([]map[common.Hash]struct{}, config.Nodes)
+	s.gossiped = make([]map[common.Hash]map[int]bool, config.Nodes)
+	for i := range s.nodes {
+		s.heads[i] = s.genesis
+		s.delivered[i] = make(map[common.Hash]struct{})
+		s.gossiped[i] = make(map[common.Hash]map[int]bool)
+	}
+	for i := range s.nodes {
+		s.nodes[i] = factory(s, i)
+	}
+	return s
+}
+
+// Clock returns the virtual clock the nodes must schedule their timers on.
+func (s *Simulator) Clock() *clock.VirtualClock {
+	return s.clock
+}
+
+// Size returns the number of nodes.
+func (s *Simulator) Size() int {
+	return len(s.nodes)
+}
+
+// Key returns the private key of the node at index.
+func (s *Simulator) Key(index int) *ecdsa.PrivateKey {
+	return s.keys[index]
+}
+
+// Address returns the address of the node at index.
+func (s *Simulator) Address(index int) common.Address {
+	return s.addrs[index]
+}
+
+// Addresses returns the sorted addresses of all the nodes.
+func (s *Simulator) Addresses() []common.Address {
+	return append([]common.Address(nil), s.addrs...)
+}
+
+// Index returns the index of the node with the given address, or -1.
+func (s *Simulator) Index(addr common.Address) int {
+	for i, a := range s.addrs {
+		if a == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// Node returns the node at index.
+func (s *Simulator) Node(index int) Node {
+	return s.nodes[index]
+}
+
+// Genesis returns the block all the nodes start from.
+func (s *Simulator) Genesis() *types.Block {
+	return s.genesis
+}
+
+// Head returns the last block committed or imported by the node at index.
+func (s *Simulator) Head(index int) *types.Block {
+	return s.heads[index]
+}
+
+// HasBlock reports whether the given block is part of the chain of the node at index.
+func (s *Simulator) HasBlock(index int, hash common.Hash, number uint64) bool {
+	block, ok := s.canonical[number]
+	return ok && number <= s.heads[index].NumberU64() && block.Hash() == hash
+}
+
+// Block returns the block at number of the chain of the node at index, or nil.
+func (s *Simulator) Block(index int, number uint64) *types.Block {
+	if number > s.heads[index].NumberU64() {
+		return nil
+	}
+	return s.canonical[number]
+}
+
+// NewBlock creates the block the node at index proposes on top of parent. The
+// coinbase of the block is its proposer.
+func (s *Simulator) NewBlock(index int, parent *types.Block) *types.Block {
+	return types.NewBlockWithHeader(&types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   s.addrs[index],
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Difficulty: big.NewInt(1),
+		Time:       parent.Time() + 1,
+	})
+}
+
+// Send sends a message from a node to another one through the simulated network.
+func (s *Simulator) Send(from, to int, payload []byte) {
+	payload = common.CopyBytes(payload)
+	s.transmit(from, to, func() {
+		s.deliver(to, payload)
+	})
+}
+
+// Call sends a control message from a node to another one through the
+// simulated network, f runs on the receiving side if the message is delivered.
+func (s *Simulator) Call(from, to int, f func()) {
+	s.transmit(from, to, func() {
+		if !s.stopped[to] {
+			f()
+		}
+	})
+}
+
+func (s *Simulator) transmit(from, to int, receive func()) {
+	s.result.Sent++
+	var delay time.Duration
+	if from != to {
+		var ok bool
+		if delay, ok = s.config.Conditions.route(s.rng, s.clock.Now().Sub(s.start), from, to); !ok {
+			s.result.Dropped++
+			return
+		}
+	}
+	s.clock.AfterFunc(delay, receive)
+}
+
+// Broadcast sends a message to every node, including the sender.
+func (s *Simulator) Broadcast(from int, payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+	for to := range s.nodes {
+		s.markGossiped(from, to, hash)
+		s.Send(from, to, payload)
+	}
+}
+
+// Gossip sends a message to every other node the sender did not already send
+// it to, as the backends do with their recent message caches.
+func (s *Simulator) Gossip(from int, payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+	for to := range s.nodes {
+		if to == from || !s.markGossiped(from, to, hash) {
+			continue
+		}
+		s.Send(from, to, payload)
+	}
+}
+
+// markGossiped records that the message was sent from one node to another and
+// reports whether it was not sent before.
+func (s *Simulator) markGossiped(from, to int, hash common.Hash) bool {
+	targets, ok := s.gossiped[from][hash]
+	if !ok {
+		targets = make(map[int]bool)
+		s.gossiped[from][hash] = targets
+	}
+	if targets[to] {
+		return false
+	}
+	targets[to] = true
+	return true
+}
+
+func (s *Simulator) deliver(to int, payload []byte) {
+	if s.stopped[to] {
+		return
+	}
+	hash := crypto.Keccak256Hash(payload)
+	if _, ok := s.delivered[to][hash]; ok {
+		return
+	}
+	s.delivered[to][hash] = struct{}{}
+	s.nodes[to].Deliver(payload)
+}
+
+// Committed records a block committed by the consensus of the node at index
+// and checks it against the blocks committed by the other nodes.
+func (s *Simulator) Committed(index int, block *types.Block) {
+	number := block.NumberU64()
+	if canonical, ok := s.canonical[number]; ok {
+		if canonical.Hash() != block.Hash() {
+			s.violation("node %d committed block %s at height %d, conflicting with %s", index, block.Hash().Hex(), number, canonical.Hash().Hex())
+			return
+		}
+	} else {
+		s.canonical[number] = block
+	}
+
+	head := s.heads[index]
+	switch {
+	case number <= head.NumberU64():
+		// Already imported through sync
+	case number != head.NumberU64()+1 || block.ParentHash() != head.Hash():
+		s.violation("node %d committed block %s at height %d on top of head %d %s", index, block.Hash().Hex(), number, head.NumberU64(), head.Hash().Hex())
+	default:
+		s.heads[index] = block
+	}
+}
+
+func (s *Simulator) violation(format string, args ...interface{}) {
+	err := fmt.Errorf("%v: %s", s.clock.Now().Sub(s.start), fmt.Sprintf(format, args...))
+	s.result.Violations = append(s.result.Violations, err)
+}
+
+// sync lets every lagging node import the next block from the most advanced
+// peer it can reach, as the downloader would.
+func (s *Simulator) sync() {
+	elapsed := s.clock.Now().Sub(s.start)
+	for i := range s.nodes {
+		if s.stopped[i] {
+			continue
+		}
+		var best uint64
+		for j := range s.nodes {
+			if h := s.heads[j].NumberU64(); j != i && h > best && s.reachable(elapsed, i, j) {
+				best = h
+			}
+		}
+		if best <= s.heads[i].NumberU64() {
+			continue
+		}
+		block := s.canonical[s.heads[i].NumberU64()+1]
+		s.heads[i] = block
+		s.nodes[i].Import(block)
+	}
+	s.clock.AfterFunc(s.config.SyncInterval, s.sync)
+}
+
+func (s *Simulator) reachable(elapsed time.Duration, i, j int) bool {
+	c := &s.config.Conditions
+	if c.StableAfter > 0 && elapsed >= c.StableAfter {
+		return true
+	}
+	for k := range c.Partitions {
+		p := &c.Partitions[k]
+		if p.active(elapsed) && p.group(i) != p.group(j) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Simulator) reached() bool {
+	for _, head := range s.heads {
+		if head.NumberU64() < s.config.Heights {
+			return false
+		}
+	}
+	return true
+}
+
+// Run starts every node and runs the simulation until all of them reached the
+// configured height, the timeout expired or a safety violation occurred.
+func (s *Simulator) Run() *Result {
+	for i := range s.nodes {
+		s.clock.AfterFunc(0, s.nodes[i].Start)
+	}
+	if s.config.SyncInterval > 0 {
+		s.clock.AfterFunc(s.config.SyncInterval, s.sync)
+	}
+	maxEvents := s.config.MaxEvents
+	if maxEvents == 0 {
+		maxEvents = defaultMaxEvents
+	}
+	deadline := s.start.Add(s.config.Timeout)
+	for !s.reached() && len(s.result.Violations) == 0 && s.result.Events < maxEvents {
+		next, ok := s.clock.Next()
+		if !ok || next.After(deadline) {
+			break
+		}
+		s.clock.Step()
+		s.result.Events++
+	}
+	for i, node := range s.nodes {
+		s.stopped[i] = true
+		node.Stop()
+	}
+
+	s.result.Elapsed = s.clock.Now().Sub(s.start)
+	s.result.Live = s.reached()
+	for _, head := range s.heads {
+		s.result.Heights = append(s.result.Heights, head.NumberU64())
+	}
+	return s.result
+}
+
+// RunSeeds runs one simulation per seed and returns the results in order.
+func RunSeeds(config Config, seeds []int64, factory NodeFactory) []*Result {
+	results := make([]*Result, len(seeds))
+	for i, seed := range seeds {
+		results[i] = NewSimulator(config, seed, factory).Run()
+	}
+	return results
+}
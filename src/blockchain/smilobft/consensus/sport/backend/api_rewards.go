@@ -0,0 +1,101 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+// maxRewardsRange is the maximum number of blocks of a rewards report.
+const maxRewardsRange = 10000
+
+// RewardsAPI is a user facing RPC API to audit the block rewards of Sport.
+type RewardsAPI struct {
+	chain consensus.ChainReader
+	smilo *backend
+}
+
+// RewardsReport is the issuance of a range of blocks.
+type RewardsReport struct {
+	From    hexutil.Uint64                  `json:"from"`
+	To      hexutil.Uint64                  `json:"to"`
+	Total   *hexutil.Big                    `json:"total"`
+	Rewards map[common.Address]*hexutil.Big `json:"rewards"`
+}
+
+// GetRewards returns the block rewards issued by the blocks from and to, both
+// included, by recipient.
+func (api *RewardsAPI) GetRewards(from, to rpc.BlockNumber) (*RewardsReport, error) {
+	first, last := api.blockNumber(from), api.blockNumber(to)
+	if first > last {
+		return nil, fmt.Errorf("invalid block range %d-%d", first, last)
+	}
+	if last-first >= maxRewardsRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds %d blocks", first, last, maxRewardsRange)
+	}
+
+	total := new(big.Int)
+	rewards := make(map[common.Address]*hexutil.Big)
+	for number := first; number <= last; number++ {
+		// The genesis block is never finalized
+		if number == 0 {
+			continue
+		}
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		block := api.chain.GetBlock(header.Hash(), number)
+		if block == nil {
+			return nil, errUnknownBlock
+		}
+		if !hasBlockRewards(block.Header(), len(block.Transactions())) {
+			continue
+		}
+		issued, err := blockRewards(api.chain, api.smilo.config.CommunityAddress, block.Header())
+		if err != nil {
+			return nil, err
+		}
+		for addr, reward := range issued {
+			if rewards[addr] == nil {
+				rewards[addr] = new(hexutil.Big)
+			}
+			rewards[addr].ToInt().Add(rewards[addr].ToInt(), reward)
+			total.Add(total, reward)
+		}
+	}
+	return &RewardsReport{
+		From:    hexutil.Uint64(first),
+		To:      hexutil.Uint64(last),
+		Total:   (*hexutil.Big)(total),
+		Rewards: rewards,
+	}, nil
+}
+
+func (api *RewardsAPI) blockNumber(number rpc.BlockNumber) uint64 {
+	if number < 0 {
+		return api.chain.CurrentHeader().Number.Uint64()
+	}
+	return uint64(number)
+}
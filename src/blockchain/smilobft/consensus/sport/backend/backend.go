@@ -28,6 +28,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 
 	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
 	"go-smilo/src/blockchain/smilobft/consensus/sport"
 	"go-smilo/src/blockchain/smilobft/consensus/sport/smilobftcore"
 	"go-smilo/src/blockchain/smilobft/core/types"
@@ -59,6 +60,15 @@ func New(config *sport.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database)
 		recentMessages:   recentMessages,
 		knownMessages:    knownMessages,
 	}
+	if config.MessageTraceFile != "" {
+		recorder, err := msgtrace.NewRecorder(config.MessageTraceFile, backend.address)
+		if err != nil {
+			log.Error("Failed to open consensus message trace", "file", config.MessageTraceFile, "err", err)
+		} else {
+			log.Info("Recording consensus messages", "file", config.MessageTraceFile)
+			backend.recorder = recorder
+		}
+	}
 	backend.core = smilobftcore.New(backend, backend.config)
 	return backend
 }
@@ -76,5 +86,13 @@ func (sb *backend) Address() common.Address {
 }
 
 func (sb *backend) Close() error {
-	return nil
+	return sb.recorder.Close()
+}
+
+// HasPendingChanges implements consensus.PendingChanges, returning whether
+// candidates wait to be voted.
+func (sb *backend) HasPendingChanges() bool {
+	sb.candidatesLock.RLock()
+	defer sb.candidatesLock.RUnlock()
+	return len(sb.candidates) > 0
 }
@@ -89,11 +89,6 @@ var (
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 
 	errMismatchTxhashes = errors.New("mismatch transaction hashes")
-
-	// errWaitTransactions is returned if an empty block is attempted to be sealed
-	// on an instant chain (0 second period). It's important to refuse these as the
-	// block reward is zero, so an empty block just bloats the chain... fast.
-	errWaitTransactions = errors.New("waiting for transactions")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -349,6 +344,13 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	}
 	header.Extra = extra
 
+	// Epoch checkpoint headers commit to the validators which seal them
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.WriteCheckpoint(header); err != nil {
+			return err
+		}
+	}
+
 	// set header's timestamp
 	header.Time = parent.Time + sb.config.BlockPeriod
 	if int64(header.Time) < time.Now().Unix() {
@@ -365,13 +367,10 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 func (sb *backend) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
 	uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
 
-	// warn for empty blocks
-	number := header.Number.Int64()
-
-	//Will generate rewards for every block until block 40000000
-	//From this point on, ddd block rewards in Sport only if there is transactions in it
-	if header.Number.Cmp(big.NewInt(1)) > 0 && len(txs) > 0 || number < 40000000 {
-		AccumulateRewards(sb.config.CommunityAddress, state, header)
+	if hasBlockRewards(header, len(txs)) {
+		if err := AccumulateRewards(chain, sb.config.CommunityAddress, state, header); err != nil {
+			return nil, err
+		}
 	}
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
@@ -420,12 +419,6 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, stop <-
 		return nil, nil
 	}
 
-	log.Trace("If we're mining, but nothing is being processed, wake on new transactions ? ", "MinBlocksEmptyMining", sb.config.MinBlocksEmptyMining, "BlockNum", block.Number(), "BlockNum Cmp MinBlocksMining", block.Number().Cmp(sb.config.MinBlocksEmptyMining))
-	if len(block.Transactions()) == 0 && block.Number().Cmp(sb.config.MinBlocksEmptyMining) >= 0 {
-		log.Debug("Seal, Bail out errWaitTransactions")
-		return nil, errWaitTransactions
-	}
-
 	// get the proposed block hash and clear it if the seal() is completed.
 	sb.sealMu.Lock()
 	sb.proposedBlockHash = block.Hash()
@@ -473,6 +466,11 @@ func (sb *backend) APIs(chain consensus.ChainReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, smilo: sb},
 		Public:    true,
+	}, {
+		Namespace: "sport",
+		Version:   "1.0",
+		Service:   &RewardsAPI{chain: chain, smilo: sb},
+		Public:    true,
 	}}
 }
 
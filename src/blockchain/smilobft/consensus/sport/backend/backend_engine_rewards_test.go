@@ -20,7 +20,14 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/sport/smilobftcore"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
 func TestBlockRewards(t *testing.T) {
@@ -196,3 +203,71 @@ func TestBlockRewards(t *testing.T) {
 	}
 
 }
+
+// rewardsChain serves the parent header of the block rewards tests
+type rewardsChain struct {
+	consensus.ChainReader
+	config *params.ChainConfig
+	parent *types.Header
+}
+
+func (c *rewardsChain) Config() *params.ChainConfig { return c.config }
+func (c *rewardsChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if hash == c.parent.Hash() && number == c.parent.Number.Uint64() {
+		return c.parent
+	}
+	return nil
+}
+
+func TestConfiguredBlockRewards(t *testing.T) {
+	community := common.HexToAddress("0x0000000000000000000000000000000000000c0c")
+	coinbase := common.HexToAddress("0x000000000000000000000000000000000000c0b0")
+
+	config := *params.TestChainConfig
+	config.Sport = &params.SportConfig{Rewards: &params.SportRewardsConfig{
+		Block:            big.NewInt(10),
+		Schedule:         []params.SportRewardStage{{Until: big.NewInt(20), Reward: big.NewInt(1000)}, {Reward: big.NewInt(100)}},
+		CommunityAddress: community,
+		CommunityShare:   2500,
+		SignersShare:     5000,
+	}}
+	require.NoError(t, config.Sport.Rewards.Validate())
+
+	// Parent block committed by three signers
+	parent := &types.Header{Number: big.NewInt(14), MixDigest: types.SportDigest, Difficulty: defaultDifficulty}
+	extra, err := prepareExtra(parent, nil)
+	require.NoError(t, err)
+	parent.Extra = extra
+	var signers []common.Address
+	var seals [][]byte
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		seal, err := crypto.Sign(crypto.Keccak256(smilobftcore.PrepareCommittedSeal(parent.Hash())), key)
+		require.NoError(t, err)
+		seals = append(seals, seal)
+		signers = append(signers, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	require.NoError(t, writeCommittedSeals(parent, seals))
+	chain := &rewardsChain{config: &config, parent: parent}
+
+	// Before the activation the legacy rewards apply
+	header := &types.Header{Number: big.NewInt(9), Coinbase: coinbase}
+	rewards, err := blockRewards(chain, community.Hex(), header)
+	require.NoError(t, err)
+	require.Equal(t, map[common.Address]*big.Int{coinbase: big.NewInt(4e18), community: big.NewInt(1e18)}, rewards)
+
+	// 250 for the community, 375 / 3 for every signer and the rest for the coinbase
+	header = &types.Header{Number: big.NewInt(15), ParentHash: parent.Hash(), Coinbase: coinbase}
+	rewards, err = blockRewards(chain, "", header)
+	require.NoError(t, err)
+	want := map[common.Address]*big.Int{community: big.NewInt(250), coinbase: big.NewInt(375)}
+	for _, signer := range signers {
+		want[signer] = big.NewInt(125)
+	}
+	require.Equal(t, want, rewards)
+
+	// Without the parent header the signers can't be rewarded
+	header = &types.Header{Number: big.NewInt(25), Coinbase: coinbase}
+	_, err = blockRewards(chain, "", header)
+	require.Equal(t, consensus.ErrUnknownAncestor, err)
+}
@@ -31,6 +31,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/consensus/sport/smilobftcore"
 	"go-smilo/src/blockchain/smilobft/core/state"
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
 // verifySigner checks whether the signer is in parent's fullnode set
@@ -60,6 +61,12 @@ func (sb *backend) verifySigner(chain consensus.ChainReader, header *types.Heade
 	return nil
 }
 
+// CheckpointRules returns the seal rules of the Sport epoch checkpoint headers,
+// which fast and light sync verify without the headers in between.
+func (sb *backend) CheckpointRules(config *params.ChainConfig) *types.CheckpointRules {
+	return smilobftcore.CheckpointRules(config.SixtySixPercentBlock)
+}
+
 // verifyCommittedSeals checks whether every committed seal is signed by one of the parent's fullnodes
 func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
 	number := header.Number.Uint64()
@@ -74,6 +81,13 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 		return err
 	}
 
+	// Epoch checkpoint headers must commit to the validators of their height
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.VerifyCheckpointValidators(header, snap.fullnodes()); err != nil {
+			return err
+		}
+	}
+
 	extra, err := types.ExtractSportExtra(header)
 	if err != nil {
 		return err
@@ -117,27 +131,95 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	return nil
 }
 
-// AccumulateRewards (override from ethash) credits the coinbase of the given block with the mining reward.
-// The total reward consists of the static block reward and rewards for  the community.
-func AccumulateRewards(communityAddress string, state *state.StateDB, header *types.Header) {
-	// add reward based on chain progression
-	blockReward := getSmiloBlockReward(header.Number)
+// AccumulateRewards (override from ethash) credits the recipients of the block
+// rewards of the given block.
+func AccumulateRewards(chain consensus.ChainReader, communityAddress string, state *state.StateDB, header *types.Header) error {
+	rewards, err := blockRewards(chain, communityAddress, header)
+	if err != nil {
+		return err
+	}
+	for addr, reward := range rewards {
+		log.Debug("AccumulateRewards", "number", header.Number, "address", addr, "reward", reward)
+		state.AddBalance(addr, reward, header.Number)
+	}
+	return nil
+}
 
-	// Accumulate the rewards
-	reward := new(big.Int).Set(blockReward)
-	emptryAddress := common.Address{}
-	if header.Coinbase != emptryAddress {
+// hasBlockRewards returns whether a block with the given number of transactions
+// issues rewards. Every block is rewarded until block 40000000, from this point
+// on only the blocks with transactions are.
+func hasBlockRewards(header *types.Header, txs int) bool {
+	return header.Number.Cmp(big.NewInt(1)) > 0 && txs > 0 || header.Number.Int64() < 40000000
+}
 
-		log.Info("$$$$$$$$$$$$$$$$$$$$$ AccumulateRewards, block: ", "blockNum", header.Number.Int64(), "BlockReward", blockReward.Int64(), "Coinbase", header.Coinbase.Hex())
+// blockRewards returns the rewards issued by the given block, by recipient.
+// Before the activation of the rewards of the chain config, the coinbase gets
+// the reward of smiloTokenMetricsTable and the community address a quarter of
+// it on top.
+func blockRewards(chain consensus.ChainReader, communityAddress string, header *types.Header) (map[common.Address]*big.Int, error) {
+	rewards := make(map[common.Address]*big.Int)
+	if header.Coinbase == (common.Address{}) {
+		return rewards, nil
+	}
+	credit := func(addr common.Address, amount *big.Int) {
+		if amount.Sign() == 0 {
+			return
+		}
+		if rewards[addr] == nil {
+			rewards[addr] = new(big.Int)
+		}
+		rewards[addr].Add(rewards[addr], amount)
+	}
 
-		// Accumulate the rewards to community
+	config := chain.Config()
+	if !config.IsSportRewards(header.Number) {
+		blockReward := getSmiloBlockReward(header.Number)
 		if communityAddress != "" {
-			rewardForCommunity := new(big.Int).Div(blockReward, big.NewInt(4))
-			state.AddBalance(common.HexToAddress(communityAddress), rewardForCommunity, header.Number)
-			log.Info("$$$$$$$$$$$$$$$$$$$$$ AccumulateRewards, adding reward to community ", "rewardForCommunity", rewardForCommunity, "communityAddress", communityAddress)
+			credit(common.HexToAddress(communityAddress), new(big.Int).Div(blockReward, big.NewInt(4)))
+		}
+		credit(header.Coinbase, blockReward)
+		return rewards, nil
+	}
+
+	rewardsConfig := config.Sport.Rewards
+	signers, err := parentSigners(chain, header)
+	if err != nil {
+		return nil, err
+	}
+	community, signer, coinbase := rewardsConfig.Split(rewardsConfig.BlockReward(header.Number), len(signers))
+	credit(rewardsConfig.CommunityAddress, community)
+	for _, addr := range signers {
+		credit(addr, signer)
+	}
+	credit(header.Coinbase, coinbase)
+	return rewards, nil
+}
+
+// parentSigners returns the committed seal signers of the parent of header. The
+// seals of header itself are only added after it is finalized.
+func parentSigners(chain consensus.ChainReader, header *types.Header) ([]common.Address, error) {
+	number := header.Number.Uint64()
+	if number <= 1 {
+		return nil, nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	extra, err := types.ExtractSportExtra(parent)
+	if err != nil {
+		return nil, err
+	}
+	proposalSeal := smilobftcore.PrepareCommittedSeal(parent.Hash())
+	signers := make([]common.Address, 0, len(extra.CommittedSeal))
+	for _, seal := range extra.CommittedSeal {
+		addr, err := sport.GetSignatureAddress(proposalSeal, seal)
+		if err != nil {
+			return nil, errInvalidSignature
 		}
-		state.AddBalance(header.Coinbase, reward, header.Number)
+		signers = append(signers, addr)
 	}
+	return signers, nil
 }
 
 func getSmiloBlockReward(blockNum *big.Int) (blockReward *big.Int) {
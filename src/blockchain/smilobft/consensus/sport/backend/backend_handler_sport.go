@@ -49,6 +49,7 @@ func (sb *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		if err := msg.Decode(&data); err != nil {
 			return true, errDecodeFailed
 		}
+		sb.recorder.Inbound(addr, msg.Code, data)
 
 		hash := types.RLPHash(data)
 
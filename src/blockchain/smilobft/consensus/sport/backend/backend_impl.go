@@ -48,6 +48,7 @@ func (sb *backend) Broadcast(fullnodeSet sport.FullnodeSet, payload []byte) erro
 	msg := sport.MessageEvent{
 		Payload: payload,
 	}
+	sb.recorder.Outbound(sb.Address(), smilobftMsg, payload)
 	go sb.smilobftEventMux.Post(msg)
 	return nil
 }
@@ -89,6 +90,7 @@ func (sb *backend) Gossip(fullnodeSet sport.FullnodeSet, payload []byte) error {
 			if err != nil {
 				log.Error("Gossip, smilobftMsg message, FAIL!!!", "payload hash", hash.Hex(), "peer", p.String(), "err", err)
 			} else {
+				sb.recorder.Outbound(addr, smilobftMsg, payload)
 				//log.Debug("Gossip, smilobftMsg message, OK!!!", "payload hash", hash.Hex(), "peer", p.String())
 			}
 
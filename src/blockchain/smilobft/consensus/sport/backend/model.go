@@ -29,6 +29,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 
 	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
 	"go-smilo/src/blockchain/smilobft/consensus/sport"
 	"go-smilo/src/blockchain/smilobft/consensus/sport/fullnode"
 	"go-smilo/src/blockchain/smilobft/consensus/sport/smilobftcore"
@@ -68,6 +69,8 @@ type backend struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	recorder *msgtrace.Recorder // optional trace of inbound and outbound smilobft messages
 }
 
 // ----------------------------------------------------------------------------
@@ -34,6 +34,7 @@ type Config struct {
 	MinFunds             int64         `toml:",omitempty"` // The minimum funds a node should have to be a full node
 	CommunityAddress     string        `toml:",omitempty"` // The community address for miner donations
 	MinBlocksEmptyMining *big.Int      `toml:",omitempty"` // Min Blocks to mine before Stop Mining Empty Blocks
+	MessageTraceFile     string        `toml:",omitempty"` // File to record consensus messages to, disabled if empty
 }
 
 var DefaultConfig = &Config{
@@ -17,6 +17,10 @@
 package smilobftcore
 
 import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
 	"go-smilo/src/blockchain/smilobft/consensus/sport"
@@ -112,11 +116,25 @@ func (c *core) storeBacklog(msg *message, src sport.Fullnode) {
 	c.backlogs[src.Address()] = backlog
 }
 
+// backlogSources returns the senders of the backlogs ordered by address, so
+// that the backlog events are always posted in the same order
+func (c *core) backlogSources() []common.Address {
+	srcs := make([]common.Address, 0, len(c.backlogs))
+	for src := range c.backlogs {
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return bytes.Compare(srcs[i].Bytes(), srcs[j].Bytes()) < 0
+	})
+	return srcs
+}
+
 func (c *core) processBacklog() {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	for srcAddress, backlog := range c.backlogs {
+	for _, srcAddress := range c.backlogSources() {
+		backlog := c.backlogs[srcAddress]
 		if backlog == nil {
 			continue
 		}
@@ -169,7 +187,7 @@ func (c *core) processBacklog() {
 			}
 			logger.Trace("Post backlog event", "msg", msg)
 
-			go c.sendEvent(backlogEvent{
+			c.sendEventAsync(backlogEvent{
 				src: src,
 				msg: msg,
 			})
@@ -18,8 +18,10 @@
 package smilobftcore
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/sport"
 )
 
@@ -325,6 +328,52 @@ func TestProcessBacklog(t *testing.T) {
 	}
 }
 
+func TestProcessBacklogOrder(t *testing.T) {
+	start := time.Unix(0, 0)
+	vclock := clock.NewVirtualClock(start)
+
+	vset := newTestFullnodeSet(4)
+	var posted []common.Address
+	c := &core{
+		logger:      log.New("backend", "test", "id", 0),
+		backlogs:    make(map[common.Address]*prque.Prque),
+		backlogsMu:  new(sync.Mutex),
+		fullnodeSet: vset,
+		state:       StatePrepared,
+		current: newRoundState(&sport.View{
+			Sequence: big.NewInt(1),
+			Round:    big.NewInt(0),
+		}, vset, common.Hash{}, nil, nil, nil),
+		clock: vclock,
+		postEvent: func(ev interface{}) {
+			posted = append(posted, ev.(backlogEvent).src.Address())
+		},
+	}
+
+	subject := &sport.Subject{
+		View:   c.currentView(),
+		Digest: cmn.StringToHash("1234567890"),
+	}
+	subjectPayload, _ := Encode(subject)
+
+	var want []common.Address
+	nodes := vset.List()
+	for i := len(nodes) - 1; i >= 0; i-- {
+		c.storeBacklog(&message{Code: msgCommit, Msg: subjectPayload}, nodes[i])
+		want = append(want, nodes[i].Address())
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return bytes.Compare(want[i].Bytes(), want[j].Bytes()) < 0
+	})
+
+	c.processBacklog()
+	vclock.RunUntil(start)
+
+	if !reflect.DeepEqual(posted, want) {
+		t.Errorf("events mismatch: have %v, want %v", posted, want)
+	}
+}
+
 func testProcessBacklog(t *testing.T, msg *message) {
 	vset := newTestFullnodeSet(1)
 	backend := &testSystemBackend{
@@ -86,6 +86,12 @@ func (c *core) handleCommit(msg *message, src sport.Fullnode) error {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
 		c.current.LockHash()
 		c.commit()
+	} else if c.current.GetPrepareOrCommitSize() >= requiredMinApprovers && c.state.Cmp(StatePrepared) < 0 {
+		// A COMMIT counts as a PREPARE too, as in handlePrepare. Locked fullnodes only send COMMIT
+		// messages, without this the fullnodes that are not locked could never reach the Prepared state.
+		c.current.LockHash()
+		c.setState(StatePrepared)
+		c.sendCommit()
 	} else {
 		logger := c.logger.New("state", c.state)
 		logger.Debug("******* Commit the proposal waiting consensus, ", "actualCommits", actualCommits, "requiredMinApprovers", requiredMinApprovers, "StateCommitted", c.state.Cmp(StateCommitted))
@@ -267,6 +267,91 @@ func sendCommitMessage(r0 *core, N uint64, subject *sport.Subject) error {
 	}, node)
 }
 
+// A COMMIT counts as a PREPARE, so a fullnode that is not locked reaches the
+// Prepared state with PREPARE and COMMIT messages of MinApprovers distinct fullnodes.
+func TestHandleCommitAsPrepare(t *testing.T) {
+	testCases := []struct {
+		name          string
+		prepares      []uint64
+		commits       []uint64
+		expectedState State
+	}{
+		{
+			"one prepare and four commits",
+			[]uint64{1},
+			[]uint64{2, 3, 4, 5},
+			StatePrepared,
+		},
+		{
+			"prepare and commit of the same fullnode",
+			[]uint64{2},
+			[]uint64{2, 3, 4, 5},
+			StatePreprepared,
+		},
+		{
+			"not enough commits",
+			[]uint64{},
+			[]uint64{2, 3, 4, 5},
+			StatePreprepared,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			// 7 fullnodes, 5 approvers needed
+			sys := NewTestSystemWithBackend(7)
+			sys.Run(false)
+
+			v0 := sys.backends[0]
+			r0 := v0.engine.(*core)
+			r0.fullnodeSet = v0.peers
+			r0.current = newTestRoundState(
+				&sport.View{
+					Round:    big.NewInt(0),
+					Sequence: newTestBlockProposal().Number(),
+				},
+				r0.fullnodeSet,
+			)
+			r0.state = StatePreprepared
+
+			subject := r0.current.Subject()
+			m, _ := Encode(subject)
+			for _, i := range test.prepares {
+				node := r0.fullnodeSet.GetByIndex(i)
+				err := r0.current.Prepares.Add(&message{
+					Code:    msgPrepare,
+					Msg:     m,
+					Address: node.Address(),
+				})
+				require.NoError(t, err)
+			}
+			for _, i := range test.commits {
+				require.NoError(t, sendCommitMessage(r0, i, subject))
+			}
+
+			if r0.state != test.expectedState {
+				t.Fatalf("state mismatch: have %v, want %v", r0.state, test.expectedState)
+			}
+			if locked := r0.current.IsHashLocked(); locked != (test.expectedState == StatePrepared) {
+				t.Errorf("locked mismatch: have %v, want %v", locked, !locked)
+			}
+			if test.expectedState != StatePrepared {
+				return
+			}
+
+			// the fullnode sends its own COMMIT once prepared
+			if len(v0.sentMsgs) != 1 {
+				t.Fatalf("the Send() should be called once: times %v", len(v0.sentMsgs))
+			}
+			decodedMsg := new(message)
+			require.NoError(t, decodedMsg.FromPayload(v0.sentMsgs[0], nil))
+			if decodedMsg.Code != msgCommit {
+				t.Errorf("message code mismatch: have %v, want %v", decodedMsg.Code, msgCommit)
+			}
+		})
+	}
+}
+
 // round is not checked for now
 func TestVerifyCommit(t *testing.T) {
 	// for log purpose
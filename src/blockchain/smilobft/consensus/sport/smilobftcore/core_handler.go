@@ -88,70 +88,87 @@ func (c *core) handleEvents() {
 				return
 			}
 			// A real event arrived, process interesting content
-			switch ev := event.Data.(type) {
-			case sport.RequestEvent:
-				c.logger.Debug("$$$ SmiloBFT, handleEvents, RequestEvent arrived, will handleRequest", "BlockProposal", ev.BlockProposal.Hash().Hex())
-				//SPORT:1
-				r := &sport.Request{
-					BlockProposal: ev.BlockProposal,
-				}
-				err := c.handleRequest(r)
-				if err == errFutureMessage {
-					c.logger.Debug("$$$ SmiloBFT, handleEvents, RequestEvent arrived, errFutureMessage", "BlockProposal", ev.BlockProposal.Hash().Hex())
-					c.storeRequestMsg(r)
-				}
-			case sport.MessageEvent:
-				if err := c.handleMsg(ev.Payload); err == nil {
-					c.logger.Debug("$$$ SmiloBFT, handleEvents, MessageEvent arrived, will send Gossip to fullnodeSet")
-					err = c.backend.Gossip(c.fullnodeSet, ev.Payload)
-					if err != nil {
-						c.logger.Error("$$$ SmiloBFT, handleEvents, handleMsg, failed to backend.Gossip", "err", err)
-					}
-				} else {
-					c.logger.Error("$$$ SmiloBFT, handleEvents, handleMsg", "err", err)
-				}
-			case backlogEvent:
-				// No need to check signature for internal messages
-				if err := c.handleCheckedMsg(ev.msg, ev.src); err == nil {
-					p, err := ev.msg.Payload()
-					if err != nil {
-						c.logger.Warn("handleEvents, Get message payload failed", "err", err)
-						continue
-					}
-					err = c.backend.Gossip(c.fullnodeSet, p)
-					if err != nil {
-						c.logger.Error("$$$ SmiloBFT, handleEvents, handleCheckedMsg, backend.Gossip ", "err", err)
-					}
-				}
-			}
-		case _, ok := <-c.timeoutSub.Chan():
+			c.handleEvent(event.Data)
+		case event, ok := <-c.timeoutSub.Chan():
 			if !ok {
 				return
 			}
-			c.handleTimeoutMsg()
+			c.handleEvent(event.Data)
 		case event, ok := <-c.finalCommittedSub.Chan():
 			if !ok {
 				return
 			}
-			switch event.Data.(type) {
-			case sport.FinalCommittedEvent:
-				err := c.handleFinalCommitted()
-				if err != nil {
-					c.logger.Error("$$$ SmiloBFT, handleEvents, FinalCommittedEvent, handleFinalCommitted", "err", err)
-				}
+			c.handleEvent(event.Data)
+		}
+	}
+}
+
+// handleEvent processes a single external or internal event
+func (c *core) handleEvent(data interface{}) {
+	switch ev := data.(type) {
+	case sport.RequestEvent:
+		c.logger.Debug("$$$ SmiloBFT, handleEvents, RequestEvent arrived, will handleRequest", "BlockProposal", ev.BlockProposal.Hash().Hex())
+		//SPORT:1
+		r := &sport.Request{
+			BlockProposal: ev.BlockProposal,
+		}
+		err := c.handleRequest(r)
+		if err == errFutureMessage {
+			c.logger.Debug("$$$ SmiloBFT, handleEvents, RequestEvent arrived, errFutureMessage", "BlockProposal", ev.BlockProposal.Hash().Hex())
+			c.storeRequestMsg(r)
+		}
+	case sport.MessageEvent:
+		if err := c.handleMsg(ev.Payload); err == nil {
+			c.logger.Debug("$$$ SmiloBFT, handleEvents, MessageEvent arrived, will send Gossip to fullnodeSet")
+			err = c.backend.Gossip(c.fullnodeSet, ev.Payload)
+			if err != nil {
+				c.logger.Error("$$$ SmiloBFT, handleEvents, handleMsg, failed to backend.Gossip", "err", err)
 			}
+		} else {
+			c.logger.Error("$$$ SmiloBFT, handleEvents, handleMsg", "err", err)
+		}
+	case backlogEvent:
+		// No need to check signature for internal messages
+		if err := c.handleCheckedMsg(ev.msg, ev.src); err == nil {
+			p, err := ev.msg.Payload()
+			if err != nil {
+				c.logger.Warn("handleEvents, Get message payload failed", "err", err)
+				return
+			}
+			err = c.backend.Gossip(c.fullnodeSet, p)
+			if err != nil {
+				c.logger.Error("$$$ SmiloBFT, handleEvents, handleCheckedMsg, backend.Gossip ", "err", err)
+			}
+		}
+	case timeoutEvent:
+		c.handleTimeoutMsg()
+	case sport.FinalCommittedEvent:
+		err := c.handleFinalCommitted()
+		if err != nil {
+			c.logger.Error("$$$ SmiloBFT, handleEvents, FinalCommittedEvent, handleFinalCommitted", "err", err)
 		}
 	}
 }
 
 // sendEvent sends events to mux
 func (c *core) sendEvent(ev interface{}) {
+	if c.postEvent != nil {
+		c.postEvent(ev)
+		return
+	}
 	err := c.backend.EventMux().Post(ev)
 	if err != nil {
 		c.logger.Error("$$$ SmiloBFT, sendEvent", "err", err)
 	}
 }
 
+// sendEventAsync sends events to mux without blocking the caller
+func (c *core) sendEventAsync(ev interface{}) {
+	c.afterFunc(0, func() {
+		c.sendEvent(ev)
+	})
+}
+
 func (c *core) handleMsg(payload []byte) error {
 	logger := c.logger.New()
 
@@ -99,7 +99,7 @@ func (c *core) handlePreprepare(msg *message, src sport.Fullnode) error {
 		// if it's a future block, we will handle it again after the duration
 		if err == consensus.ErrFutureBlock {
 			c.stopFuturePreprepareTimer()
-			c.futurePreprepareTimer = time.AfterFunc(duration, func() {
+			c.futurePreprepareTimer = c.afterFunc(duration, func() {
 				c.sendEvent(backlogEvent{
 					src: src,
 					msg: msg,
@@ -0,0 +1,266 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package smilobftcore
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/mock/gomock"
+
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
+	"go-smilo/src/blockchain/smilobft/consensus/sport"
+	"go-smilo/src/blockchain/smilobft/consensus/sport/fullnode"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+// smilobftMsgCode is the p2p code used by the sport backend for consensus messages
+const smilobftMsgCode = 0x11
+
+// ReplayEvent describes a state transition of the core observed while replaying
+// a message trace.
+type ReplayEvent struct {
+	Record    int         // Index of the trace record that triggered the transition
+	Sequence  uint64      // Sequence after the transition
+	Round     int64       // Round after the transition
+	State     string      // State after the transition
+	Committed common.Hash // Hash of the committed block, if the transition is a commit
+	Err       error       // Error returned by the core while handling the record
+}
+
+func (e ReplayEvent) String() string {
+	if e.Committed != (common.Hash{}) {
+		return fmt.Sprintf("#%d commit sequence=%d round=%d hash=%s", e.Record, e.Sequence, e.Round, e.Committed.Hex())
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("#%d error sequence=%d round=%d state=%s err=%v", e.Record, e.Sequence, e.Round, e.State, e.Err)
+	}
+	return fmt.Sprintf("#%d state sequence=%d round=%d state=%s", e.Record, e.Sequence, e.Round, e.State)
+}
+
+// Replayer feeds recorded consensus messages into a smilobft core running
+// against a stub backend. The replaying core acts as an observer which is not
+// part of the fullnode set, so it never proposes and its own messages are not
+// counted, and every proposal in the trace is accepted as valid. The core runs
+// on a virtual clock which never advances, so round change timeouts are not
+// replayed and a replay is deterministic for a given trace.
+type Replayer struct {
+	core  *core
+	clock *clock.VirtualClock
+
+	fullnodes   []common.Address
+	policy      sport.SpeakerPolicy
+	lastBlock   *types.Block
+	lastSpeaker common.Address
+	blocks      map[common.Hash]bool
+
+	record int // Index of the record being replayed
+	known  map[common.Hash]struct{}
+	events []ReplayEvent
+}
+
+// NewReplayer creates a replayer starting at the sequence following lastBlock,
+// using a static fullnode set.
+func NewReplayer(fullnodes []common.Address, policy sport.SpeakerPolicy, lastBlock *types.Block,
+	lastSpeaker common.Address) *Replayer {
+	r := &Replayer{
+		clock:       clock.NewVirtualClock(time.Unix(0, 0)),
+		fullnodes:   fullnodes,
+		policy:      policy,
+		lastBlock:   lastBlock,
+		lastSpeaker: lastSpeaker,
+		blocks:      map[common.Hash]bool{lastBlock.Hash(): true},
+		record:      -1,
+		known:       make(map[common.Hash]struct{}),
+	}
+	r.core = New(newReplayBackend(r), sport.DefaultConfig).(*core)
+	r.core.clock = r.clock
+	r.core.postEvent = r.post
+	return r
+}
+
+// Replay feeds every consensus record of the trace to the core, in order, and
+// returns the observed state transitions. Loopback records, which hold the
+// messages the recording node delivered to itself, are replayed as inbound
+// messages; duplicates are dropped the same way the live backend drops them.
+func (r *Replayer) Replay(records []*msgtrace.Record) []ReplayEvent {
+	defer r.core.stopTimer()
+
+	r.core.startNewRound(common.Big0)
+	r.settle()
+	r.observe(nil)
+
+	for i, record := range records {
+		if record.Code != smilobftMsgCode || (record.Direction != msgtrace.Inbound && !record.Loopback()) {
+			continue
+		}
+		hash := sport.RLPHash([]byte(record.Payload))
+		if _, ok := r.known[hash]; ok {
+			continue
+		}
+		r.known[hash] = struct{}{}
+
+		r.record = i
+		if err := r.core.handleMsg(record.Payload); err != nil && err != errFutureMessage && err != errIgnored {
+			r.observe(err)
+		}
+		r.settle()
+		r.observe(nil)
+	}
+	return r.events
+}
+
+// Events returns the transitions observed so far.
+func (r *Replayer) Events() []ReplayEvent {
+	return r.events
+}
+
+// settle runs the backlog events and chain head updates the core scheduled
+// without delay. Timeouts are scheduled later on the clock and never run.
+func (r *Replayer) settle() {
+	r.clock.RunUntil(r.clock.Now())
+}
+
+// post handles the backlog events the core sends to itself. Timeouts never
+// fire on the replay clock.
+func (r *Replayer) post(ev interface{}) {
+	if _, ok := ev.(backlogEvent); ok {
+		r.core.handleEvent(ev)
+	}
+}
+
+// committed records the commit of block and moves the core to the next
+// sequence, as the live backend does once the block is inserted.
+func (r *Replayer) committed(block *types.Block) {
+	r.lastBlock = block
+	r.lastSpeaker = r.core.fullnodeSet.GetSpeaker().Address()
+	r.blocks[block.Hash()] = true
+	r.events = append(r.events, ReplayEvent{
+		Record:    r.record,
+		Sequence:  block.NumberU64(),
+		Round:     r.core.current.Round().Int64(),
+		Committed: block.Hash(),
+	})
+	r.clock.AfterFunc(0, func() {
+		r.core.handleEvent(sport.FinalCommittedEvent{})
+		r.observe(nil)
+	})
+}
+
+// observe records the current view of the core if it changed since the last event.
+func (r *Replayer) observe(err error) {
+	event := ReplayEvent{
+		Record:   r.record,
+		Sequence: r.core.current.Sequence().Uint64(),
+		Round:    r.core.current.Round().Int64(),
+		State:    r.core.state.String(),
+		Err:      err,
+	}
+	if err == nil {
+		for i := len(r.events) - 1; i >= 0; i-- {
+			last := r.events[i]
+			if last.Err != nil || last.Committed != (common.Hash{}) {
+				continue
+			}
+			if last.Sequence == event.Sequence && last.Round == event.Round && last.State == event.State {
+				return
+			}
+			break
+		}
+	}
+	r.events = append(r.events, event)
+}
+
+// ScanTrace returns the sorted senders of the smilobft messages in the trace
+// and the lowest sequence they refer to.
+func ScanTrace(records []*msgtrace.Record) ([]common.Address, uint64) {
+	var (
+		seen   = make(map[common.Address]struct{})
+		lowest uint64
+	)
+	for _, r := range records {
+		if r.Code != smilobftMsgCode {
+			continue
+		}
+		msg := new(message)
+		if err := rlp.DecodeBytes(r.Payload, msg); err != nil {
+			continue
+		}
+		seen[msg.Address] = struct{}{}
+
+		var view *sport.View
+		if msg.Code == msgPreprepare {
+			var p *sport.Preprepare
+			if err := msg.Decode(&p); err == nil {
+				view = p.View
+			}
+		} else {
+			var s *sport.Subject
+			if err := msg.Decode(&s); err == nil {
+				view = s.View
+			}
+		}
+		if view != nil && view.Sequence != nil && (lowest == 0 || view.Sequence.Uint64() < lowest) {
+			lowest = view.Sequence.Uint64()
+		}
+	}
+	senders := make([]common.Address, 0, len(seen))
+	for addr := range seen {
+		senders = append(senders, addr)
+	}
+	sort.Slice(senders, func(i, j int) bool { return senders[i].Hex() < senders[j].Hex() })
+	return senders, lowest
+}
+
+// newReplayBackend mocks the parts of sport.Backend used by the core of a
+// Replayer. Any other call panics, as the replay does not support it.
+func newReplayBackend(r *Replayer) sport.Backend {
+	b := sport.NewMockBackend(gomock.NewController(msgtrace.Reporter{}))
+	fullnodes := func(sport.BlockProposal) sport.FullnodeSet {
+		return fullnode.NewFullnodeSet(r.fullnodes, r.policy)
+	}
+	b.EXPECT().Address().Return(common.Address{}).AnyTimes()
+	b.EXPECT().Fullnodes(gomock.Any()).DoAndReturn(fullnodes).AnyTimes()
+	b.EXPECT().ParentFullnodes(gomock.Any()).DoAndReturn(fullnodes).AnyTimes()
+	b.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	b.EXPECT().Gossip(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	b.EXPECT().Commit(gomock.Any(), gomock.Any()).DoAndReturn(func(blockproposal sport.BlockProposal, seals [][]byte) error {
+		r.committed(blockproposal.(*types.Block))
+		return nil
+	}).AnyTimes()
+	b.EXPECT().Verify(gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	b.EXPECT().Sign(gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	b.EXPECT().LastBlockProposal().DoAndReturn(func() (sport.BlockProposal, common.Address) {
+		return r.lastBlock, r.lastSpeaker
+	}).AnyTimes()
+	b.EXPECT().HasBlockProposal(gomock.Any(), gomock.Any()).DoAndReturn(func(hash common.Hash, number *big.Int) bool {
+		return r.blocks[hash]
+	}).AnyTimes()
+	b.EXPECT().GetSpeaker(gomock.Any()).DoAndReturn(func(number uint64) common.Address {
+		if number == r.lastBlock.NumberU64() {
+			return r.lastSpeaker
+		}
+		return common.Address{}
+	}).AnyTimes()
+	b.EXPECT().HasBadBlockProposal(gomock.Any()).Return(false).AnyTimes()
+	return b
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package smilobftcore
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
+	"go-smilo/src/blockchain/smilobft/consensus/sport"
+	"go-smilo/src/blockchain/smilobft/consensus/sport/fullnode"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+func signedReplayPayload(t *testing.T, key *ecdsa.PrivateKey, code uint64, val interface{}, seal []byte) []byte {
+	encoded, err := Encode(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &message{
+		Code:          code,
+		Msg:           encoded,
+		Address:       crypto.PubkeyToAddress(key.PublicKey),
+		CommittedSeal: []byte{},
+	}
+	if seal != nil {
+		m.CommittedSeal, err = crypto.Sign(crypto.Keccak256(seal), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := m.PayloadNoSig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Signature, err = crypto.Sign(crypto.Keccak256(data), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := m.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestReplayCommitAndRoundChange(t *testing.T) {
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	var addrs []common.Address
+	for i := 0; i < 4; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		keys[addr] = key
+		addrs = append(addrs, addr)
+	}
+	fullnodeSet := fullnode.NewFullnodeSet(addrs, sport.RoundRobin)
+	fullnodeSet.CalcSpeaker(common.Address{}, 0)
+	speaker := fullnodeSet.GetSpeaker().Address()
+
+	var others []common.Address
+	for _, val := range fullnodeSet.List() {
+		if val.Address() != speaker {
+			others = append(others, val.Address())
+		}
+	}
+
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash()})
+	view := &sport.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}
+	subject := &sport.Subject{View: view, Digest: block.Hash()}
+
+	var records []*msgtrace.Record
+	add := func(addr common.Address, code uint64, val interface{}, seal []byte) {
+		records = append(records, &msgtrace.Record{
+			Direction: msgtrace.Inbound,
+			Peer:      addr,
+			Code:      smilobftMsgCode,
+			Payload:   signedReplayPayload(t, keys[addr], code, val, seal),
+		})
+	}
+
+	// Sequence 1: the speaker proposes at round 0 and a quorum commits
+	add(speaker, msgPreprepare, &sport.Preprepare{View: view, BlockProposal: block}, nil)
+	for _, addr := range others {
+		add(addr, msgPrepare, subject, nil)
+	}
+	for _, addr := range others {
+		add(addr, msgCommit, subject, PrepareCommittedSeal(block.Hash()))
+	}
+	// Sequence 2: a quorum moves to round 1
+	for _, addr := range others {
+		add(addr, msgRoundChange, &sport.Subject{View: &sport.View{Round: big.NewInt(1), Sequence: big.NewInt(2)}}, nil)
+	}
+	// Duplicates and outbound messages to other peers are ignored
+	records = append(records, records[len(records)-1])
+	records = append(records, &msgtrace.Record{Direction: msgtrace.Outbound, Peer: others[0], Code: smilobftMsgCode, Payload: records[0].Payload})
+
+	senders, lowest := ScanTrace(records)
+	if len(senders) != len(addrs) || lowest != 1 {
+		t.Errorf("have %d senders from sequence %d, want %d senders from sequence 1", len(senders), lowest, len(addrs))
+	}
+
+	events := NewReplayer(addrs, sport.RoundRobin, genesis, common.Address{}).Replay(records)
+	if again := NewReplayer(addrs, sport.RoundRobin, genesis, common.Address{}).Replay(records); !reflect.DeepEqual(again, events) {
+		t.Fatalf("replay is not deterministic, have %v, want %v", again, events)
+	}
+
+	var (
+		committed  bool
+		roundOne   bool
+		lastRecord = -1
+	)
+	for _, e := range events {
+		if e.Committed == block.Hash() && e.Sequence == 1 {
+			committed = true
+		}
+		if e.Sequence == 2 && e.Round == 1 {
+			roundOne = true
+		}
+		if e.Err != nil {
+			t.Errorf("unexpected replay error: %v", e)
+		}
+		if e.Record > lastRecord {
+			lastRecord = e.Record
+		}
+	}
+	if !committed {
+		t.Fatalf("block 1 was not committed: %v", events)
+	}
+	if !roundOne {
+		t.Fatalf("round change at sequence 2 was not reproduced: %v", events)
+	}
+	if lastRecord >= len(records)-2 {
+		t.Fatalf("duplicate or outbound record was replayed: %v", events)
+	}
+}
@@ -18,10 +18,12 @@
 package smilobftcore
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	elog "github.com/ethereum/go-ethereum/log"
 )
 
@@ -57,3 +59,28 @@ func TestNewRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckpointRules(t *testing.T) {
+	rules := CheckpointRules(big.NewInt(10))
+	if have, want := rules.CommitCode, PrepareCommittedSeal(common.Hash{})[common.HashLength]; have != want {
+		t.Errorf("commit code: have %d, want %d", have, want)
+	}
+	tests := []struct {
+		n      int
+		number int64
+		quorum int
+	}{
+		{4, 10, 2},
+		{4, 11, 3},
+		{6, 10, 3},
+		{6, 11, 4},
+	}
+	for _, tt := range tests {
+		if have := rules.Quorum(tt.n, big.NewInt(tt.number)); have != tt.quorum {
+			t.Errorf("quorum of %d fullnodes at block %d: have %d, want %d", tt.n, tt.number, have, tt.quorum)
+		}
+	}
+	if have := CheckpointRules(nil).Quorum(6, big.NewInt(1000)); have != 3 {
+		t.Errorf("quorum without fork: have %d, want 3", have)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/sport"
 )
 
@@ -44,7 +45,7 @@ type core struct {
 	events                *cmn.TypeMuxSubscription
 	finalCommittedSub     *cmn.TypeMuxSubscription
 	timeoutSub            *cmn.TypeMuxSubscription
-	futurePreprepareTimer *time.Timer
+	futurePreprepareTimer clock.Timer
 
 	fullnodeSet           sport.FullnodeSet
 	waitingForRoundChange bool
@@ -57,11 +58,17 @@ type core struct {
 	handlerWg *sync.WaitGroup
 
 	roundChangeSet   *roundChangeSet
-	roundChangeTimer *time.Timer
+	roundChangeTimer clock.Timer
 
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	// clock schedules the timers of the core, the system clock is used if nil
+	clock clock.Clock
+	// postEvent receives the events the core sends to itself instead of the
+	// backend event mux if set, so that a simulation can dispatch them in order
+	postEvent func(ev interface{})
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -87,3 +87,11 @@ func (sb *Backend) Address() common.Address {
 func (sb *Backend) Close() error {
 	return nil
 }
+
+// HasPendingChanges implements consensus.PendingChanges, returning whether
+// candidates wait to be voted.
+func (sb *Backend) HasPendingChanges() bool {
+	sb.candidatesLock.RLock()
+	defer sb.candidatesLock.RUnlock()
+	return len(sb.candidates) > 0
+}
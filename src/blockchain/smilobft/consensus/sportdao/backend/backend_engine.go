@@ -88,11 +88,6 @@ var (
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 
 	errMismatchTxhashes = errors.New("mismatch transaction hashes")
-
-	// errWaitTransactions is returned if an empty block is attempted to be sealed
-	// on an instant chain (0 second period). It's important to refuse these as the
-	// block reward is zero, so an empty block just bloats the chain... fast.
-	errWaitTransactions = errors.New("waiting for transactions")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -305,6 +300,13 @@ func (sb *Backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	}
 	header.Extra = extra
 
+	// Epoch checkpoint headers commit to the validators which seal them
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.WriteCheckpoint(header); err != nil {
+			return err
+		}
+	}
+
 	// set header's timestamp
 	header.Time = parent.Time + sb.config.BlockPeriod
 	if int64(header.Time) < time.Now().Unix() {
@@ -386,14 +388,6 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, stop <-
 		return nil, nil
 	}
 
-	log.Debug("will crash ?? ", "sb.config.MinBlocksEmptyMining", sb.config.MinBlocksEmptyMining)
-	log.Debug("will crash ?? ", "block.Number()", block.Number())
-	log.Trace("If we're mining, but nothing is being processed, wake on new transactions ? ", "MinBlocksEmptyMining", sb.config.MinBlocksEmptyMining, "BlockNum", block.Number(), "BlockNum Cmp MinBlocksMining", block.Number().Cmp(sb.config.MinBlocksEmptyMining))
-	if len(block.Transactions()) == 0 && block.Number().Cmp(sb.config.MinBlocksEmptyMining) >= 0 {
-		log.Debug("Seal, Bail out errWaitTransactions")
-		return nil, errWaitTransactions
-	}
-
 	// get the proposed block hash and clear it if the seal() is completed.
 	sb.sealMu.Lock()
 	sb.proposedBlockHash = block.Hash()
@@ -22,8 +22,6 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/crypto"
-
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao/fullnode"
 	"go-smilo/src/blockchain/smilobft/core"
 
@@ -36,6 +34,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao/smilobftcore"
 	"go-smilo/src/blockchain/smilobft/core/state"
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 )
 
 // SetProposedBlockHash will set the proposed hash into the backend
@@ -71,6 +70,12 @@ func (sb *Backend) verifySigner(chain consensus.ChainReader, header *types.Heade
 	return errUnauthorized
 }
 
+// CheckpointRules returns the seal rules of the SportDAO epoch checkpoint headers,
+// which fast and light sync verify without the headers in between.
+func (sb *Backend) CheckpointRules(config *params.ChainConfig) *types.CheckpointRules {
+	return smilobftcore.CheckpointRules(config.SixtySixPercentBlock)
+}
+
 // verifyCommittedSeals checks whether every committed seal is signed by one of the parent's fullnodes
 func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
 	number := header.Number.Uint64()
@@ -84,6 +89,12 @@ func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 		return err
 	}
 	fullnodes := fullnode.NewSet(fullnodeAddresses, sb.config.GetProposerPolicy())
+	// Epoch checkpoint headers must commit to the validators of their height
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.VerifyCheckpointValidators(header, fullnodeAddresses); err != nil {
+			return err
+		}
+	}
 
 	extra, err := types.ExtractSportExtra(header)
 	if err != nil {
@@ -354,23 +365,23 @@ func (sb *Backend) getValidators(header *types.Header, chain consensus.ChainRead
 			chain := chain
 			return sb.retrieveSavedValidators(i, chain)
 		}
-		contractAddress, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
+		_, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
 		if err != nil {
 			log.Error("Deploy autonity contract error", "error", err)
 			return nil, err
 		}
-		sb.autonityContractAddress = contractAddress
 		validators, err = sb.retrieveSavedValidators(1, chain)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		if sb.autonityContractAddress == common.HexToAddress("0000000000000000000000000000000000000000") {
-			sb.autonityContractAddress = crypto.CreateAddress(chain.Config().AutonityContractConfig.Deployer, 0)
-		}
 		log.Warn("Autonity Contract Deployer, getValidators", "header Number", header.Number.Int64(), "Address", chain.Config().AutonityContractConfig.Deployer)
 
 		var err error
+		if err = sb.blockchain.GetAutonityContract().ApplyUpgrade(header, state); err != nil {
+			log.Error("ApplyUpgrade error", "error", err)
+			return nil, err
+		}
 		validators, err = sb.blockchain.GetAutonityContract().ContractGetValidators(chain, header, state)
 		if err != nil {
 			log.Error("ContractGetValidators error", "error", err)
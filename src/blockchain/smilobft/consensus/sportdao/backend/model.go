@@ -70,8 +70,7 @@ type Backend struct {
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
 
-	autonityContractAddress common.Address // Ethereum address of the autonity contract
-	vmConfig                *vm.Config
+	vmConfig *vm.Config
 }
 
 // ----------------------------------------------------------------------------
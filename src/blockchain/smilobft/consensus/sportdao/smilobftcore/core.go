@@ -26,6 +26,7 @@ import (
 
 	"math"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
 	"go-smilo/src/blockchain/smilobft/core/types"
 )
@@ -260,6 +261,14 @@ func (c *core) stopFuturePreprepareTimer() {
 	}
 }
 
+// afterFunc schedules f on the clock of the core
+func (c *core) afterFunc(d time.Duration, f func()) clock.Timer {
+	if c.clock == nil {
+		return time.AfterFunc(d, f)
+	}
+	return c.clock.AfterFunc(d, f)
+}
+
 func (c *core) stopTimer() {
 	c.stopFuturePreprepareTimer()
 
@@ -290,7 +299,7 @@ func (c *core) newRoundChangeTimer() {
 
 	c.roundChangeTimerMu.Lock()
 	defer c.roundChangeTimerMu.Unlock()
-	c.roundChangeTimer = time.AfterFunc(timeout, func() {
+	c.roundChangeTimer = c.afterFunc(timeout, func() {
 		c.logger.Debug("newRoundChangeTimer, Timeout for round !", "round", round, "timeout", timeout, "timeoutOriginal", time.Duration(c.config.RequestTimeout)*time.Millisecond)
 		c.sendEvent(timeoutEvent{})
 	})
@@ -17,6 +17,9 @@
 package smilobftcore
 
 import (
+	"bytes"
+	"sort"
+
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
@@ -90,6 +93,7 @@ func (c *core) storeBacklog(msg *message, src sportdao.Fullnode) {
 	defer c.backlogsMu.Unlock()
 
 	logger.Debug("Retrieving backlog queue", "for", src.Address(), "backlogs_size", len(c.backlogs))
+	src = c.backlogSource(src)
 	backlog := c.backlogs[src]
 	if backlog == nil {
 		backlog = prque.New()
@@ -112,11 +116,36 @@ func (c *core) storeBacklog(msg *message, src sportdao.Fullnode) {
 	c.backlogs[src] = backlog
 }
 
+// backlogSource returns the key of the backlog of src, as the copies of the
+// fullnode set hold distinct instances of the same fullnode
+func (c *core) backlogSource(src sportdao.Fullnode) sportdao.Fullnode {
+	for s := range c.backlogs {
+		if s.Address() == src.Address() {
+			return s
+		}
+	}
+	return src
+}
+
+// backlogSources returns the senders of the backlogs ordered by address, so
+// that the backlog events are always posted in the same order
+func (c *core) backlogSources() []sportdao.Fullnode {
+	srcs := make([]sportdao.Fullnode, 0, len(c.backlogs))
+	for src := range c.backlogs {
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return bytes.Compare(srcs[i].Address().Bytes(), srcs[j].Address().Bytes()) < 0
+	})
+	return srcs
+}
+
 func (c *core) processBacklog() {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	for srcAddress, backlog := range c.backlogs {
+	for _, srcAddress := range c.backlogSources() {
+		backlog := c.backlogs[srcAddress]
 		if backlog == nil {
 			continue
 		}
@@ -164,7 +193,7 @@ func (c *core) processBacklog() {
 			}
 			logger.Trace("Post backlog event", "msg", msg)
 
-			go c.sendEvent(backlogEvent{
+			c.sendEventAsync(backlogEvent{
 				src: srcAddress,
 				msg: msg,
 			})
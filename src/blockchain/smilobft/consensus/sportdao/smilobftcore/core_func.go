@@ -19,11 +19,14 @@ package smilobftcore
 
 import (
 	"bytes"
+	"math"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
+	"go-smilo/src/blockchain/smilobft/core/types"
 )
 
 // PrepareCommittedSeal returns a committed seal for the given hash
@@ -34,6 +37,22 @@ func PrepareCommittedSeal(hash common.Hash) []byte {
 	return buf.Bytes()
 }
 
+// CheckpointRules returns the seal rules of the epoch checkpoint headers: two
+// thirds of the fullnodes must commit them, one less up to the
+// sixtySixPercentBlock fork.
+func CheckpointRules(sixtySixPercentBlock *big.Int) *types.CheckpointRules {
+	return &types.CheckpointRules{
+		CommitCode: byte(msgCommit),
+		Quorum: func(n int, number *big.Int) int {
+			quorum := int(math.Ceil(float64(2*n) / 3.0))
+			if sixtySixPercentBlock == nil || number.Cmp(sixtySixPercentBlock) <= 0 {
+				quorum--
+			}
+			return quorum
+		},
+	}
+}
+
 func Encode(val interface{}) ([]byte, error) {
 	return rlp.EncodeToBytes(val)
 }
@@ -0,0 +1,159 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package smilobftcore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/simulation"
+	"go-smilo/src/blockchain/smilobft/consensus/simulation/simtest"
+	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
+	"go-smilo/src/blockchain/smilobft/consensus/sportdao/fullnode"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+func TestSimulation(t *testing.T) {
+	simtest.Run(t, newSimulationNode(sportdao.DefaultConfig))
+}
+
+// simulationNode runs a smilobft core on top of a deterministic network
+// simulation. Events are dispatched synchronously on the simulation goroutine
+// and every node requests a new block as soon as its chain head changes.
+type simulationNode struct {
+	sim    *simulation.Simulator
+	index  int
+	config *sportdao.Config
+	core   *core
+}
+
+// newSimulationNode returns a factory of simulated smilobft fullnodes.
+func newSimulationNode(config *sportdao.Config) simulation.NodeFactory {
+	return func(sim *simulation.Simulator, index int) simulation.Node {
+		n := &simulationNode{
+			sim:    sim,
+			index:  index,
+			config: config,
+		}
+		n.core = New(&simulationBackend{node: n}, config).(*core)
+		n.core.clock = sim.Clock()
+		n.core.postEvent = n.core.handleEvent
+		return n
+	}
+}
+
+func (n *simulationNode) Start() {
+	n.core.startNewRound(common.Big0)
+	n.request()
+}
+
+func (n *simulationNode) Deliver(payload []byte) {
+	n.core.handleEvent(sportdao.MessageEvent{Payload: payload})
+}
+
+func (n *simulationNode) Import(block *types.Block) {
+	n.newChainHead()
+}
+
+func (n *simulationNode) Stop() {
+	n.core.stopTimer()
+}
+
+// newChainHead moves the core to the next sequence, as the chain head event of
+// the backend does, and requests the block of the next sequence
+func (n *simulationNode) newChainHead() {
+	n.core.handleEvent(sportdao.FinalCommittedEvent{})
+	n.request()
+}
+
+// request hands the block the node mines on top of its head to the core, as
+// the Seal method of the backend does
+func (n *simulationNode) request() {
+	block := n.sim.NewBlock(n.index, n.sim.Head(n.index))
+	n.core.handleEvent(sportdao.RequestEvent{BlockProposal: block})
+}
+
+// simulationBackend implements sportdao.Backend over the simulated network
+type simulationBackend struct {
+	sportdao.Backend
+	node *simulationNode
+}
+
+func (b *simulationBackend) Address() common.Address {
+	return b.node.sim.Address(b.node.index)
+}
+
+func (b *simulationBackend) Fullnodes(number uint64) sportdao.FullnodeSet {
+	return fullnode.NewFullnodeSet(b.node.sim.Addresses(), b.node.config.SpeakerPolicy)
+}
+
+func (b *simulationBackend) Broadcast(fullnodeSet sportdao.FullnodeSet, payload []byte) error {
+	b.node.sim.Broadcast(b.node.index, payload)
+	return nil
+}
+
+func (b *simulationBackend) Gossip(fullnodeSet sportdao.FullnodeSet, payload []byte) error {
+	b.node.sim.Gossip(b.node.index, payload)
+	return nil
+}
+
+func (b *simulationBackend) Commit(blockproposal sportdao.BlockProposal, seals [][]byte) error {
+	b.node.sim.Committed(b.node.index, blockproposal.(*types.Block))
+	// The chain head event follows the block insertion
+	b.node.sim.Clock().AfterFunc(0, b.node.newChainHead)
+	return nil
+}
+
+func (b *simulationBackend) Verify(blockproposal sportdao.BlockProposal) (time.Duration, error) {
+	head := b.node.sim.Head(b.node.index)
+	block := blockproposal.(*types.Block)
+	if block.ParentHash() != head.Hash() || block.NumberU64() != head.NumberU64()+1 {
+		return 0, consensus.ErrUnknownAncestor
+	}
+	return 0, nil
+}
+
+func (b *simulationBackend) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), b.node.sim.Key(b.node.index))
+}
+
+func (b *simulationBackend) LastBlockProposal() (sportdao.BlockProposal, common.Address) {
+	head := b.node.sim.Head(b.node.index)
+	return head, head.Coinbase()
+}
+
+func (b *simulationBackend) HasBlockProposal(hash common.Hash, number *big.Int) bool {
+	return b.node.sim.HasBlock(b.node.index, hash, number.Uint64())
+}
+
+func (b *simulationBackend) SetProposedBlockHash(hash common.Hash) {}
+
+func (b *simulationBackend) GetSpeaker(number uint64) common.Address {
+	if block := b.node.sim.Block(b.node.index, number); block != nil {
+		return block.Coinbase()
+	}
+	return common.Address{}
+}
+
+func (b *simulationBackend) HasBadBlockProposal(hash common.Hash) bool {
+	return false
+}
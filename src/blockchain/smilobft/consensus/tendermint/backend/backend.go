@@ -30,6 +30,7 @@ import (
 
 	"go-smilo/src/blockchain/smilobft/cmn"
 	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
 	tendermintConfig "go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
 	tendermintCore "go-smilo/src/blockchain/smilobft/consensus/tendermint/core"
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/events"
@@ -102,6 +103,16 @@ func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb
 		vmConfig:       vmConfig,
 	}
 
+	if config.MessageTraceFile != "" {
+		recorder, err := msgtrace.NewRecorder(config.MessageTraceFile, backend.address)
+		if err != nil {
+			logger.Error("Failed to open consensus message trace", "file", config.MessageTraceFile, "err", err)
+		} else {
+			logger.Info("Recording consensus messages", "file", config.MessageTraceFile)
+			backend.recorder = recorder
+		}
+	}
+
 	backend.core = tendermintCore.New(backend, backend.config)
 
 	backend.pendingMessages.SetCapacity(ringCapacity)
@@ -145,9 +156,10 @@ type Backend struct {
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
 
-	autonityContractAddress common.Address // Ethereum address of the white list contract
-	contractsMu             sync.RWMutex
-	vmConfig                *vm.Config
+	recorder *msgtrace.Recorder // optional trace of inbound and outbound tendermint messages
+
+	contractsMu sync.RWMutex
+	vmConfig    *vm.Config
 }
 
 // Address implements tendermint.Backend.Address
@@ -174,6 +186,7 @@ func (sb *Backend) Broadcast(ctx context.Context, valSet validator.Set, payload
 	msg := events.MessageEvent{
 		Payload: payload,
 	}
+	sb.recorder.Outbound(sb.Address(), tendermintMsg, payload)
 	go func() {
 		sb.eventMux.Post(msg)
 	}()
@@ -199,7 +212,11 @@ func (sb *Backend) AskSync(valSet validator.Set) {
 				break
 			}
 			sb.logger.Info("Asking sync to", "addr", addr)
-			go p.Send(tendermintSyncMsg, []byte{}) //nolint
+			go func(addr common.Address, p consensus.Peer) {
+				if err := p.Send(tendermintSyncMsg, []byte{}); err == nil {
+					sb.recorder.Outbound(addr, tendermintSyncMsg, nil)
+				}
+			}(addr, p)
 			count++
 		}
 	}
@@ -242,6 +259,7 @@ func (sb *Backend) Gossip(ctx context.Context, valSet validator.Set, payload []b
 			if err != nil {
 				log.Error("Gossip, tendermintMsg message, FAIL!!!", "payload hash", hash.Hex(), "peer", p.String(), "err", err)
 			} else {
+				sb.recorder.Outbound(addr, tendermintMsg, payload)
 				log.Debug("Gossip, tendermintMsg message, OK!!!", "payload hash", hash.Hex(), "peer", p.String())
 			}
 		}
@@ -388,6 +406,11 @@ func (sb *Backend) VerifyProposal(proposal types.Block) (time.Duration, error) {
 				return 0, err
 			}
 		} else if proposalNumber > 1 {
+			err = sb.blockchain.GetAutonityContract().ApplyUpgrade(header, state)
+			if err != nil {
+				sb.logger.Error("Error when ApplyUpgrade Autonity Contract ", "err", err)
+				return 0, err
+			}
 			err = sb.blockchain.GetAutonityContract().ApplyPerformRedistribution(block.Transactions(), receipts, block.Header(), state)
 			if err != nil {
 				sb.logger.Error("Error when ApplyPerformRedistribution Autonity Contract ", "err", err)
@@ -522,7 +545,7 @@ func (sb *Backend) GetContractAddress() common.Address {
 }
 
 func (sb *Backend) GetContractABI() string {
-	return sb.blockchain.Config().AutonityContractConfig.ABI
+	return sb.blockchain.GetAutonityContract().ABI()
 }
 
 // Whitelist for the current block
@@ -584,6 +607,7 @@ func (sb *Backend) SyncPeer(address common.Address, messages []*tendermintCore.M
 		if err != nil {
 			log.Error("SyncPeer, tendermintMsg message, FAIL!!!", "payload hash", hash.Hex(), "peer", p.String(), "err", err)
 		} else {
+			sb.recorder.Outbound(address, tendermintMsg, payload)
 			log.Debug("SyncPeer, tendermintMsg message, OK!!!", "payload hash", hash.Hex(), "peer", p.String())
 		}
 	}
@@ -608,5 +632,5 @@ func (sb *Backend) Close() error {
 
 	close(sb.stopped)
 
-	return nil
+	return sb.recorder.Close()
 }
@@ -30,11 +30,11 @@ import (
 	"go-smilo/src/blockchain/smilobft/core"
 	"go-smilo/src/blockchain/smilobft/core/state"
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
 	"go-smilo/src/blockchain/smilobft/rpc"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -224,6 +224,12 @@ func (sb *Backend) verifySigner(chain consensus.ChainReader, header *types.Heade
 	return errUnauthorized
 }
 
+// CheckpointRules returns the seal rules of the Tendermint epoch checkpoint headers,
+// which fast and light sync verify without the headers in between.
+func (sb *Backend) CheckpointRules(config *params.ChainConfig) *types.CheckpointRules {
+	return tendermintCore.CheckpointRules()
+}
+
 // verifyCommittedSeals checks whether every committed seal is signed by one of the parent's validators
 func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
 	number := header.Number.Uint64()
@@ -237,6 +243,12 @@ func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 		return err
 	}
 	validators := validator.NewSet(validatorAddresses, sb.config.GetProposerPolicy())
+	// Epoch checkpoint headers must commit to the validators of their height
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.VerifyCheckpointValidators(header, validatorAddresses); err != nil {
+			return err
+		}
+	}
 
 	extra, err := types.ExtractBFTHeaderExtra(header)
 	if err != nil {
@@ -338,6 +350,13 @@ func (sb *Backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	}
 	header.Extra = extra
 
+	// Epoch checkpoint headers commit to the validators which seal them
+	if chain.Config().IsCheckpoint(header.Number) {
+		if err := types.WriteCheckpoint(header); err != nil {
+			return err
+		}
+	}
+
 	// set header's timestamp
 	header.Time = parent.Time + sb.config.BlockPeriod
 	if int64(header.Time) < time.Now().Unix() {
@@ -406,23 +425,23 @@ func (sb *Backend) getValidators(header *types.Header, chain consensus.ChainRead
 			chain := chain
 			return sb.retrieveSavedValidators(i, chain)
 		}
-		contractAddress, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
+		_, err := sb.blockchain.GetAutonityContract().DeployAutonityContract(chain, header, state)
 		if err != nil {
 			sb.logger.Error("Deploy autonity contract error", "error", err)
 			return nil, err
 		}
-		sb.autonityContractAddress = contractAddress
 		validators, err = sb.retrieveSavedValidators(1, chain)
 		if err != nil {
 			return nil, err
 		}
 
 	} else {
-		if sb.autonityContractAddress == common.HexToAddress("0000000000000000000000000000000000000000") {
-			sb.autonityContractAddress = crypto.CreateAddress(chain.Config().AutonityContractConfig.Deployer, 0)
-		}
 
 		var err error
+		if err = sb.blockchain.GetAutonityContract().ApplyUpgrade(header, state); err != nil {
+			sb.logger.Error("Autonity contract upgrade returns err", "err", err)
+			return nil, err
+		}
 		validators, err = sb.blockchain.GetAutonityContract().ContractGetValidators(chain, header, state)
 		if err != nil {
 			sb.logger.Error("ContractGetValidators returns err", "err", err)
@@ -19,7 +19,10 @@ package backend
 import (
 	"bytes"
 	tendermintCore "go-smilo/src/blockchain/smilobft/consensus/tendermint/core"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -29,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 
 	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/events"
 	"go-smilo/src/blockchain/smilobft/core/types"
 )
@@ -111,6 +115,54 @@ func TestSealCommitted(t *testing.T) {
 	}
 }
 
+// Tests that epoch checkpoint blocks keep their checkpoint through Finalize and
+// the sealing, and are imported by the chain.
+func TestSealCheckpoint(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	config := chain.Config()
+	config.Tendermint.Epoch, config.CheckpointBlock = 2, big.NewInt(0)
+	defer func() { config.CheckpointBlock, now = nil, time.Now }()
+
+	parent := chain.Genesis()
+	for i := 1; i <= 2; i++ {
+		block, err := makeBlockWithoutSeal(chain, engine, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Seal the block as the core commits it, with the seal of the single validator
+		if block, err = engine.updateBlock(block); err != nil {
+			t.Fatal(err)
+		}
+		committedSeal, err := engine.Sign(tendermintCore.PrepareCommittedSeal(block.Hash()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := block.Header()
+		if err := types.WriteCommittedSeals(header, [][]byte{committedSeal}); err != nil {
+			t.Fatal(err)
+		}
+		block = block.WithSeal(header)
+
+		now = func() time.Time {
+			return time.Unix(int64(header.Time), 0)
+		}
+		if err := engine.VerifyHeader(chain, block.Header(), false); err != nil {
+			t.Fatalf("block %d: header verification failed: %v", i, err)
+		}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("block %d: import failed: %v", i, err)
+		}
+		parent = block
+	}
+	checkpoint, err := types.ExtractCheckpoint(parent.Header())
+	if err != nil {
+		t.Fatalf("checkpoint block: %v", err)
+	}
+	if validators := engine.Validators(2).List(); len(checkpoint.Validators) != len(validators) || checkpoint.Validators[0] != validators[0].Address() {
+		t.Errorf("checkpoint validators mismatch: have %v", checkpoint.Validators)
+	}
+}
+
 func TestVerifyHeader(t *testing.T) {
 	chain, engine := newBlockChain(1)
 
@@ -581,6 +633,31 @@ func TestClose(t *testing.T) {
 			t.Fatalf("expected <nil>, got %v", err)
 		}
 	})
+
+	t.Run("engine is running, trace recorder closed", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "tendermint-trace")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		recorder, err := msgtrace.NewRecorder(filepath.Join(dir, "trace.jsonl"), common.Address{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := &Backend{
+			coreStarted: true,
+			stopped:     make(chan struct{}),
+			recorder:    recorder,
+		}
+		b.core = tendermintCore.New(b, b.config)
+
+		if err := b.Close(); err != nil {
+			t.Fatalf("expected <nil>, got %v", err)
+		}
+		if err := recorder.Close(); err == nil {
+			t.Fatal("expected the trace recorder to be closed")
+		}
+	})
 }
 
 func TestBackendSealHash(t *testing.T) {
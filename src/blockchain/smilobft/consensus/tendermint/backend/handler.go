@@ -97,6 +97,7 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		if err := msg.Decode(&data); err != nil {
 			return true, errDecodeFailed
 		}
+		sb.recorder.Inbound(addr, msg.Code, data)
 
 		hash := types.RLPHash(data)
 
@@ -135,6 +136,7 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 			return true, nil // we return nil as we don't want to shutdown the connection if core is stopped
 		}
 		sb.logger.Info("Received sync message", "from", addr)
+		sb.recorder.Inbound(addr, msg.Code, nil)
 		go func() {
 			err := sb.eventMux.Post(events.SyncEvent{Addr: addr})
 			if err != nil {
@@ -17,7 +17,9 @@
 package core
 
 import (
+	"bytes"
 	"math/big"
+	"sort"
 
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/validator"
 
@@ -77,6 +79,7 @@ func (c *core) storeBacklog(msg *Message, src validator.Validator) {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
+	src = c.backlogSource(src)
 	backlogPrque := c.backlogs[src]
 	if backlogPrque == nil {
 		backlogPrque = prque.New()
@@ -99,11 +102,36 @@ func (c *core) storeBacklog(msg *Message, src validator.Validator) {
 	c.backlogs[src] = backlogPrque
 }
 
+// backlogSource returns the key of the backlog of src, as the copies of the
+// validator set hold distinct instances of the same validator
+func (c *core) backlogSource(src validator.Validator) validator.Validator {
+	for s := range c.backlogs {
+		if s.Address() == src.Address() {
+			return s
+		}
+	}
+	return src
+}
+
+// backlogSources returns the senders of the backlogs ordered by address, so
+// that the backlog events are always posted in the same order
+func (c *core) backlogSources() []validator.Validator {
+	srcs := make([]validator.Validator, 0, len(c.backlogs))
+	for src := range c.backlogs {
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return bytes.Compare(srcs[i].Address().Bytes(), srcs[j].Address().Bytes()) < 0
+	})
+	return srcs
+}
+
 func (c *core) processBacklog() {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	for src, backlog := range c.backlogs {
+	for _, src := range c.backlogSources() {
+		backlog := c.backlogs[src]
 		if backlog == nil {
 			continue
 		}
@@ -151,7 +179,7 @@ func (c *core) processBacklog() {
 			}
 			logger.Debug("Post backlog event", "msg", msg)
 
-			go c.sendEvent(backlogEvent{
+			c.sendEventAsync(backlogEvent{
 				src: src,
 				msg: msg,
 			})
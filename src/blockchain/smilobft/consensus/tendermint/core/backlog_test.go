@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/validator"
 	"go-smilo/src/blockchain/smilobft/core/types"
 )
@@ -168,9 +169,85 @@ func TestStoreBacklog(t *testing.T) {
 			t.Fatalf("Expected message %+v, but got %+v", msg, savedMsg)
 		}
 	})
+
+	t.Run("copies of the same validator share a backlog", func(t *testing.T) {
+		c := &core{
+			logger:            log.New("backend", "test", "id", 0),
+			address:           common.HexToAddress("0x1234567890"),
+			backlogs:          make(map[validator.Validator]*prque.Prque),
+			currentRoundState: NewRoundState(big.NewInt(1), big.NewInt(2)),
+		}
+
+		vote := &Vote{
+			Round:  big.NewInt(1),
+			Height: big.NewInt(2),
+		}
+
+		votePayload, err := Encode(vote)
+		if err != nil {
+			t.Fatalf("have %v, want nil", err)
+		}
+
+		addr := common.HexToAddress("0x0987654321")
+		for i := 0; i < 2; i++ {
+			c.storeBacklog(&Message{Code: msgPrevote, Msg: votePayload}, validator.New(addr))
+		}
+
+		if len(c.backlogs) != 1 {
+			t.Fatalf("have %v backlogs, want 1", len(c.backlogs))
+		}
+		for _, pque := range c.backlogs {
+			if pque.Size() != 2 {
+				t.Fatalf("have %v messages, want 2", pque.Size())
+			}
+		}
+	})
 }
 
 func TestProcessBacklog(t *testing.T) {
+	t.Run("backlogs processed in address order", func(t *testing.T) {
+		vote := &Vote{
+			Round:  big.NewInt(1),
+			Height: big.NewInt(2),
+		}
+
+		votePayload, err := Encode(vote)
+		if err != nil {
+			t.Fatalf("have %v, want nil", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var posted []common.Address
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().Post(gomock.Any()).Do(func(ev interface{}) {
+			posted = append(posted, ev.(backlogEvent).src.Address())
+		}).Times(3)
+
+		start := time.Unix(0, 0)
+		vclock := clock.NewVirtualClock(start)
+		c := &core{
+			logger:            log.New("backend", "test", "id", 0),
+			backend:           backendMock,
+			address:           common.HexToAddress("0x1234567890"),
+			backlogs:          make(map[validator.Validator]*prque.Prque),
+			currentRoundState: NewRoundState(big.NewInt(1), big.NewInt(2)),
+			clock:             vclock,
+		}
+
+		addrs := []common.Address{common.HexToAddress("0x03"), common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+		for _, addr := range addrs {
+			c.storeBacklog(&Message{Code: msgPrevote, Msg: votePayload}, validator.New(addr))
+		}
+		c.setStep(prevote)
+		vclock.RunUntil(start)
+
+		want := []common.Address{addrs[1], addrs[2], addrs[0]}
+		if !reflect.DeepEqual(posted, want) {
+			t.Fatalf("have %v, want %v", posted, want)
+		}
+	})
 	t.Run("valid proposal received", func(t *testing.T) {
 		proposal := &Proposal{
 			Round:         big.NewInt(1),
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
 	"go-smilo/src/blockchain/smilobft/consensus/tendermint/validator"
 	"go-smilo/src/blockchain/smilobft/core/types"
@@ -114,7 +115,7 @@ type core struct {
 	committedSub            *cmn.TypeMuxSubscription
 	timeoutEventSub         *cmn.TypeMuxSubscription
 	syncEventSub            *cmn.TypeMuxSubscription
-	futureProposalTimer     *time.Timer
+	futureProposalTimer     clock.Timer
 	stopped                 chan struct{}
 	isStarted               *uint32
 	isStarting              *uint32
@@ -151,6 +152,9 @@ type core struct {
 	prevoteTimeout   *timeout
 	precommitTimeout *timeout
 
+	// clock schedules the timers of the core, the system clock is used if nil
+	clock clock.Clock
+
 	//map[futureRoundNumber]NumberOfMessagesReceivedForTheRound
 	futureRoundsChange map[int64]int64
 }
@@ -366,9 +370,9 @@ func (c *core) setCore(r *big.Int, h *big.Int, lastProposer common.Address) {
 	// We only add old round prevote messages to c.currentHeightOldRoundsStates, while future messages are sent to the
 	// backlog which are processed when the step is set to propose
 	if r.Int64() > 0 {
-		// This is a shallow copy, should be fine for now
+		// Update resets c.currentRoundState in place, so the old round keeps a copy of its own
 		c.currentHeightOldRoundsStatesMu.Lock()
-		c.currentHeightOldRoundsStates[r.Int64()-1] = c.currentRoundState
+		c.currentHeightOldRoundsStates[r.Int64()-1] = c.currentRoundState.copy()
 		c.currentHeightOldRoundsStatesMu.Unlock()
 	}
 	c.currentRoundState.Update(r, h)
@@ -409,6 +413,22 @@ func (c *core) setStep(step Step) {
 	c.processBacklog()
 }
 
+// afterFunc schedules f on the clock of the core
+func (c *core) afterFunc(d time.Duration, f func()) clock.Timer {
+	if c.clock == nil {
+		return time.AfterFunc(d, f)
+	}
+	return c.clock.AfterFunc(d, f)
+}
+
+// setClock replaces the clock of the core and of its step timeouts
+func (c *core) setClock(clk clock.Clock) {
+	c.clock = clk
+	c.proposeTimeout.clock = clk
+	c.prevoteTimeout.clock = clk
+	c.precommitTimeout.clock = clk
+}
+
 func (c *core) stopFutureProposalTimer() {
 	if c.futureProposalTimer != nil {
 		c.futureProposalTimer.Stop()
@@ -426,3 +446,14 @@ func PrepareCommittedSeal(hash common.Hash) []byte {
 	buf.Write([]byte{byte(msgPrecommit)})
 	return buf.Bytes()
 }
+
+// CheckpointRules returns the seal rules of the Tendermint epoch checkpoint
+// headers: the quorum of the validator set must precommit them.
+func CheckpointRules() *types.CheckpointRules {
+	return &types.CheckpointRules{
+		CommitCode: byte(msgPrecommit),
+		Quorum: func(n int, number *big.Int) int {
+			return int(math.Ceil((2 * float64(n)) / 3.))
+		},
+	}
+}
@@ -87,3 +87,45 @@ func TestCore_measureMetricsOnTimeOut(t *testing.T) {
 		}
 	})
 }
+
+func TestCore_SetCore(t *testing.T) {
+	t.Run("new round keeps the votes of the old round", func(t *testing.T) {
+		logger := log.New("core", "test", "id", 0)
+		c := &core{
+			address:                      common.Address{},
+			logger:                       logger,
+			proposeTimeout:               newTimeout(propose, logger),
+			prevoteTimeout:               newTimeout(prevote, logger),
+			precommitTimeout:             newTimeout(precommit, logger),
+			currentRoundState:            NewRoundState(big.NewInt(0), big.NewInt(1)),
+			currentHeightOldRoundsStates: make(map[int64]*roundState),
+			futureRoundsChange:           make(map[int64]int64),
+			valSet:                       &validatorSet{Set: newTestValidatorSet(4)},
+		}
+
+		hash := common.HexToHash("0x1")
+		c.currentRoundState.Prevotes.AddVote(hash, Message{Code: msgPrevote, Address: common.HexToAddress("0x2")})
+
+		c.setCore(big.NewInt(1), big.NewInt(1), common.Address{})
+
+		old, ok := c.currentHeightOldRoundsStates[0]
+		if !ok {
+			t.Fatal("round 0 not kept in the old rounds states")
+		}
+		if old == c.currentRoundState {
+			t.Fatal("round 0 aliases the current round state")
+		}
+		if r := old.Round().Int64(); r != 0 {
+			t.Errorf("old round: have %v, want 0", r)
+		}
+		if n := old.Prevotes.VotesSize(hash); n != 1 {
+			t.Errorf("old round prevotes: have %v, want 1", n)
+		}
+		if r := c.currentRoundState.Round().Int64(); r != 1 {
+			t.Errorf("current round: have %v, want 1", r)
+		}
+		if n := c.currentRoundState.Prevotes.TotalSize(); n != 0 {
+			t.Errorf("current round prevotes: have %v, want 0", n)
+		}
+	})
+}
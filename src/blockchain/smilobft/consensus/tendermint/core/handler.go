@@ -172,58 +172,17 @@ eventLoop:
 				break eventLoop
 			}
 			// A real ev arrived, process interesting content
-			switch e := ev.Data.(type) {
-			case events.MessageEvent:
-				if len(e.Payload) == 0 {
-					c.logger.Error("core.handleConsensusEvents Get message(MessageEvent) empty payload")
-				}
-
-				c.logger.Debug("$$$ tendermint, handleEvents, MessageEvent arrived, will send Gossip to valSet")
-
-				if err := c.handleMsg(ctx, e.Payload); err != nil {
-					c.logger.Debug("core.handleConsensusEvents Get message(MessageEvent) payload failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.valSet.Copy(), e.Payload)
-			case backlogEvent:
-				// No need to check signature for internal messages
-				c.logger.Debug("Started handling backlogEvent")
-				err := c.handleCheckedMsg(ctx, e.msg, e.src)
-				if err != nil {
-					c.logger.Debug("core.handleConsensusEvents handleCheckedMsg message failed", "err", err)
-					continue
-				}
-
-				p, err := e.msg.Payload()
-				if err != nil {
-					c.logger.Debug("core.handleConsensusEvents Get message payload failed", "err", err)
-					continue
-				}
-
-				c.backend.Gossip(ctx, c.valSet.Copy(), p)
-			}
+			c.handleEvent(ctx, ev.Data)
 		case ev, ok := <-c.timeoutEventSub.Chan():
 			if !ok {
 				break eventLoop
 			}
-			if timeoutE, ok := ev.Data.(TimeoutEvent); ok {
-				switch timeoutE.step {
-				case msgProposal:
-					c.handleTimeoutPropose(ctx, timeoutE)
-				case msgPrevote:
-					c.handleTimeoutPrevote(ctx, timeoutE)
-				case msgPrecommit:
-					c.handleTimeoutPrecommit(ctx, timeoutE)
-				}
-			}
+			c.handleEvent(ctx, ev.Data)
 		case ev, ok := <-c.committedSub.Chan():
 			if !ok {
 				break eventLoop
 			}
-			switch ev.Data.(type) {
-			case events.CommitEvent:
-				c.handleCommit(ctx)
-			}
+			c.handleEvent(ctx, ev.Data)
 		case <-ctx.Done():
 			c.logger.Info("handleConsensusEvents is stopped", "event", ctx.Err())
 			break eventLoop
@@ -233,6 +192,51 @@ eventLoop:
 	c.stopped <- struct{}{}
 }
 
+// handleEvent processes a single event modifying the consensus state
+func (c *core) handleEvent(ctx context.Context, data interface{}) {
+	switch e := data.(type) {
+	case events.MessageEvent:
+		if len(e.Payload) == 0 {
+			c.logger.Error("core.handleConsensusEvents Get message(MessageEvent) empty payload")
+		}
+
+		c.logger.Debug("$$$ tendermint, handleEvents, MessageEvent arrived, will send Gossip to valSet")
+
+		if err := c.handleMsg(ctx, e.Payload); err != nil {
+			c.logger.Debug("core.handleConsensusEvents Get message(MessageEvent) payload failed", "err", err)
+			return
+		}
+		c.backend.Gossip(ctx, c.valSet.Copy(), e.Payload)
+	case backlogEvent:
+		// No need to check signature for internal messages
+		c.logger.Debug("Started handling backlogEvent")
+		err := c.handleCheckedMsg(ctx, e.msg, e.src)
+		if err != nil {
+			c.logger.Debug("core.handleConsensusEvents handleCheckedMsg message failed", "err", err)
+			return
+		}
+
+		p, err := e.msg.Payload()
+		if err != nil {
+			c.logger.Debug("core.handleConsensusEvents Get message payload failed", "err", err)
+			return
+		}
+
+		c.backend.Gossip(ctx, c.valSet.Copy(), p)
+	case TimeoutEvent:
+		switch e.step {
+		case msgProposal:
+			c.handleTimeoutPropose(ctx, e)
+		case msgPrevote:
+			c.handleTimeoutPrevote(ctx, e)
+		case msgPrecommit:
+			c.handleTimeoutPrecommit(ctx, e)
+		}
+	case events.CommitEvent:
+		c.handleCommit(ctx)
+	}
+}
+
 func (c *core) syncLoop(ctx context.Context) {
 	/*
 		this method is responsible for asking the network to send us the current consensus state
@@ -277,6 +281,13 @@ func (c *core) sendEvent(ev interface{}) {
 	c.backend.Post(ev)
 }
 
+// sendEventAsync sends event to mux without blocking the caller
+func (c *core) sendEventAsync(ev interface{}) {
+	c.afterFunc(0, func() {
+		c.sendEvent(ev)
+	})
+}
+
 func (c *core) handleMsg(ctx context.Context, payload []byte) error {
 	logger := c.logger.New()
 
@@ -416,6 +416,11 @@ func TestHandlePrecommit(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Expected nil, got %v", err)
 		}
+		if !c.precommitTimeout.timerStarted() {
+			t.Fatal("Expected the pre-commit timeout to be started")
+		}
+		// The core has no backend to handle the timeout, stop it before it fires in a later test
+		_ = c.precommitTimeout.stopTimer()
 	})
 }
 
@@ -511,4 +516,7 @@ func TestHandleCommit(t *testing.T) {
 		valSet:            new(validatorSet),
 	}
 	c.handleCommit(context.Background())
+
+	// The new round schedules a propose timeout the mocked backend does not expect
+	_ = c.proposeTimeout.stopTimer()
 }
@@ -443,5 +443,10 @@ func TestHandlePrevote(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Expected nil, got %v", err)
 		}
+		if !c.prevoteTimeout.timerStarted() {
+			t.Fatal("Expected the pre-vote timeout to be started")
+		}
+		// The mocked backend does not expect the timeout, stop it before it fires in a later test
+		_ = c.prevoteTimeout.stopTimer()
 	})
 }
@@ -18,7 +18,6 @@ package core
 
 import (
 	"context"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -110,7 +109,7 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 			c.logger.Warn("if it's a future block, we will handle it again after the duration", "err", err, "duration", duration)
 
 			c.stopFutureProposalTimer()
-			c.futureProposalTimer = time.AfterFunc(duration, func() {
+			c.futureProposalTimer = c.afterFunc(duration, func() {
 				_, sender := c.valSet.GetByAddress(msg.Address)
 				toSend := backlogEvent{
 					src: sender,
@@ -129,12 +128,6 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 	if c.currentRoundState.Step() == propose {
 		c.logger.Warn("Here is about to accept the Proposal ", "c.currentRoundState.Step()", c.currentRoundState.Step())
 
-		if err := c.proposeTimeout.stopTimer(); err != nil {
-			c.logger.Error("propose, Here is about to accept the Proposal, proposeTimeout err ", "c.currentRoundState.Step()", c.currentRoundState.Step(), "err", err)
-			return err
-		}
-		c.logger.Debug("propose, Stopped Scheduled Proposal Timeout")
-
 		// Set the proposal for the current round
 		c.currentRoundState.SetProposal(&proposal, msg)
 
@@ -155,6 +148,9 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 
 			}
 			c.logger.Debug("prevote, Line 22 in Algorithm 1 of The latest gossip on BFT consensus", "voteForProposal", voteForProposal)
+			if err := c.stopProposeTimeout(); err != nil {
+				return err
+			}
 			c.sendPrevote(ctx, voteForProposal)
 			c.setStep(prevote)
 			return nil
@@ -180,6 +176,9 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 			c.logger.Debug("prevote, Line 28 in Algorithm 1 of The latest gossip on BFT consensus", "ok", ok, "vr", vr, "curR", curR, "rs.Prevotes.VotesSize(h)", rs.Prevotes.VotesSize(h),
 				"voteForProposal", voteForProposal)
 
+			if err := c.stopProposeTimeout(); err != nil {
+				return err
+			}
 			c.sendPrevote(ctx, voteForProposal)
 			c.setStep(prevote)
 		}
@@ -188,6 +187,17 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 	return nil
 }
 
+// stopProposeTimeout stops the propose timeout once the proposal is prevoted. As long as the proposal of a
+// valid round lacks the prevotes of that round, the timeout must still be able to move us to the prevote step.
+func (c *core) stopProposeTimeout() error {
+	if err := c.proposeTimeout.stopTimer(); err != nil {
+		c.logger.Error("propose, Here is about to accept the Proposal, proposeTimeout err ", "c.currentRoundState.Step()", c.currentRoundState.Step(), "err", err)
+		return err
+	}
+	c.logger.Debug("propose, Stopped Scheduled Proposal Timeout")
+	return nil
+}
+
 func (c *core) logProposalMessageEvent(message string, proposal Proposal, from, to string) {
 	c.logger.Debug(message,
 		"type", "Proposal",
@@ -332,6 +332,78 @@ func TestHandleProposal(t *testing.T) {
 		}
 	})
 
+	t.Run("valid proposal given, valid round lacks prevotes, propose timeout keeps running", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		addr := common.HexToAddress("0x0123456789")
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: big.NewInt(1),
+		})
+
+		curRoundState := NewRoundState(big.NewInt(2), big.NewInt(1))
+		validRound := big.NewInt(1)
+
+		logger := log.New("backend", "test", "id", 0)
+		proposalBlock := NewProposal(curRoundState.Round(), curRoundState.Height(), validRound, block, logger)
+		proposal, err := Encode(proposalBlock)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+
+		msg := &Message{
+			Code:          msgProposal,
+			Msg:           proposal,
+			Address:       addr,
+			CommittedSeal: []byte{},
+			Signature:     []byte{0x1},
+		}
+
+		valSetMock := validator.NewMockSet(ctrl)
+		valSetMock.EXPECT().IsProposer(addr).Return(true).AnyTimes()
+		valSetMock.EXPECT().GetProposer().AnyTimes()
+		valSetMock.EXPECT().Size().Return(4).AnyTimes()
+
+		valSet := &validatorSet{
+			Set: valSetMock,
+		}
+
+		var decProposal Proposal
+		if decErr := msg.Decode(&decProposal); decErr != nil {
+			t.Fatalf("Expected <nil>, got %v", decErr)
+		}
+
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(*decProposal.ProposalBlock)
+
+		c := &core{
+			address:           addr,
+			backend:           backendMock,
+			currentRoundState: curRoundState,
+			currentHeightOldRoundsStates: map[int64]*roundState{
+				1: NewRoundState(validRound, curRoundState.Height()),
+			},
+			logger:         logger,
+			proposeTimeout: newTimeout(propose, logger),
+			validRound:     validRound,
+			valSet:         valSet,
+		}
+		c.proposeTimeout.scheduleTimeout(time.Hour, curRoundState.Round().Int64(), curRoundState.Height().Int64(), func(r int64, h int64) {})
+		defer c.proposeTimeout.stopTimer()
+
+		err = c.handleProposal(context.Background(), msg)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+
+		if !c.proposeTimeout.timerStarted() {
+			t.Fatal("propose timeout stopped without a prevote")
+		}
+		if s := curRoundState.Step(); s != propose {
+			t.Fatalf("Expected step %v, got %v", propose, s)
+		}
+	})
+
 	t.Run("valid proposal given, valid round -1, pre-vote is sent", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
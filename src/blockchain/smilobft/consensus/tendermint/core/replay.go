@@ -0,0 +1,262 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/mock/gomock"
+
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/events"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/validator"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+// tendermintMsgCode is the p2p code used by the tendermint backend for consensus messages
+const tendermintMsgCode = 0x11
+
+// ReplayEvent describes a state transition of the core observed while replaying
+// a message trace.
+type ReplayEvent struct {
+	Record    int         // Index of the trace record that triggered the transition
+	Height    uint64      // Height after the transition
+	Round     int64       // Round after the transition
+	Step      string      // Step after the transition
+	Committed common.Hash // Hash of the committed block, if the transition is a commit
+	Err       error       // Error returned by the core while handling the record
+}
+
+func (e ReplayEvent) String() string {
+	if e.Committed != (common.Hash{}) {
+		return fmt.Sprintf("#%d commit height=%d round=%d hash=%s", e.Record, e.Height, e.Round, e.Committed.Hex())
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("#%d error height=%d round=%d step=%s err=%v", e.Record, e.Height, e.Round, e.Step, e.Err)
+	}
+	return fmt.Sprintf("#%d state height=%d round=%d step=%s", e.Record, e.Height, e.Round, e.Step)
+}
+
+// Replayer feeds recorded consensus messages into a tendermint core running
+// against a stub backend. The replaying core acts as an observer which is not
+// part of the validator set, so it never proposes and its own votes are not
+// counted, and every proposal in the trace is accepted as valid. The core runs
+// on a virtual clock which never advances, so timeouts are not replayed and
+// round changes are reproduced from the recorded messages only, which makes a
+// replay deterministic for a given trace.
+type Replayer struct {
+	core  *core
+	clock *clock.VirtualClock
+	ctx   context.Context
+
+	validators   []common.Address
+	policy       config.ProposerPolicy
+	lastBlock    *types.Block
+	lastProposer common.Address
+
+	record int // Index of the record being replayed
+	known  map[common.Hash]struct{}
+	events []ReplayEvent
+}
+
+// NewReplayer creates a replayer starting at the height following lastBlock,
+// using a static validator set.
+func NewReplayer(validators []common.Address, policy config.ProposerPolicy, lastBlock *types.Block,
+	lastProposer common.Address) *Replayer {
+	r := &Replayer{
+		clock:        clock.NewVirtualClock(time.Unix(0, 0)),
+		ctx:          context.Background(),
+		validators:   validators,
+		policy:       policy,
+		lastBlock:    lastBlock,
+		lastProposer: lastProposer,
+		record:       -1,
+		known:        make(map[common.Hash]struct{}),
+	}
+	r.core = New(newReplayBackend(r), config.DefaultConfig())
+	r.core.setClock(r.clock)
+	return r
+}
+
+// Replay feeds every consensus record of the trace to the core, in order, and
+// returns the observed state transitions. Loopback records, which hold the
+// messages the recording node delivered to itself, are replayed as inbound
+// messages; duplicates are dropped the same way the live backend drops them.
+func (r *Replayer) Replay(records []*msgtrace.Record) []ReplayEvent {
+	defer r.stopTimers()
+
+	r.core.startRound(r.ctx, common.Big0)
+	r.settle()
+	r.observe(nil)
+
+	for i, record := range records {
+		if record.Code != tendermintMsgCode || (record.Direction != msgtrace.Inbound && !record.Loopback()) {
+			continue
+		}
+		hash := types.RLPHash([]byte(record.Payload))
+		if _, ok := r.known[hash]; ok {
+			continue
+		}
+		r.known[hash] = struct{}{}
+
+		r.record = i
+		err := r.core.handleMsg(r.ctx, record.Payload)
+		if err != nil && err != errFutureHeightMessage && err != errFutureRoundMessage && err != errFutureStepMessage {
+			r.observe(err)
+		}
+		r.settle()
+		r.observe(nil)
+	}
+	return r.events
+}
+
+// Events returns the transitions observed so far.
+func (r *Replayer) Events() []ReplayEvent {
+	return r.events
+}
+
+// settle runs the backlog events and chain head updates the core scheduled
+// without delay. Timeouts are scheduled later on the clock and never run.
+func (r *Replayer) settle() {
+	r.clock.RunUntil(r.clock.Now())
+}
+
+// committed records the commit of block and moves the core to the next height,
+// as the live backend does once the block is inserted.
+func (r *Replayer) committed(block *types.Block) {
+	r.lastBlock = block
+	r.lastProposer = r.core.valSet.GetProposer().Address()
+	r.events = append(r.events, ReplayEvent{
+		Record:    r.record,
+		Height:    block.NumberU64(),
+		Round:     r.core.currentRoundState.Round().Int64(),
+		Committed: block.Hash(),
+	})
+	r.clock.AfterFunc(0, func() {
+		r.core.handleEvent(r.ctx, events.CommitEvent{})
+		r.observe(nil)
+	})
+}
+
+// observe records the current view of the core if it changed since the last event.
+func (r *Replayer) observe(err error) {
+	event := ReplayEvent{
+		Record: r.record,
+		Height: r.core.currentRoundState.Height().Uint64(),
+		Round:  r.core.currentRoundState.Round().Int64(),
+		Step:   r.core.currentRoundState.Step().String(),
+		Err:    err,
+	}
+	if err == nil {
+		for i := len(r.events) - 1; i >= 0; i-- {
+			last := r.events[i]
+			if last.Err != nil || last.Committed != (common.Hash{}) {
+				continue
+			}
+			if last.Height == event.Height && last.Round == event.Round && last.Step == event.Step {
+				return
+			}
+			break
+		}
+	}
+	r.events = append(r.events, event)
+}
+
+func (r *Replayer) stopTimers() {
+	_ = r.core.proposeTimeout.stopTimer()
+	_ = r.core.prevoteTimeout.stopTimer()
+	_ = r.core.precommitTimeout.stopTimer()
+	r.core.stopFutureProposalTimer()
+}
+
+// ScanTrace returns the sorted senders of the tendermint messages in the trace
+// and the lowest height they refer to.
+func ScanTrace(records []*msgtrace.Record) ([]common.Address, uint64) {
+	var (
+		seen   = make(map[common.Address]struct{})
+		lowest uint64
+	)
+	for _, r := range records {
+		if r.Code != tendermintMsgCode {
+			continue
+		}
+		msg := new(Message)
+		if err := rlp.DecodeBytes(r.Payload, msg); err != nil {
+			continue
+		}
+		seen[msg.Address] = struct{}{}
+
+		var h *big.Int
+		if msg.Code == msgProposal {
+			var p Proposal
+			if err := msg.Decode(&p); err == nil {
+				h = p.Height
+			}
+		} else {
+			var v Vote
+			if err := msg.Decode(&v); err == nil {
+				h = v.Height
+			}
+		}
+		if h != nil && (lowest == 0 || h.Uint64() < lowest) {
+			lowest = h.Uint64()
+		}
+	}
+	senders := make([]common.Address, 0, len(seen))
+	for addr := range seen {
+		senders = append(senders, addr)
+	}
+	sort.Slice(senders, func(i, j int) bool { return senders[i].Hex() < senders[j].Hex() })
+	return senders, lowest
+}
+
+// newReplayBackend mocks the parts of Backend used by the core of a Replayer.
+// Any other call panics, as the replay does not support it.
+func newReplayBackend(r *Replayer) Backend {
+	b := NewMockBackend(gomock.NewController(msgtrace.Reporter{}))
+	b.EXPECT().Address().Return(common.Address{}).AnyTimes()
+	b.EXPECT().Validators(gomock.Any()).DoAndReturn(func(number uint64) validator.Set {
+		return validator.NewSet(r.validators, r.policy)
+	}).AnyTimes()
+	// Only the backlog events are handled, timeouts never fire on the replay clock
+	b.EXPECT().Post(gomock.Any()).Do(func(ev interface{}) {
+		if _, ok := ev.(backlogEvent); ok {
+			r.core.handleEvent(r.ctx, ev)
+		}
+	}).AnyTimes()
+	b.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	b.EXPECT().Gossip(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	b.EXPECT().Commit(gomock.Any(), gomock.Any()).DoAndReturn(func(proposalBlock types.Block, seals [][]byte) error {
+		r.committed(&proposalBlock)
+		return nil
+	}).AnyTimes()
+	b.EXPECT().VerifyProposal(gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	b.EXPECT().Sign(gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	b.EXPECT().LastCommittedProposal().DoAndReturn(func() (*types.Block, common.Address) {
+		return r.lastBlock, r.lastProposer
+	}).AnyTimes()
+	b.EXPECT().SetProposedBlockHash(gomock.Any()).AnyTimes()
+	return b
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/consensus/msgtrace"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+func newReplayKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	// order the keys the same way the validator set orders addresses
+	sort.Slice(keys, func(i, j int) bool {
+		return crypto.PubkeyToAddress(keys[i].PublicKey).Hex() < crypto.PubkeyToAddress(keys[j].PublicKey).Hex()
+	})
+	addrs := make([]common.Address, n)
+	for i, key := range keys {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return keys, addrs
+}
+
+func signedReplayPayload(t *testing.T, key *ecdsa.PrivateKey, code uint64, msg interface{}, seal []byte) []byte {
+	encoded, err := Encode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Message{
+		Code:          code,
+		Msg:           encoded,
+		Address:       crypto.PubkeyToAddress(key.PublicKey),
+		CommittedSeal: []byte{},
+	}
+	if seal != nil {
+		m.CommittedSeal, err = crypto.Sign(crypto.Keccak256(seal), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := m.PayloadNoSig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Signature, err = crypto.Sign(crypto.Keccak256(data), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := m.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestReplayCommitAndRoundChange(t *testing.T) {
+	keys, addrs := newReplayKeys(t, 4)
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash()})
+
+	var records []*msgtrace.Record
+	add := func(key *ecdsa.PrivateKey, code uint64, msg interface{}, seal []byte) {
+		records = append(records, &msgtrace.Record{
+			Direction: msgtrace.Inbound,
+			Peer:      crypto.PubkeyToAddress(key.PublicKey),
+			Code:      tendermintMsgCode,
+			Payload:   signedReplayPayload(t, key, code, msg, seal),
+		})
+	}
+
+	// Height 1: the first validator proposes at round 0 and a quorum commits
+	add(keys[0], msgProposal, NewProposal(big.NewInt(0), big.NewInt(1), big.NewInt(-1), block, log.New()), nil)
+	for _, key := range keys[:3] {
+		add(key, msgPrevote, &Vote{Round: big.NewInt(0), Height: big.NewInt(1), ProposedBlockHash: block.Hash()}, nil)
+	}
+	for _, key := range keys[:3] {
+		add(key, msgPrecommit, &Vote{Round: big.NewInt(0), Height: big.NewInt(1), ProposedBlockHash: block.Hash()},
+			PrepareCommittedSeal(block.Hash()))
+	}
+	// Height 2: f+1 validators moved to round 1
+	for _, key := range keys[2:] {
+		add(key, msgPrevote, &Vote{Round: big.NewInt(1), Height: big.NewInt(2)}, nil)
+	}
+	// Duplicates and outbound messages to other peers are ignored
+	records = append(records, records[len(records)-1])
+	records = append(records, &msgtrace.Record{Direction: msgtrace.Outbound, Peer: addrs[1], Code: tendermintMsgCode, Payload: records[0].Payload})
+
+	senders, lowest := ScanTrace(records)
+	if len(senders) != len(addrs) || lowest != 1 {
+		t.Errorf("have %d senders from height %d, want %d senders from height 1", len(senders), lowest, len(addrs))
+	}
+
+	replayer := NewReplayer(addrs, config.RoundRobin, genesis, common.Address{})
+	events := replayer.Replay(records)
+	if again := NewReplayer(addrs, config.RoundRobin, genesis, common.Address{}).Replay(records); !reflect.DeepEqual(again, events) {
+		t.Fatalf("replay is not deterministic, have %v, want %v", again, events)
+	}
+
+	var (
+		committed  bool
+		roundOne   bool
+		lastRecord = -1
+	)
+	for _, e := range events {
+		if e.Committed == block.Hash() && e.Height == 1 {
+			committed = true
+		}
+		if e.Height == 2 && e.Round == 1 {
+			roundOne = true
+		}
+		if e.Err != nil {
+			t.Errorf("unexpected replay error: %v", e)
+		}
+		if e.Record > lastRecord {
+			lastRecord = e.Record
+		}
+	}
+	if !committed {
+		t.Fatalf("block 1 was not committed: %v", events)
+	}
+	if !roundOne {
+		t.Fatalf("round change at height 2 was not reproduced: %v", events)
+	}
+	if lastRecord >= len(records)-2 {
+		t.Fatalf("duplicate or outbound record was replayed: %v", events)
+	}
+}
@@ -60,6 +60,21 @@ func (s *roundState) Update(r *big.Int, h *big.Int) {
 	s.Precommits = newMessageSet()
 }
 
+// copy returns a new round state sharing the proposal and the votes of s
+func (s *roundState) copy() *roundState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &roundState{
+		round:       s.round,
+		height:      s.height,
+		step:        s.step,
+		proposal:    s.proposal,
+		proposalMsg: s.proposalMsg,
+		Prevotes:    s.Prevotes,
+		Precommits:  s.Precommits,
+	}
+}
+
 func (s *roundState) SetProposal(proposal *Proposal, msg *Message) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,204 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
+	"go-smilo/src/blockchain/smilobft/consensus/simulation"
+	"go-smilo/src/blockchain/smilobft/consensus/simulation/simtest"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/events"
+	"go-smilo/src/blockchain/smilobft/consensus/tendermint/validator"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+// simulationSyncInterval is the interval of the sync loop of the simulated nodes
+const simulationSyncInterval = 10 * time.Second
+
+func TestSimulation(t *testing.T) {
+	simtest.Run(t, newSimulationNode(config.DefaultConfig()))
+}
+
+// simulationNode runs a tendermint core on top of a deterministic network
+// simulation. Events are dispatched synchronously on the simulation goroutine
+// and every node mines a new block as soon as its chain head changes.
+type simulationNode struct {
+	sim    *simulation.Simulator
+	index  int
+	config *config.Config
+	core   *core
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	syncTimer  clock.Timer
+	syncRound  *big.Int
+	syncHeight *big.Int
+}
+
+// newSimulationNode returns a factory of simulated tendermint validators.
+func newSimulationNode(cfg *config.Config) simulation.NodeFactory {
+	return func(sim *simulation.Simulator, index int) simulation.Node {
+		n := &simulationNode{
+			sim:    sim,
+			index:  index,
+			config: cfg,
+		}
+		n.ctx, n.cancel = context.WithCancel(context.Background())
+		n.core = New(&simulationBackend{node: n}, cfg)
+		n.core.setClock(sim.Clock())
+		return n
+	}
+}
+
+func (n *simulationNode) Start() {
+	head := n.sim.Head(n.index)
+	n.core.currentRoundState.Update(common.Big0, new(big.Int).Add(head.Number(), common.Big1))
+	n.core.storeUnminedBlockMsg(n.sim.NewBlock(n.index, head))
+	n.core.startRound(n.ctx, common.Big0)
+
+	n.syncRound, n.syncHeight = n.core.currentRoundState.Round(), n.core.currentRoundState.Height()
+	n.core.backend.AskSync(n.core.valSet.Copy())
+	n.syncTimer = n.sim.Clock().AfterFunc(simulationSyncInterval, n.syncLoop)
+}
+
+// syncLoop asks the network for the current consensus state when the view of
+// the core did not change for a sync interval, as core.syncLoop does
+func (n *simulationNode) syncLoop() {
+	round, height := n.core.currentRoundState.Round(), n.core.currentRoundState.Height()
+	if height.Cmp(n.syncHeight) == 0 && round.Cmp(n.syncRound) == 0 {
+		n.core.backend.AskSync(n.core.valSet.Copy())
+	}
+	n.syncRound, n.syncHeight = round, height
+	n.syncTimer = n.sim.Clock().AfterFunc(simulationSyncInterval, n.syncLoop)
+}
+
+func (n *simulationNode) Deliver(payload []byte) {
+	n.core.handleEvent(n.ctx, events.MessageEvent{Payload: payload})
+}
+
+func (n *simulationNode) Import(block *types.Block) {
+	n.newChainHead()
+}
+
+func (n *simulationNode) Stop() {
+	n.cancel()
+	if n.syncTimer != nil {
+		n.syncTimer.Stop()
+	}
+	_ = n.core.proposeTimeout.stopTimer()
+	_ = n.core.prevoteTimeout.stopTimer()
+	_ = n.core.precommitTimeout.stopTimer()
+	n.core.stopFutureProposalTimer()
+}
+
+// newChainHead mines the block of the next height and moves the core to it
+func (n *simulationNode) newChainHead() {
+	n.core.storeUnminedBlockMsg(n.sim.NewBlock(n.index, n.sim.Head(n.index)))
+	n.core.handleEvent(n.ctx, events.CommitEvent{})
+}
+
+// simulationBackend implements the parts of Backend used by the core
+type simulationBackend struct {
+	Backend
+	node *simulationNode
+}
+
+func (b *simulationBackend) Address() common.Address {
+	return b.node.sim.Address(b.node.index)
+}
+
+func (b *simulationBackend) Validators(number uint64) validator.Set {
+	return validator.NewSet(b.node.sim.Addresses(), b.node.config.GetProposerPolicy())
+}
+
+func (b *simulationBackend) Post(ev interface{}) {
+	b.node.core.handleEvent(b.node.ctx, ev)
+}
+
+func (b *simulationBackend) Broadcast(ctx context.Context, valSet validator.Set, payload []byte) error {
+	b.node.sim.Broadcast(b.node.index, payload)
+	return nil
+}
+
+func (b *simulationBackend) Gossip(ctx context.Context, valSet validator.Set, payload []byte) {
+	b.node.sim.Gossip(b.node.index, payload)
+}
+
+func (b *simulationBackend) Commit(proposalBlock types.Block, seals [][]byte) error {
+	b.node.sim.Committed(b.node.index, &proposalBlock)
+	// The chain head event follows the block insertion
+	b.node.sim.Clock().AfterFunc(0, b.node.newChainHead)
+	return nil
+}
+
+func (b *simulationBackend) VerifyProposal(block types.Block) (time.Duration, error) {
+	head := b.node.sim.Head(b.node.index)
+	if block.ParentHash() != head.Hash() || block.NumberU64() != head.NumberU64()+1 {
+		return 0, consensus.ErrUnknownAncestor
+	}
+	return 0, nil
+}
+
+func (b *simulationBackend) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), b.node.sim.Key(b.node.index))
+}
+
+func (b *simulationBackend) LastCommittedProposal() (*types.Block, common.Address) {
+	head := b.node.sim.Head(b.node.index)
+	return head, head.Coinbase()
+}
+
+func (b *simulationBackend) SetProposedBlockHash(hash common.Hash) {}
+
+func (b *simulationBackend) AskSync(valSet validator.Set) {
+	var count int
+	for _, val := range valSet.List() {
+		// Ask a quorum of the other validators, one of them must be honest and up to date
+		if count == valSet.Quorum() {
+			break
+		}
+		if val.Address() == b.Address() {
+			continue
+		}
+		peer := b.node.sim.Index(val.Address())
+		b.node.sim.Call(b.node.index, peer, func() {
+			b.node.sim.Node(peer).(*simulationNode).core.SyncPeer(b.Address())
+		})
+		count++
+	}
+}
+
+func (b *simulationBackend) SyncPeer(address common.Address, messages []*Message) {
+	peer := b.node.sim.Index(address)
+	for _, msg := range messages {
+		payload, err := msg.Payload()
+		if err != nil {
+			continue
+		}
+		b.node.sim.Send(b.node.index, peer, payload)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/consensus/clock"
 )
 
 //TODO: why is this like this ?
@@ -36,7 +38,8 @@ type TimeoutEvent struct {
 }
 
 type timeout struct {
-	timer   *time.Timer
+	timer   clock.Timer
+	clock   clock.Clock // the system clock is used if nil
 	started bool
 	step    Step
 	// start will be refreshed on each new schedule, it is used for metric collection of tendermint timeout.
@@ -60,9 +63,14 @@ func (t *timeout) scheduleTimeout(stepTimeout time.Duration, round int64, height
 	defer t.Unlock()
 	t.started = true
 	t.start = time.Now()
-	t.timer = time.AfterFunc(stepTimeout, func() {
+	f := func() {
 		runAfterTimeout(round, height)
-	})
+	}
+	if t.clock == nil {
+		t.timer = time.AfterFunc(stepTimeout, f)
+	} else {
+		t.timer = t.clock.AfterFunc(stepTimeout, f)
+	}
 }
 
 func (t *timeout) timerStarted() bool {
@@ -159,6 +159,9 @@ func TestHandleTimeoutPrecommit(t *testing.T) {
 		if engine.currentRoundState.step != propose {
 			t.Fatalf("should be propose step")
 		}
+
+		// The new round schedules a propose timeout the mocked backend does not expect
+		_ = engine.proposeTimeout.stopTimer()
 	})
 }
 
@@ -155,9 +155,19 @@ func (c *Console) init(preload []string) error {
 			flatten += fmt.Sprintf("var %s = web3.%s; ", api, api)
 		}
 	}
+	if _, ok := apis["eth"]; ok {
+		// Load the private contract helpers on top of the eth module
+		if err = c.jsre.Compile("vault.js", web3ext.VaultJs); err != nil {
+			return fmt.Errorf("vault.js: %v", err)
+		}
+		flatten += "var vault = web3.vault; "
+	}
 	if _, err = c.jsre.Run(flatten); err != nil {
 		return fmt.Errorf("namespace flattening: %v", err)
 	}
+	if vault, err := c.jsre.Get("vault"); err == nil && vault.IsObject() {
+		vault.Object().Set("sleep", bridge.Sleep)
+	}
 	// Initialize the global name register (disabled for now)
 	//c.jsre.Run(`var GlobalRegistrar = eth.contract(` + registrar.GlobalRegistrarAbi + `);   registrar = GlobalRegistrar.at("` + registrar.GlobalRegistrarAddr + `");`)
 
@@ -18,6 +18,7 @@ package console
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -35,6 +36,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/eth"
 	"go-smilo/src/blockchain/smilobft/internal/jsre"
 	"go-smilo/src/blockchain/smilobft/node"
+	"go-smilo/src/blockchain/smilobft/p2p"
 )
 
 const (
@@ -87,6 +89,12 @@ type tester struct {
 // newTester creates a test environment based on which the console can operate.
 // Please ensure you call Close() on the returned tester to avoid leaks.
 func newTester(t *testing.T, confOverride func(*eth.Config)) *tester {
+	return newKeyedTester(t, nil, confOverride)
+}
+
+// newKeyedTester creates a test environment whose node runs with the given node
+// key, generated if nil.
+func newKeyedTester(t *testing.T, nodeKey *ecdsa.PrivateKey, confOverride func(*eth.Config)) *tester {
 	// Create a temporary storage for the node keys and initialize it
 	workspace, err := ioutil.TempDir("", "console-tester-")
 	if err != nil {
@@ -94,7 +102,7 @@ func newTester(t *testing.T, confOverride func(*eth.Config)) *tester {
 	}
 
 	// Create a networkless protocol stack and start an Ethereum service within
-	stack, err := node.New(&node.Config{DataDir: workspace, UseLightweightKDF: true, Name: testInstance})
+	stack, err := node.New(&node.Config{DataDir: workspace, UseLightweightKDF: true, Name: testInstance, P2P: p2p.Config{PrivateKey: nodeKey}})
 	if err != nil {
 		t.Fatalf("failed to create node: %v", err)
 	}
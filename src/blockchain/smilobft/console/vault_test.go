@@ -0,0 +1,136 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/accounts/keystore"
+	tendermintConfig "go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/eth"
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/vault"
+	"go-smilo/src/blockchain/smilobft/vault/blackbox"
+)
+
+// Storage contract storing its constructor argument. Calls with an argument
+// set the stored value, the others return it.
+const (
+	storageABI  = `[{"constant":false,"inputs":[{"name":"x","type":"uint256"}],"name":"set","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"get","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"inputs":[{"name":"initVal","type":"uint256"}],"type":"constructor"}]`
+	storageCode = `602060203803600039600051600055601a80601a6000396000f3` + `6004361160125760005460005260206000f35b60043560005500`
+)
+
+// Tests that the vault helpers deploy, call and inspect a private contract
+// through a stand-in vault.
+func TestVaultHelpers(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	// The miner only seals with the BFT engines, run a single validator
+	extra, err := types.PrepareExtra(nil, []common.Address{sender})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.AllEthashProtocolChanges
+	config.Ethash, config.Tendermint = nil, &params.TendermintConfig{Epoch: 30000, BlockPeriod: 1, RequestTimeout: 10000}
+	config.IsSmilo, config.IsGas = true, true
+	config.AutonityContractConfig = &params.AutonityContractGenesis{
+		Users: []params.User{{
+			Address: sender,
+			Enode:   enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), 30303, 30303).URLv4(),
+			Type:    params.UserValidator,
+			Stake:   1,
+		}},
+	}
+	if err := config.AutonityContractConfig.AddDefault().Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tester := newKeyedTester(t, key, func(conf *eth.Config) {
+		conf.Genesis = &core.Genesis{
+			Config:     &config,
+			ExtraData:  extra,
+			GasLimit:   params.GenesisGasLimit,
+			Difficulty: big.NewInt(1),
+			Mixhash:    types.BFTDigest,
+			Alloc:      core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+		}
+		conf.Tendermint = *tendermintConfig.DefaultConfig()
+	})
+	defer tester.Close(t)
+
+	standIn, err := blackbox.NewStandIn(blackbox.NewStandInStore(), filepath.Join(tester.workspace, "blackbox.ipc"))
+	if err != nil {
+		t.Fatalf("failed to start stand-in vault: %v", err)
+	}
+	defer standIn.Close()
+	defer func(instance vault.BlackboxVault) { vault.VaultInstance = instance }(vault.VaultInstance)
+	vault.VaultInstance = blackbox.CreateNew(filepath.Join(tester.workspace, "blackbox.ipc"))
+
+	ks := tester.stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, err := ks.ImportECDSA(key, "")
+	if err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	if err := tester.ethereum.StartMining(1); err != nil {
+		t.Fatalf("failed to start mining: %v", err)
+	}
+
+	evaluate := func(statement string) string {
+		tester.output.Reset()
+		tester.console.Evaluate(statement)
+		return tester.output.String()
+	}
+	options := fmt.Sprintf(`{from: '%s', sharedWith: ['%s'], gas: 1000000, timeout: 30`, sender.Hex(), standIn.PublicKey)
+
+	if output := evaluate(`vault.deploy('` + storageABI + `', '` + storageCode + `', {})`); !strings.Contains(output, "sharedWith") {
+		t.Errorf("deploy without sharedWith not rejected: %s", output)
+	}
+	output := evaluate(`var deployed = vault.deploy('` + storageABI + `', '` + storageCode + `', ` + options + `, args: [42]})`)
+	if !strings.Contains(output, "SmiloPay of "+sender.Hex()) {
+		t.Errorf("SmiloPay not shown before sending: %s", output)
+	}
+	if output := evaluate(`deployed.isPrivate && deployed.payload.indexOf('` + storageCode[:64] + `') === 2`); !strings.Contains(output, "true") {
+		t.Fatalf("private receipt mismatch: %s", evaluate(`deployed`))
+	}
+	if output := evaluate(`deployed.contract.get().toNumber()`); !strings.Contains(output, "42") {
+		t.Errorf("private contract state mismatch: have %s, want 42", output)
+	}
+	if output := evaluate(`vault.storageAt(deployed.contractAddress, 0)`); !strings.Contains(output, "2a") {
+		t.Errorf("private storage mismatch: have %s, want 0x..2a", output)
+	}
+	output = evaluate(`vault.send('` + storageABI + `', deployed.contractAddress, 'set', [7], ` + options + `}).isPrivate`)
+	if !strings.Contains(output, "true") {
+		t.Errorf("private call receipt mismatch: %s", output)
+	}
+	if output := evaluate(`vault.call('` + storageABI + `', deployed.contractAddress, 'get').toNumber()`); !strings.Contains(output, "7") {
+		t.Errorf("private call state mismatch: have %s, want 7", output)
+	}
+}
@@ -3,7 +3,6 @@ package autonity
 import (
 	"errors"
 	"math/big"
-	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -46,8 +45,9 @@ type Blockchainer interface {
 	ChainContext
 	GetVMConfig() *vm.Config
 	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
 
-	UpdateEnodeWhitelist(newWhitelist *types.Nodes)
+	UpdateEnodeWhitelist(newWhitelist *types.Nodes, number uint64)
 	ReadEnodeWhitelist(EnableNodePermissionFlag bool) *types.Nodes
 
 	UpdateBlacklist(newBlacklist *types.Nodes)
@@ -55,11 +55,12 @@ type Blockchainer interface {
 }
 
 type Contract struct {
-	address                  common.Address
 	contractABI              *abi.ABI
+	upgradeABIs              map[int]*abi.ABI
 	bc                       Blockchainer
 	SavedValidatorsRetriever func(i uint64) ([]common.Address, error)
 	metrics                  EconomicMetrics
+	history                  *EconomicHistory
 
 	canTransfer func(db vm.StateDB, addr common.Address, amount *big.Int) bool
 	transfer    func(db vm.StateDB, sender, recipient common.Address, amount, blockNumber *big.Int)
@@ -79,7 +80,7 @@ func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateD
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, deployer, stateDB)
 
-	ABI, err := ac.abi()
+	contractAddress, ABI, err := ac.contract(stateDB)
 	if err != nil {
 		return
 	}
@@ -93,7 +94,7 @@ func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateD
 
 	// call evm.
 	value := new(big.Int).SetUint64(0x00)
-	ret, _, vmerr := evm.Call(sender, ac.Address(), input, gas, value, false)
+	ret, _, vmerr := evm.Call(sender, contractAddress, input, gas, value, false)
 	log.Debug("bytes return from contract: ", ret)
 	if vmerr != nil {
 		log.Warn("Error Autonity Contract dumpNetworkEconomics")
@@ -140,7 +141,7 @@ func (ac *Contract) DeployAutonityContract(chain consensus.ChainReader, header *
 	evm := ac.getEVM(header, chain.Config().AutonityContractConfig.Deployer, statedb)
 	sender := vm.AccountRef(chain.Config().AutonityContractConfig.Deployer)
 
-	contractABI, err := ac.abi()
+	contractABI, err := ac.abiAt(-1)
 	if err != nil {
 		log.Error("abi.JSON returns err", "err", err)
 		return common.Address{}, err
@@ -182,9 +183,6 @@ func (ac *Contract) DeployAutonityContract(chain consensus.ChainReader, header *
 		log.Error("evm.Create returns err", "err", vmerr)
 		return contractAddress, vmerr
 	}
-	ac.Lock()
-	ac.address = contractAddress
-	ac.Unlock()
 	log.Info("Deployed Autonity Contract", "Address", contractAddress.String())
 
 	return contractAddress, nil
@@ -197,7 +195,7 @@ func (ac *Contract) ContractGetValidators(chain consensus.ChainReader, header *t
 	sender := vm.AccountRef(chain.Config().AutonityContractConfig.Deployer)
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, chain.Config().AutonityContractConfig.Deployer, statedb)
-	contractABI, err := ac.abi()
+	contractAddress, contractABI, err := ac.contract(statedb)
 	if err != nil {
 		return nil, err
 	}
@@ -216,7 +214,7 @@ func (ac *Contract) ContractGetValidators(chain consensus.ChainReader, header *t
 		"gas", gas)
 
 	//A standard call is issued - we leave the possibility to modify the state
-	ret, _, vmerr := evm.Call(sender, ac.Address(), input, gas, value, false)
+	ret, _, vmerr := evm.Call(sender, contractAddress, input, gas, value, false)
 	if vmerr != nil {
 		return nil, vmerr
 	}
@@ -243,7 +241,7 @@ func (ac *Contract) UpdateEnodesWhitelist(state, vaultstate *state.StateDB, bloc
 		return ErrAutonityContract
 	}
 
-	ac.bc.UpdateEnodeWhitelist(newWhitelist)
+	ac.bc.UpdateEnodeWhitelist(newWhitelist, block.NumberU64())
 	return nil
 }
 
@@ -273,7 +271,7 @@ func (ac *Contract) callGetWhitelist(state, vaultstate *state.StateDB, header *t
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, deployer, state)
 
-	ABI, err := ac.abi()
+	contractAddress, ABI, err := ac.contract(state)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +281,7 @@ func (ac *Contract) callGetWhitelist(state, vaultstate *state.StateDB, header *t
 		return nil, err
 	}
 
-	ret, _, vmerr := evm.StaticCall(sender, ac.Address(), input, gas, false)
+	ret, _, vmerr := evm.StaticCall(sender, contractAddress, input, gas, false)
 	if vmerr != nil {
 		log.Error("Error Autonity Contract getWhitelist()")
 		return nil, vmerr
@@ -321,7 +319,7 @@ func (ac *Contract) callGetMinimumGasPrice(state, vaultstate *state.StateDB, hea
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, deployer, state)
 
-	ABI, err := ac.abi()
+	contractAddress, ABI, err := ac.contract(state)
 	if err != nil {
 		return 0, err
 	}
@@ -332,7 +330,7 @@ func (ac *Contract) callGetMinimumGasPrice(state, vaultstate *state.StateDB, hea
 	}
 
 	value := new(big.Int).SetUint64(0x00)
-	ret, _, vmerr := evm.Call(sender, ac.Address(), input, gas, value, false)
+	ret, _, vmerr := evm.Call(sender, contractAddress, input, gas, value, false)
 	if vmerr != nil {
 		log.Error("Error Autonity Contract getMinimumGasPrice()")
 		return 0, vmerr
@@ -354,7 +352,7 @@ func (ac *Contract) callSetMinimumGasPrice(state, vaultstate *state.StateDB, hea
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, deployer, state)
 
-	ABI, err := ac.abi()
+	contractAddress, ABI, err := ac.contract(state)
 	if err != nil {
 		return err
 	}
@@ -364,7 +362,7 @@ func (ac *Contract) callSetMinimumGasPrice(state, vaultstate *state.StateDB, hea
 		return err
 	}
 
-	_, _, vmerr := evm.Call(sender, ac.Address(), input, gas, price, false)
+	_, _, vmerr := evm.Call(sender, contractAddress, input, gas, price, false)
 	if vmerr != nil {
 		log.Error("Error Autonity Contract getMinimumGasPrice()")
 		return vmerr
@@ -372,14 +370,16 @@ func (ac *Contract) callSetMinimumGasPrice(state, vaultstate *state.StateDB, hea
 	return nil
 }
 
-func (ac *Contract) PerformRedistribution(header *types.Header, db *state.StateDB, gasUsed *big.Int) error {
+// PerformRedistribution redistributes the fees of the block and returns the
+// rewards, nil if they could not be read.
+func (ac *Contract) PerformRedistribution(header *types.Header, db *state.StateDB, gasUsed *big.Int) (*RewardDistributionMetaData, error) {
 	if header.Number.Uint64() <= 1 {
-		return nil
+		return nil, nil
 	}
 	return ac.callPerformRedistribution(db, header, gasUsed)
 }
 
-func (ac *Contract) callPerformRedistribution(state *state.StateDB, header *types.Header, blockGas *big.Int) error {
+func (ac *Contract) callPerformRedistribution(state *state.StateDB, header *types.Header, blockGas *big.Int) (*RewardDistributionMetaData, error) {
 	// Needs to be refactored somehow
 	deployer := ac.bc.Config().AutonityContractConfig.Deployer
 
@@ -387,23 +387,23 @@ func (ac *Contract) callPerformRedistribution(state *state.StateDB, header *type
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.getEVM(header, deployer, state)
 
-	ABI, err := ac.abi()
+	contractAddress, ABI, err := ac.contract(state)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	input, err := ABI.Pack("performRedistribution", blockGas)
 	if err != nil {
 		log.Error("Error Autonity Contract callPerformRedistribution()", "err", err)
-		return err
+		return nil, err
 	}
 
 	value := new(big.Int).SetUint64(0x00)
 
-	ret, _, vmerr := evm.Call(sender, ac.Address(), input, gas, value, false)
+	ret, _, vmerr := evm.Call(sender, contractAddress, input, gas, value, false)
 	if vmerr != nil {
 		log.Error("Error Autonity Contract callPerformRedistribution()", "err", err)
-		return vmerr
+		return nil, vmerr
 	}
 
 	// after reward distribution, update metrics with the return values.
@@ -416,11 +416,11 @@ func (ac *Contract) callPerformRedistribution(state *state.StateDB, header *type
 
 	if err := ABI.Unpack(&v, "performRedistribution", ret); err != nil { // can't work with aliased types
 		log.Error("Could not unpack performRedistribution returned value", "err", err, "header.num", header.Number.Uint64())
-		return nil
+		return nil, nil
 	}
 
 	ac.metrics.SubmitRewardDistributionMetrics(&v, header.Number.Uint64())
-	return nil
+	return &v, nil
 }
 
 func (ac *Contract) ApplyPerformRedistribution(transactions types.Transactions, receipts types.Receipts, header *types.Header, statedb *state.StateDB) error {
@@ -433,36 +433,113 @@ func (ac *Contract) ApplyPerformRedistribution(transactions types.Transactions,
 		blockGas.Add(blockGas, new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(receipts[i].GasUsed)))
 	}
 
-	log.Info("execution start ApplyPerformRedistribution", "balance", statedb.GetBalance(ac.Address()), "block", header.Number.Uint64(), "gas", blockGas.Uint64())
+	address := ac.AddressAt(header.Number.Uint64())
+	log.Info("execution start ApplyPerformRedistribution", "balance", statedb.GetBalance(address), "block", header.Number.Uint64(), "gas", blockGas.Uint64())
+	var (
+		rewards *RewardDistributionMetaData
+		err     error
+	)
 	if blockGas.Cmp(new(big.Int)) == 0 {
-		log.Info("execution start ApplyPerformRedistribution with 0 gas", "balance", statedb.GetBalance(ac.Address()), "block", header.Number.Uint64())
-		return nil
+		log.Info("execution start ApplyPerformRedistribution with 0 gas", "balance", statedb.GetBalance(address), "block", header.Number.Uint64())
+	} else {
+		rewards, err = ac.PerformRedistribution(header, statedb, blockGas)
 	}
-	return ac.PerformRedistribution(header, statedb, blockGas)
+	// Every block has an economic snapshot, whether it redistributed fees or not
+	ac.takeEconomicSnapshot(header, statedb, rewards)
+	return err
 }
 
+// Address returns the address of the Autonity contract at the head of the chain.
 func (ac *Contract) Address() common.Address {
-	if reflect.DeepEqual(ac.address, common.Address{}) {
-		addr, err := ac.bc.Config().AutonityContractConfig.GetContractAddress()
-		if err != nil {
-			log.Error("Cant get contract address", "err", err)
+	return ac.AddressAt(ac.bc.CurrentBlock().NumberU64())
+}
+
+// AddressAt returns the address of the Autonity contract in the state of block
+// number.
+func (ac *Contract) AddressAt(number uint64) common.Address {
+	addr, err := ac.bc.Config().AutonityContractConfig.GetContractAddressAt(number)
+	if err != nil {
+		log.Error("Cant get contract address", "err", err)
+	}
+	return addr
+}
+
+// ABI returns the JSON ABI of the Autonity contract at the head of the chain.
+func (ac *Contract) ABI() string {
+	return ac.ABIAt(ac.bc.CurrentBlock().NumberU64())
+}
+
+// ABIAt returns the JSON ABI of the Autonity contract in the state of block
+// number.
+func (ac *Contract) ABIAt(number uint64) string {
+	config := ac.bc.Config().AutonityContractConfig
+	if i := config.GetUpgradeIndex(number); i >= 0 {
+		return config.Upgrades[i].ABI
+	}
+	return config.ABI
+}
+
+// ContractAt returns the address and the parsed ABI of the Autonity contract
+// in the state of block number.
+func (ac *Contract) ContractAt(number uint64) (common.Address, *abi.ABI, error) {
+	config := ac.bc.Config().AutonityContractConfig
+	i := config.GetUpgradeIndex(number)
+	contractABI, err := ac.abiAt(i)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	addr, err := config.GetContractAddressAt(number)
+	return addr, contractABI, err
+}
+
+// contract returns the address and the ABI of the Autonity contract deployed
+// in statedb, which depends on the upgrades already applied to the state.
+func (ac *Contract) contract(statedb vm.StateDB) (common.Address, *abi.ABI, error) {
+	config := ac.bc.Config().AutonityContractConfig
+	i := len(config.Upgrades) - 1
+	for i >= 0 {
+		if _, applied := upgradeApplied(statedb, config.Deployer, i); applied {
+			break
 		}
-		return addr
+		i--
 	}
-	return ac.address
+	contractABI, err := ac.abiAt(i)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if i >= 0 {
+		return config.GetUpgradeAddress(i), contractABI, nil
+	}
+	addr, err := config.GetContractAddress()
+	return addr, contractABI, err
 }
 
-func (ac *Contract) abi() (*abi.ABI, error) {
+// abiAt returns the parsed ABI of the contract deployed by the i-th upgrade,
+// or of the genesis contract if i is negative.
+func (ac *Contract) abiAt(i int) (*abi.ABI, error) {
 	ac.Lock()
 	defer ac.Unlock()
-	if ac.contractABI != nil {
+	if i < 0 {
+		if ac.contractABI != nil {
+			return ac.contractABI, nil
+		}
+		ABI, err := abi.JSON(strings.NewReader(ac.bc.Config().AutonityContractConfig.ABI))
+		if err != nil {
+			return nil, err
+		}
+		ac.contractABI = &ABI
 		return ac.contractABI, nil
 	}
-	ABI, err := abi.JSON(strings.NewReader(ac.bc.Config().AutonityContractConfig.ABI))
+	if ABI, ok := ac.upgradeABIs[i]; ok {
+		return ABI, nil
+	}
+	ABI, err := abi.JSON(strings.NewReader(ac.bc.Config().AutonityContractConfig.Upgrades[i].ABI))
 	if err != nil {
 		return nil, err
 	}
-	ac.contractABI = &ABI
-	return ac.contractABI, nil
-
+	if ac.upgradeABIs == nil {
+		ac.upgradeABIs = make(map[int]*abi.ABI)
+	}
+	ac.upgradeABIs[i] = &ABI
+	return &ABI, nil
 }
@@ -0,0 +1,298 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package autonity
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+)
+
+// MaxEconomicHistoryRange is the maximum number of blocks read by a single
+// economic history query.
+const MaxEconomicHistoryRange = 10000
+
+// economicSnapshotPrefix + num (uint64 big endian) -> RLP encoded EconomicSnapshot
+var economicSnapshotPrefix = []byte("autonity-economics-")
+
+// EconomicSnapshot is the economic state of the Autonity contract after a
+// block, and the fees it redistributed.
+type EconomicSnapshot struct {
+	Number          uint64                 `json:"number"`
+	MinGasPrice     *big.Int               `json:"minGasPrice"`
+	StakeSupply     *big.Int               `json:"stakeSupply"`
+	OperatorBalance *big.Int               `json:"operatorBalance"`
+	BlockReward     *big.Int               `json:"blockReward"` // Fees redistributed to the stakeholders
+	Users           []EconomicUserSnapshot `json:"users"`
+
+	operator common.Address // Governance operator whose balance is recorded
+}
+
+// EconomicUserSnapshot is the economic state of a user of the Autonity
+// contract after a block.
+type EconomicUserSnapshot struct {
+	Address        common.Address `json:"address"`
+	Type           uint8          `json:"type"`
+	Stake          *big.Int       `json:"stake"`
+	Balance        *big.Int       `json:"balance"`
+	CommissionRate *big.Int       `json:"commissionRate"`
+	Reward         *big.Int       `json:"reward"` // Share of the redistributed fees
+}
+
+// Filter returns a copy of the snapshot with the user of the given address
+// only.
+func (s *EconomicSnapshot) Filter(address common.Address) *EconomicSnapshot {
+	filtered := *s
+	filtered.Users = nil
+	for _, u := range s.Users {
+		if u.Address == address {
+			filtered.Users = append(filtered.Users, u)
+		}
+	}
+	return &filtered
+}
+
+// EconomicHistory persists an economic snapshot of the Autonity contract for
+// every block, beyond the window kept by the in-process metrics.
+type EconomicHistory struct {
+	db ethdb.KeyValueStore
+
+	mu      sync.Mutex
+	pending map[uint64]*EconomicSnapshot // Snapshots of the blocks processed but not written yet
+}
+
+// NewEconomicHistory creates an economic history stored in db, which may be the
+// chain database or a dedicated one.
+func NewEconomicHistory(db ethdb.KeyValueStore) *EconomicHistory {
+	return &EconomicHistory{
+		db:      db,
+		pending: make(map[uint64]*EconomicSnapshot),
+	}
+}
+
+func economicSnapshotKey(number uint64) []byte {
+	key := make([]byte, len(economicSnapshotPrefix)+8)
+	copy(key, economicSnapshotPrefix)
+	binary.BigEndian.PutUint64(key[len(economicSnapshotPrefix):], number)
+	return key
+}
+
+// Read returns the snapshot of block number, nil if none was recorded.
+func (h *EconomicHistory) Read(number uint64) (*EconomicSnapshot, error) {
+	key := economicSnapshotKey(number)
+	if has, err := h.db.Has(key); err != nil || !has {
+		return nil, err
+	}
+	data, err := h.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := new(EconomicSnapshot)
+	if err := rlp.DecodeBytes(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid economic snapshot of block %d: %v", number, err)
+	}
+	return snapshot, nil
+}
+
+// Range returns the recorded snapshots of the blocks from and to, both
+// included.
+func (h *EconomicHistory) Range(from, to uint64) ([]*EconomicSnapshot, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	if to-from >= MaxEconomicHistoryRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds %d blocks", from, to, MaxEconomicHistoryRange)
+	}
+	var snapshots []*EconomicSnapshot
+	for number := from; number <= to; number++ {
+		snapshot, err := h.Read(number)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+// Write stores snapshot, replacing the one of a block of the same number
+// recorded before a reorg.
+func (h *EconomicHistory) Write(snapshot *EconomicSnapshot) error {
+	data, err := rlp.EncodeToBytes(snapshot)
+	if err != nil {
+		return err
+	}
+	return h.db.Put(economicSnapshotKey(snapshot.Number), data)
+}
+
+// recordPending keeps the snapshot of a processed block until the block is
+// written. Blocks are finalized more than once when they are mined, the last
+// snapshot taken wins.
+func (h *EconomicHistory) recordPending(snapshot *EconomicSnapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending[snapshot.Number] = snapshot
+}
+
+// takePending returns the snapshot of block number and drops the ones of the
+// blocks up to it.
+func (h *EconomicHistory) takePending(number uint64) *EconomicSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot := h.pending[number]
+	for n := range h.pending {
+		if n <= number {
+			delete(h.pending, n)
+		}
+	}
+	return snapshot
+}
+
+// SetEconomicHistory enables the recording of the economic snapshots into
+// history.
+func (ac *Contract) SetEconomicHistory(history *EconomicHistory) {
+	ac.Lock()
+	defer ac.Unlock()
+	ac.history = history
+}
+
+// EconomicHistory returns the recorded economic snapshots, nil if the
+// recording is disabled.
+func (ac *Contract) EconomicHistory() *EconomicHistory {
+	ac.RLock()
+	defer ac.RUnlock()
+	return ac.history
+}
+
+// takeEconomicSnapshot reads the contract state of a block from the state the
+// fee redistribution left, if the recording is enabled, and keeps it until
+// the block is written.
+func (ac *Contract) takeEconomicSnapshot(header *types.Header, stateDB *state.StateDB, rewards *RewardDistributionMetaData) {
+	history := ac.EconomicHistory()
+	if history == nil {
+		return
+	}
+	snapshot, err := ac.economicSnapshot(header, stateDB, rewards)
+	if err != nil {
+		log.Warn("Could not read the Autonity economic snapshot", "number", header.Number, "err", err)
+		return
+	}
+	history.recordPending(snapshot)
+}
+
+// RecordEconomicSnapshot writes the economic snapshot taken while processing
+// a block to the history, if enabled. The balances are updated from stateDB,
+// which is about to be committed.
+func (ac *Contract) RecordEconomicSnapshot(header *types.Header, stateDB *state.StateDB) {
+	history := ac.EconomicHistory()
+	if history == nil || header == nil || stateDB == nil {
+		return
+	}
+	number := header.Number.Uint64()
+	snapshot := history.takePending(number)
+	if snapshot == nil {
+		return
+	}
+	snapshot.OperatorBalance = stateDB.GetBalance(snapshot.operator)
+	for i := range snapshot.Users {
+		snapshot.Users[i].Balance = stateDB.GetBalance(snapshot.Users[i].Address)
+	}
+	if err := history.Write(snapshot); err != nil {
+		log.Error("Could not write the Autonity economic snapshot", "number", number, "err", err)
+	}
+}
+
+func (ac *Contract) economicSnapshot(header *types.Header, stateDB *state.StateDB, rewards *RewardDistributionMetaData) (*EconomicSnapshot, error) {
+	address, contractABI, err := ac.contract(stateDB)
+	if err != nil {
+		return nil, err
+	}
+	evm := ac.getEVM(header, ac.bc.Config().AutonityContractConfig.Deployer, stateDB)
+	st, err := ac.readContractState(evm, address, contractABI)
+	if err != nil {
+		return nil, err
+	}
+
+	fractions := make(map[common.Address]*big.Int)
+	snapshot := &EconomicSnapshot{
+		Number:          header.Number.Uint64(),
+		MinGasPrice:     st.minGasPrice,
+		StakeSupply:     new(big.Int),
+		OperatorBalance: stateDB.GetBalance(st.operator),
+		BlockReward:     new(big.Int),
+		operator:        st.operator,
+	}
+	if rewards != nil && len(rewards.Holders) == len(rewards.Rewardfractions) {
+		snapshot.BlockReward.Set(rewards.Amount)
+		for i, holder := range rewards.Holders {
+			fractions[holder] = rewards.Rewardfractions[i]
+		}
+	}
+	for _, u := range st.users {
+		reward := fractions[u.address]
+		if reward == nil {
+			reward = new(big.Int)
+		}
+		snapshot.StakeSupply.Add(snapshot.StakeSupply, u.stake)
+		snapshot.Users = append(snapshot.Users, EconomicUserSnapshot{
+			Address:        u.address,
+			Type:           u.userType,
+			Stake:          u.stake,
+			Balance:        stateDB.GetBalance(u.address),
+			CommissionRate: u.rate,
+			Reward:         reward,
+		})
+	}
+	return snapshot, nil
+}
+
+// WriteEconomicCSV writes snapshots to w as CSV, a row per user and block.
+func WriteEconomicCSV(w io.Writer, snapshots []*EconomicSnapshot) error {
+	out := csv.NewWriter(w)
+	header := []string{"block", "address", "role", "stake", "balance", "commission_rate", "reward",
+		"block_reward", "min_gas_price", "stake_supply", "operator_balance"}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	em := new(EconomicMetrics)
+	for _, s := range snapshots {
+		for _, u := range s.Users {
+			row := []string{
+				fmt.Sprint(s.Number), u.Address.Hex(), em.resolveUserTypeName(u.Type),
+				u.Stake.String(), u.Balance.String(), u.CommissionRate.String(), u.Reward.String(),
+				s.BlockReward.String(), s.MinGasPrice.String(), s.StakeSupply.String(), s.OperatorBalance.String(),
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
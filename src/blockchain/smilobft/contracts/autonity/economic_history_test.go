@@ -0,0 +1,145 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package autonity
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+)
+
+func TestEconomicHistory(t *testing.T) {
+	ac, chain, statedb, _ := newTestContract(t, 100)
+	config := chain.config.AutonityContractConfig
+	history := NewEconomicHistory(rawdb.NewMemoryDatabase())
+	ac.SetEconomicHistory(history)
+
+	stakeholder := config.Users[3].Address
+	ac.takeEconomicSnapshot(testHeader(2), statedb, &RewardDistributionMetaData{
+		Holders:         []common.Address{stakeholder},
+		Rewardfractions: []*big.Int{big.NewInt(5)},
+		Amount:          big.NewInt(9),
+	})
+	ac.takeEconomicSnapshot(testHeader(3), statedb, nil)
+	// The balances are the ones of the written state
+	statedb.AddBalance(stakeholder, big.NewInt(77), big.NewInt(2))
+	ac.RecordEconomicSnapshot(testHeader(2), statedb)
+	ac.RecordEconomicSnapshot(testHeader(3), statedb)
+	// Blocks which were not processed are not recorded
+	ac.RecordEconomicSnapshot(testHeader(4), statedb)
+
+	snapshots, err := history.Range(1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Number != 2 || snapshots[1].Number != 3 {
+		t.Fatalf("snapshots: have %+v, want blocks 2 and 3", snapshots)
+	}
+	s := snapshots[0]
+	if s.MinGasPrice.Uint64() != config.MinGasPrice || s.StakeSupply.Uint64() != 650 || s.BlockReward.Int64() != 9 {
+		t.Errorf("block 2: min gas price %v, stake supply %v, reward %v", s.MinGasPrice, s.StakeSupply, s.BlockReward)
+	}
+	if len(s.Users) != len(config.Users) {
+		t.Fatalf("block 2: have %d users, want %d", len(s.Users), len(config.Users))
+	}
+	// The redistribution is only recorded for its own block
+	if snapshots[1].BlockReward.Sign() != 0 {
+		t.Errorf("block 3: reward %v, want 0", snapshots[1].BlockReward)
+	}
+
+	filtered := s.Filter(stakeholder)
+	if len(filtered.Users) != 1 {
+		t.Fatalf("filtered users: have %d, want 1", len(filtered.Users))
+	}
+	u := filtered.Users[0]
+	if u.Stake.Int64() != 50 || u.Balance.Int64() != 77 || u.Reward.Int64() != 5 || u.Type != Stakeholder {
+		t.Errorf("stakeholder: have %+v", u)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEconomicCSV(&buf, []*EconomicSnapshot{filtered}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2", stakeholder.Hex(), RoleStakeHolder, "50", "77", "0", "5", "9", "10", "650", "0"}
+	if len(rows) != 2 || len(rows[1]) != len(want) {
+		t.Fatalf("csv rows: have %v", rows)
+	}
+	for i := range want {
+		if rows[1][i] != want[i] {
+			t.Errorf("csv column %s: have %s, want %s", rows[0][i], rows[1][i], want[i])
+		}
+	}
+
+	if _, err := history.Range(0, MaxEconomicHistoryRange); err == nil {
+		t.Error("range over the limit accepted")
+	}
+}
+
+// Blocks without fees don't redistribute anything but are still recorded
+func TestEconomicHistoryWithoutFees(t *testing.T) {
+	ac, _, statedb, _ := newTestContract(t, 100)
+	history := NewEconomicHistory(rawdb.NewMemoryDatabase())
+	ac.SetEconomicHistory(history)
+
+	header := testHeader(5)
+	if err := ac.ApplyPerformRedistribution(nil, nil, header, statedb); err != nil {
+		t.Fatal(err)
+	}
+	ac.RecordEconomicSnapshot(header, statedb)
+
+	snapshot, err := history.Read(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot == nil || snapshot.BlockReward.Sign() != 0 {
+		t.Fatalf("block without fees: have %+v, want a snapshot without reward", snapshot)
+	}
+}
+
+// failingGetStore is a database whose reads fail
+type failingGetStore struct {
+	ethdb.KeyValueStore
+}
+
+func (failingGetStore) Has(key []byte) (bool, error) { return true, nil }
+
+func (failingGetStore) Get(key []byte) ([]byte, error) { return nil, errors.New("read failure") }
+
+func TestEconomicHistoryReadError(t *testing.T) {
+	history := NewEconomicHistory(rawdb.NewMemoryDatabase())
+	if snapshot, err := history.Read(1); snapshot != nil || err != nil {
+		t.Errorf("missing snapshot: have %v, %v, want nil, nil", snapshot, err)
+	}
+	history = NewEconomicHistory(failingGetStore{rawdb.NewMemoryDatabase()})
+	if _, err := history.Read(1); err == nil {
+		t.Error("read failure swallowed")
+	}
+	if _, err := history.Range(1, 2); err == nil {
+		t.Error("read failure swallowed by range")
+	}
+}
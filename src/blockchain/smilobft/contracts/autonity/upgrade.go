@@ -0,0 +1,361 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package autonity
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/accounts/abi"
+	"go-smilo/src/blockchain/smilobft/cmn"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+)
+
+var errValidatorSetChanged = errors.New("validator set changed across the contract upgrade")
+
+// UpgradeRecord describes an upgrade of the Autonity contract scheduled in the
+// chain config.
+type UpgradeRecord struct {
+	Block    uint64         `json:"block"`
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	CodeHash common.Hash    `json:"codeHash"`
+	Applied  bool           `json:"applied"`
+}
+
+// UpgradeHistory returns the scheduled upgrades of the Autonity contract and
+// whether they were applied to statedb.
+func (ac *Contract) UpgradeHistory(statedb vm.SmiloAPIState) ([]UpgradeRecord, error) {
+	config := ac.bc.Config().AutonityContractConfig
+	from, err := config.GetContractAddress()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]UpgradeRecord, 0, len(config.Upgrades))
+	for i, upgrade := range config.Upgrades {
+		to := config.GetUpgradeAddress(i)
+		codeHash, applied := upgradeApplied(statedb, config.Deployer, i)
+		records = append(records, UpgradeRecord{
+			Block:    upgrade.Block,
+			From:     from,
+			To:       to,
+			CodeHash: codeHash,
+			Applied:  applied,
+		})
+		from = to
+	}
+	return records, nil
+}
+
+// upgradeKey returns the storage key of the deployer account recording the code
+// hash of the contract deployed by the i-th upgrade.
+func upgradeKey(i int) common.Hash {
+	return crypto.Keccak256Hash([]byte("autonity.upgrade"), common.BigToHash(big.NewInt(int64(i))).Bytes())
+}
+
+// upgradeApplied returns the code hash of the contract deployed by the i-th
+// upgrade, and whether the upgrade was applied to statedb. The deployer nonce
+// is set by the genesis deployment, so its account and records are never
+// cleared as empty.
+func upgradeApplied(statedb vm.SmiloAPIState, deployer common.Address, i int) (common.Hash, bool) {
+	codeHash := statedb.GetState(deployer, upgradeKey(i))
+	return codeHash, codeHash != (common.Hash{})
+}
+
+// contractUser is a user of the Autonity contract as read before an upgrade.
+type contractUser struct {
+	address  common.Address
+	enode    string
+	userType uint8
+	stake    *big.Int
+	rate     *big.Int
+}
+
+// contractState is the state of the Autonity contract migrated by an upgrade.
+type contractState struct {
+	users         []*contractUser
+	validators    []common.Address
+	operator      common.Address
+	minGasPrice   *big.Int
+	committeeSize *big.Int // nil if the contract has no committee
+}
+
+// ApplyUpgrade deploys the Autonity contract upgrade scheduled at the block of
+// header, if any, and migrates the users, stakes and settings of the running
+// contract to it. The upgrade is applied once per state, before the reward
+// redistribution of the block, and fails if the validator set changes. Applied
+// upgrades are recorded in the storage of the deployer account.
+func (ac *Contract) ApplyUpgrade(header *types.Header, statedb *state.StateDB) error {
+	config := ac.bc.Config().AutonityContractConfig
+	index := -1
+	for i := range config.Upgrades {
+		if config.Upgrades[i].Block == header.Number.Uint64() {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+	if _, applied := upgradeApplied(statedb, config.Deployer, index); applied {
+		return nil
+	}
+	newAddress := config.GetUpgradeAddress(index)
+	oldAddress, oldABI, err := ac.contract(statedb)
+	if err != nil {
+		return err
+	}
+	newABI, err := ac.abiAt(index)
+	if err != nil {
+		return err
+	}
+
+	deployer := config.Deployer
+	evm := ac.getEVM(header, deployer, statedb)
+	st, err := ac.readContractState(evm, oldAddress, oldABI)
+	if err != nil {
+		log.Error("Could not read the Autonity contract state", "address", oldAddress, "err", err)
+		return err
+	}
+
+	users := make(cmn.Addresses, 0, len(st.users))
+	enodes := make([]string, 0, len(st.users))
+	userTypes := make([]*big.Int, 0, len(st.users))
+	stakes := make([]*big.Int, 0, len(st.users))
+	for _, u := range st.users {
+		users = append(users, u.address)
+		enodes = append(enodes, u.enode)
+		userTypes = append(userTypes, new(big.Int).SetUint64(uint64(u.userType)))
+		stakes = append(stakes, u.stake)
+	}
+	//"" means contructor
+	constructorParams, err := newABI.Pack("", users, enodes, userTypes, stakes, st.operator, st.minGasPrice)
+	if err != nil {
+		return err
+	}
+	data := append(common.FromHex(config.Upgrades[index].Bytecode), constructorParams...)
+
+	// The address of every upgrade is fixed by its index, whatever the deployer nonce
+	if _, _, _, vmerr := evm.CreateAt(vm.AccountRef(deployer), data, uint64(0xFFFFFFFF), new(big.Int), newAddress); vmerr != nil {
+		log.Error("evm.CreateAt returns err", "err", vmerr)
+		return vmerr
+	}
+
+	// Restore the settings the constructor does not take
+	if _, ok := newABI.Methods["setCommitteeSize"]; ok && st.committeeSize != nil {
+		if err := callContract(evm, st.operator, newAddress, newABI, nil, "setCommitteeSize", st.committeeSize); err != nil {
+			return err
+		}
+	}
+	if _, ok := newABI.Methods["setCommissionRate"]; ok {
+		for _, u := range st.users {
+			if u.rate.Sign() == 0 {
+				continue
+			}
+			if err := callContract(evm, u.address, newAddress, newABI, nil, "setCommissionRate", u.rate); err != nil {
+				return err
+			}
+		}
+	}
+	balance := statedb.GetBalance(oldAddress)
+	statedb.SubBalance(oldAddress, balance, header.Number)
+	statedb.AddBalance(newAddress, balance, header.Number)
+
+	var validators []common.Address
+	if err := callContract(evm, deployer, newAddress, newABI, &validators, "getValidators"); err != nil {
+		return err
+	}
+	if !sameAddresses(validators, st.validators) {
+		log.Error("Autonity contract upgrade changed the validators", "before", st.validators, "after", validators)
+		return errValidatorSetChanged
+	}
+
+	statedb.SetState(deployer, upgradeKey(index), statedb.GetCodeHash(newAddress))
+
+	log.Info("Upgraded Autonity Contract", "block", header.Number, "from", oldAddress.String(), "to", newAddress.String(), "users", len(st.users))
+	return nil
+}
+
+// readContractState reads the state of the Autonity contract at address. The
+// user list is dumped by the contracts which implement dumpEconomicsMetricData,
+// older contracts only expose their participants through the enode whitelist.
+func (ac *Contract) readContractState(evm *vm.EVM, address common.Address, contractABI *abi.ABI) (*contractState, error) {
+	config := ac.bc.Config().AutonityContractConfig
+	caller := config.Deployer
+	st := &contractState{
+		operator:    config.Operator,
+		minGasPrice: new(big.Int),
+	}
+
+	byAddress := make(map[common.Address]*contractUser)
+	add := func(u *contractUser) {
+		st.users = append(st.users, u)
+		byAddress[u.address] = u
+	}
+
+	if err := callContract(evm, caller, address, contractABI, &st.validators, "getValidators"); err != nil {
+		return nil, err
+	}
+	_, dumped := contractABI.Methods["dumpEconomicsMetricData"]
+	if dumped {
+		v := EconomicMetaData{make([]common.Address, 32), make([]uint8, 32), make([]*big.Int, 32),
+			make([]*big.Int, 32), new(big.Int), new(big.Int)}
+		if err := callContract(evm, caller, address, contractABI, &v, "dumpEconomicsMetricData"); err != nil {
+			return nil, err
+		}
+		for i := range v.Accounts {
+			add(&contractUser{address: v.Accounts[i], userType: v.Usertypes[i], stake: v.Stakes[i], rate: v.Commissionrates[i]})
+		}
+	} else {
+		var stakeholders []common.Address
+		if err := callContract(evm, caller, address, contractABI, &stakeholders, "getStakeholders"); err != nil {
+			return nil, err
+		}
+		for _, addr := range st.validators {
+			add(&contractUser{address: addr, userType: Validator})
+		}
+		for _, addr := range stakeholders {
+			if byAddress[addr] == nil {
+				add(&contractUser{address: addr, userType: Stakeholder})
+			}
+		}
+		_, hasRate := contractABI.Methods["getRate"]
+		for _, u := range st.users {
+			u.stake, u.rate = new(big.Int), new(big.Int)
+			if err := callContract(evm, caller, address, contractABI, &u.stake, "getAccountStake", u.address); err != nil {
+				return nil, err
+			}
+			if hasRate {
+				if err := callContract(evm, caller, address, contractABI, &u.rate, "getRate", u.address); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Give every whitelisted enode back to its user. The enodes of the genesis
+	// users are known, the others are owned by the address of their key.
+	var whitelist []string
+	if err := callContract(evm, caller, address, contractABI, &whitelist, "getWhitelist"); err != nil {
+		return nil, err
+	}
+	owners := make(map[string]common.Address)
+	for _, u := range config.Users {
+		owners[u.Enode] = u.Address
+	}
+	for _, e := range whitelist {
+		addr, ok := owners[e]
+		if !ok {
+			var err error
+			if addr, err = enodeAddress(e); err != nil {
+				return nil, fmt.Errorf("whitelisted enode %q: %v", e, err)
+			}
+		}
+		switch u := byAddress[addr]; {
+		case u != nil && u.enode == "":
+			u.enode = e
+		case u == nil && !dumped:
+			add(&contractUser{address: addr, enode: e, userType: Participant, stake: new(big.Int), rate: new(big.Int)})
+		default:
+			return nil, fmt.Errorf("whitelisted enode %q has no user", e)
+		}
+	}
+	for _, addr := range st.validators {
+		if byAddress[addr].enode == "" {
+			return nil, fmt.Errorf("validator %v has no enode", addr.String())
+		}
+	}
+
+	if err := callContract(evm, caller, address, contractABI, &st.minGasPrice, "getMinimumGasPrice"); err != nil {
+		return nil, err
+	}
+	if _, ok := contractABI.Methods["operatorAccount"]; ok {
+		if err := callContract(evm, caller, address, contractABI, &st.operator, "operatorAccount"); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := contractABI.Methods["getMaxCommitteeSize"]; ok {
+		st.committeeSize = new(big.Int)
+		if err := callContract(evm, caller, address, contractABI, &st.committeeSize, "getMaxCommitteeSize"); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// callContract calls method of the contract at address and unpacks the
+// returned values into result, unless it is nil.
+func callContract(evm *vm.EVM, caller, address common.Address, contractABI *abi.ABI, result interface{}, method string, args ...interface{}) error {
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	ret, _, vmerr := evm.Call(vm.AccountRef(caller), address, input, uint64(0xFFFFFFFF), new(big.Int), false)
+	if vmerr != nil {
+		return fmt.Errorf("autonity contract %s: %v", method, vmerr)
+	}
+	if result == nil {
+		return nil
+	}
+	return contractABI.Unpack(result, method, ret)
+}
+
+// enodeAddress returns the address of the key of an enode URL. The host is
+// not resolved, as block processing must not depend on the DNS.
+func enodeAddress(rawurl string) (common.Address, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if u.Scheme != "enode" || u.User == nil {
+		return common.Address{}, errors.New("invalid enode URL")
+	}
+	id, err := hex.DecodeString(u.User.String())
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.UnmarshalPubkey(append([]byte{0x04}, id...))
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func sameAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append(cmn.Addresses{}, a...)
+	y := append(cmn.Addresses{}, b...)
+	sort.Sort(x)
+	sort.Sort(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
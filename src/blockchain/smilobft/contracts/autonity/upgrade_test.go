@@ -0,0 +1,287 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package autonity
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// testChain implements Blockchainer and the part of consensus.ChainReader the
+// contract uses
+type testChain struct {
+	consensus.ChainReader
+	config *params.ChainConfig
+	head   *types.Block
+}
+
+func (c *testChain) Config() *params.ChainConfig                 { return c.config }
+func (c *testChain) GetVMConfig() *vm.Config                     { return &vm.Config{} }
+func (c *testChain) CurrentBlock() *types.Block                  { return c.head }
+func (c *testChain) Engine() consensus.Engine                    { return nil }
+func (c *testChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (c *testChain) UpdateEnodeWhitelist(*types.Nodes, uint64)   {}
+func (c *testChain) ReadEnodeWhitelist(bool) *types.Nodes        { return nil }
+func (c *testChain) UpdateBlacklist(*types.Nodes)                {}
+func (c *testChain) ReadBlacklist(bool) *types.Nodes             { return nil }
+func (c *testChain) setHead(number uint64)                       { c.head = types.NewBlockWithHeader(testHeader(number)) }
+func (c *testChain) getHashFn(*types.Header, ChainContext) func(uint64) common.Hash {
+	return func(uint64) common.Hash { return common.Hash{} }
+}
+
+func testHeader(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   100000000,
+		Difficulty: big.NewInt(1),
+		Time:       number,
+	}
+}
+
+func testUser(t *testing.T, userType params.UserType, stake uint64) (params.User, *ecdsa.PrivateKey) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params.User{
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+		Enode:   enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 0).String(),
+		Type:    userType,
+		Stake:   stake,
+	}, key
+}
+
+func newTestContract(t *testing.T, upgradeBlock uint64) (*Contract, *testChain, *state.StateDB, []*ecdsa.PrivateKey) {
+	operatorKey, _ := crypto.GenerateKey()
+	config := &params.AutonityContractGenesis{
+		Operator:    crypto.PubkeyToAddress(operatorKey.PublicKey),
+		MinGasPrice: 10,
+	}
+	var keys []*ecdsa.PrivateKey
+	for _, u := range []struct {
+		userType params.UserType
+		stake    uint64
+	}{{params.UserValidator, 100}, {params.UserValidator, 200}, {params.UserValidator, 300}, {params.UserStakeHolder, 50}, {params.UserParticipant, 0}} {
+		user, key := testUser(t, u.userType, u.stake)
+		config.Users = append(config.Users, user)
+		keys = append(keys, key)
+	}
+	config.AddDefault()
+
+	upgrade := params.AutonityContractUpgrade{Block: upgradeBlock, Bytecode: params.DefaultBytecode, ABI: params.DefaultABI}
+	sig, err := crypto.Sign(upgrade.SigHash().Bytes(), operatorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgrade.Signature = sig
+	config.Upgrades = append(config.Upgrades, upgrade)
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.AutonityContractConfig = config
+	chain := &testChain{config: &chainConfig}
+	chain.setHead(1)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	transfer := func(db vm.StateDB, sender, recipient common.Address, amount, blockNumber *big.Int) {
+		db.SubBalance(sender, amount, blockNumber)
+		db.AddBalance(recipient, amount, blockNumber)
+	}
+	canTransfer := func(db vm.StateDB, addr common.Address, amount *big.Int) bool {
+		return db.GetBalance(addr).Cmp(amount) >= 0
+	}
+	ac := NewAutonityContract(chain, canTransfer, transfer, chain.getHashFn)
+	if _, err := ac.DeployAutonityContract(chain, testHeader(1), statedb); err != nil {
+		t.Fatal(err)
+	}
+	return ac, chain, statedb, append(keys, operatorKey)
+}
+
+func TestApplyUpgrade(t *testing.T) {
+	ac, chain, statedb, keys := newTestContract(t, 5)
+	config := chain.config.AutonityContractConfig
+	oldAddress, _ := config.GetContractAddress()
+	operator := crypto.PubkeyToAddress(keys[len(keys)-1].PublicKey)
+
+	// Change the contract state away from its genesis values
+	header := testHeader(3)
+	evm := ac.getEVM(header, config.Deployer, statedb)
+	genesisABI, _ := ac.abiAt(-1)
+	if err := callContract(evm, operator, oldAddress, genesisABI, nil, "setMinimumGasPrice", big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := callContract(evm, config.Users[1].Address, oldAddress, genesisABI, nil, "setCommissionRate", big.NewInt(7)); err != nil {
+		t.Fatal(err)
+	}
+	statedb.AddBalance(oldAddress, big.NewInt(1000), header.Number)
+
+	before, err := ac.ContractGetValidators(chain, testHeader(4), statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	whitelist, err := ac.callGetWhitelist(statedb, nil, testHeader(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks without a scheduled upgrade leave the contract alone
+	if err := ac.ApplyUpgrade(testHeader(4), statedb); err != nil {
+		t.Fatal(err)
+	}
+	if addr, _, _ := ac.contract(statedb); addr != oldAddress {
+		t.Fatalf("contract moved before the upgrade block to %v", addr.String())
+	}
+
+	nonce := statedb.GetNonce(config.Deployer)
+	if err := ac.ApplyUpgrade(testHeader(5), statedb); err != nil {
+		t.Fatal(err)
+	}
+	if statedb.GetNonce(config.Deployer) != nonce {
+		t.Fatal("upgrade changed the deployer nonce")
+	}
+	newAddress := config.GetUpgradeAddress(0)
+	if addr, _, _ := ac.contract(statedb); addr != newAddress {
+		t.Fatalf("contract address: have %v, want %v", addr.String(), newAddress.String())
+	}
+	// Applying the upgrade again, as the state processor and Finalize both
+	// do, must not deploy a second contract
+	codeHash := statedb.GetCodeHash(newAddress)
+	if err := ac.ApplyUpgrade(testHeader(5), statedb); err != nil {
+		t.Fatal(err)
+	}
+	if statedb.GetCodeHash(newAddress) != codeHash {
+		t.Fatal("upgrade applied twice")
+	}
+
+	after, err := ac.ContractGetValidators(chain, testHeader(5), statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("validators: have %v, want %v", after, before)
+	}
+	newWhitelist, err := ac.callGetWhitelist(statedb, nil, testHeader(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The contract does not keep the whitelist in the order of its users
+	sort.Strings(whitelist.StrList)
+	sort.Strings(newWhitelist.StrList)
+	if !reflect.DeepEqual(whitelist.StrList, newWhitelist.StrList) {
+		t.Errorf("whitelist: have %v, want %v", newWhitelist.StrList, whitelist.StrList)
+	}
+
+	newABI, _ := ac.abiAt(0)
+	evm = ac.getEVM(testHeader(5), config.Deployer, statedb)
+	for _, u := range config.Users {
+		if u.Type == params.UserParticipant {
+			continue
+		}
+		stake := new(big.Int)
+		if err := callContract(evm, config.Deployer, newAddress, newABI, &stake, "getAccountStake", u.Address); err != nil {
+			t.Fatal(err)
+		}
+		if stake.Uint64() != u.Stake {
+			t.Errorf("stake of %v: have %v, want %d", u.Address.String(), stake, u.Stake)
+		}
+	}
+	rate := new(big.Int)
+	if err := callContract(evm, config.Deployer, newAddress, newABI, &rate, "getRate", config.Users[1].Address); err != nil {
+		t.Fatal(err)
+	}
+	if rate.Int64() != 7 {
+		t.Errorf("commission rate: have %v, want 7", rate)
+	}
+	if price, err := ac.callGetMinimumGasPrice(statedb, nil, testHeader(5)); err != nil || price != 42 {
+		t.Errorf("minimum gas price: have %d (%v), want 42", price, err)
+	}
+	if balance := statedb.GetBalance(newAddress); balance.Int64() != 1000 {
+		t.Errorf("contract balance: have %v, want 1000", balance)
+	}
+
+	if addr := ac.Address(); addr != oldAddress {
+		t.Errorf("address before the upgrade block is the head: have %v, want %v", addr.String(), oldAddress.String())
+	}
+	chain.setHead(5)
+	if addr := ac.Address(); addr != newAddress {
+		t.Errorf("address after the upgrade: have %v, want %v", addr.String(), newAddress.String())
+	}
+
+	// The address of a block doesn't depend on the head
+	chain.setHead(1)
+	if addr := ac.AddressAt(5); addr != newAddress {
+		t.Errorf("address at 5: have %v, want %v", addr.String(), newAddress.String())
+	}
+	if contractABI := ac.ABIAt(4); contractABI != config.ABI {
+		t.Errorf("ABI at 4 is not the genesis ABI")
+	}
+	if addr, contractABI, err := ac.ContractAt(4); err != nil || addr != oldAddress || contractABI != genesisABI {
+		t.Errorf("contract at 4: have %v (%v), want %v", addr.String(), err, oldAddress.String())
+	}
+	if addr, contractABI, err := ac.ContractAt(5); err != nil || addr != newAddress || contractABI != newABI {
+		t.Errorf("contract at 5: have %v (%v), want %v", addr.String(), err, newAddress.String())
+	}
+
+	history, err := ac.UpgradeHistory(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UpgradeRecord{{Block: 5, From: oldAddress, To: newAddress, CodeHash: statedb.GetCodeHash(newAddress), Applied: true}}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("history: have %+v, want %+v", history, want)
+	}
+}
+
+func TestUpgradeNotInferredFromCode(t *testing.T) {
+	ac, chain, statedb, _ := newTestContract(t, 5)
+	config := chain.config.AutonityContractConfig
+	oldAddress, _ := config.GetContractAddress()
+	newAddress := config.GetUpgradeAddress(0)
+
+	// Code at the address of an upgrade does not make it applied
+	statedb.SetCode(newAddress, []byte{0x00})
+	if addr, _, _ := ac.contract(statedb); addr != oldAddress {
+		t.Errorf("contract address: have %v, want %v", addr.String(), oldAddress.String())
+	}
+	history, err := ac.UpgradeHistory(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Applied {
+		t.Errorf("history: have %+v, want the upgrade not applied", history)
+	}
+	if err := ac.ApplyUpgrade(testHeader(5), statedb); err == nil {
+		t.Error("upgrade over existing code: have nil, want an error")
+	}
+}
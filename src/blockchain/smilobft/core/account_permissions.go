@@ -0,0 +1,116 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/accounts/abi"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// accountAccessABI is the interface of the governance contract of the account
+// permissions.
+const accountAccessABI = `[{"constant":true,"inputs":[{"name":"account","type":"address"}],"name":"getAccountAccess","outputs":[{"name":"","type":"uint8"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+// accountAccessGas is the gas allowance of the account access queries.
+const accountAccessGas = 100000
+
+var (
+	// ErrAccountNotPermitted is returned if the sender of a transaction does not
+	// have the account access it requires.
+	ErrAccountNotPermitted = errors.New("account not permitted")
+
+	accountAccessContract, _ = abi.JSON(strings.NewReader(accountAccessABI))
+)
+
+// RequiredAccountAccess returns the account access needed to send tx.
+func RequiredAccountAccess(tx *types.Transaction) params.AccountAccess {
+	if tx.To() == nil {
+		return params.AccountAccessDeploy
+	}
+	return params.AccountAccessTransact
+}
+
+// GetAccountAccess returns the access of account at the state of evm, from the
+// governance contract if deployed, else from the genesis allow-list. Accounts
+// are unrestricted before the account permissions fork.
+func GetAccountAccess(evm *vm.EVM, account common.Address) (params.AccountAccess, error) {
+	if !evm.ChainConfig().IsAccountPermissions(evm.BlockNumber) {
+		return params.AccountAccessAdmin, nil
+	}
+	config := evm.ChainConfig().AccountPermissions
+	if config.Contract != (common.Address{}) && evm.StateDB.GetCodeSize(config.Contract) > 0 {
+		return callAccountAccess(evm, config.Contract, account)
+	}
+	return config.Access(account), nil
+}
+
+// CheckAccountAccess checks that from has the account access needed to send tx.
+func CheckAccountAccess(evm *vm.EVM, tx *types.Transaction, from common.Address) error {
+	access, err := GetAccountAccess(evm, from)
+	if err != nil {
+		return err
+	}
+	if access < RequiredAccountAccess(tx) {
+		return ErrAccountNotPermitted
+	}
+	return nil
+}
+
+// NewAccountAccessEVM returns an EVM to query the account access at the state
+// of header, outside of a transaction.
+func NewAccountAccessEVM(config *params.ChainConfig, header *types.Header, statedb vm.StateDB) *vm.EVM {
+	context := vm.Context{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Coinbase:    header.Coinbase,
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        new(big.Int).SetUint64(header.Time),
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+		GasLimit:    header.GasLimit,
+		GasPrice:    new(big.Int),
+	}
+	return vm.NewEVM(context, statedb, statedb, config, vm.Config{})
+}
+
+func callAccountAccess(evm *vm.EVM, contract, account common.Address) (params.AccountAccess, error) {
+	input, err := accountAccessContract.Pack("getAccountAccess", account)
+	if err != nil {
+		return params.AccountAccessNone, err
+	}
+	ret, _, err := evm.StaticCall(vm.AccountRef(account), contract, input, accountAccessGas, false)
+	if err != nil {
+		return params.AccountAccessNone, fmt.Errorf("account access contract %s: %v", contract.Hex(), err)
+	}
+	var access uint8
+	if err := accountAccessContract.Unpack(&access, "getAccountAccess", ret); err != nil {
+		return params.AccountAccessNone, fmt.Errorf("account access contract %s: %v", contract.Hex(), err)
+	}
+	if access > uint8(params.AccountAccessAdmin) {
+		access = uint8(params.AccountAccessAdmin)
+	}
+	return params.AccountAccess(access), nil
+}
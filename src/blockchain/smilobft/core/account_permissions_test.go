@@ -0,0 +1,158 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus/ethash"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+func TestAccountAccess(t *testing.T) {
+	var (
+		deployer   = common.HexToAddress("0x01")
+		transactor = common.HexToAddress("0x02")
+		stranger   = common.HexToAddress("0x03")
+		contract   = common.HexToAddress("0x0100")
+	)
+	var permissions params.AccountPermissionsConfig
+	blob := `{"accounts": {"` + deployer.Hex() + `": "deploy", "` + transactor.Hex() + `": "transact"}}`
+	if err := json.Unmarshal([]byte(blob), &permissions); err != nil {
+		t.Fatal(err)
+	}
+	config := *params.TestChainConfig
+	config.AccountPermissions = &permissions
+	config.AccountPermissionsBlock = big.NewInt(1)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	evm := NewAccountAccessEVM(&config, header, statedb)
+
+	call := types.NewTransaction(0, contract, big.NewInt(1), 21000, big.NewInt(1), nil)
+	create := types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), nil)
+	tests := []struct {
+		from common.Address
+		tx   *types.Transaction
+		err  error
+	}{
+		{deployer, create, nil},
+		{transactor, call, nil},
+		{transactor, create, ErrAccountNotPermitted},
+		{stranger, call, ErrAccountNotPermitted},
+	}
+	for i, tt := range tests {
+		if err := CheckAccountAccess(evm, tt.tx, tt.from); err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+
+	// The governance contract takes over the allow-list once deployed
+	permissions.Contract = contract
+	statedb.SetCode(contract, common.FromHex("0x600260005260206000f3")) // returns 2 (deploy) to every account
+	if access, err := GetAccountAccess(evm, stranger); err != nil || access != params.AccountAccessDeploy {
+		t.Errorf("contract access: have %v (%v), want %v", access, err, params.AccountAccessDeploy)
+	}
+
+	// A failing contract call is an error, not a fallback to the allow-list
+	statedb.SetCode(contract, common.FromHex("0x60006000fd")) // reverts
+	if _, err := GetAccountAccess(evm, deployer); err == nil {
+		t.Errorf("reverting contract: access granted")
+	}
+	if err := CheckAccountAccess(evm, create, deployer); err == nil {
+		t.Errorf("reverting contract: transaction permitted")
+	}
+
+	// Accounts are unrestricted before the fork and without account permissions
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	if access, err := GetAccountAccess(NewAccountAccessEVM(&config, genesis, statedb), stranger); err != nil || access != params.AccountAccessAdmin {
+		t.Errorf("access before the fork: have %v (%v), want %v", access, err, params.AccountAccessAdmin)
+	}
+	if access, err := GetAccountAccess(NewAccountAccessEVM(params.TestChainConfig, header, statedb), stranger); err != nil || access != params.AccountAccessAdmin {
+		t.Errorf("unrestricted access: have %v (%v), want %v", access, err, params.AccountAccessAdmin)
+	}
+}
+
+// Tests that blocks with a transaction from an account without the access it
+// requires are rejected by the block processing.
+func TestProcessAccountAccess(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		db       = rawdb.NewMemoryDatabase()
+		gspec    = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{sender: {Balance: big.NewInt(1000000000)}}}
+		genesis  = gspec.MustCommit(db)
+		signer   = types.HomesteadSigner{}
+		contract = common.HexToAddress("0x0100")
+	)
+	// Build the blocks without permissions, so that the forbidden transaction is included
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(sender), contract, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		gen.AddTx(tx)
+	})
+
+	config := *params.TestChainConfig
+	config.AccountPermissions = &params.AccountPermissionsConfig{
+		Accounts: map[common.Address]params.AccountAccess{sender: params.AccountAccessNone},
+	}
+	config.AccountPermissionsBlock = big.NewInt(2)
+
+	db = rawdb.NewMemoryDatabase()
+	gspec.Config = &config
+	gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("block before the fork rejected: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[1:]); err != ErrAccountNotPermitted {
+		t.Errorf("block after the fork: have %v, want %v", err, ErrAccountNotPermitted)
+	}
+}
+
+func TestTxPoolAccountAccess(t *testing.T) {
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	config := *pool.chainconfig
+	config.AccountPermissions = &params.AccountPermissionsConfig{
+		Accounts: map[common.Address]params.AccountAccess{crypto.PubkeyToAddress(key.PublicKey): params.AccountAccessTransact},
+	}
+	config.AccountPermissionsBlock = big.NewInt(1) // the block on top of the pool head
+	pool.chainconfig = &config
+
+	create, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(create); err != ErrAccountNotPermitted {
+		t.Errorf("contract creation of a transact account: have %v, want %v", err, ErrAccountNotPermitted)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err == ErrAccountNotPermitted {
+		t.Errorf("transaction of a transact account rejected")
+	}
+}
@@ -37,40 +37,58 @@ import (
 )
 
 func BenchmarkInsertChain_empty_memdb(b *testing.B) {
-	benchInsertChain(b, false, nil)
+	benchInsertChain(b, "", nil)
 }
 func BenchmarkInsertChain_empty_diskdb(b *testing.B) {
-	benchInsertChain(b, true, nil)
+	benchInsertChain(b, rawdb.LevelDBEngine, nil)
+}
+func BenchmarkInsertChain_empty_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, nil)
 }
 func BenchmarkInsertChain_valueTx_memdb(b *testing.B) {
-	benchInsertChain(b, false, genValueTx(0))
+	benchInsertChain(b, "", genValueTx(0))
 }
 func BenchmarkInsertChain_valueTx_diskdb(b *testing.B) {
-	benchInsertChain(b, true, genValueTx(0))
+	benchInsertChain(b, rawdb.LevelDBEngine, genValueTx(0))
+}
+func BenchmarkInsertChain_valueTx_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, genValueTx(0))
 }
 func BenchmarkInsertChain_valueTx_100kB_memdb(b *testing.B) {
-	benchInsertChain(b, false, genValueTx(100*1024))
+	benchInsertChain(b, "", genValueTx(100*1024))
 }
 func BenchmarkInsertChain_valueTx_100kB_diskdb(b *testing.B) {
-	benchInsertChain(b, true, genValueTx(100*1024))
+	benchInsertChain(b, rawdb.LevelDBEngine, genValueTx(100*1024))
+}
+func BenchmarkInsertChain_valueTx_100kB_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, genValueTx(100*1024))
 }
 func BenchmarkInsertChain_uncles_memdb(b *testing.B) {
-	benchInsertChain(b, false, genUncles)
+	benchInsertChain(b, "", genUncles)
 }
 func BenchmarkInsertChain_uncles_diskdb(b *testing.B) {
-	benchInsertChain(b, true, genUncles)
+	benchInsertChain(b, rawdb.LevelDBEngine, genUncles)
+}
+func BenchmarkInsertChain_uncles_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, genUncles)
 }
 func BenchmarkInsertChain_ring200_memdb(b *testing.B) {
-	benchInsertChain(b, false, genTxRing(200))
+	benchInsertChain(b, "", genTxRing(200))
 }
 func BenchmarkInsertChain_ring200_diskdb(b *testing.B) {
-	benchInsertChain(b, true, genTxRing(200))
+	benchInsertChain(b, rawdb.LevelDBEngine, genTxRing(200))
+}
+func BenchmarkInsertChain_ring200_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, genTxRing(200))
 }
 func BenchmarkInsertChain_ring1000_memdb(b *testing.B) {
-	benchInsertChain(b, false, genTxRing(1000))
+	benchInsertChain(b, "", genTxRing(1000))
 }
 func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
-	benchInsertChain(b, true, genTxRing(1000))
+	benchInsertChain(b, rawdb.LevelDBEngine, genTxRing(1000))
+}
+func BenchmarkInsertChain_ring1000_boltdb(b *testing.B) {
+	benchInsertChain(b, rawdb.BoltDBEngine, genTxRing(1000))
 }
 
 var (
@@ -148,10 +166,11 @@ func genUncles(i int, gen *BlockGen) {
 	}
 }
 
-func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
-	// Create the database in memory or in a temporary directory.
+func benchInsertChain(b *testing.B, engine string, gen func(int, *BlockGen)) {
+	// Create the database in memory or in a temporary directory with the
+	// storage engine.
 	var db ethdb.Database
-	if !disk {
+	if engine == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
 		dir, err := ioutil.TempDir("", "eth-core-bench")
@@ -159,7 +178,7 @@ func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
 			b.Fatalf("cannot create temporary directory: %v", err)
 		}
 		defer os.RemoveAll(dir)
-		db, err = rawdb.NewLevelDBDatabase(dir, 128, 128, "")
+		db, err = rawdb.NewDiskDatabase(engine, dir, 128, 128, "")
 		if err != nil {
 			b.Fatalf("cannot create temporary database: %v", err)
 		}
@@ -142,6 +142,8 @@ type BlockChain struct {
 	triegc *prque.Prque   // Priority queue mapping block numbers to tries to gc
 	gcproc time.Duration  // Accumulates canonical block processing for trie dumping
 
+	vaultTriegc *prque.Prque // Priority queue mapping block numbers to vault tries to gc
+
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
@@ -151,9 +153,15 @@ type BlockChain struct {
 	blockProcFeed event.Feed
 	glienickeFeed event.Feed
 	autonityFeed  event.Feed
+	blacklistFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
+	whitelistMu      sync.Mutex
+	whitelist        *types.Whitelist // Versioned enode whitelist, loaded on first update
+	whitelistEvents  []WhitelistEvent // Whitelist changes waiting to be posted, in block order
+	whitelistSending bool             // Whether a routine is posting the whitelist changes
+
 	mu      sync.RWMutex // global mutex for locking chain operations
 	chainmu sync.RWMutex // blockchain insertion lock
 	procmu  sync.RWMutex // block processor lock
@@ -187,6 +195,11 @@ type BlockChain struct {
 	terminateInsert func(common.Hash, uint64) bool // Testing hook used to terminate ancient receipt chain insertion.
 
 	autonityContract *autonity.Contract
+
+	vaultCheckpoint     *VaultCheckpoint // Trusted vault state to start rebuilds from, nil to start from genesis
+	vaultRebuilding     int32            // Whether a vault state rebuild is running (atomic)
+	vaultRebuildCurrent uint64           // Last block of the vault state rebuild (atomic)
+	vaultRebuildHighest uint64           // Head block the vault state rebuild walks to (atomic)
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -212,6 +225,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		cacheConfig:     cacheConfig,
 		db:              db,
 		triegc:          prque.New(nil),
+		vaultTriegc:     prque.New(nil),
 		stateCache:      state.NewDatabaseWithCache(db, cacheConfig.TrieCleanLimit),
 		quit:            make(chan struct{}),
 		shouldPreserve:  shouldPreserve,
@@ -309,6 +323,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Resume an interrupted rebuild of the vault state
+	if _, ok := ReadVaultRebuildMarker(bc.db); ok && chainConfig.IsSmilo {
+		if err := bc.RebuildVaultState(); err != nil {
+			log.Error("Failed to resume vault state rebuild", "err", err)
+		}
+	}
 	// Take ownership of this particular state
 	go bc.update()
 	return bc, nil
@@ -635,8 +655,11 @@ func (bc *BlockChain) repair(head **types.Block) error {
 	for {
 		// Abort if we've rewound to a head block that does have associated state
 		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
-			log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
-			return nil
+			// Smilo VAULT
+			if _, err := state.New(GetVaultStateRoot(bc.db, (*head).Root()), bc.vaultStateCache); err == nil {
+				log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
+				return nil
+			}
 		}
 		// Otherwise rewind one block and recheck state availability there
 		block := bc.GetBlock((*head).ParentHash(), (*head).NumberU64()-1)
@@ -896,6 +919,7 @@ func (bc *BlockChain) Stop() {
 	//  - HEAD-127: So we have a hard limit on the number of blocks reexecuted
 	if !bc.cacheConfig.TrieDirtyDisabled {
 		triedb := bc.stateCache.TrieDB()
+		vaultTriedb := bc.vaultStateCache.TrieDB()
 
 		for _, offset := range []uint64{0, 1, TriesInMemory - 1} {
 			if number := bc.CurrentBlock().NumberU64(); number > offset {
@@ -905,14 +929,26 @@ func (bc *BlockChain) Stop() {
 				if err := triedb.Commit(recent.Root(), true); err != nil {
 					log.Error("Failed to commit recent state trie", "err", err)
 				}
+				// Smilo VAULT
+				if vaultRoot := GetVaultStateRoot(bc.db, recent.Root()); vaultRoot != (common.Hash{}) {
+					if err := vaultTriedb.Commit(vaultRoot, true); err != nil {
+						log.Error("Failed to commit recent vault state trie", "err", err)
+					}
+				}
 			}
 		}
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
 		}
+		for !bc.vaultTriegc.Empty() {
+			vaultTriedb.Dereference(bc.vaultTriegc.PopItem().(common.Hash))
+		}
 		if size, _ := triedb.Size(); size != 0 {
 			log.Error("Dangling trie nodes after full cleanup")
 		}
+		if size, _ := vaultTriedb.Size(); size != 0 {
+			log.Error("Dangling vault trie nodes after full cleanup")
+		}
 	}
 	log.Info("Blockchain manager stopped")
 }
@@ -1398,6 +1434,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		if bc.chainConfig.Tendermint != nil {
 			bc.GetAutonityContract().MeasureMetricsOfNetworkEconomic(block.Header(), state)
 		}
+		bc.GetAutonityContract().RecordEconomicSnapshot(block.Header(), state)
 
 	} else {
 		msg := "Wont set Istanbul Tendermint SportDAO UpdateEnodesWhitelist, is this correct ? "
@@ -1413,28 +1450,36 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	}
 	triedb := bc.stateCache.TrieDB()
 
-	// Explicit commit for vault state
+	// Explicit commit for vault state, the vault trie is garbage collected
+	// along with the public one
+	var vaultRoot common.Hash
 	if vaultState != nil {
-		vaultRoot, err := vaultState.Commit(bc.chainConfig.IsEIP158(block.Number()))
-		if err != nil {
-			return NonStatTy, err
-		}
-		vaultTriedb := bc.vaultStateCache.TrieDB()
-		if err := vaultTriedb.Commit(vaultRoot, false); err != nil {
+		if vaultRoot, err = vaultState.Commit(bc.chainConfig.IsEIP158(block.Number())); err != nil {
 			return NonStatTy, err
 		}
 	}
+	vaultTriedb := bc.vaultStateCache.TrieDB()
 
 	// If we're running an archive node, always flush
 	if bc.cacheConfig.TrieDirtyDisabled {
 		if err := triedb.Commit(root, false); err != nil {
 			return NonStatTy, err
 		}
+		if vaultState != nil {
+			if err := vaultTriedb.Commit(vaultRoot, false); err != nil {
+				return NonStatTy, err
+			}
+		}
 	} else {
 		// Full but not archive node, do proper garbage collection
 		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
 		bc.triegc.Push(root, -int64(block.NumberU64()))
 
+		if vaultState != nil {
+			vaultTriedb.Reference(vaultRoot, common.Hash{})
+			bc.vaultTriegc.Push(vaultRoot, -int64(block.NumberU64()))
+		}
+
 		if current := block.NumberU64(); current > TriesInMemory {
 			// If we exceeded our memory allowance, flush matured singleton nodes to disk
 			var (
@@ -1444,6 +1489,10 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			if nodes > limit || imgs > 4*1024*1024 {
 				triedb.Cap(limit - ethdb.IdealBatchSize)
 			}
+			// The vault trie gets the same allowance as the public one
+			if nodes, imgs := vaultTriedb.Size(); nodes > limit || imgs > 4*1024*1024 {
+				vaultTriedb.Cap(limit - ethdb.IdealBatchSize)
+			}
 			// Find the next state trie we need to commit
 			chosen := current - TriesInMemory
 
@@ -1462,6 +1511,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 					}
 					// Flush an entire trie and restart the counters
 					triedb.Commit(header.Root, true)
+					if vaultRoot := GetVaultStateRoot(bc.db, header.Root); vaultRoot != (common.Hash{}) {
+						vaultTriedb.Commit(vaultRoot, true)
+					}
 					atomic.StoreUint64(&lastWrite, chosen)
 					bc.gcproc = 0
 				}
@@ -1475,6 +1527,14 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 				}
 				triedb.Dereference(root.(common.Hash))
 			}
+			for !bc.vaultTriegc.Empty() {
+				root, number := bc.vaultTriegc.Pop()
+				if uint64(-number) > chosen {
+					bc.vaultTriegc.Push(root, number)
+					break
+				}
+				vaultTriedb.Dereference(root.(common.Hash))
+			}
 		}
 	}
 
@@ -2408,9 +2468,59 @@ func (bc *BlockChain) SubscribeAutonityEvents(ch chan<- WhitelistEvent) event.Su
 	return bc.scope.Track(bc.autonityFeed.Subscribe(ch))
 }
 
-func (bc *BlockChain) UpdateEnodeWhitelist(newWhitelist *types.Nodes) {
-	rawdb.WriteEnodeWhitelist(bc.db, newWhitelist)
-	go bc.autonityFeed.Send(WhitelistEvent{Whitelist: newWhitelist.List})
+// UpdateEnodeWhitelist sets the enode whitelist at block number. Only the
+// entries added and removed since the previous update are stored and posted.
+func (bc *BlockChain) UpdateEnodeWhitelist(newWhitelist *types.Nodes, number uint64) {
+	bc.whitelistMu.Lock()
+	defer bc.whitelistMu.Unlock()
+
+	if bc.whitelist == nil {
+		bc.whitelist = rawdb.ReadWhitelist(bc.db)
+	}
+	diff := bc.whitelist.Diff(newWhitelist.StrList, number)
+	if diff.Empty() {
+		return
+	}
+	bc.whitelist.Apply(diff)
+	rawdb.WriteWhitelist(bc.db, bc.whitelist)
+
+	log.Info("Enode whitelist changed", "block", number, "added", len(diff.Added), "removed", len(diff.Removed), "size", len(bc.whitelist.Entries))
+	for _, url := range diff.Added {
+		log.Info("Enode whitelist audit", "block", number, "action", "add", "enode", url)
+	}
+	for _, url := range diff.Removed {
+		log.Info("Enode whitelist audit", "block", number, "action", "remove", "enode", url)
+	}
+	bc.postWhitelistEvent(WhitelistEvent{
+		Block:   number,
+		Added:   types.NewNodes(diff.Added, false),
+		Removed: types.NewNodes(diff.Removed, false),
+	})
+}
+
+// postWhitelistEvent posts ev after the whitelist changes before it, without
+// blocking the block import. The whitelist lock must be held.
+func (bc *BlockChain) postWhitelistEvent(ev WhitelistEvent) {
+	bc.whitelistEvents = append(bc.whitelistEvents, ev)
+	if bc.whitelistSending {
+		return
+	}
+	bc.whitelistSending = true
+	go func() {
+		for {
+			bc.whitelistMu.Lock()
+			if len(bc.whitelistEvents) == 0 {
+				bc.whitelistSending = false
+				bc.whitelistMu.Unlock()
+				return
+			}
+			ev := bc.whitelistEvents[0]
+			bc.whitelistEvents = bc.whitelistEvents[1:]
+			bc.whitelistMu.Unlock()
+
+			bc.autonityFeed.Send(ev)
+		}
+	}()
 }
 
 func (bc *BlockChain) ReadEnodeWhitelist(openNetwork bool) *types.Nodes {
@@ -2419,7 +2529,13 @@ func (bc *BlockChain) ReadEnodeWhitelist(openNetwork bool) *types.Nodes {
 
 func (bc *BlockChain) UpdateBlacklist(newBlacklist *types.Nodes) {
 	rawdb.WriteBlacklist(bc.db, newBlacklist)
-	go bc.autonityFeed.Send(WhitelistEvent{Whitelist: newBlacklist.List})
+
+	go bc.blacklistFeed.Send(BlacklistEvent{Blacklist: newBlacklist})
+}
+
+// SubscribeBlacklistEvents registers a subscription of BlacklistEvent.
+func (bc *BlockChain) SubscribeBlacklistEvents(ch chan<- BlacklistEvent) event.Subscription {
+	return bc.scope.Track(bc.blacklistFeed.Subscribe(ch))
 }
 
 func (bc *BlockChain) ReadBlacklist(TxPoolBlacklistFlag bool) *types.Nodes {
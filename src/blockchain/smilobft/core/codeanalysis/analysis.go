@@ -0,0 +1,196 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package codeanalysis implements a static security analyzer of EVM bytecode.
+//
+// The code is disassembled and split into a control flow graph, whose jumps are
+// resolved by an abstract interpretation of the stack tracking the constants and
+// the sources of the values. Detectors then look for insecure patterns in the
+// graph and report typed findings.
+package codeanalysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity is the severity of a finding.
+type Severity uint8
+
+const (
+	SeverityLow Severity = iota + 1
+	SeverityMedium
+	SeverityHigh
+)
+
+var severityNames = map[Severity]string{SeverityLow: "low", SeverityMedium: "medium", SeverityHigh: "high"}
+
+// String implements the stringer interface.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", uint8(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	if _, ok := severityNames[s]; !ok {
+		return nil, fmt.Errorf("invalid severity %d", uint8(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for severity, name := range severityNames {
+		if strings.EqualFold(string(text), name) {
+			*s = severity
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", text)
+}
+
+// SourceHint is the location in the source of an instruction, from the source
+// map of the compiler.
+type SourceHint struct {
+	Offset int `json:"offset"` // Byte offset in the source file
+	Length int `json:"length"` // Length of the source range
+	File   int `json:"file"`   // Index of the source file, -1 if generated
+}
+
+// Finding is an insecure pattern found in the code.
+type Finding struct {
+	Detector string      `json:"detector"`
+	Severity Severity    `json:"severity"`
+	PC       uint64      `json:"pc"`                // Offset of the offending instruction
+	Related  []uint64    `json:"related,omitempty"` // Offsets of related instructions
+	Message  string      `json:"message"`
+	Source   *SourceHint `json:"source,omitempty"`
+}
+
+// String returns the finding on one line.
+func (f Finding) String() string {
+	return fmt.Sprintf("%-6s %s at %05x: %s", strings.ToUpper(f.Severity.String()), f.Detector, f.PC, f.Message)
+}
+
+// Options configures an analysis.
+type Options struct {
+	// Detectors are the names of the detectors to run, all if empty.
+	Detectors []string
+
+	// SourceMap is the source map of the code, in the compressed format of the
+	// Solidity compiler, used to locate the findings in the source.
+	SourceMap string
+}
+
+// Report is the result of the analysis of some code.
+type Report struct {
+	Instructions []Instruction
+	CFG          *CFG
+	Findings     []Finding
+
+	// Incomplete is set if the code could not be fully analyzed, because of a
+	// truncated instruction or of a too complex control flow.
+	Incomplete bool
+}
+
+// Vulnerable returns whether a finding is at least of severity min.
+func (r *Report) Vulnerable(min Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity >= min {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze analyzes code. opts may be nil.
+func Analyze(code []byte, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	detectors := Detectors
+	if len(opts.Detectors) > 0 {
+		detectors = nil
+		for _, name := range opts.Detectors {
+			d := findDetector(name)
+			if d == nil {
+				return nil, fmt.Errorf("unknown detector %q", name)
+			}
+			detectors = append(detectors, *d)
+		}
+	}
+	instrs, err := Disassemble(code)
+	report := &Report{Instructions: instrs, CFG: newCFG(instrs), Incomplete: err != nil}
+	if !report.CFG.resolve() {
+		report.Incomplete = true
+	}
+	facts := collectFacts(report.CFG)
+	for _, d := range detectors {
+		report.Findings = append(report.Findings, d.run(report.CFG, facts)...)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		if report.Findings[i].Severity != report.Findings[j].Severity {
+			return report.Findings[i].Severity > report.Findings[j].Severity
+		}
+		return report.Findings[i].PC < report.Findings[j].PC
+	})
+	if opts.SourceMap != "" {
+		hints, err := parseSourceMap(opts.SourceMap)
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[uint64]int, len(instrs))
+		for i, in := range instrs {
+			index[in.PC] = i
+		}
+		for i := range report.Findings {
+			if n, ok := index[report.Findings[i].PC]; ok && n < len(hints) {
+				hint := hints[n]
+				report.Findings[i].Source = &hint
+			}
+		}
+	}
+	return report, nil
+}
+
+// parseSourceMap decodes a compressed Solidity source map, giving the source
+// location of every instruction. Empty fields repeat the previous entry.
+func parseSourceMap(srcmap string) ([]SourceHint, error) {
+	var (
+		hints []SourceHint
+		prev  = SourceHint{File: -1}
+	)
+	for i, entry := range strings.Split(srcmap, ";") {
+		fields := strings.Split(entry, ":")
+		for j, dst := range []*int{&prev.Offset, &prev.Length, &prev.File} {
+			if j >= len(fields) || fields[j] == "" {
+				continue
+			}
+			n, err := strconv.Atoi(fields[j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid source map entry %d: %q", i, entry)
+			}
+			*dst = n
+		}
+		hints = append(hints, prev)
+	}
+	return hints, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/core/asm"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+)
+
+func compile(t *testing.T, src string) []byte {
+	c := asm.NewCompiler(false)
+	c.Feed(asm.Lex([]byte(src), false))
+	bin, errs := c.Compile()
+	if len(errs) > 0 {
+		t.Fatalf("compile errors: %v", errs)
+	}
+	code, err := hex.DecodeString(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func detected(report *Report) map[string]Severity {
+	found := make(map[string]Severity)
+	for _, f := range report.Findings {
+		if f.Severity > found[f.Detector] {
+			found[f.Detector] = f.Severity
+		}
+	}
+	return found
+}
+
+var analysisTests = []struct {
+	name string
+	src  string
+	want map[string]Severity
+}{
+	{
+		name: "unprotected selfdestruct",
+		src: `
+			caller
+			selfdestruct
+		`,
+		want: map[string]Severity{DetectorSelfdestruct: SeverityHigh},
+	},
+	{
+		name: "selfdestruct behind a caller check",
+		src: `
+			push 0x1234
+			caller
+			eq
+			jumpi @owner
+			push 0
+			dup1
+			revert
+		owner:
+			caller
+			selfdestruct
+		`,
+		want: map[string]Severity{},
+	},
+	{
+		name: "tx.origin authorization",
+		src: `
+			push 0x1234
+			origin
+			eq
+			jumpi @owner
+			push 0
+			dup1
+			revert
+		owner:
+			stop
+		`,
+		want: map[string]Severity{DetectorTxOrigin: SeverityMedium},
+	},
+	{
+		name: "delegatecall to the call data",
+		src: `
+			push 0
+			push 0
+			push 0
+			push 0
+			push 0
+			calldataload
+			gas
+			delegatecall
+			push 0
+			mstore
+			stop
+		`,
+		want: map[string]Severity{DetectorDelegatecall: SeverityHigh},
+	},
+	{
+		name: "unchecked call",
+		src: `
+			push 0
+			push 0
+			push 0
+			push 0
+			push 0
+			push 0x1234
+			gas
+			call
+			pop
+			stop
+		`,
+		want: map[string]Severity{DetectorUncheckedCall: SeverityMedium},
+	},
+	{
+		name: "storage written after a call sending value",
+		src: `
+			push 0
+			push 0
+			push 0
+			push 0
+			push 1
+			caller
+			gas
+			call
+			jumpi @sent
+			push 0
+			dup1
+			revert
+		sent:
+			push 1
+			push 0
+			sstore
+			stop
+		`,
+		want: map[string]Severity{DetectorReentrancy: SeverityHigh},
+	},
+	{
+		name: "storage written after a call with the stipend",
+		src: `
+			push 0
+			push 0
+			push 0
+			push 0
+			push 1
+			caller
+			push 2300
+			call
+			jumpi @sent
+			push 0
+			dup1
+			revert
+		sent:
+			push 1
+			push 0
+			sstore
+			stop
+		`,
+		want: map[string]Severity{},
+	},
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, test := range analysisTests {
+		report, err := Analyze(compile(t, test.src), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := detected(report); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: findings mismatch:\ngot  %v\nwant %v", test.name, report.Findings, test.want)
+		}
+	}
+}
+
+func TestCFGResolvesReturns(t *testing.T) {
+	// An internal function call: the return address is pushed by the caller and
+	// jumped to by the function.
+	code := []byte{
+		byte(vm.PUSH1), 0x05, // return address
+		byte(vm.PUSH1), 0x07, // function
+		byte(vm.JUMP),
+		byte(vm.JUMPDEST), // 0x05
+		byte(vm.STOP),
+		byte(vm.JUMPDEST), // 0x07
+		byte(vm.JUMP),
+	}
+	report, err := Analyze(code, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := report.CFG.Block(0x07)
+	if fn == nil || !fn.Reached {
+		t.Fatal("function block not reached")
+	}
+	if fn.Unresolved || !reflect.DeepEqual(fn.Succs, []uint64{0x05}) {
+		t.Errorf("return not resolved: successors %v, unresolved %v", fn.Succs, fn.Unresolved)
+	}
+	if !report.CFG.Block(0x05).Reached {
+		t.Error("return block not reached")
+	}
+}
+
+func TestSourceMapHints(t *testing.T) {
+	code := compile(t, `
+		caller
+		selfdestruct
+	`)
+	report, err := Analyze(code, &Options{SourceMap: "10:20:0:-;:5;"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("want one finding, got %v", report.Findings)
+	}
+	want := &SourceHint{Offset: 10, Length: 5, File: 0}
+	if got := report.Findings[0].Source; !reflect.DeepEqual(got, want) {
+		t.Errorf("source hint mismatch: got %+v, want %+v", got, want)
+	}
+}
+
+func TestDeployedCode(t *testing.T) {
+	init := compile(t, `
+		push 2
+		dup1
+		push 0x0b
+		push 0
+		codecopy
+		push 0
+		return
+		caller
+		selfdestruct
+	`)
+	if got, want := DeployedCode(init), []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deployed code mismatch: got %x, want %x", got, want)
+	}
+	if got := DeployedCode([]byte{byte(vm.STOP)}); got != nil {
+		t.Errorf("deployed code without a copy: %x", got)
+	}
+}
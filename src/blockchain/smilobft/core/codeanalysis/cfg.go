@@ -0,0 +1,200 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import (
+	"sort"
+
+	"go-smilo/src/blockchain/smilobft/core/vm"
+)
+
+// maxVisits is the number of block visits after which the data flow analysis
+// gives up on reaching a fixpoint.
+const maxVisits = 100000
+
+// BasicBlock is a sequence of instructions entered at its first one and left at
+// its last one.
+type BasicBlock struct {
+	Start        uint64        // PC of the first instruction
+	End          uint64        // PC of the last instruction
+	Instructions []Instruction // Instructions of the block
+	Succs        []uint64      // Start of the successors
+	Reached      bool          // Whether the block is reachable from the entry
+	Unresolved   bool          // Whether the block ends with a jump to an unknown target
+}
+
+// last returns the last instruction of the block.
+func (b *BasicBlock) last() Instruction {
+	return b.Instructions[len(b.Instructions)-1]
+}
+
+// fallsThrough returns whether the execution may continue with the next block.
+func (b *BasicBlock) fallsThrough() bool {
+	last := b.last()
+	info := vm.InstructionInfo(last.Op)
+	return info.Valid && !info.Halts && !info.Reverts && last.Op != vm.JUMP
+}
+
+// CFG is the control flow graph of some code. Jump targets are resolved by the
+// data flow analysis, following the constant values through the stack, so the
+// internal function returns of the compilers are resolved too.
+type CFG struct {
+	Blocks []*BasicBlock // Blocks, by start
+
+	blocks map[uint64]*BasicBlock
+	states map[uint64]stack // Stack at the entry of the reached blocks
+}
+
+// newCFG splits instrs into basic blocks. Blocks start at the jump destinations
+// and after the jumps and the halting instructions.
+func newCFG(instrs []Instruction) *CFG {
+	cfg := &CFG{blocks: make(map[uint64]*BasicBlock), states: make(map[uint64]stack)}
+	var current *BasicBlock
+	for _, in := range instrs {
+		if current == nil || in.Op == vm.JUMPDEST {
+			current = &BasicBlock{Start: in.PC}
+			cfg.Blocks = append(cfg.Blocks, current)
+			cfg.blocks[in.PC] = current
+		}
+		current.Instructions = append(current.Instructions, in)
+		current.End = in.PC
+		if info := vm.InstructionInfo(in.Op); !info.Valid || info.Jumps || info.Halts || info.Reverts {
+			current = nil
+		}
+	}
+	return cfg
+}
+
+// Block returns the block starting at pc, or nil.
+func (cfg *CFG) Block(pc uint64) *BasicBlock {
+	return cfg.blocks[pc]
+}
+
+// next returns the block following b, or nil.
+func (cfg *CFG) next(b *BasicBlock) *BasicBlock {
+	i := sort.Search(len(cfg.Blocks), func(i int) bool { return cfg.Blocks[i].Start > b.Start })
+	if i < len(cfg.Blocks) {
+		return cfg.Blocks[i]
+	}
+	return nil
+}
+
+// isJumpdest returns whether pc is the start of a block at a JUMPDEST.
+func (cfg *CFG) isJumpdest(pc uint64) bool {
+	b := cfg.blocks[pc]
+	return b != nil && b.Instructions[0].Op == vm.JUMPDEST
+}
+
+// resolve runs the data flow analysis from the entry, recording the reached
+// blocks, their entry stacks and the edges. It returns false if it gave up
+// before reaching a fixpoint.
+func (cfg *CFG) resolve() bool {
+	if len(cfg.Blocks) == 0 {
+		return true
+	}
+	succs := make(map[uint64]map[uint64]bool)
+	entry := cfg.Blocks[0].Start
+	cfg.states[entry] = stack{}
+	worklist := []uint64{entry}
+	queued := map[uint64]bool{entry: true}
+
+	for visits := 0; len(worklist) > 0; visits++ {
+		if visits == maxVisits {
+			return false
+		}
+		start := worklist[0]
+		worklist = worklist[1:]
+		queued[start] = false
+
+		b := cfg.blocks[start]
+		b.Reached = true
+		out, target := interpret(b, cfg.states[start].copy(), nil)
+
+		var next []uint64
+		if b.fallsThrough() {
+			if n := cfg.next(b); n != nil {
+				next = append(next, n.Start)
+			}
+		}
+		if op := b.last().Op; op == vm.JUMP || op == vm.JUMPI {
+			if target.consts == nil {
+				b.Unresolved = true
+			}
+			for _, dest := range target.consts {
+				if cfg.isJumpdest(dest) {
+					next = append(next, dest)
+				}
+			}
+		}
+		if succs[start] == nil {
+			succs[start] = make(map[uint64]bool)
+		}
+		for _, dest := range next {
+			succs[start][dest] = true
+			state, ok := cfg.states[dest]
+			if ok {
+				merged, changed := state.merge(out)
+				if !changed {
+					continue
+				}
+				cfg.states[dest] = merged
+			} else {
+				cfg.states[dest] = out.copy()
+			}
+			if !queued[dest] {
+				queued[dest] = true
+				worklist = append(worklist, dest)
+			}
+		}
+	}
+	for start, dests := range succs {
+		b := cfg.blocks[start]
+		for dest := range dests {
+			b.Succs = append(b.Succs, dest)
+		}
+		sort.Slice(b.Succs, func(i, j int) bool { return b.Succs[i] < b.Succs[j] })
+	}
+	return true
+}
+
+// reachable returns the blocks reachable from the given ones, including them,
+// without leaving the blocks for which stop returns true.
+func (cfg *CFG) reachable(from []uint64, stop func(*BasicBlock) bool) []*BasicBlock {
+	var (
+		seen   = make(map[uint64]bool)
+		queue  = append([]uint64{}, from...)
+		blocks []*BasicBlock
+	)
+	for len(queue) > 0 {
+		start := queue[0]
+		queue = queue[1:]
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		b := cfg.blocks[start]
+		if b == nil {
+			continue
+		}
+		blocks = append(blocks, b)
+		if stop != nil && stop(b) {
+			continue
+		}
+		queue = append(queue, b.Succs...)
+	}
+	return blocks
+}
@@ -0,0 +1,219 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// maxConsts is the number of possible constants of a stack item above which it
+// is considered unknown.
+const maxConsts = 16
+
+// taint is the set of the sources a stack item is computed from.
+type taint uint8
+
+const (
+	taintCaller     taint = 1 << iota // msg.sender
+	taintOrigin                       // tx.origin
+	taintInput                        // Call data
+	taintCallResult                   // Success of an external call
+	taintStorage                      // Contract storage
+)
+
+// value is the abstract value of a stack item.
+type value struct {
+	taint  taint
+	consts []uint64 // Possible values if constant, sorted, nil if unknown
+	calls  []uint64 // PC of the calls whose success flows in the value, sorted
+}
+
+// constValue returns the value of a push argument.
+func constValue(arg []byte) value {
+	if len(arg) > 8 {
+		return value{}
+	}
+	var buf [8]byte
+	copy(buf[8-len(arg):], arg)
+	return value{consts: []uint64{binary.BigEndian.Uint64(buf[:])}}
+}
+
+// merge returns the union of v and o.
+func (v value) merge(o value) value {
+	merged := value{taint: v.taint | o.taint, calls: unionSorted(v.calls, o.calls)}
+	if v.consts != nil && o.consts != nil {
+		if merged.consts = unionSorted(v.consts, o.consts); len(merged.consts) > maxConsts {
+			merged.consts = nil
+		}
+	}
+	return merged
+}
+
+// equal returns whether v and o are the same abstract value.
+func (v value) equal(o value) bool {
+	return v.taint == o.taint && (v.consts == nil) == (o.consts == nil) &&
+		equalSorted(v.consts, o.consts) && equalSorted(v.calls, o.calls)
+}
+
+// stack is the abstract stack, top last. Items below the known ones are
+// unknown.
+type stack []value
+
+func (s stack) copy() stack {
+	return append(stack{}, s...)
+}
+
+// merge returns the union of s and o, keeping the items both know, and whether
+// it differs from s.
+func (s stack) merge(o stack) (stack, bool) {
+	n := len(s)
+	if len(o) < n {
+		n = len(o)
+	}
+	merged := make(stack, n)
+	changed := n != len(s)
+	for i := 0; i < n; i++ {
+		a, b := s[len(s)-n+i], o[len(o)-n+i]
+		merged[i] = a.merge(b)
+		changed = changed || !merged[i].equal(a)
+	}
+	return merged, changed
+}
+
+// pop removes the top n items, returning them top first.
+func (s *stack) pop(n int) []value {
+	items := make([]value, n)
+	for i := 0; i < n; i++ {
+		if len(*s) > 0 {
+			items[i] = (*s)[len(*s)-1]
+			*s = (*s)[:len(*s)-1]
+		}
+	}
+	return items
+}
+
+// push adds v on top, dropping the bottom item at the stack limit.
+func (s *stack) push(v value) {
+	if len(*s) >= int(params.StackLimit) {
+		*s = (*s)[1:]
+	}
+	*s = append(*s, v)
+}
+
+// peek returns the n-th item from the top, starting at 1.
+func (s stack) peek(n int) value {
+	if n > len(s) {
+		return value{}
+	}
+	return s[len(s)-n]
+}
+
+// interpret runs the instructions of b on st, calling visit if set with every
+// instruction and its operands, top first. It returns the stack at the end of
+// the block and the target of its final jump, if any.
+func interpret(b *BasicBlock, st stack, visit func(in Instruction, operands []value)) (stack, value) {
+	var target value
+	for _, in := range b.Instructions {
+		switch {
+		case in.Op.IsPush():
+			st.push(constValue(in.Arg))
+			continue
+		case in.Op >= vm.DUP1 && in.Op <= vm.DUP16:
+			st.push(st.peek(int(in.Op-vm.DUP1) + 1))
+			continue
+		case in.Op >= vm.SWAP1 && in.Op <= vm.SWAP16:
+			n := int(in.Op-vm.SWAP1) + 1
+			for len(st) <= n {
+				st = append(stack{{}}, st...)
+			}
+			top := len(st) - 1
+			st[top], st[top-n] = st[top-n], st[top]
+			continue
+		}
+		info := vm.InstructionInfo(in.Op)
+		operands := st.pop(info.Pops)
+		if visit != nil {
+			visit(in, operands)
+		}
+		if in.Op == vm.JUMP || in.Op == vm.JUMPI {
+			target = operands[0]
+		}
+		if info.Pushes == 0 {
+			continue
+		}
+		var result value
+		switch in.Op {
+		case vm.CALLER:
+			result.taint = taintCaller
+		case vm.ORIGIN:
+			result.taint = taintOrigin
+		case vm.CALLDATALOAD, vm.CALLDATASIZE:
+			result.taint = taintInput
+		case vm.SLOAD:
+			result.taint = taintStorage
+		case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+			result = value{taint: taintCallResult, calls: []uint64{in.PC}}
+		case vm.MLOAD:
+			// Memory is not tracked
+		default:
+			for _, operand := range operands {
+				result.taint |= operand.taint
+				result.calls = unionSorted(result.calls, operand.calls)
+			}
+		}
+		st.push(result)
+	}
+	return st, target
+}
+
+func unionSorted(a, b []uint64) []uint64 {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	set := make(map[uint64]bool, len(a)+len(b))
+	for _, x := range a {
+		set[x] = true
+	}
+	for _, x := range b {
+		set[x] = true
+	}
+	union := make([]uint64, 0, len(set))
+	for x := range set {
+		union = append(union, x)
+	}
+	sort.Slice(union, func(i, j int) bool { return union[i] < union[j] })
+	return union
+}
+
+func equalSorted(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
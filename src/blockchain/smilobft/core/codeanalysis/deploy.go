@@ -0,0 +1,50 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import "go-smilo/src/blockchain/smilobft/core/vm"
+
+// DeployedCode returns the code deployed by the init code of a contract
+// creation, found as the largest constant range of the init code copied to
+// memory by a CODECOPY, which is how compilers embed the runtime code. It
+// returns nil if there is no such copy.
+func DeployedCode(init []byte) []byte {
+	instrs, _ := Disassemble(init)
+	cfg := newCFG(instrs)
+	cfg.resolve()
+
+	var deployed []byte
+	for _, b := range cfg.Blocks {
+		if !b.Reached {
+			continue
+		}
+		interpret(b, cfg.states[b.Start].copy(), func(in Instruction, operands []value) {
+			if in.Op != vm.CODECOPY {
+				return
+			}
+			offset, size := operands[1], operands[2]
+			if len(offset.consts) != 1 || len(size.consts) != 1 {
+				return
+			}
+			start, end := offset.consts[0], offset.consts[0]+size.consts[0]
+			if start < end && end <= uint64(len(init)) && end-start > uint64(len(deployed)) {
+				deployed = init[start:end]
+			}
+		})
+	}
+	return deployed
+}
@@ -0,0 +1,267 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import (
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// Names of the detectors.
+const (
+	DetectorSelfdestruct  = "unprotected-selfdestruct"
+	DetectorDelegatecall  = "delegatecall-input"
+	DetectorTxOrigin      = "tx-origin-auth"
+	DetectorUncheckedCall = "unchecked-call"
+	DetectorReentrancy    = "reentrancy"
+)
+
+// accessControls are the sources of the values checked to restrict the callers.
+const accessControls = taintCaller | taintOrigin
+
+// Detector looks for an insecure pattern in the code.
+type Detector struct {
+	Name        string
+	Description string
+
+	run func(cfg *CFG, facts *facts) []Finding
+}
+
+// Detectors are the available detectors.
+var Detectors = []Detector{
+	{
+		Name:        DetectorSelfdestruct,
+		Description: "SELFDESTRUCT reachable without checking the caller",
+		run:         detectSelfdestruct,
+	},
+	{
+		Name:        DetectorDelegatecall,
+		Description: "DELEGATECALL or CALLCODE to an address from the call data",
+		run:         detectDelegatecall,
+	},
+	{
+		Name:        DetectorTxOrigin,
+		Description: "Authorization with tx.origin",
+		run:         detectTxOrigin,
+	},
+	{
+		Name:        DetectorUncheckedCall,
+		Description: "External call whose success is never checked",
+		run:         detectUncheckedCall,
+	},
+	{
+		Name:        DetectorReentrancy,
+		Description: "Storage written after an external call forwarding gas",
+		run:         detectReentrancy,
+	},
+}
+
+func findDetector(name string) *Detector {
+	for i := range Detectors {
+		if Detectors[i].Name == name {
+			return &Detectors[i]
+		}
+	}
+	return nil
+}
+
+// instrFact is an instruction of a reached block with its operands.
+type instrFact struct {
+	in       Instruction
+	block    *BasicBlock
+	index    int     // Index of the instruction in the block
+	operands []value // Operands, top first
+}
+
+// facts are the instructions of interest of the reached blocks.
+type facts struct {
+	calls         []instrFact
+	jumpis        []instrFact
+	selfdestructs []instrFact
+	sstores       map[uint64][]int // Indexes of the SSTOREs by block
+	used          map[uint64]bool  // Calls whose success is checked or stored
+}
+
+// collectFacts interprets the reached blocks from their entry stacks.
+func collectFacts(cfg *CFG) *facts {
+	f := &facts{sstores: make(map[uint64][]int), used: make(map[uint64]bool)}
+	for _, b := range cfg.Blocks {
+		if !b.Reached {
+			continue
+		}
+		index := 0
+		interpret(b, cfg.states[b.Start].copy(), func(in Instruction, operands []value) {
+			for index < len(b.Instructions) && b.Instructions[index].PC != in.PC {
+				index++
+			}
+			fact := instrFact{in: in, block: b, index: index, operands: operands}
+			switch in.Op {
+			case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+				f.calls = append(f.calls, fact)
+			case vm.JUMPI:
+				f.jumpis = append(f.jumpis, fact)
+				f.use(operands[1])
+			case vm.SELFDESTRUCT:
+				f.selfdestructs = append(f.selfdestructs, fact)
+			case vm.SSTORE:
+				f.sstores[b.Start] = append(f.sstores[b.Start], index)
+				f.use(operands[1])
+			case vm.MSTORE, vm.MSTORE8:
+				f.use(operands[1])
+			}
+		})
+	}
+	return f
+}
+
+// use marks the calls whose success flows in v as checked.
+func (f *facts) use(v value) {
+	for _, pc := range v.calls {
+		f.used[pc] = true
+	}
+}
+
+// guards returns whether b ends with a branch on the caller.
+func (f *facts) guards(b *BasicBlock) bool {
+	for _, j := range f.jumpis {
+		if j.block == b && j.operands[1].taint&accessControls != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func detectSelfdestruct(cfg *CFG, f *facts) []Finding {
+	if len(f.selfdestructs) == 0 || len(cfg.Blocks) == 0 {
+		return nil
+	}
+	unguarded := make(map[*BasicBlock]bool)
+	for _, b := range cfg.reachable([]uint64{cfg.Blocks[0].Start}, f.guards) {
+		unguarded[b] = true
+	}
+	var findings []Finding
+	for _, sd := range f.selfdestructs {
+		if unguarded[sd.block] {
+			findings = append(findings, Finding{
+				Detector: DetectorSelfdestruct,
+				Severity: SeverityHigh,
+				PC:       sd.in.PC,
+				Message:  "anyone can destroy the contract: no check of the caller on the path to SELFDESTRUCT",
+			})
+		}
+	}
+	return findings
+}
+
+func detectDelegatecall(cfg *CFG, f *facts) []Finding {
+	var findings []Finding
+	for _, call := range f.calls {
+		if call.in.Op != vm.DELEGATECALL && call.in.Op != vm.CALLCODE {
+			continue
+		}
+		if call.operands[1].taint&taintInput != 0 {
+			findings = append(findings, Finding{
+				Detector: DetectorDelegatecall,
+				Severity: SeverityHigh,
+				PC:       call.in.PC,
+				Message:  call.in.Op.String() + " to an address given by the caller, who can run any code in the contract context",
+			})
+		}
+	}
+	return findings
+}
+
+func detectTxOrigin(cfg *CFG, f *facts) []Finding {
+	var findings []Finding
+	for _, j := range f.jumpis {
+		cond := j.operands[1].taint
+		if cond&taintOrigin == 0 {
+			continue
+		}
+		finding := Finding{
+			Detector: DetectorTxOrigin,
+			Severity: SeverityMedium,
+			PC:       j.in.PC,
+			Message:  "branch on tx.origin: a contract called by the account can pass the check",
+		}
+		if cond&taintCaller != 0 {
+			finding.Severity = SeverityLow
+			finding.Message = "branch on tx.origin and msg.sender: contracts are denied, which breaks composability"
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func detectUncheckedCall(cfg *CFG, f *facts) []Finding {
+	var findings []Finding
+	for _, call := range f.calls {
+		if !f.used[call.in.PC] {
+			findings = append(findings, Finding{
+				Detector: DetectorUncheckedCall,
+				Severity: SeverityMedium,
+				PC:       call.in.PC,
+				Message:  "the success of the " + call.in.Op.String() + " is never checked, a failure goes unnoticed",
+			})
+		}
+	}
+	return findings
+}
+
+func detectReentrancy(cfg *CFG, f *facts) []Finding {
+	var findings []Finding
+	for _, call := range f.calls {
+		if call.in.Op != vm.CALL && call.in.Op != vm.CALLCODE {
+			continue
+		}
+		// Calls limited to the stipend can't reenter.
+		if gas := call.operands[0]; gas.consts != nil && maxConst(gas) <= params.CallStipend {
+			continue
+		}
+		var related []uint64
+		for _, idx := range f.sstores[call.block.Start] {
+			if idx > call.index {
+				related = append(related, call.block.Instructions[idx].PC)
+			}
+		}
+		for _, b := range cfg.reachable(call.block.Succs, nil) {
+			for _, idx := range f.sstores[b.Start] {
+				related = append(related, b.Instructions[idx].PC)
+			}
+		}
+		if len(related) == 0 {
+			continue
+		}
+		finding := Finding{
+			Detector: DetectorReentrancy,
+			Severity: SeverityMedium,
+			PC:       call.in.PC,
+			Related:  unionSorted(related[:1], related[1:]),
+			Message:  "storage written after an external call, which can reenter the contract before the update",
+		}
+		if v := call.operands[2]; v.consts == nil || maxConst(v) > 0 {
+			finding.Severity = SeverityHigh
+			finding.Message = "storage written after an external call sending value, which can reenter the contract before the update"
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func maxConst(v value) uint64 {
+	return v.consts[len(v.consts)-1]
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package codeanalysis
+
+import (
+	"fmt"
+
+	"go-smilo/src/blockchain/smilobft/core/asm"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+)
+
+// Instruction is a disassembled EVM instruction.
+type Instruction struct {
+	PC  uint64
+	Op  vm.OpCode
+	Arg []byte // Immediate argument of the push instructions
+}
+
+// String returns the instruction in the format of the disassembler.
+func (in Instruction) String() string {
+	if len(in.Arg) > 0 {
+		return fmt.Sprintf("%05x: %v 0x%x", in.PC, in.Op, in.Arg)
+	}
+	return fmt.Sprintf("%05x: %v", in.PC, in.Op)
+}
+
+// Disassemble returns the instructions of code. If the code ends with a
+// truncated push, as the metadata appended by the compilers may, the preceding
+// instructions are returned with the error.
+func Disassemble(code []byte) ([]Instruction, error) {
+	var instrs []Instruction
+	it := asm.NewInstructionIterator(code)
+	for it.Next() {
+		instrs = append(instrs, Instruction{PC: it.PC(), Op: it.Op(), Arg: it.Arg()})
+	}
+	return instrs, it.Error()
+}
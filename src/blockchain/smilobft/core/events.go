@@ -19,8 +19,6 @@ package core
 import (
 	"github.com/ethereum/go-ethereum/common"
 
-	"go-smilo/src/blockchain/smilobft/p2p/enode"
-
 	"go-smilo/src/blockchain/smilobft/core/types"
 )
 
@@ -53,5 +51,16 @@ type ChainSideEvent struct {
 
 type ChainHeadEvent struct{ Block *types.Block }
 
-// WhitelistEvent is posted when the list of authorized enodes is updated.
-type WhitelistEvent struct{ Whitelist []*enode.Node }
+// WhitelistEvent is posted when the list of authorized enodes changes, with
+// the enodes added and removed at Block. Enode URLs carry their policy
+// parameters.
+type WhitelistEvent struct {
+	Block   uint64
+	Added   *types.Nodes // Enodes added or changed
+	Removed *types.Nodes // Enodes removed
+}
+
+// BlacklistEvent is posted when the list of blacklisted enodes is updated.
+type BlacklistEvent struct {
+	Blacklist *types.Nodes
+}
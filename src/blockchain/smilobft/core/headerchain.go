@@ -224,6 +224,17 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int)
 		}
 	}
 
+	// A negative frequency skips the engine for headers verified otherwise, such
+	// as from BFT checkpoint to BFT checkpoint
+	if checkFreq < 0 {
+		for i, header := range chain {
+			if BadHashes[header.Hash()] {
+				return i, ErrBlacklistedHash
+			}
+		}
+		return 0, nil
+	}
+
 	// Generate the list of seal verification requests, and start the parallel verifier
 	seals := make([]bool, len(chain))
 	if checkFreq != 0 {
@@ -59,9 +59,26 @@ func WriteCanonicalHash(db ethdb.KeyValueWriter, hash common.Hash, number uint64
 	}
 }
 
+// storedWhitelist is the RLP encoding of the versioned whitelist.
+type storedWhitelist struct {
+	Block   uint64
+	Entries []types.WhitelistEntry
+}
+
 // WriteEnodeWhitelist stores the list of permitted enodes
 func WriteEnodeWhitelist(db ethdb.KeyValueWriter, whitelist *types.Nodes) {
-	bytes, err := rlp.EncodeToBytes(whitelist.StrList)
+	WriteWhitelist(db, types.NewWhitelist(whitelist.StrList, 0))
+}
+
+// WriteWhitelist stores the versioned list of permitted enodes, with the block
+// at which each entry changed.
+func WriteWhitelist(db ethdb.KeyValueWriter, whitelist *types.Whitelist) {
+	stored := storedWhitelist{Block: whitelist.Block}
+	for _, url := range whitelist.URLs() {
+		id, _ := types.WhitelistID(url)
+		stored.Entries = append(stored.Entries, whitelist.Entries[id])
+	}
+	bytes, err := rlp.EncodeToBytes(&stored)
 	if err != nil {
 		log.Crit("Failed to RLP encode enode whitelist", "err", err)
 	}
@@ -70,23 +87,40 @@ func WriteEnodeWhitelist(db ethdb.KeyValueWriter, whitelist *types.Nodes) {
 	}
 }
 
-// ReadEnodeWhitelist retrieve the list of permitted enodes
-func ReadEnodeWhitelist(db ethdb.KeyValueReader, EnableNodePermissionFlag bool) *types.Nodes {
-	var strList []string
-	nodes := &types.Nodes{List: make([]*enode.Node, 0)}
-
+// ReadWhitelist retrieves the versioned list of permitted enodes. Lists stored
+// before the versioning are read as changed at the genesis block.
+func ReadWhitelist(db ethdb.KeyValueReader) *types.Whitelist {
 	data, _ := db.Get(enodeWhiteList)
 	if len(data) == 0 {
-		return nodes
+		return types.NewWhitelist(nil, 0)
+	}
+	var stored storedWhitelist
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		var strList []string
+		if err := rlp.DecodeBytes(data, &strList); err != nil {
+			log.Error("Invalid Enode whitelist", "err", err)
+			return types.NewWhitelist(nil, 0)
+		}
+		return types.NewWhitelist(strList, 0)
 	}
-	if err := rlp.Decode(bytes.NewReader(data), &strList); err != nil {
-		log.Error("Invalid Enode whitelist", "err", err)
-		return nodes
+	whitelist := types.NewWhitelist(nil, stored.Block)
+	for _, entry := range stored.Entries {
+		if id, err := types.WhitelistID(entry.URL); err == nil {
+			whitelist.Entries[id] = entry
+		}
 	}
-	log.Warn("ReadEnodeWhitelist, strList, ", "strList", strList)
+	return whitelist
+}
 
-	nodes = types.NewNodes(strList, EnableNodePermissionFlag)
-	return nodes
+// ReadEnodeWhitelist retrieve the list of permitted enodes
+func ReadEnodeWhitelist(db ethdb.KeyValueReader, EnableNodePermissionFlag bool) *types.Nodes {
+	strList := ReadWhitelist(db).URLs()
+	if len(strList) == 0 {
+		return &types.Nodes{List: make([]*enode.Node, 0)}
+	}
+	log.Debug("ReadEnodeWhitelist, strList, ", "strList", strList)
+
+	return types.NewNodes(strList, EnableNodePermissionFlag)
 }
 
 // WriteBlacklist stores the list of permitted enodes
@@ -95,7 +129,7 @@ func WriteBlacklist(db ethdb.KeyValueWriter, blacklist *types.Nodes) {
 	if err != nil {
 		log.Crit("Failed to RLP encode addresses blacklist", "err", err)
 	}
-	if err := db.Put(enodeWhiteList, bytes); err != nil {
+	if err := db.Put(blackList, bytes); err != nil {
 		log.Crit("Failed to store last header's hash", "err", err)
 	}
 }
@@ -31,11 +31,15 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 )
 
 // Tests block header storage and retrieval operations.
-func TestHeaderStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestHeaderStorage(t *testing.T) { dbtest.ForEach(t, testHeaderStorage) }
+
+func testHeaderStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a test header to move around the database and make sure it's really new
 	header := &types.Header{Number: big.NewInt(42), Extra: []byte("test header")}
@@ -67,8 +71,10 @@ func TestHeaderStorage(t *testing.T) {
 }
 
 // Tests block body storage and retrieval operations.
-func TestBodyStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestBodyStorage(t *testing.T) { dbtest.ForEach(t, testBodyStorage) }
+
+func testBodyStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a test body to move around the database and make sure it's really new
 	body := &types.Body{Uncles: []*types.Header{{Extra: []byte("test header")}}}
@@ -105,8 +111,10 @@ func TestBodyStorage(t *testing.T) {
 }
 
 // Tests block storage and retrieval operations.
-func TestBlockStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestBlockStorage(t *testing.T) { dbtest.ForEach(t, testBlockStorage) }
+
+func testBlockStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a test block to move around the database and make sure it's really new
 	block := types.NewBlockWithHeader(&types.Header{
@@ -155,8 +163,10 @@ func TestBlockStorage(t *testing.T) {
 }
 
 // Tests that partial block contents don't get reassembled into full blocks.
-func TestPartialBlockStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestPartialBlockStorage(t *testing.T) { dbtest.ForEach(t, testPartialBlockStorage) }
+
+func testPartialBlockStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 	block := types.NewBlockWithHeader(&types.Header{
 		Extra:       []byte("test block"),
 		UncleHash:   types.EmptyUncleHash,
@@ -189,8 +199,10 @@ func TestPartialBlockStorage(t *testing.T) {
 }
 
 // Tests block total difficulty storage and retrieval operations.
-func TestTdStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestTdStorage(t *testing.T) { dbtest.ForEach(t, testTdStorage) }
+
+func testTdStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a test TD to move around the database and make sure it's really new
 	hash, td := common.Hash{}, big.NewInt(314)
@@ -212,8 +224,10 @@ func TestTdStorage(t *testing.T) {
 }
 
 // Tests that canonical numbers can be mapped to hashes and retrieved.
-func TestCanonicalMappingStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestCanonicalMappingStorage(t *testing.T) { dbtest.ForEach(t, testCanonicalMappingStorage) }
+
+func testCanonicalMappingStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a test canonical number and assinged hash to move around
 	hash, number := common.Hash{0: 0xff}, uint64(314)
@@ -235,8 +249,10 @@ func TestCanonicalMappingStorage(t *testing.T) {
 }
 
 // Tests that head headers and head blocks can be assigned, individually.
-func TestHeadStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestHeadStorage(t *testing.T) { dbtest.ForEach(t, testHeadStorage) }
+
+func testHeadStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	blockHead := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block header")})
 	blockFull := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block full")})
@@ -270,8 +286,10 @@ func TestHeadStorage(t *testing.T) {
 }
 
 // Tests that receipts associated with a single block can be stored and retrieved.
-func TestBlockReceiptStorage(t *testing.T) {
-	db := NewMemoryDatabase()
+func TestBlockReceiptStorage(t *testing.T) { dbtest.ForEach(t, testBlockReceiptStorage) }
+
+func testBlockReceiptStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 
 	// Create a live block since we need metadata to reconstruct the receipt
 	tx1 := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
@@ -22,13 +22,16 @@ import (
 
 	"go-smilo/src/blockchain/smilobft/core/types"
 	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // Tests that positional lookup metadata can be stored and retrieved.
-func TestLookupStorage(t *testing.T) {
+func TestLookupStorage(t *testing.T) { dbtest.ForEach(t, testLookupStorage) }
+
+func testLookupStorage(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	tests := []struct {
 		name                 string
 		writeTxLookupEntries func(ethdb.Writer, *types.Block)
@@ -65,7 +68,7 @@ func TestLookupStorage(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			db := NewMemoryDatabase()
+			db := NewDatabase(newDB())
 
 			tx1 := types.NewTransaction(1, common.BytesToAddress([]byte{0x11}), big.NewInt(111), 1111, big.NewInt(11111), []byte{0x11, 0x11, 0x11})
 			tx2 := types.NewTransaction(2, common.BytesToAddress([]byte{0x22}), big.NewInt(222), 2222, big.NewInt(22222), []byte{0x22, 0x22, 0x22})
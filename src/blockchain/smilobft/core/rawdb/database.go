@@ -21,9 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/boltdb"
 	"go-smilo/src/blockchain/smilobft/ethdb/leveldb"
 	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
 
@@ -220,6 +222,100 @@ func NewLevelDBDatabaseWithFreezer(file string, cache int, handles int, freezer
 	return frdb, nil
 }
 
+// Storage engines of the persistent key-value databases.
+const (
+	LevelDBEngine = "leveldb"
+	BoltDBEngine  = "boltdb"
+)
+
+// NewBoltDBDatabase creates a persistent key-value database based on BoltDB
+// without a freezer moving immutable chain segments into cold storage.
+func NewBoltDBDatabase(file string, namespace string) (ethdb.Database, error) {
+	db, err := boltdb.New(file, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return NewDatabase(db), nil
+}
+
+// NewBoltDBDatabaseWithFreezer creates a persistent key-value database based
+// on BoltDB with a freezer moving immutable chain segments into cold storage.
+func NewBoltDBDatabaseWithFreezer(file string, freezer string, namespace string) (ethdb.Database, error) {
+	kvdb, err := boltdb.New(file, namespace)
+	if err != nil {
+		return nil, err
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, freezer, namespace)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// NewDiskDatabase creates a persistent key-value database with the given
+// storage engine, without a freezer moving immutable chain segments into cold
+// storage. An empty engine selects the one of the existing database in the
+// directory, LevelDB for a new one.
+func NewDiskDatabase(engine string, file string, cache int, handles int, namespace string) (ethdb.Database, error) {
+	engine, err := resolveDatabaseEngine(engine, file)
+	if err != nil {
+		return nil, err
+	}
+	if engine == BoltDBEngine {
+		return NewBoltDBDatabase(file, namespace)
+	}
+	return NewLevelDBDatabase(file, cache, handles, namespace)
+}
+
+// NewDiskDatabaseWithFreezer creates a persistent key-value database with the
+// given storage engine and a freezer moving immutable chain segments into cold
+// storage. An empty engine selects the one of the existing database in the
+// directory, LevelDB for a new one.
+func NewDiskDatabaseWithFreezer(engine string, file string, cache int, handles int, freezer string, namespace string) (ethdb.Database, error) {
+	engine, err := resolveDatabaseEngine(engine, file)
+	if err != nil {
+		return nil, err
+	}
+	if engine == BoltDBEngine {
+		return NewBoltDBDatabaseWithFreezer(file, freezer, namespace)
+	}
+	return NewLevelDBDatabaseWithFreezer(file, cache, handles, freezer, namespace)
+}
+
+// PreexistingDatabaseEngine returns the storage engine of the database in the
+// directory, or an empty string if there is none.
+func PreexistingDatabaseEngine(file string) string {
+	if _, err := os.Stat(filepath.Join(file, "CURRENT")); err == nil {
+		return LevelDBEngine
+	}
+	if _, err := os.Stat(filepath.Join(file, boltdb.FileName)); err == nil {
+		return BoltDBEngine
+	}
+	return ""
+}
+
+// resolveDatabaseEngine checks the requested storage engine against the one of
+// the existing database, as opening it with another engine would create a
+// second, empty database next to it.
+func resolveDatabaseEngine(engine string, file string) (string, error) {
+	switch engine {
+	case "", LevelDBEngine, BoltDBEngine:
+	default:
+		return "", fmt.Errorf("unknown database engine %q", engine)
+	}
+	existing := PreexistingDatabaseEngine(file)
+	switch {
+	case existing == "" && engine == "":
+		return LevelDBEngine, nil
+	case existing == "":
+		return engine, nil
+	case engine != "" && engine != existing:
+		return "", fmt.Errorf("database %s uses the %s engine, not %s (see geth db migrate)", file, existing, engine)
+	}
+	return existing, nil
+}
+
 // InspectDatabase traverses the entire database and checks the size
 // of all different categories of data.
 func InspectDatabase(db ethdb.Database) error {
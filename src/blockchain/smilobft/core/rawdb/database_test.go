@@ -0,0 +1,68 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that disk databases are opened with the engine of the existing
+// database, and refused with another one.
+func TestDiskDatabaseEngine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rawdb-engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		engines  []string // Engines the database is opened with in turn
+		existing string   // Engine of the database after the first open
+		fails    int      // Index of the open failing, -1 if none
+	}{
+		{"default", []string{"", LevelDBEngine, "", BoltDBEngine}, LevelDBEngine, 3},
+		{"leveldb", []string{LevelDBEngine, ""}, LevelDBEngine, -1},
+		{"boltdb", []string{BoltDBEngine, "", BoltDBEngine, LevelDBEngine}, BoltDBEngine, 3},
+		{"unknown", []string{"rocksdb"}, "", 0},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(dir, tt.name)
+		for i, engine := range tt.engines {
+			db, err := NewDiskDatabaseWithFreezer(engine, file, 16, 16, filepath.Join(file, "ancient"), "")
+			if i == tt.fails {
+				if err == nil {
+					db.Close()
+					t.Errorf("%s: open %d with engine %q succeeded", tt.name, i, engine)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: open %d with engine %q failed: %v", tt.name, i, engine, err)
+			}
+			WriteHeadBlockHash(db, [32]byte{byte(i + 1)})
+			db.Close()
+
+			if existing := PreexistingDatabaseEngine(file); existing != tt.existing {
+				t.Errorf("%s: open %d: engine mismatch: have %q, want %q", tt.name, i, existing, tt.existing)
+			}
+		}
+	}
+}
@@ -126,6 +126,10 @@ func (p *StateProcessor) Process(block *types.Block, statedb, vaultState *state.
 		}
 	}
 	if (p.bc.chainConfig.Istanbul != nil || p.bc.chainConfig.SportDAO != nil || p.bc.chainConfig.Tendermint != nil) && p.autonityContract != nil {
+		if err := p.autonityContract.ApplyUpgrade(header, statedb); err != nil {
+			log.Error("Could not upgrade the Autonity contract, ", "err", err)
+			return nil, nil, nil, 0, err
+		}
 		err := p.autonityContract.ApplyPerformRedistribution(block.Transactions(), receipts, block.Header(), statedb)
 		if err != nil {
 			log.Error("Could not ApplyPerformRedistribution on smart contract, ", "err", err)
@@ -166,6 +170,11 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, vaultState, config, cfg)
 
+	// Reject the transactions the sender is not permitted to send
+	if err := CheckAccountAccess(vmenv, tx, msg.From()); err != nil {
+		return nil, nil, 0, err
+	}
+
 	// Apply the transaction to the current state (included in the env)
 	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
 	if err != nil {
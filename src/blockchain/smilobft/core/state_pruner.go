@@ -0,0 +1,297 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/trie"
+)
+
+var (
+	// statePruneKey tracks the progress of an interrupted state pruning.
+	statePruneKey = []byte("statePrune")
+
+	emptyStateRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	emptyCodeHash  = crypto.Keccak256Hash(nil)
+
+	errNoHeadBlock   = errors.New("no head block in the database")
+	errNoStateToKeep = errors.New("none of the blocks to keep has its state in the database")
+)
+
+// statePruneProgress is the stored progress of a state pruning, resumed from
+// if the pruning is interrupted.
+type statePruneProgress struct {
+	Roots []common.Hash // Public and vault state roots kept by the pruning
+	Next  []byte        // Key the deletion of the unreachable trie nodes resumes at
+}
+
+// StatePruneReport sums up the trie nodes of a database, and those a state
+// pruning deletes.
+type StatePruneReport struct {
+	Roots       []common.Hash      // Public and vault state roots kept
+	Nodes       int                // Trie nodes and contract codes in the database
+	Size        common.StorageSize // Size of the trie nodes and contract codes
+	Unreachable int                // Trie nodes and codes unreachable from the kept roots
+	Reclaimable common.StorageSize // Size of the unreachable trie nodes and codes
+	Resumed     bool               // Whether an interrupted pruning was resumed
+}
+
+// PruneState deletes from the database the trie nodes and contract codes of
+// the public and vault states no longer reachable from the states of the
+// last keep blocks and of the genesis. The database must not be in use.
+//
+// The pruning first marks the nodes reachable from the kept states, then
+// sweeps the unreachable ones, recording its progress so an interrupted
+// pruning resumes where it stopped. A dry run only reports what would be
+// deleted.
+func PruneState(db ethdb.Database, keep uint64, dryRun bool) (*StatePruneReport, error) {
+	report := new(StatePruneReport)
+
+	progress, err := readStatePruneProgress(db)
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		log.Info("Resuming state pruning", "roots", len(progress.Roots), "next", common.Bytes2Hex(progress.Next))
+		report.Resumed = true
+	} else {
+		roots, err := statePruneRoots(db, keep)
+		if err != nil {
+			return nil, err
+		}
+		progress = &statePruneProgress{Roots: roots}
+		if !dryRun {
+			if err := writeStatePruneProgress(db, progress); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.Roots = progress.Roots
+
+	start := time.Now()
+	reachable, err := markReachableState(db, progress.Roots)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Marked reachable state", "nodes", len(reachable), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if err := sweepState(db, reachable, progress, dryRun, report); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+	if err := db.Delete(statePruneKey); err != nil {
+		return nil, err
+	}
+	log.Info("Compacting database", "reclaimed", report.Reclaimable)
+	if err := db.Compact(nil, nil); err != nil {
+		return nil, err
+	}
+	log.Info("Pruned state", "deleted", report.Unreachable, "reclaimed", report.Reclaimable, "elapsed", common.PrettyDuration(time.Since(start)))
+	return report, nil
+}
+
+// statePruneRoots returns the public and vault state roots of the genesis and
+// of the last keep blocks whose state is in the database.
+func statePruneRoots(db ethdb.Database, keep uint64) ([]common.Hash, error) {
+	headHash := rawdb.ReadHeadBlockHash(db)
+	number := rawdb.ReadHeaderNumber(db, headHash)
+	if number == nil {
+		return nil, errNoHeadBlock
+	}
+	var (
+		roots []common.Hash
+		seen  = make(map[common.Hash]bool)
+		kept  int
+	)
+	// Only the states in the database are kept, a full node flushes a few
+	// of the recent ones
+	has := func(root common.Hash) bool {
+		ok, _ := db.Has(root.Bytes())
+		return ok || root == emptyStateRoot
+	}
+	add := func(root common.Hash) {
+		if !seen[root] && root != (common.Hash{}) && root != emptyStateRoot && has(root) {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	for n := *number; n+keep > *number; n-- {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n)
+		if header == nil {
+			return nil, fmt.Errorf("missing canonical header %d", n)
+		}
+		if has(header.Root) {
+			add(header.Root)
+			add(GetVaultStateRoot(db, header.Root))
+			kept++
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if kept == 0 {
+		return nil, errNoStateToKeep
+	}
+	// The genesis state is checked at every start, keep it
+	if genesis := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, 0), 0); genesis != nil {
+		add(genesis.Root)
+		add(GetVaultStateRoot(db, genesis.Root))
+	}
+	return roots, nil
+}
+
+// markReachableState returns the trie nodes and contract codes reachable from
+// the state roots.
+func markReachableState(db ethdb.Database, roots []common.Hash) (map[common.Hash]struct{}, error) {
+	var (
+		triedb    = trie.NewDatabase(db)
+		reachable = make(map[common.Hash]struct{})
+	)
+	for _, root := range roots {
+		err := markTrie(triedb, root, reachable, func(leaf []byte) error {
+			var account state.Account
+			if err := rlp.DecodeBytes(leaf, &account); err != nil {
+				return err
+			}
+			if codeHash := common.BytesToHash(account.CodeHash); codeHash != emptyCodeHash {
+				reachable[codeHash] = struct{}{}
+			}
+			return markTrie(triedb, account.Root, reachable, nil)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("state %x: %v", root, err)
+		}
+	}
+	return reachable, nil
+}
+
+// markTrie marks the nodes of the trie of root, skipping the subtries already
+// marked, and calls onLeaf with the values of the leaves it reaches.
+func markTrie(triedb *trie.Database, root common.Hash, marked map[common.Hash]struct{}, onLeaf func([]byte) error) error {
+	if root == emptyStateRoot {
+		return nil
+	}
+	if _, ok := marked[root]; ok {
+		return nil
+	}
+	t, err := trie.New(root, triedb)
+	if err != nil {
+		return err
+	}
+	it := t.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		descend = true
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if _, ok := marked[hash]; ok {
+				descend = false
+				continue
+			}
+			marked[hash] = struct{}{}
+		}
+		if it.Leaf() && onLeaf != nil {
+			if err := onLeaf(it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// sweepState deletes the trie nodes and contract codes which aren't
+// reachable, recording the progress after every batch.
+func sweepState(db ethdb.Database, reachable map[common.Hash]struct{}, progress *statePruneProgress, dryRun bool, report *StatePruneReport) error {
+	it := db.NewIteratorWithStart(progress.Next)
+	defer it.Release()
+
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		size := common.StorageSize(len(key) + len(it.Value()))
+		report.Nodes++
+		report.Size += size
+		if _, ok := reachable[common.BytesToHash(key)]; ok {
+			continue
+		}
+		report.Unreachable++
+		report.Reclaimable += size
+		if dryRun {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			progress.Next = common.CopyBytes(key)
+			if err := writeStatePruneProgress(batch, progress); err != nil {
+				return err
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state", "deleted", report.Unreachable, "reclaimed", report.Reclaimable, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func readStatePruneProgress(db ethdb.KeyValueReader) (*statePruneProgress, error) {
+	data, _ := db.Get(statePruneKey)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	progress := new(statePruneProgress)
+	if err := rlp.Decode(bytes.NewReader(data), progress); err != nil {
+		return nil, fmt.Errorf("invalid state pruning progress: %v", err)
+	}
+	return progress, nil
+}
+
+func writeStatePruneProgress(db ethdb.KeyValueWriter, progress *statePruneProgress) error {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	return db.Put(statePruneKey, data)
+}
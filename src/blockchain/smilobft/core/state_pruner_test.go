@@ -0,0 +1,161 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/consensus/ethash"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+// makeArchiveVaultChain imports n blocks each changing the value of a vault
+// contract into an archive node, so the state of every block is on disk.
+func makeArchiveVaultChain(t *testing.T, n int) (ethdb.Database, []*types.Block, common.Address) {
+	db := rawdb.NewMemoryDatabase()
+	blocks, contract := importVaultChain(t, db, &CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyLimit:    256,
+		TrieTimeLimit:     5 * time.Minute,
+		TrieDirtyDisabled: true,
+	}, n)
+	return db, blocks, contract
+}
+
+// checkStates checks the public and vault states of the block are complete.
+func checkStates(db ethdb.Database, block *types.Block) error {
+	sdb := state.NewDatabase(db)
+	for _, root := range []common.Hash{block.Root(), GetVaultStateRoot(db, block.Root())} {
+		statedb, err := state.New(root, sdb)
+		if err != nil {
+			return err
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+		}
+		if it.Error != nil {
+			return it.Error
+		}
+	}
+	return nil
+}
+
+func TestPruneState(t *testing.T) {
+	db, blocks, contract := makeArchiveVaultChain(t, 20)
+	nodes := countTrieNodes(db)
+
+	// A dry run reports the reclaimable nodes, deleting nothing
+	report, err := PruneState(db, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Nodes != nodes || report.Unreachable == 0 || report.Reclaimable == 0 {
+		t.Fatalf("dry run report mismatch: %+v, %d nodes", report, nodes)
+	}
+	if count := countTrieNodes(db); count != nodes {
+		t.Fatalf("dry run deleted nodes: have %d, want %d", count, nodes)
+	}
+	if _, err := readStatePruneProgress(db); err != nil || statePruneKeyExists(db) {
+		t.Fatal("dry run recorded a pruning progress")
+	}
+
+	pruned, err := PruneState(db, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned.Unreachable != report.Unreachable {
+		t.Errorf("deleted nodes mismatch: have %d, dry run %d", pruned.Unreachable, report.Unreachable)
+	}
+	if count := countTrieNodes(db); count != nodes-report.Unreachable {
+		t.Errorf("trie nodes mismatch after pruning: have %d, want %d", count, nodes-report.Unreachable)
+	}
+	for i, block := range blocks {
+		err := checkStates(db, block)
+		if kept := i >= len(blocks)-5; kept && err != nil {
+			t.Errorf("block %d: kept state incomplete: %v", i+1, err)
+		} else if !kept && err == nil {
+			t.Errorf("block %d: old state not pruned", i+1)
+		}
+	}
+	if err := checkStates(db, rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, 0), 0)); err != nil {
+		t.Errorf("genesis state pruned: %v", err)
+	}
+	if statePruneKeyExists(db) {
+		t.Error("pruning progress left after the pruning")
+	}
+	// The node restarts on the pruned database
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = mapVault{}
+
+	bc, err := NewBlockChain(db, nil, params.SmiloTestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if head := bc.CurrentBlock().NumberU64(); head != 20 {
+		t.Fatalf("head mismatch after pruning: have %d, want 20", head)
+	}
+	if value := vaultStorage(t, bc, bc.CurrentBlock(), contract); value != common.BigToHash(big.NewInt(20)) {
+		t.Errorf("vault storage mismatch after pruning: have %x", value)
+	}
+}
+
+func TestPruneStateResume(t *testing.T) {
+	db, blocks, _ := makeArchiveVaultChain(t, 20)
+
+	// Record the progress of a pruning keeping 5 blocks, interrupted before
+	// deleting anything
+	roots, err := statePruneRoots(db, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeStatePruneProgress(db, &statePruneProgress{Roots: roots}); err != nil {
+		t.Fatal(err)
+	}
+	// The resumed pruning keeps the recorded states, whatever it is asked
+	report, err := PruneState(db, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Resumed || len(report.Roots) != len(roots) {
+		t.Fatalf("pruning not resumed: %+v", report)
+	}
+	for _, block := range blocks[len(blocks)-5:] {
+		if err := checkStates(db, block); err != nil {
+			t.Errorf("block %d: kept state incomplete: %v", block.NumberU64(), err)
+		}
+	}
+	if err := checkStates(db, blocks[len(blocks)-6]); err == nil {
+		t.Errorf("block %d: old state not pruned", len(blocks)-5)
+	}
+}
+
+func statePruneKeyExists(db ethdb.Database) bool {
+	ok, _ := db.Has(statePruneKey)
+	return ok
+}
@@ -302,7 +302,13 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	if st.evm.ChainConfig().AutonityContractConfig != nil && (st.evm.ChainConfig().Istanbul != nil || st.evm.ChainConfig().SportDAO != nil || st.evm.ChainConfig().Tendermint != nil) {
 
 		st.refundGas()
-		addr, innerErr := st.evm.ChainConfig().AutonityContractConfig.GetContractAddress()
+		// The fees go to the contract running before the upgrade of the block, if
+		// any, which moves them along with its balance
+		number := st.evm.BlockNumber.Uint64()
+		if number > 0 {
+			number--
+		}
+		addr, innerErr := st.evm.ChainConfig().AutonityContractConfig.GetContractAddressAt(number)
 		if innerErr != nil {
 			return nil, 0, true, innerErr
 		}
@@ -0,0 +1,297 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
+
+	"go-smilo/src/blockchain/smilobft/core/codeanalysis"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+var (
+	// ErrContractRejected is returned if the code of a contract creation
+	// trips a rule of the contract analysis.
+	ErrContractRejected = errors.New("contract rejected by the code analysis")
+
+	// ErrContractQuarantined is returned if the code of a contract creation
+	// trips a rule of the contract analysis, and the transaction is kept aside
+	// for inspection instead of entering the pool.
+	ErrContractQuarantined = errors.New("contract quarantined by the code analysis")
+)
+
+const (
+	verdictCacheSize = 1024      // Number of the verdicts of recent codes to keep
+	maxQuarantined   = 256       // Maximum number of the quarantined transactions
+	maxAnalyzedCode  = 64 * 1024 // Maximum size of the analyzed codes, larger ones are not fully analyzed
+)
+
+// ContractAction is what is done with a contract creation tripping a rule.
+type ContractAction string
+
+const (
+	ContractAdmit      ContractAction = ""
+	ContractQuarantine ContractAction = "quarantine"
+	ContractReject     ContractAction = "reject"
+)
+
+// stronger returns whether a is a stronger action than b.
+func (a ContractAction) stronger(b ContractAction) bool {
+	rank := map[ContractAction]int{ContractAdmit: 0, ContractQuarantine: 1, ContractReject: 2}
+	return rank[a] > rank[b]
+}
+
+// ContractRule selects the findings of the analysis leading to an action.
+type ContractRule struct {
+	Detectors []string              `toml:",omitempty"` // Detectors whose findings match, any if empty
+	Severity  codeanalysis.Severity `toml:",omitempty"` // Minimum severity of the findings, any if unset
+	Action    ContractAction        // Action on the matching contracts
+}
+
+// matches returns whether the finding is selected by the rule.
+func (r *ContractRule) matches(f codeanalysis.Finding) bool {
+	if f.Severity < r.Severity {
+		return false
+	}
+	if len(r.Detectors) == 0 {
+		return true
+	}
+	for _, name := range r.Detectors {
+		if name == f.Detector {
+			return true
+		}
+	}
+	return false
+}
+
+func knownDetector(name string) bool {
+	for _, d := range codeanalysis.Detectors {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractAnalysisConfig are the rules of the analysis of the contract creations.
+type ContractAnalysisConfig struct {
+	Rules      []ContractRule
+	Incomplete ContractAction   `toml:",omitempty"` // Action on the codes that can't be fully analyzed
+	Deployers  []common.Address `toml:",omitempty"` // Accounts whose contracts are not analyzed
+}
+
+// ContractFinding is a finding of the analysis of a contract creation.
+type ContractFinding struct {
+	codeanalysis.Finding
+	Deployed bool `json:"deployed"` // Whether found in the deployed code rather than in the init code
+}
+
+// ContractVerdict is the outcome of the admission of a contract creation.
+type ContractVerdict struct {
+	Action   ContractAction
+	Findings []ContractFinding // Findings matching the rules
+}
+
+// Err returns the error to report to the sender, nil if admitted.
+func (v *ContractVerdict) Err() error {
+	if v == nil {
+		return nil
+	}
+	switch v.Action {
+	case ContractQuarantine:
+		return ErrContractQuarantined
+	case ContractReject:
+		return ErrContractRejected
+	}
+	return nil
+}
+
+// ContractAdmission decides whether contract creations may enter the pool or
+// a block. It is called without holding the pool lock, possibly concurrently.
+type ContractAdmission interface {
+	// AdmitContract returns the verdict on a contract creation sent by from,
+	// nil admitting it.
+	AdmitContract(tx *types.Transaction, from common.Address) *ContractVerdict
+}
+
+// ContractAnalysis is the ContractAdmission running the code analysis on the
+// init code of the contract creations and on the code they deploy.
+type ContractAnalysis struct {
+	config    ContractAnalysisConfig
+	deployers map[common.Address]bool
+	verdicts  *lru.Cache // Verdicts by hash of the init code
+}
+
+// NewContractAnalysis creates the admission of the contract creations from the
+// analysis rules.
+func NewContractAnalysis(config ContractAnalysisConfig) (*ContractAnalysis, error) {
+	for i, rule := range config.Rules {
+		if rule.Action != ContractQuarantine && rule.Action != ContractReject {
+			return nil, fmt.Errorf("contract analysis rule %d: invalid action %q", i, rule.Action)
+		}
+		for _, name := range rule.Detectors {
+			if !knownDetector(name) {
+				return nil, fmt.Errorf("contract analysis rule %d: unknown detector %q", i, name)
+			}
+		}
+	}
+	if a := config.Incomplete; a != ContractAdmit && a != ContractQuarantine && a != ContractReject {
+		return nil, fmt.Errorf("contract analysis: invalid action %q on incomplete analysis", a)
+	}
+	deployers := make(map[common.Address]bool, len(config.Deployers))
+	for _, addr := range config.Deployers {
+		deployers[addr] = true
+	}
+	verdicts, _ := lru.New(verdictCacheSize)
+	return &ContractAnalysis{config: config, deployers: deployers, verdicts: verdicts}, nil
+}
+
+// AdmitContract implements ContractAdmission. The contracts of the allowed
+// deployers are admitted unchecked. The vault contract creations are analyzed
+// on their payload in the vault, and are handled as incomplete analyses if this
+// node can't retrieve it, as are the codes too large to be analyzed.
+func (ca *ContractAnalysis) AdmitContract(tx *types.Transaction, from common.Address) *ContractVerdict {
+	if tx.To() != nil || ca.deployers[from] {
+		return &ContractVerdict{}
+	}
+	code := tx.Data()
+	if tx.IsVault() {
+		payload, err := vaultPayload(code)
+		if err != nil {
+			log.Debug("Vault contract creation not analyzed", "hash", tx.Hash(), "err", err)
+			return &ContractVerdict{Action: ca.config.Incomplete}
+		}
+		code = payload
+	}
+	if len(code) > maxAnalyzedCode {
+		return &ContractVerdict{Action: ca.config.Incomplete}
+	}
+	hash := crypto.Keccak256Hash(code)
+	if verdict, ok := ca.verdicts.Get(hash); ok {
+		return verdict.(*ContractVerdict)
+	}
+	verdict := ca.analyze(code)
+	ca.verdicts.Add(hash, verdict)
+	return verdict
+}
+
+// vaultPayload retrieves the payload of a vault transaction from the vault.
+func vaultPayload(hash []byte) ([]byte, error) {
+	if vault.VaultInstance == nil {
+		return nil, errors.New("vault offline")
+	}
+	payload, err := vault.VaultInstance.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, errors.New("payload not found")
+	}
+	return payload, nil
+}
+
+// analyze runs the analysis on the init code and the deployed code, and applies
+// the rules to the findings.
+func (ca *ContractAnalysis) analyze(init []byte) *ContractVerdict {
+	verdict := new(ContractVerdict)
+	codes := [][]byte{init}
+	if deployed := codeanalysis.DeployedCode(init); deployed != nil {
+		codes = append(codes, deployed)
+	}
+	for i, code := range codes {
+		report, err := codeanalysis.Analyze(code, nil)
+		if err != nil {
+			log.Error("Failed to analyze contract code", "err", err)
+			continue
+		}
+		if report.Incomplete && ca.config.Incomplete.stronger(verdict.Action) {
+			verdict.Action = ca.config.Incomplete
+		}
+		for _, f := range report.Findings {
+			matched := false
+			for _, rule := range ca.config.Rules {
+				if rule.matches(f) {
+					matched = true
+					if rule.Action.stronger(verdict.Action) {
+						verdict.Action = rule.Action
+					}
+				}
+			}
+			if matched {
+				verdict.Findings = append(verdict.Findings, ContractFinding{Finding: f, Deployed: i > 0})
+			}
+		}
+	}
+	return verdict
+}
+
+// QuarantinedTx is a contract creation kept aside by the pool.
+type QuarantinedTx struct {
+	Tx       *types.Transaction
+	From     common.Address
+	Findings []ContractFinding
+	Time     time.Time
+}
+
+// quarantine is the bounded set of the quarantined transactions, the oldest
+// evicted first.
+type quarantine struct {
+	txs   map[common.Hash]*QuarantinedTx
+	order []common.Hash
+}
+
+func newQuarantine() *quarantine {
+	return &quarantine{txs: make(map[common.Hash]*QuarantinedTx)}
+}
+
+func (q *quarantine) add(qtx *QuarantinedTx) {
+	hash := qtx.Tx.Hash()
+	if _, ok := q.txs[hash]; ok {
+		return
+	}
+	if len(q.order) >= maxQuarantined {
+		delete(q.txs, q.order[0])
+		q.order = q.order[1:]
+	}
+	q.txs[hash] = qtx
+	q.order = append(q.order, hash)
+}
+
+// expire drops the transactions quarantined for longer than lifetime.
+func (q *quarantine) expire(lifetime time.Duration) {
+	for len(q.order) > 0 && time.Since(q.txs[q.order[0]].Time) > lifetime {
+		delete(q.txs, q.order[0])
+		q.order = q.order[1:]
+	}
+}
+
+// list returns the quarantined transactions, oldest first.
+func (q *quarantine) list() []*QuarantinedTx {
+	list := make([]*QuarantinedTx, len(q.order))
+	for i, hash := range q.order {
+		list[i] = q.txs[hash]
+	}
+	return list
+}
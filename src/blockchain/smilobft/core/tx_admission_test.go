@@ -0,0 +1,172 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/core/codeanalysis"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+// selfdestructInit is the init code deploying a contract anyone can destroy.
+var selfdestructInit = []byte{
+	byte(vm.PUSH1), 0x02, // size
+	byte(vm.DUP1),
+	byte(vm.PUSH1), 0x0b, // offset of the deployed code
+	byte(vm.PUSH1), 0x00,
+	byte(vm.CODECOPY),
+	byte(vm.PUSH1), 0x00,
+	byte(vm.RETURN),
+	byte(vm.CALLER), // 0x0b
+	byte(vm.SELFDESTRUCT),
+}
+
+func contractCreation(nonce uint64, code []byte, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewContractCreation(nonce, common.Big0, 100000, big.NewInt(1), code), types.HomesteadSigner{}, key)
+	return tx
+}
+
+func setupAnalysisTxPool(t *testing.T, config ContractAnalysisConfig) (*TxPool, *ecdsa.PrivateKey) {
+	pool, key := setupTxPool()
+	admission, err := NewContractAnalysis(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.SetContractAdmission(admission)
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(100000000000000), big.NewInt(1))
+	return pool, key
+}
+
+func TestContractAnalysisQuarantine(t *testing.T) {
+	pool, key := setupAnalysisTxPool(t, ContractAnalysisConfig{
+		Rules: []ContractRule{{Severity: codeanalysis.SeverityHigh, Action: ContractQuarantine}},
+	})
+	defer pool.Stop()
+
+	tx := contractCreation(0, selfdestructInit, key)
+	if err := pool.AddRemote(tx); err != ErrContractQuarantined {
+		t.Fatalf("error mismatch: got %v, want %v", err, ErrContractQuarantined)
+	}
+	if pool.all.Count() != 0 {
+		t.Errorf("quarantined transaction pooled")
+	}
+	quarantined := pool.Quarantined()
+	if len(quarantined) != 1 || quarantined[0].Tx.Hash() != tx.Hash() {
+		t.Fatalf("quarantine mismatch: %v", quarantined)
+	}
+	findings := quarantined[0].Findings
+	if len(findings) != 1 || findings[0].Detector != codeanalysis.DetectorSelfdestruct || !findings[0].Deployed {
+		t.Errorf("findings mismatch: %v", findings)
+	}
+	// A safe contract still enters the pool
+	if err := pool.AddRemote(contractCreation(0, []byte{byte(vm.STOP)}, key)); err != nil {
+		t.Errorf("safe contract refused: %v", err)
+	}
+}
+
+func TestContractAnalysisReject(t *testing.T) {
+	pool, key := setupAnalysisTxPool(t, ContractAnalysisConfig{
+		Rules: []ContractRule{
+			{Detectors: []string{codeanalysis.DetectorSelfdestruct}, Action: ContractQuarantine},
+			{Severity: codeanalysis.SeverityMedium, Action: ContractReject},
+		},
+	})
+	defer pool.Stop()
+
+	if err := pool.AddRemote(contractCreation(0, selfdestructInit, key)); err != ErrContractRejected {
+		t.Fatalf("error mismatch: got %v, want %v", err, ErrContractRejected)
+	}
+	if len(pool.Quarantined()) != 0 {
+		t.Errorf("rejected transaction quarantined")
+	}
+}
+
+func TestContractAnalysisDeployers(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool, _ := setupAnalysisTxPool(t, ContractAnalysisConfig{
+		Rules:     []ContractRule{{Action: ContractReject}},
+		Deployers: []common.Address{crypto.PubkeyToAddress(key.PublicKey)},
+	})
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(100000000000000), big.NewInt(1))
+	if err := pool.AddRemote(contractCreation(0, selfdestructInit, key)); err != nil {
+		t.Errorf("contract of an allowed deployer refused: %v", err)
+	}
+}
+
+// Tests that vault contract creations are analyzed on their payload, and that
+// the codes which can't be analyzed get the action on incomplete analyses.
+func TestContractAnalysisVault(t *testing.T) {
+	analysis, err := NewContractAnalysis(ContractAnalysisConfig{
+		Rules:      []ContractRule{{Severity: codeanalysis.SeverityHigh, Action: ContractReject}},
+		Incomplete: ContractQuarantine,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := mapVault{}
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	digest, _ := v.Post(selfdestructInit, "", nil)
+	safe, _ := v.Post([]byte{byte(vm.STOP)}, "", nil)
+
+	tests := []struct {
+		data   []byte
+		vault  bool
+		action ContractAction
+	}{
+		{digest, true, ContractReject},                                  // Payload tripping a rule
+		{safe, true, ContractAdmit},                                     // Safe payload
+		{crypto.Keccak256([]byte("missing")), true, ContractQuarantine}, // Payload not in the vault
+		{make([]byte, maxAnalyzedCode+1), false, ContractQuarantine},    // Code too large to analyze
+	}
+	for i, tt := range tests {
+		tx := contractCreation(0, tt.data, key)
+		if tt.vault {
+			tx.SetVault()
+		}
+		if verdict := analysis.AdmitContract(tx, from); verdict.Action != tt.action {
+			t.Errorf("test %d: action mismatch: have %q, want %q", i, verdict.Action, tt.action)
+		}
+	}
+}
+
+func TestContractAnalysisConfig(t *testing.T) {
+	invalid := []ContractAnalysisConfig{
+		{Rules: []ContractRule{{Action: "drop"}}},
+		{Rules: []ContractRule{{Detectors: []string{"unknown"}, Action: ContractReject}}},
+		{Incomplete: "drop"},
+	}
+	for i, config := range invalid {
+		if _, err := NewContractAnalysis(config); err == nil {
+			t.Errorf("config %d: invalid rules accepted", i)
+		}
+	}
+}
@@ -119,6 +119,10 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 
+	// Metrics for the contract creations tripping the code analysis
+	contractRejectMeter     = metrics.NewRegisteredMeter("txpool/contract/rejected", nil)
+	contractQuarantineMeter = metrics.NewRegisteredMeter("txpool/contract/quarantined", nil)
+
 	pendingCounter = metrics.NewRegisteredCounter("txpool/pending", nil)
 	queuedCounter  = metrics.NewRegisteredCounter("txpool/queued", nil)
 	localCounter   = metrics.NewRegisteredCounter("txpool/local", nil)
@@ -165,6 +169,8 @@ type TxPoolConfig struct {
 
 	CustomTransactionSizeLimit uint64 // Maximum size allowed for valid transaction (in KB)
 	Blacklist                  string // Blacklist of addresses we should refuse transactions from
+
+	ContractAnalysis *ContractAnalysisConfig `toml:",omitempty"` // Rules of the analysis of the contract creations, none if nil
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -255,6 +261,9 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	admission  ContractAdmission // Admission of the contract creations, if any
+	quarantine *quarantine       // Contract creations kept aside for inspection
+
 	chainHeadCh     chan ChainHeadEvent
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
@@ -292,6 +301,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
+		quarantine:      newQuarantine(),
+	}
+	if config.ContractAnalysis != nil {
+		if analysis, err := NewContractAnalysis(*config.ContractAnalysis); err != nil {
+			log.Error("Invalid contract analysis rules, contract creations not analyzed", "err", err)
+		} else {
+			pool.admission = analysis
+		}
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
@@ -387,6 +404,7 @@ func (pool *TxPool) loop() {
 					}
 				}
 			}
+			pool.quarantine.expire(pool.config.Lifetime)
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -493,6 +511,33 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// Quarantined retrieves the contract creations kept aside by the code analysis,
+// oldest first.
+func (pool *TxPool) Quarantined() []*QuarantinedTx {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.quarantine.list()
+}
+
+// ContractAdmission returns the admission of the contract creations, nil if
+// they are not checked.
+func (pool *TxPool) ContractAdmission() ContractAdmission {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.admission
+}
+
+// SetContractAdmission replaces the admission of the contract creations, nil
+// disabling the checks. The transactions already pooled are not checked again.
+func (pool *TxPool) SetContractAdmission(admission ContractAdmission) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.admission = admission
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -568,6 +613,16 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Check the account access the transaction will be executed with, in the
+	// block on top of the current head
+	pending := types.CopyHeader(pool.chain.CurrentBlock().Header())
+	pending.Number.Add(pending.Number, common.Big1)
+	if pool.chainconfig.IsAccountPermissions(pending.Number) {
+		evm := NewAccountAccessEVM(pool.chainconfig, pending, pool.currentState)
+		if err := CheckAccountAccess(evm, tx, from); err != nil {
+			return err
+		}
+	}
 	if (pool.chain.Config().Istanbul != nil || pool.chain.Config().SportDAO != nil || pool.chain.Config().Tendermint != nil) && pool.chain.GetAutonityContract() != nil {
 
 		//if blacklistlist, err := pool.chain.GetAutonityContract().GetBlacklist(pool.chain.CurrentBlock(), pool.currentState, pool.currentState); err == nil {
@@ -660,7 +715,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // whitelisted, preventing any associated transaction from being dropped out of the pool
 // due to pricing constraints.
-func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
+//
+// The verdict of the contract admission on a contract creation is computed by
+// the caller without holding the pool lock, nil admitting the transaction.
+func (pool *TxPool) add(tx *types.Transaction, local bool, verdict *ContractVerdict) (replaced bool, err error) {
 	isSmilo := pool.chainconfig.IsSmilo
 	isGas := pool.chainconfig.IsGas
 
@@ -677,6 +735,18 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
+	// If the contract creation trips the code analysis, discard or quarantine it
+	if err := verdict.Err(); err != nil {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		log.Debug("Discarding contract creation", "hash", hash, "from", from, "action", verdict.Action, "findings", len(verdict.Findings))
+		if verdict.Action == ContractQuarantine {
+			pool.quarantine.add(&QuarantinedTx{Tx: tx, From: from, Findings: verdict.Findings, Time: time.Now()})
+			contractQuarantineMeter.Mark(1)
+		} else {
+			contractRejectMeter.Mark(1)
+		}
+		return false, err
+	}
 
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
@@ -876,9 +946,11 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	for _, tx := range txs {
 		types.Sender(pool.signer, tx)
 	}
+	// Analyze the contract creations before obtaining lock too, as it may be slow
+	verdicts := pool.admitContracts(txs)
 
 	pool.mu.Lock()
-	errs, dirtyAddrs := pool.addTxsLocked(txs, local)
+	errs, dirtyAddrs := pool.addTxsLocked(txs, local, verdicts)
 	pool.mu.Unlock()
 
 	done := pool.requestPromoteExecutables(dirtyAddrs)
@@ -888,13 +960,39 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	return errs
 }
 
-// addTxsLocked attempts to queue a batch of transactions if they are valid.
-// The transaction pool lock must be held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+// admitContracts returns the verdicts of the contract admission on the contract
+// creations of txs, nil if they are not checked.
+func (pool *TxPool) admitContracts(txs []*types.Transaction) []*ContractVerdict {
+	admission := pool.ContractAdmission()
+	if admission == nil {
+		return nil
+	}
+	verdicts := make([]*ContractVerdict, len(txs))
+	for i, tx := range txs {
+		if tx.To() != nil {
+			continue
+		}
+		from, err := types.Sender(pool.signer, tx)
+		if err != nil {
+			continue // rejected by validateTx
+		}
+		verdicts[i] = admission.AdmitContract(tx, from)
+	}
+	return verdicts
+}
+
+// addTxsLocked attempts to queue a batch of transactions if they are valid, with
+// the verdicts of the contract admission on them, if any. The transaction pool
+// lock must be held.
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool, verdicts []*ContractVerdict) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
-		replaced, err := pool.add(tx, local)
+		var verdict *ContractVerdict
+		if verdicts != nil {
+			verdict = verdicts[i]
+		}
+		replaced, err := pool.add(tx, local, verdict)
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1222,8 +1320,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
+	// They were included in the chain already, so the contract admission is skipped
 	senderCacher.recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, nil)
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -55,15 +55,16 @@ type BFTExtra struct {
 	Validators    []common.Address
 	Seal          []byte
 	CommittedSeal [][]byte
+	Checkpoint    common.Hash // Digest of the validators in epoch checkpoint headers, zero in the others
 }
 
 // EncodeRLP serializes pos into the Ethereum RLP format.
 func (pos *BFTExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	return rlp.Encode(w, appendCheckpoint([]interface{}{
 		pos.Validators,
 		pos.Seal,
 		pos.CommittedSeal,
-	})
+	}, pos.Checkpoint))
 }
 
 // DecodeRLP implements rlp.Decoder, and load the pos fields from a RLP stream.
@@ -72,11 +73,16 @@ func (pos *BFTExtra) DecodeRLP(s *rlp.Stream) error {
 		Validators    []common.Address
 		Seal          []byte
 		CommittedSeal [][]byte
+		Checkpoint    []common.Hash `rlp:"tail"`
 	}
 	if err := s.Decode(&bftExtra); err != nil {
 		return err
 	}
-	pos.Validators, pos.Seal, pos.CommittedSeal = bftExtra.Validators, bftExtra.Seal, bftExtra.CommittedSeal
+	checkpoint, err := decodeCheckpoint(bftExtra.Checkpoint)
+	if err != nil {
+		return err
+	}
+	pos.Validators, pos.Seal, pos.CommittedSeal, pos.Checkpoint = bftExtra.Validators, bftExtra.Seal, bftExtra.CommittedSeal, checkpoint
 	return nil
 }
 
@@ -168,7 +174,9 @@ func Ecrecover(header *Header) (common.Address, error) {
 	return addr, nil
 }
 
-// PrepareExtra returns a extra-data of the given header and validators
+// PrepareExtra returns a extra-data of the given header and validators. The
+// extra-data of an epoch checkpoint header stays a checkpoint, committed to the
+// given validators.
 func PrepareExtra(extraData []byte, vals []common.Address) ([]byte, error) {
 	extraDataCopy := append([]byte{}, extraData...)
 
@@ -177,6 +185,12 @@ func PrepareExtra(extraData []byte, vals []common.Address) ([]byte, error) {
 		Seal:          []byte{},
 		CommittedSeal: [][]byte{},
 	}
+	if len(extraDataCopy) > BFTExtraVanity {
+		var prepared *BFTExtra
+		if err := rlp.DecodeBytes(extraDataCopy[BFTExtraVanity:], &prepared); err == nil && prepared.Checkpoint != (common.Hash{}) {
+			pos.Checkpoint = ValidatorsDigest(vals)
+		}
+	}
 
 	payload, err := rlp.EncodeToBytes(&pos)
 	if err != nil {
@@ -0,0 +1,206 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// ErrMissingCheckpoint is returned if an epoch checkpoint header does not
+	// commit to its validators.
+	ErrMissingCheckpoint = errors.New("missing checkpoint in extra-data")
+	// ErrInvalidCheckpoint is returned if the checkpoint digest of a header does
+	// not match its validators.
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint validators digest")
+	// ErrUntrustedCheckpoint is returned if a checkpoint is not committed by
+	// enough validators of the trusted checkpoint before it.
+	ErrUntrustedCheckpoint = errors.New("checkpoint not committed by the trusted validators")
+)
+
+// CheckpointRules are the seal rules of the BFT engine which committed the
+// checkpoint headers.
+type CheckpointRules struct {
+	// CommitCode is the code of the commit message of the engine, which the
+	// committed seals sign after the block hash.
+	CommitCode byte
+	// Quorum returns the number of committed seals the engine needs out of n
+	// validators for the header of block number.
+	Quorum func(n int, number *big.Int) int
+}
+
+// Checkpoint is the validator set committed by an epoch checkpoint header,
+// which sealed the header.
+type Checkpoint struct {
+	Number     uint64           `json:"number"`
+	Hash       common.Hash      `json:"hash"`
+	Validators []common.Address `json:"validators"`
+}
+
+// ValidatorsDigest returns the commitment of checkpoint headers to a validator
+// set, which does not depend on the order of the validators.
+func ValidatorsDigest(validators []common.Address) common.Hash {
+	sorted := make([]common.Address, len(validators))
+	copy(sorted, validators)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return RLPHash(sorted)
+}
+
+// appendCheckpoint adds the checkpoint to the RLP fields of an extra-data, if
+// any, so the extra-data of the other headers keeps its original encoding.
+func appendCheckpoint(fields []interface{}, checkpoint common.Hash) []interface{} {
+	if checkpoint == (common.Hash{}) {
+		return fields
+	}
+	return append(fields, checkpoint)
+}
+
+func decodeCheckpoint(tail []common.Hash) (common.Hash, error) {
+	switch len(tail) {
+	case 0:
+		return common.Hash{}, nil
+	case 1:
+		return tail[0], nil
+	}
+	return common.Hash{}, ErrInvalidCheckpoint
+}
+
+// WriteCheckpoint commits the extra-data of h to the validators it lists,
+// making it an epoch checkpoint header. Sport and BFT extra-data share their
+// encoding.
+func WriteCheckpoint(h *Header) error {
+	extra, err := ExtractBFTHeaderExtra(h)
+	if err != nil {
+		return err
+	}
+	extra.Checkpoint = ValidatorsDigest(extra.Validators)
+
+	payload, err := rlp.EncodeToBytes(&extra)
+	if err != nil {
+		return err
+	}
+	h.Extra = append(h.Extra[:BFTExtraVanity:BFTExtraVanity], payload...)
+	return nil
+}
+
+// ExtractCheckpoint returns the validator set committed by the checkpoint
+// header h.
+func ExtractCheckpoint(h *Header) (*Checkpoint, error) {
+	extra, err := ExtractBFTHeaderExtra(h)
+	if err != nil {
+		return nil, err
+	}
+	if extra.Checkpoint == (common.Hash{}) {
+		return nil, ErrMissingCheckpoint
+	}
+	if ValidatorsDigest(extra.Validators) != extra.Checkpoint {
+		return nil, ErrInvalidCheckpoint
+	}
+	return &Checkpoint{
+		Number:     h.Number.Uint64(),
+		Hash:       h.Hash(),
+		Validators: extra.Validators,
+	}, nil
+}
+
+// VerifyCheckpointValidators checks that the checkpoint header h commits to the
+// given validators, the ones of the engine at its height.
+func VerifyCheckpointValidators(h *Header, validators []common.Address) error {
+	checkpoint, err := ExtractCheckpoint(h)
+	if err != nil {
+		return err
+	}
+	if ValidatorsDigest(checkpoint.Validators) != ValidatorsDigest(validators) {
+		return ErrInvalidCheckpoint
+	}
+	return nil
+}
+
+// VerifyCheckpoint verifies the checkpoint header h from the trusted checkpoint
+// before it, without the headers in between. The committed seals of h must come
+// from the quorum of the engine among the validators it commits to, and from
+// more than a third of the trusted validators, so that at least one honest
+// trusted validator vouches for the new set.
+func VerifyCheckpoint(rules *CheckpointRules, trusted *Checkpoint, h *Header) (*Checkpoint, error) {
+	checkpoint, err := ExtractCheckpoint(h)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := CommittedSigners(h, rules.CommitCode)
+	if err != nil {
+		return nil, err
+	}
+	if signed(signers, checkpoint.Validators) < rules.Quorum(len(checkpoint.Validators), h.Number) {
+		return nil, ErrInvalidCommittedSeals
+	}
+	if signed(signers, trusted.Validators) < maxFaulty(len(trusted.Validators))+1 {
+		return nil, ErrUntrustedCheckpoint
+	}
+	return checkpoint, nil
+}
+
+// CommittedSigners returns the validators which committed the BFT header h with
+// the commit message code of its engine.
+func CommittedSigners(h *Header, commitCode byte) ([]common.Address, error) {
+	extra, err := ExtractBFTHeaderExtra(h)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra.CommittedSeal) == 0 {
+		return nil, ErrEmptyCommittedSeals
+	}
+	commit := append(h.Hash().Bytes(), commitCode)
+
+	signers := make([]common.Address, 0, len(extra.CommittedSeal))
+	for _, seal := range extra.CommittedSeal {
+		addr, err := GetSignatureAddress(commit, seal)
+		if err != nil {
+			return nil, ErrInvalidCommittedSeals
+		}
+		signers = append(signers, addr)
+	}
+	return signers, nil
+}
+
+// signed returns the number of validators among the signers.
+func signed(signers []common.Address, validators []common.Address) int {
+	set := make(map[common.Address]bool, len(validators))
+	for _, v := range validators {
+		set[v] = true
+	}
+	count := 0
+	for _, s := range signers {
+		if set[s] {
+			count++
+			delete(set, s)
+		}
+	}
+	return count
+}
+
+// maxFaulty returns the number of faulty validators tolerated out of n.
+func maxFaulty(n int) int {
+	return (n - 1) / 3
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testCheckpointRules are the seal rules of an engine committing with message
+// code 2 and needing two thirds of the validators.
+var testCheckpointRules = &CheckpointRules{
+	CommitCode: 2,
+	Quorum: func(n int, number *big.Int) int {
+		return int(math.Ceil(float64(2*n) / 3))
+	},
+}
+
+func checkpointKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	addrs := make([]common.Address, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], addrs[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return keys, addrs
+}
+
+// checkpointHeader returns a checkpoint header of validators committed by
+// signers.
+func checkpointHeader(t *testing.T, number int64, validators []common.Address, signers []*ecdsa.PrivateKey) *Header {
+	extra, err := PrepareExtra(nil, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Header{Number: big.NewInt(number), MixDigest: BFTDigest, Extra: extra}
+	if err := WriteCheckpoint(h); err != nil {
+		t.Fatal(err)
+	}
+	commit := append(h.Hash().Bytes(), testCheckpointRules.CommitCode)
+	var seals [][]byte
+	for _, key := range signers {
+		seal, err := crypto.Sign(crypto.Keccak256(commit), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seals = append(seals, seal)
+	}
+	if err := WriteCommittedSeals(h, seals); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestCheckpointExtraEncoding(t *testing.T) {
+	_, validators := checkpointKeys(t, 3)
+	extra, err := PrepareExtra(nil, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Header{Number: big.NewInt(10), Extra: extra}
+	if _, err := ExtractCheckpoint(h); err != ErrMissingCheckpoint {
+		t.Fatalf("header without checkpoint: have %v, want %v", err, ErrMissingCheckpoint)
+	}
+	if err := WriteCheckpoint(h); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both extra-data formats carry the checkpoint
+	sportExtra, err := ExtractSportExtra(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sportExtra.Checkpoint != ValidatorsDigest(validators) {
+		t.Errorf("sport checkpoint: have %x, want %x", sportExtra.Checkpoint, ValidatorsDigest(validators))
+	}
+	reversed := []common.Address{validators[2], validators[1], validators[0]}
+	if err := VerifyCheckpointValidators(h, reversed); err != nil {
+		t.Errorf("checkpoint of the same set in another order rejected: %v", err)
+	}
+	if err := VerifyCheckpointValidators(h, validators[:2]); err != ErrInvalidCheckpoint {
+		t.Errorf("checkpoint of another set: have %v, want %v", err, ErrInvalidCheckpoint)
+	}
+
+	// The extra-data of the other headers keeps its encoding
+	legacy, _ := PrepareExtra(nil, validators)
+	if !bytes.Equal(legacy, extra) {
+		t.Errorf("extra-data without checkpoint changed")
+	}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	keys, addrs := checkpointKeys(t, 7)
+	trusted := &Checkpoint{Number: 100, Validators: addrs[:4]}
+
+	// Rotating a validator keeps enough trusted signers
+	next := checkpointHeader(t, 200, addrs[1:5], keys[1:4])
+	checkpoint, err := VerifyCheckpoint(testCheckpointRules, trusted, next)
+	if err != nil {
+		t.Fatalf("valid checkpoint rejected: %v", err)
+	}
+	if checkpoint.Number != 200 || checkpoint.Hash != next.Hash() || len(checkpoint.Validators) != 4 {
+		t.Errorf("checkpoint: have %+v", checkpoint)
+	}
+
+	// A set of new validators cannot vouch for itself
+	untrusted := checkpointHeader(t, 200, addrs[4:7], keys[4:7])
+	if _, err := VerifyCheckpoint(testCheckpointRules, trusted, untrusted); err != ErrUntrustedCheckpoint {
+		t.Errorf("untrusted checkpoint: have %v, want %v", err, ErrUntrustedCheckpoint)
+	}
+	// Nor can a minority of its own validators commit it
+	minority := checkpointHeader(t, 200, addrs[:4], keys[:2])
+	if _, err := VerifyCheckpoint(testCheckpointRules, trusted, minority); err != ErrInvalidCommittedSeals {
+		t.Errorf("checkpoint without quorum: have %v, want %v", err, ErrInvalidCommittedSeals)
+	}
+	// The quorum is the one of the engine, two thirds of six validators are four
+	six := &Checkpoint{Number: 100, Validators: addrs[:6]}
+	if _, err := VerifyCheckpoint(testCheckpointRules, six, checkpointHeader(t, 200, addrs[:6], keys[:3])); err != ErrInvalidCommittedSeals {
+		t.Errorf("checkpoint with 3 of 6 seals: have %v, want %v", err, ErrInvalidCommittedSeals)
+	}
+	if _, err := VerifyCheckpoint(testCheckpointRules, six, checkpointHeader(t, 200, addrs[:6], keys[:4])); err != nil {
+		t.Errorf("checkpoint with 4 of 6 seals rejected: %v", err)
+	}
+	// The seals must sign the commit message of the engine
+	other := &CheckpointRules{CommitCode: 1, Quorum: testCheckpointRules.Quorum}
+	if _, err := VerifyCheckpoint(other, trusted, next); err != ErrInvalidCommittedSeals {
+		t.Errorf("checkpoint committed with another message code: have %v, want %v", err, ErrInvalidCommittedSeals)
+	}
+}
@@ -37,15 +37,16 @@ type SportExtra struct {
 	Fullnodes     []common.Address
 	Seal          []byte
 	CommittedSeal [][]byte
+	Checkpoint    common.Hash // Digest of the fullnodes in epoch checkpoint headers, zero in the others
 }
 
 // EncodeRLP serializes ist into the Ethereum RLP format.
 func (ist *SportExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	return rlp.Encode(w, appendCheckpoint([]interface{}{
 		ist.Fullnodes,
 		ist.Seal,
 		ist.CommittedSeal,
-	})
+	}, ist.Checkpoint))
 }
 
 // DecodeRLP implements rlp.Decoder, and load the sport fields from a RLP stream.
@@ -54,11 +55,16 @@ func (ist *SportExtra) DecodeRLP(s *rlp.Stream) error {
 		Fullnodes     []common.Address
 		Seal          []byte
 		CommittedSeal [][]byte
+		Checkpoint    []common.Hash `rlp:"tail"`
 	}
 	if err := s.Decode(&sportExtra); err != nil {
 		return err
 	}
-	ist.Fullnodes, ist.Seal, ist.CommittedSeal = sportExtra.Fullnodes, sportExtra.Seal, sportExtra.CommittedSeal
+	checkpoint, err := decodeCheckpoint(sportExtra.Checkpoint)
+	if err != nil {
+		return err
+	}
+	ist.Fullnodes, ist.Seal, ist.CommittedSeal, ist.Checkpoint = sportExtra.Fullnodes, sportExtra.Seal, sportExtra.CommittedSeal, checkpoint
 	return nil
 }
 
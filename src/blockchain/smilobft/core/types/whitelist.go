@@ -0,0 +1,132 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+var errWhitelistURL = errors.New("enode URL without node ID")
+
+// WhitelistEntry is an enode URL of the whitelist with the block at which it
+// was last added or changed.
+type WhitelistEntry struct {
+	URL   string
+	Block uint64
+}
+
+// Whitelist is the versioned set of the whitelisted enodes, keyed by enode ID.
+type Whitelist struct {
+	Block   uint64 // Block of the last change
+	Entries map[enode.ID]WhitelistEntry
+}
+
+// WhitelistDiff is the change of the whitelist at a block.
+type WhitelistDiff struct {
+	Block   uint64
+	Added   []string // URLs added or changed
+	Removed []string // URLs removed
+}
+
+// NewWhitelist returns the whitelist of urls, all changed at block.
+func NewWhitelist(urls []string, block uint64) *Whitelist {
+	w := &Whitelist{Block: block, Entries: make(map[enode.ID]WhitelistEntry)}
+	for _, rawurl := range urls {
+		if id, err := WhitelistID(rawurl); err == nil {
+			w.Entries[id] = WhitelistEntry{URL: rawurl, Block: block}
+		}
+	}
+	return w
+}
+
+// WhitelistID returns the enode ID of an enode URL, without resolving its host.
+func WhitelistID(rawurl string) (enode.ID, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return enode.ID{}, err
+	}
+	if u.User == nil {
+		return enode.ID{}, errWhitelistURL
+	}
+	node, err := enode.ParseV4("enode://" + u.User.String())
+	if err != nil {
+		return enode.ID{}, err
+	}
+	return node.ID(), nil
+}
+
+// URLs returns the whitelisted enode URLs, sorted.
+func (w *Whitelist) URLs() []string {
+	urls := make([]string, 0, len(w.Entries))
+	for _, entry := range w.Entries {
+		urls = append(urls, entry.URL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// Diff returns the change from w to the whitelist of urls at block. URLs that
+// are not valid enode URLs are ignored.
+func (w *Whitelist) Diff(urls []string, block uint64) *WhitelistDiff {
+	diff := &WhitelistDiff{Block: block}
+	next := make(map[enode.ID]bool, len(urls))
+	for _, rawurl := range urls {
+		id, err := WhitelistID(rawurl)
+		if err != nil || next[id] {
+			continue
+		}
+		next[id] = true
+		if entry, ok := w.Entries[id]; !ok || entry.URL != rawurl {
+			diff.Added = append(diff.Added, rawurl)
+		}
+	}
+	for id, entry := range w.Entries {
+		if !next[id] {
+			diff.Removed = append(diff.Removed, entry.URL)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// Apply updates w with diff.
+func (w *Whitelist) Apply(diff *WhitelistDiff) {
+	if diff.Empty() {
+		return
+	}
+	for _, rawurl := range diff.Removed {
+		if id, err := WhitelistID(rawurl); err == nil {
+			delete(w.Entries, id)
+		}
+	}
+	for _, rawurl := range diff.Added {
+		if id, err := WhitelistID(rawurl); err == nil {
+			w.Entries[id] = WhitelistEntry{URL: rawurl, Block: diff.Block}
+		}
+	}
+	w.Block = diff.Block
+}
+
+// Empty returns whether the diff changes nothing.
+func (d *WhitelistDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	whitelistNode1 = "enode://1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439@10.3.58.6:30303"
+	whitelistNode2 = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+	whitelistNode3 = "enode://3f1d12044546b76342d59d4a05532c14b85aa669704bfe1f864fe079415aa2c02d743e03218e57a33fb94523adb54032871a6c51b2cc5514cb7c7e35b3ed0a99@13.93.211.84:30303"
+)
+
+func TestWhitelistDiff(t *testing.T) {
+	w := NewWhitelist([]string{whitelistNode1, whitelistNode2}, 1)
+
+	// An unchanged list has an empty diff.
+	if diff := w.Diff([]string{whitelistNode2, whitelistNode1}, 2); !diff.Empty() {
+		t.Fatalf("unchanged whitelist diff: %+v", diff)
+	}
+
+	// A moved node is added again, a dropped one removed.
+	moved := "enode://1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439@10.3.58.7:30303"
+	diff := w.Diff([]string{moved, whitelistNode3, "enode://invalid"}, 3)
+	want := &WhitelistDiff{
+		Block:   3,
+		Added:   []string{moved, whitelistNode3},
+		Removed: []string{whitelistNode2},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("diff mismatch:\ngot  %+v\nwant %+v", diff, want)
+	}
+
+	w.Apply(diff)
+	if w.Block != 3 {
+		t.Errorf("block mismatch: got %d, want 3", w.Block)
+	}
+	if urls, want := w.URLs(), []string{moved, whitelistNode3}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("urls mismatch:\ngot  %v\nwant %v", urls, want)
+	}
+	id, _ := WhitelistID(whitelistNode3)
+	if entry := w.Entries[id]; entry.Block != 3 {
+		t.Errorf("entry block mismatch: got %d, want 3", entry.Block)
+	}
+}
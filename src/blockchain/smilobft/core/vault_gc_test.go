@@ -0,0 +1,177 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus/ethash"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+// importVaultChain imports n blocks into a new chain on db, the first one
+// deploying a vault storage contract storing 1, and each next one storing its
+// number in the contract.
+func importVaultChain(t *testing.T, db ethdb.Database, cacheConfig *CacheConfig, n int) ([]*types.Block, common.Address) {
+	v := mapVault{}
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	genesis := &Genesis{
+		Config: params.SmiloTestChainConfig,
+		Alloc:  GenesisAlloc{vaultRebuildAddr: {Balance: big.NewInt(params.Ether)}},
+	}
+	genesis.MustCommit(db)
+	engine := ethash.NewFaker()
+	bc, err := NewBlockChain(db, cacheConfig, genesis.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	contract := crypto.CreateAddress(vaultRebuildAddr, 0)
+	blocks := make([]*types.Block, n)
+	for i := range blocks {
+		value := common.BigToHash(big.NewInt(int64(i + 1))).Bytes()
+		var tx *types.Transaction
+		if i == 0 {
+			digest, _ := v.Post(append(common.CopyBytes(storageCode), value...), "", nil)
+			tx = types.NewContractCreation(0, new(big.Int), 1000000, new(big.Int), digest)
+		} else {
+			digest, _ := v.Post(append(common.FromHex("60fe47b1"), value...), "", nil)
+			tx = types.NewTransaction(uint64(i), contract, new(big.Int), 1000000, new(big.Int), digest)
+		}
+		tx, err := types.SignTx(tx, types.HomesteadSigner{}, vaultRebuildSender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.SetVault()
+
+		parent := bc.CurrentBlock()
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   parent.GasLimit(),
+			Time:       parent.Time() + 10,
+		}
+		header.Difficulty = engine.CalcDifficulty(bc, header.Time, parent.Header())
+
+		statedb, vaultState, err := bc.StateAt(parent.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+		vaultState.Prepare(tx.Hash(), common.Hash{}, 0)
+		receipt, _, _, err := ApplyTransaction(genesis.Config, bc, &header.Coinbase, new(GasPool).AddGas(header.GasLimit), statedb, vaultState, header, tx, &header.GasUsed, vm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := engine.Finalize(bc, header, statedb, types.Transactions{tx}, nil, []*types.Receipt{receipt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bc.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatal(err)
+		}
+		blocks[i] = block
+	}
+	return blocks, contract
+}
+
+// makeVaultGCChain imports blocks each changing the value of a vault
+// contract, and returns the database and the vault roots of the blocks once
+// the chain is stopped.
+func makeVaultGCChain(t *testing.T, cacheConfig *CacheConfig, n int) (ethdb.Database, []common.Hash, common.Address) {
+	db := rawdb.NewMemoryDatabase()
+	blocks, contract := importVaultChain(t, db, cacheConfig, n)
+
+	roots := make([]common.Hash, len(blocks))
+	for i, block := range blocks {
+		roots[i] = GetVaultStateRoot(db, block.Root())
+	}
+	return db, roots, contract
+}
+
+func countTrieNodes(db ethdb.Database) (count int) {
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == common.HashLength {
+			count++
+		}
+	}
+	return count
+}
+
+func TestVaultTrieGarbageCollection(t *testing.T) {
+	n := 2*TriesInMemory + 10
+
+	archiveDb, archiveRoots, _ := makeVaultGCChain(t, &CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyLimit:    256,
+		TrieTimeLimit:     5 * time.Minute,
+		TrieDirtyDisabled: true,
+	}, n)
+	for i, root := range archiveRoots {
+		if ok, _ := archiveDb.Has(root.Bytes()); !ok {
+			t.Fatalf("archive node: vault state of block %d missing", i+1)
+		}
+	}
+
+	fullDb, fullRoots, contract := makeVaultGCChain(t, nil, n)
+	var persisted int
+	for _, root := range fullRoots {
+		if ok, _ := fullDb.Has(root.Bytes()); ok {
+			persisted++
+		}
+	}
+	// Only the head, its parent and the state TriesInMemory blocks back are
+	// flushed at shutdown
+	if persisted != 3 {
+		t.Errorf("full node: persisted vault states mismatch: have %d, want 3", persisted)
+	}
+	if full, archive := countTrieNodes(fullDb), countTrieNodes(archiveDb); full*2 > archive {
+		t.Errorf("full node: trie nodes not garbage collected: have %d, archive node %d", full, archive)
+	}
+	// The vault state of the head survives a restart
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = mapVault{}
+
+	bc, err := NewBlockChain(fullDb, nil, params.SmiloTestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if head := bc.CurrentBlock().NumberU64(); head != uint64(n) {
+		t.Fatalf("head mismatch after restart: have %d, want %d", head, n)
+	}
+	if value := vaultStorage(t, bc, bc.CurrentBlock(), contract); value != common.BigToHash(big.NewInt(int64(n))) {
+		t.Errorf("vault storage mismatch after restart: have %x", value)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+var (
+	// ErrVaultRebuildRunning is returned if a vault state rebuild is already running.
+	ErrVaultRebuildRunning = errors.New("vault state rebuild already running")
+
+	errVaultRebuildNotSmilo = errors.New("vault state needs a Smilo chain")
+	errVaultRebuildOffline  = errors.New("vault state rebuild needs a running vault")
+)
+
+// VaultCheckpoint is a trusted vault state, known to be present in the local
+// database, a vault state rebuild starts from instead of the genesis.
+type VaultCheckpoint struct {
+	Number    uint64      // Number of the block of the checkpoint
+	VaultRoot common.Hash // Root of the vault state after the block
+}
+
+// VaultRebuildProgress is the progress of a vault state rebuild.
+type VaultRebuildProgress struct {
+	Current uint64 // Last block whose vault state is rebuilt
+	Highest uint64 // Head block the rebuild walks to
+	Active  bool   // Whether a rebuild is running
+}
+
+// SetVaultCheckpoint sets the trusted vault state the next rebuild starts from.
+func (bc *BlockChain) SetVaultCheckpoint(checkpoint *VaultCheckpoint) {
+	bc.vaultCheckpoint = checkpoint
+}
+
+// VaultRebuildProgress returns the progress of the vault state rebuild.
+func (bc *BlockChain) VaultRebuildProgress() VaultRebuildProgress {
+	return VaultRebuildProgress{
+		Current: atomic.LoadUint64(&bc.vaultRebuildCurrent),
+		Highest: atomic.LoadUint64(&bc.vaultRebuildHighest),
+		Active:  atomic.LoadInt32(&bc.vaultRebuilding) == 1,
+	}
+}
+
+// RebuildVaultState starts to rebuild in the background the vault state of
+// the canonical chain, which fast sync can't download from peers. It walks
+// the chain from the genesis, or from the vault checkpoint, and re-executes
+// the blocks with the payloads of the local vault, writing the vault state
+// root of every block. The rebuild is resumed at the next start if the node
+// is stopped before it ends.
+//
+// A block is replayed on the public state of its parent. If it is not
+// available, as for the blocks before the fast sync pivot, only the vault
+// transactions are replayed, on the public state of the head block, and the
+// vault state may differ from the one of the nodes which executed the block.
+func (bc *BlockChain) RebuildVaultState() error {
+	if !bc.chainConfig.IsSmilo {
+		return errVaultRebuildNotSmilo
+	}
+	if vault.VaultInstance == nil {
+		return errVaultRebuildOffline
+	}
+	if !atomic.CompareAndSwapInt32(&bc.vaultRebuilding, 0, 1) {
+		return ErrVaultRebuildRunning
+	}
+	next, ok := ReadVaultRebuildMarker(bc.db)
+	if !ok {
+		var err error
+		if next, err = bc.startVaultRebuild(); err != nil {
+			atomic.StoreInt32(&bc.vaultRebuilding, 0)
+			return err
+		}
+	} else {
+		log.Info("Resuming vault state rebuild", "block", next)
+	}
+	atomic.StoreUint64(&bc.vaultRebuildCurrent, next-1)
+	atomic.StoreUint64(&bc.vaultRebuildHighest, bc.CurrentBlock().NumberU64())
+
+	bc.wg.Add(1)
+	go bc.rebuildVaultState(next)
+	return nil
+}
+
+// startVaultRebuild writes the marker of a new rebuild and returns its first
+// block.
+func (bc *BlockChain) startVaultRebuild() (uint64, error) {
+	next := uint64(1)
+	if checkpoint := bc.vaultCheckpoint; checkpoint != nil {
+		block := bc.GetBlockByNumber(checkpoint.Number)
+		if block == nil {
+			return 0, fmt.Errorf("unknown vault checkpoint block %d", checkpoint.Number)
+		}
+		if _, err := state.New(checkpoint.VaultRoot, bc.vaultStateCache); err != nil {
+			return 0, fmt.Errorf("missing vault checkpoint state %x: %v", checkpoint.VaultRoot, err)
+		}
+		if err := WriteVaultStateRoot(bc.db, block.Root(), checkpoint.VaultRoot); err != nil {
+			return 0, err
+		}
+		next = checkpoint.Number + 1
+	}
+	log.Info("Starting vault state rebuild", "block", next)
+	return next, WriteVaultRebuildMarker(bc.db, next)
+}
+
+// rebuildVaultState rebuilds the vault state from the next block up to the
+// head. The blocks are rebuilt without the insertion lock, which is only held
+// to end the rebuild, so that the rebuild ends with a head whose vault state is
+// complete, and the blocks imported later execute on top of it.
+//
+// The vault state root recorded for a block is checked against the rebuilt one
+// when the block was executed on the vault state of its parent, which is known
+// when the root recorded for the parent matches the rebuilt one.
+func (bc *BlockChain) rebuildVaultState(next uint64) {
+	defer bc.wg.Done()
+	defer atomic.StoreInt32(&bc.vaultRebuilding, 0)
+
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		first    = next
+		verified = true // Whether the recorded root of the parent block was rebuilt
+		inexact  uint64 // Number of blocks replayed on the public state of the head
+	)
+	for {
+		select {
+		case <-bc.quit:
+			log.Info("Interrupted vault state rebuild", "block", next)
+			return
+		default:
+		}
+		head := bc.CurrentBlock().NumberU64()
+		atomic.StoreUint64(&bc.vaultRebuildHighest, head)
+		if next > head {
+			bc.chainmu.Lock()
+			if head = bc.CurrentBlock().NumberU64(); next <= head {
+				bc.chainmu.Unlock()
+				continue
+			}
+			err := DeleteVaultRebuildMarker(bc.db)
+			bc.chainmu.Unlock()
+			if err != nil {
+				log.Error("Failed to delete vault rebuild marker", "err", err)
+			}
+			if inexact > 0 {
+				log.Warn("Rebuilt vault state without the public state of some blocks, it may differ from the other nodes", "blocks", inexact)
+			}
+			log.Info("Rebuilt vault state", "blocks", next-first, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			return
+		}
+		block := bc.GetBlockByNumber(next)
+		if block == nil {
+			log.Error("Failed to rebuild vault state", "block", next, "err", "missing canonical block")
+			return
+		}
+		var want common.Hash
+		recorded := GetVaultStateRoot(bc.db, block.Root())
+		if verified {
+			want = recorded
+		}
+		root, exact, err := bc.rebuildVaultBlock(block, want)
+		if err == nil {
+			err = WriteVaultRebuildMarker(bc.db, next+1)
+		}
+		if err != nil {
+			log.Error("Failed to rebuild vault state", "block", next, "err", err)
+			return
+		}
+		verified = recorded == root
+		if !exact {
+			if inexact == 0 {
+				log.Warn("Public state of the block unavailable, replaying its vault transactions on the head state", "block", next)
+			}
+			inexact++
+		}
+		atomic.StoreUint64(&bc.vaultRebuildCurrent, next)
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Rebuilding vault state", "block", next, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		next++
+	}
+}
+
+// rebuildVaultBlock executes block on the vault state of its parent, and
+// stores the resulting vault state root, receipts and bloom. The root is
+// checked against want unless it is zero. It returns the root, and whether
+// the block was replayed on the public state of its parent.
+func (bc *BlockChain) rebuildVaultBlock(block *types.Block, want common.Hash) (common.Hash, bool, error) {
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return common.Hash{}, false, consensus.ErrUnknownAncestor
+	}
+	vaultRoot := GetVaultStateRoot(bc.db, parent.Root())
+
+	var txs []int
+	for i, tx := range block.Transactions() {
+		if tx.IsVault() {
+			txs = append(txs, i)
+		}
+	}
+	if len(txs) == 0 {
+		if want != (common.Hash{}) && want != vaultRoot {
+			return common.Hash{}, true, fmt.Errorf("vault state root mismatch: have %x, want %x", vaultRoot, want)
+		}
+		return vaultRoot, true, WriteVaultStateRoot(bc.db, block.Root(), vaultRoot)
+	}
+	vaultState, err := state.New(vaultRoot, bc.vaultStateCache)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	var vaultReceipts types.Receipts
+	publicState, err := state.New(parent.Root(), bc.stateCache)
+	exact := err == nil
+	if exact {
+		if _, vaultReceipts, _, _, err = bc.processor.Process(block, publicState, vaultState, bc.vmConfig); err != nil {
+			return common.Hash{}, true, err
+		}
+	} else if vaultReceipts, err = bc.replayVaultTransactions(block, txs, vaultState); err != nil {
+		return common.Hash{}, false, err
+	}
+	root, err := vaultState.Commit(bc.chainConfig.IsEIP158(block.Number()))
+	if err != nil {
+		return common.Hash{}, exact, err
+	}
+	if want != (common.Hash{}) && want != root {
+		return common.Hash{}, exact, fmt.Errorf("vault state root mismatch: have %x, want %x", root, want)
+	}
+	if err := bc.vaultStateCache.TrieDB().Commit(root, false); err != nil {
+		return common.Hash{}, exact, err
+	}
+	if err := WriteVaultStateRoot(bc.db, block.Root(), root); err != nil {
+		return common.Hash{}, exact, err
+	}
+	// The receipts moved to the freezer can't be rewritten
+	if frozen, _ := bc.db.Ancients(); block.NumberU64() >= frozen {
+		if receipts := rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64()); receipts != nil {
+			rawdb.WriteReceipts(bc.db, block.Hash(), block.NumberU64(), mergeReceipts(receipts, vaultReceipts))
+			bc.receiptsCache.Remove(block.Hash())
+		}
+	}
+	return root, exact, WriteVaultBlockBloom(bc.db, block.NumberU64(), vaultReceipts)
+}
+
+// replayVaultTransactions executes the vault transactions txs of block on
+// vaultState and on the public state of the head block, for the blocks whose
+// parent public state is unavailable.
+func (bc *BlockChain) replayVaultTransactions(block *types.Block, txs []int, vaultState *state.StateDB) (types.Receipts, error) {
+	publicState, err := state.New(bc.CurrentBlock().Root(), bc.stateCache)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		header        = block.Header()
+		signer        = types.MakeSigner(bc.chainConfig, header.Number)
+		gp            = new(GasPool).AddGas(block.GasLimit())
+		usedGas       = new(uint64)
+		vaultReceipts types.Receipts
+	)
+	for _, i := range txs {
+		tx := block.Transactions()[i]
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		// The public state isn't the one the transaction was sent on, align
+		// the nonce it was sent with.
+		publicState.SetNonce(from, tx.Nonce())
+		publicState.Prepare(tx.Hash(), block.Hash(), i)
+		vaultState.Prepare(tx.Hash(), block.Hash(), i)
+
+		_, vaultReceipt, _, err := ApplyTransaction(bc.chainConfig, bc, nil, gp, publicState, vaultState, header, tx, usedGas, bc.vmConfig)
+		if err != nil {
+			return nil, fmt.Errorf("vault transaction %x: %v", tx.Hash(), err)
+		}
+		vaultReceipts = append(vaultReceipts, vaultReceipt)
+	}
+	return vaultReceipts, nil
+}
@@ -0,0 +1,328 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/consensus/ethash"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/vault"
+)
+
+// mapVault is a vault keeping the payloads in memory, under their hash. It
+// is a non party vault if the payloads are missing.
+type mapVault map[common.Hash][]byte
+
+func (v mapVault) Post(data []byte, from string, to []string) ([]byte, error) {
+	hash := crypto.Keccak256Hash(data)
+	v[hash] = data
+	return hash.Bytes(), nil
+}
+
+func (v mapVault) PostRawTransaction(data []byte, to []string) ([]byte, error) {
+	return v.Post(data, "", to)
+}
+
+func (v mapVault) Get(data []byte) ([]byte, error) {
+	payload, ok := v[common.BytesToHash(data)]
+	if !ok {
+		return nil, errors.New("not a party of the transaction")
+	}
+	return payload, nil
+}
+
+var (
+	// storageCode stores its constructor argument, returns it when called
+	// without arguments and stores the first argument otherwise.
+	storageCode = common.FromHex("602060203803600039600051600055601a80601a6000396000f3" + "6004361160125760005460005260206000f35b60043560005500")
+
+	vaultRebuildSender, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	vaultRebuildAddr      = crypto.PubkeyToAddress(vaultRebuildSender.PublicKey)
+)
+
+// makeVaultRebuildChain returns a chain deploying a vault storage contract
+// storing 42 in block 1, setting it to 7 in block 3, with public transfers
+// in between.
+func makeVaultRebuildChain(t *testing.T, v mapVault) (*Genesis, []*types.Block, common.Address) {
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	genesis := &Genesis{
+		Config: params.SmiloTestChainConfig,
+		Alloc:  GenesisAlloc{vaultRebuildAddr: {Balance: big.NewInt(params.Ether)}},
+	}
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	engine := ethash.NewFaker()
+	bc, err := NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	signer := types.HomesteadSigner{}
+	send := func(tx *types.Transaction, vaultTx bool) *types.Transaction {
+		tx, err := types.SignTx(tx, signer, vaultRebuildSender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vaultTx {
+			tx.SetVault()
+		}
+		return tx
+	}
+	post := func(payload []byte) []byte {
+		digest, _ := v.Post(payload, "", nil)
+		return digest
+	}
+	contract := crypto.CreateAddress(vaultRebuildAddr, 0)
+	txs := [][]*types.Transaction{
+		{send(types.NewContractCreation(0, new(big.Int), 1000000, new(big.Int), post(append(storageCode, common.LeftPadBytes([]byte{42}, 32)...))), true)},
+		{send(types.NewTransaction(1, common.Address{1}, big.NewInt(1000), 21000, new(big.Int), nil), false)},
+		{
+			send(types.NewTransaction(2, common.Address{2}, big.NewInt(1000), 21000, new(big.Int), nil), false),
+			send(types.NewTransaction(3, contract, new(big.Int), 1000000, new(big.Int), post(append(common.FromHex("60fe47b1"), common.LeftPadBytes([]byte{7}, 32)...))), true),
+		},
+		nil,
+	}
+	var blocks []*types.Block
+	for _, blockTxs := range txs {
+		parent := bc.CurrentBlock()
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   parent.GasLimit(),
+			Time:       parent.Time() + 10,
+		}
+		header.Difficulty = engine.CalcDifficulty(bc, header.Time, parent.Header())
+
+		statedb, vaultState, err := bc.StateAt(parent.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		gp := new(GasPool).AddGas(header.GasLimit)
+		var receipts []*types.Receipt
+		for i, tx := range blockTxs {
+			statedb.Prepare(tx.Hash(), common.Hash{}, i)
+			vaultState.Prepare(tx.Hash(), common.Hash{}, i)
+			receipt, _, _, err := ApplyTransaction(genesis.Config, bc, &header.Coinbase, gp, statedb, vaultState, header, tx, &header.GasUsed, vm.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			receipts = append(receipts, receipt)
+		}
+		block, err := engine.Finalize(bc, header, statedb, blockTxs, nil, receipts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bc.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, block)
+	}
+	return genesis, blocks, contract
+}
+
+// newVaultRebuildChain imports the blocks into a new chain and drops their
+// vault state roots, as fast sync leaves them.
+func newVaultRebuildChain(t *testing.T, genesis *Genesis, blocks []*types.Block, v mapVault) (ethdb.Database, *BlockChain) {
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	bc, err := NewBlockChain(db, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if err := db.Delete(append(vaultRootPrefix, block.Root().Bytes()...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, bc
+}
+
+func waitVaultRebuild(t *testing.T, bc *BlockChain) {
+	for i := 0; i < 500 && bc.VaultRebuildProgress().Active; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bc.VaultRebuildProgress().Active {
+		t.Fatal("vault state rebuild didn't end")
+	}
+}
+
+func vaultStorage(t *testing.T, bc *BlockChain, block *types.Block, contract common.Address) common.Hash {
+	_, vaultState, err := bc.StateAt(block.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vaultState.GetState(contract, common.Hash{})
+}
+
+func TestRebuildVaultState(t *testing.T) {
+	v := mapVault{}
+	genesis, blocks, contract := makeVaultRebuildChain(t, v)
+
+	db, bc := newVaultRebuildChain(t, genesis, blocks, v)
+	defer bc.Stop()
+	if value := vaultStorage(t, bc, blocks[0], contract); value != (common.Hash{}) {
+		t.Fatalf("vault state before the rebuild: have %x, want empty", value)
+	}
+
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	if err := bc.RebuildVaultState(); err != nil {
+		t.Fatal(err)
+	}
+	waitVaultRebuild(t, bc)
+
+	for i, want := range []byte{42, 42, 7, 7} {
+		if value := vaultStorage(t, bc, blocks[i], contract); value != common.BytesToHash([]byte{want}) {
+			t.Errorf("block %d: vault storage mismatch: have %x, want %x", i+1, value, want)
+		}
+	}
+	if progress := bc.VaultRebuildProgress(); progress.Current != 4 || progress.Highest != 4 {
+		t.Errorf("progress mismatch: have %d/%d, want 4/4", progress.Current, progress.Highest)
+	}
+	if _, ok := ReadVaultRebuildMarker(db); ok {
+		t.Error("rebuild marker left after the rebuild")
+	}
+}
+
+func TestRebuildVaultStateResume(t *testing.T) {
+	v := mapVault{}
+	genesis, blocks, contract := makeVaultRebuildChain(t, v)
+
+	db, bc := newVaultRebuildChain(t, genesis, blocks, v)
+
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	// Rebuild the first block, as if the node stopped right after it
+	if _, _, err := bc.rebuildVaultBlock(blocks[0], common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVaultRebuildMarker(db, 2); err != nil {
+		t.Fatal(err)
+	}
+	bc.Stop()
+
+	bc, err := NewBlockChain(db, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	waitVaultRebuild(t, bc)
+
+	if value := vaultStorage(t, bc, blocks[3], contract); value != common.BytesToHash([]byte{7}) {
+		t.Errorf("vault storage mismatch after the resumed rebuild: have %x, want 7", value)
+	}
+	if _, ok := ReadVaultRebuildMarker(db); ok {
+		t.Error("rebuild marker left after the rebuild")
+	}
+}
+
+func TestRebuildVaultStateCheckpoint(t *testing.T) {
+	v := mapVault{}
+	genesis, blocks, contract := makeVaultRebuildChain(t, v)
+
+	db, bc := newVaultRebuildChain(t, genesis, blocks, v)
+	defer bc.Stop()
+
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	// A checkpoint missing from the database is refused
+	bc.SetVaultCheckpoint(&VaultCheckpoint{Number: 2, VaultRoot: common.Hash{1}})
+	if err := bc.RebuildVaultState(); err == nil {
+		t.Fatal("rebuild started from a missing checkpoint")
+	}
+	// Store the vault state of block 2 with the value 42 as the checkpoint
+	vaultState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	vaultState.SetCode(contract, common.FromHex("6004361160125760005460005260206000f35b60043560005500"))
+	vaultState.SetState(contract, common.Hash{}, common.BytesToHash([]byte{42}))
+	root, _ := vaultState.Commit(false)
+	if err := vaultState.Database().TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	bc.SetVaultCheckpoint(&VaultCheckpoint{Number: 2, VaultRoot: root})
+	if err := bc.RebuildVaultState(); err != nil {
+		t.Fatal(err)
+	}
+	waitVaultRebuild(t, bc)
+
+	if value := vaultStorage(t, bc, blocks[0], contract); value != (common.Hash{}) {
+		t.Errorf("vault state before the checkpoint rebuilt: have %x, want empty", value)
+	}
+	if value := vaultStorage(t, bc, blocks[3], contract); value != common.BytesToHash([]byte{7}) {
+		t.Errorf("vault storage mismatch: have %x, want 7", value)
+	}
+}
+
+func TestRebuildVaultStateMismatch(t *testing.T) {
+	v := mapVault{}
+	genesis, blocks, _ := makeVaultRebuildChain(t, v)
+
+	db, bc := newVaultRebuildChain(t, genesis, blocks, v)
+	defer bc.Stop()
+
+	saved := vault.VaultInstance
+	defer func() { vault.VaultInstance = saved }()
+	vault.VaultInstance = v
+
+	// The first block executed on the genesis vault state, its recorded root
+	// is checked
+	if err := WriteVaultStateRoot(db, blocks[0].Root(), common.Hash{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.RebuildVaultState(); err != nil {
+		t.Fatal(err)
+	}
+	waitVaultRebuild(t, bc)
+
+	if progress := bc.VaultRebuildProgress(); progress.Current != 0 {
+		t.Errorf("progress mismatch: have %d, want 0", progress.Current)
+	}
+	if root := GetVaultStateRoot(db, blocks[0].Root()); root != (common.Hash{1}) {
+		t.Errorf("mismatching vault state root overwritten: %x", root)
+	}
+	if next, ok := ReadVaultRebuildMarker(db); !ok || next != 1 {
+		t.Errorf("rebuild marker mismatch: have %d (%v), want 1", next, ok)
+	}
+}
@@ -47,3 +47,26 @@ func GetVaultBlockBloom(db ethdb.Database, number uint64) (bloom types.Bloom) {
 	}
 	return bloom
 }
+
+// vaultRebuildKey tracks the next block of an interrupted vault state rebuild.
+var vaultRebuildKey = []byte("vaultRebuild")
+
+// ReadVaultRebuildMarker retrieves the next block of an unfinished vault
+// state rebuild, if any.
+func ReadVaultRebuildMarker(db ethdb.Database) (uint64, bool) {
+	data, _ := db.Get(vaultRebuildKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteVaultRebuildMarker stores the next block of the vault state rebuild.
+func WriteVaultRebuildMarker(db ethdb.Database, number uint64) error {
+	return db.Put(vaultRebuildKey, encodeBlockNumber(number))
+}
+
+// DeleteVaultRebuildMarker removes the marker of a finished vault state rebuild.
+func DeleteVaultRebuildMarker(db ethdb.Database) error {
+	return db.Delete(vaultRebuildKey)
+}
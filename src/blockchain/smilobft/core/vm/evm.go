@@ -468,7 +468,7 @@ func (c *codeAndHash) Hash() common.Hash {
 }
 
 // create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address, isVault bool) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address, isVault bool, incrementNonce bool) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -501,8 +501,10 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		isVault = isVaultOnDB
 	}
 
-	nonce := creatorStateDb.GetNonce(caller.Address())
-	creatorStateDb.SetNonce(caller.Address(), nonce+1)
+	if incrementNonce {
+		nonce := creatorStateDb.GetNonce(caller.Address())
+		creatorStateDb.SetNonce(caller.Address(), nonce+1)
+	}
 
 	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.StateDB.GetCodeHash(address)
@@ -593,7 +595,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 		creatorStateDb = evm.publicState
 	}
 	contractAddr = crypto.CreateAddress(caller.Address(), creatorStateDb.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr, isVault)
+	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr, isVault, true)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -603,7 +605,14 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int, isVault bool) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, isVault)
+	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, isVault, true)
+}
+
+// CreateAt creates a new contract using code as deployment code at the given
+// address, without incrementing the nonce of the caller. It deploys the system
+// contracts at their predetermined addresses.
+func (evm *EVM) CreateAt(caller ContractRef, code []byte, gas uint64, value *big.Int, address common.Address) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	return evm.create(caller, &codeAndHash{code: code}, gas, value, address, false, false)
 }
 
 // ChainConfig returns the environment's chain configuration
@@ -0,0 +1,48 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "go-smilo/src/blockchain/smilobft/params"
+
+// OpInfo is the effect of an instruction, as defined by the jump table, for
+// static analysis of the code.
+type OpInfo struct {
+	Valid   bool // Whether the opcode is defined
+	Pops    int  // Stack items taken
+	Pushes  int  // Stack items added
+	Halts   bool // Whether the instruction ends the execution
+	Jumps   bool // Whether the instruction sets the program counter
+	Writes  bool // Whether the instruction modifies the state
+	Reverts bool // Whether the instruction reverts the state
+}
+
+// InstructionInfo returns the effect of op in the latest instruction set.
+func InstructionInfo(op OpCode) OpInfo {
+	operation := constantinopleInstructionSet[op]
+	if !operation.valid {
+		return OpInfo{}
+	}
+	return OpInfo{
+		Valid:   true,
+		Pops:    operation.minStack,
+		Pushes:  operation.minStack + int(params.StackLimit) - operation.maxStack,
+		Halts:   operation.halts,
+		Jumps:   operation.jumps,
+		Writes:  operation.writes,
+		Reverts: operation.reverts,
+	}
+}
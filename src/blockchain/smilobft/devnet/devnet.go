@@ -0,0 +1,285 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package devnet generates and runs local networks of BFT validators, for
+// development and testing.
+package devnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/p2p"
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+const (
+	manifestFile      = "devnet.json"
+	genesisFile       = "genesis.json"
+	instanceDir       = "geth" // Instance directory of the nodes in their data directory
+	staticNodesFile   = "static-nodes.json"
+	blackboxConfig    = "blackbox.conf"
+	blackboxSocket    = "blackbox.ipc"
+	defaultValidators = 4
+)
+
+// Consensus engines of the devnets.
+const (
+	Sport      = "sport"
+	SportDAO   = "sportdao"
+	Istanbul   = "istanbul"
+	Tendermint = "tendermint"
+)
+
+// Engines are the consensus engines a devnet can run.
+var Engines = []string{Sport, SportDAO, Istanbul, Tendermint}
+
+var (
+	// ErrExists is returned if the directory already holds a devnet.
+	ErrExists = errors.New("devnet already exists, reset or tear it down first")
+	// ErrNotEmpty is returned if the directory of a new devnet holds other files,
+	// which a teardown of the devnet would delete.
+	ErrNotEmpty = errors.New("devnet directory is not empty")
+	// ErrNotFound is returned if the directory doesn't hold a devnet.
+	ErrNotFound = errors.New("no devnet found")
+)
+
+// Config are the parameters of a devnet.
+type Config struct {
+	Engine       string `json:"engine"`
+	Validators   int    `json:"validators"`
+	ChainID      uint64 `json:"chainId"`
+	Port         int    `json:"port"`    // P2P port of the first node, the next ones following
+	RPCPort      int    `json:"rpcPort"` // HTTP RPC port of the first node, the next ones following
+	Vault        bool   `json:"vault"`   // Whether the nodes use stand-in vaults
+	Permissioned bool   `json:"permissioned"`
+}
+
+// DefaultConfig is a devnet of four Tendermint validators.
+var DefaultConfig = Config{
+	Engine:     Tendermint,
+	Validators: defaultValidators,
+	ChainID:    20190101,
+	Port:       30310,
+	RPCPort:    22000,
+}
+
+// Node is a validator of a devnet.
+type Node struct {
+	Name    string         `json:"name"`
+	NodeKey string         `json:"nodeKey"` // Hex node key, also the key of the validator account
+	Address common.Address `json:"address"`
+	Enode   string         `json:"enode"`
+	Port    int            `json:"port"`
+	RPCPort int            `json:"rpcPort"`
+}
+
+// Network is a devnet, described by the manifest of its directory.
+type Network struct {
+	Config Config  `json:"config"`
+	Nodes  []*Node `json:"nodes"`
+
+	dir string
+}
+
+// Generate creates a devnet in dir: the keys of the validators, the genesis,
+// and the data directory of each node with its static and permissioned nodes
+// and the configuration of its vault. The directory must be missing or empty,
+// as Teardown deletes it.
+func Generate(dir string, config Config) (*Network, error) {
+	if err := checkConfig(config); err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+		return nil, ErrExists
+	}
+	if files, err := ioutil.ReadDir(dir); err == nil && len(files) > 0 {
+		return nil, ErrNotEmpty
+	}
+	network := &Network{Config: config, dir: dir}
+	for i := 0; i < config.Validators; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		port := config.Port + i
+		network.Nodes = append(network.Nodes, &Node{
+			Name:    fmt.Sprintf("node%d", i),
+			NodeKey: fmt.Sprintf("%x", crypto.FromECDSA(key)),
+			Address: crypto.PubkeyToAddress(key.PublicKey),
+			Enode:   enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), port, port).URLv4(),
+			Port:    port,
+			RPCPort: config.RPCPort + i,
+		})
+	}
+	genesis, err := network.makeGenesis()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(filepath.Join(dir, genesisFile), genesis); err != nil {
+		return nil, err
+	}
+	enodes := network.enodes()
+	for _, n := range network.Nodes {
+		datadir := network.DataDir(n)
+		if err := os.MkdirAll(filepath.Join(datadir, instanceDir), 0700); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(datadir, instanceDir, "nodekey"), []byte(n.NodeKey), 0600); err != nil {
+			return nil, err
+		}
+		if err := writeJSON(filepath.Join(datadir, instanceDir, staticNodesFile), enodes); err != nil {
+			return nil, err
+		}
+		if err := writeJSON(filepath.Join(datadir, p2p.PERMISSIONED_CONFIG), enodes); err != nil {
+			return nil, err
+		}
+		if config.Vault {
+			conf := fmt.Sprintf("socket = %q\nworkdir = %q\n", blackboxSocket, datadir)
+			if err := ioutil.WriteFile(filepath.Join(datadir, blackboxConfig), []byte(conf), 0600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, manifestFile), network); err != nil {
+		return nil, err
+	}
+	log.Info("Generated devnet", "dir", dir, "engine", config.Engine, "validators", config.Validators)
+	return network, nil
+}
+
+func checkConfig(config Config) error {
+	known := false
+	for _, engine := range Engines {
+		known = known || engine == config.Engine
+	}
+	if !known {
+		return fmt.Errorf("unknown engine %q", config.Engine)
+	}
+	if config.Validators < 1 {
+		return fmt.Errorf("invalid number of validators %d", config.Validators)
+	}
+	if config.ChainID <= 1 {
+		return fmt.Errorf("invalid chain id %d", config.ChainID)
+	}
+	return nil
+}
+
+// Load returns the devnet of dir.
+func Load(dir string) (*Network, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := ioutil.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	network := &Network{dir: dir}
+	if err := json.Unmarshal(blob, network); err != nil {
+		return nil, fmt.Errorf("invalid devnet manifest: %v", err)
+	}
+	return network, nil
+}
+
+// Dir returns the directory of the devnet.
+func (n *Network) Dir() string {
+	return n.dir
+}
+
+// GenesisPath returns the path of the genesis of the devnet.
+func (n *Network) GenesisPath() string {
+	return filepath.Join(n.dir, genesisFile)
+}
+
+// DataDir returns the data directory of the node.
+func (n *Network) DataDir(node *Node) string {
+	return filepath.Join(n.dir, node.Name)
+}
+
+// VaultConfig returns the path of the vault configuration of the node, empty
+// if the devnet runs without vaults.
+func (n *Network) VaultConfig(node *Node) string {
+	if !n.Config.Vault {
+		return ""
+	}
+	return filepath.Join(n.DataDir(node), blackboxConfig)
+}
+
+// vaultSocket returns the path of the vault socket of the node.
+func (n *Network) vaultSocket(node *Node) string {
+	return filepath.Join(n.DataDir(node), blackboxSocket)
+}
+
+func (n *Network) enodes() []string {
+	enodes := make([]string, len(n.Nodes))
+	for i, node := range n.Nodes {
+		enodes[i] = node.Enode
+	}
+	return enodes
+}
+
+// Reset drops the chain of every node, keeping the keys and the genesis, so
+// the devnet restarts from its genesis block.
+func (n *Network) Reset() error {
+	for _, node := range n.Nodes {
+		datadir := n.DataDir(node)
+		for _, name := range []string{"chaindata", "lightchaindata", "nodes", "transactions.rlp", "triecache"} {
+			if err := os.RemoveAll(filepath.Join(datadir, instanceDir, name)); err != nil {
+				return err
+			}
+		}
+		os.Remove(n.vaultSocket(node))
+	}
+	log.Info("Reset devnet", "dir", n.dir)
+	return nil
+}
+
+// Teardown deletes the devnet and its directory.
+func Teardown(dir string) error {
+	network, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	log.Info("Tearing down devnet", "dir", network.dir)
+	return os.RemoveAll(network.dir)
+}
+
+func writeJSON(path string, v interface{}) error {
+	blob, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, blob, 0600)
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package devnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/types"
+)
+
+// Tests that the genesis of every engine commits with the validators of the
+// devnet in its extra-data.
+func TestGenerate(t *testing.T) {
+	for _, engine := range Engines {
+		dir, err := ioutil.TempDir("", "devnet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		config := DefaultConfig
+		config.Engine, config.Validators, config.Vault = engine, 3, true
+		network, err := Generate(dir, config)
+		if err != nil {
+			t.Fatalf("%s: failed to generate devnet: %v", engine, err)
+		}
+		if _, err := Generate(dir, config); err != ErrExists {
+			t.Errorf("%s: regenerate error mismatch: have %v, want %v", engine, err, ErrExists)
+		}
+		loaded, err := Load(dir)
+		if err != nil {
+			t.Fatalf("%s: failed to load devnet: %v", engine, err)
+		}
+		if !reflect.DeepEqual(loaded.Nodes, network.Nodes) {
+			t.Errorf("%s: loaded nodes mismatch: have %v, want %v", engine, loaded.Nodes, network.Nodes)
+		}
+		genesis, err := loaded.loadGenesis()
+		if err != nil {
+			t.Fatalf("%s: failed to load genesis: %v", engine, err)
+		}
+		block, err := genesis.Commit(rawdb.NewMemoryDatabase())
+		if err != nil {
+			t.Fatalf("%s: failed to commit genesis: %v", engine, err)
+		}
+		extra, err := types.ExtractBFTHeaderExtra(block.Header())
+		if err != nil {
+			t.Fatalf("%s: failed to decode extra-data: %v", engine, err)
+		}
+		want := make([]common.Address, len(network.Nodes))
+		for i, node := range network.Nodes {
+			want[i] = node.Address
+		}
+		if !reflect.DeepEqual(extra.Validators, want) {
+			t.Errorf("%s: validators mismatch: have %v, want %v", engine, extra.Validators, want)
+		}
+		for _, node := range network.Nodes {
+			for _, file := range []string{"geth/nodekey", "geth/static-nodes.json", "permissioned-nodes.json", "blackbox.conf"} {
+				if _, err := os.Stat(filepath.Join(network.DataDir(node), file)); err != nil {
+					t.Errorf("%s: missing %s of %s: %v", engine, file, node.Name, err)
+				}
+			}
+		}
+	}
+}
+
+// Tests that a reset drops the chains but keeps the keys, and that a teardown
+// only deletes devnets.
+func TestResetTeardown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	network, err := Generate(dir, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaindata := filepath.Join(network.DataDir(network.Nodes[0]), instanceDir, "chaindata")
+	if err := os.MkdirAll(chaindata, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := network.Reset(); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	if _, err := os.Stat(chaindata); !os.IsNotExist(err) {
+		t.Errorf("chain kept after reset: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(network.DataDir(network.Nodes[0]), instanceDir, "nodekey")); err != nil {
+		t.Errorf("node key dropped by reset: %v", err)
+	}
+	if _, err := Generate(filepath.Join(dir, "node0"), DefaultConfig); err != ErrNotEmpty {
+		t.Errorf("generate in a non-empty directory: have %v, want %v", err, ErrNotEmpty)
+	}
+	if err := Teardown(filepath.Join(dir, "node0")); err != ErrNotFound {
+		t.Errorf("teardown error mismatch: have %v, want %v", err, ErrNotFound)
+	}
+	if err := Teardown(dir); err != nil {
+		t.Fatalf("failed to tear down: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("devnet kept after teardown: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package devnet
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+const (
+	epoch          = 30000
+	blockPeriod    = 1     // Seconds between the blocks of Istanbul and Tendermint
+	requestTimeout = 10000 // Milliseconds of a round of Istanbul and Tendermint
+	minFunds       = 1     // Funds of a Sport fullnode to seal
+	stake          = 100   // Stake of the validators in the Autonity contract
+)
+
+// validatorFunds is the genesis balance of the validator accounts.
+var validatorFunds = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
+
+// makeGenesis returns the genesis of the devnet, all forks enabled from the
+// first block and the validators funded.
+func (n *Network) makeGenesis() (*core.Genesis, error) {
+	config := &params.ChainConfig{
+		ChainID:                    new(big.Int).SetUint64(n.Config.ChainID),
+		HomesteadBlock:             big.NewInt(0),
+		EIP150Block:                big.NewInt(0),
+		EIP155Block:                big.NewInt(0),
+		EIP158Block:                big.NewInt(0),
+		ByzantiumBlock:             big.NewInt(0),
+		ConstantinopleBlock:        big.NewInt(0),
+		PetersburgBlock:            big.NewInt(0),
+		IsSmilo:                    true,
+		IsGas:                      true,
+		IsGasRefunded:              true,
+		RequiredMinFunds:           1,
+		CustomTransactionSizeLimit: 32,
+	}
+	// We have to use '1' to have TD == BlockNumber for BFT consensus
+	genesis := &core.Genesis{
+		Config:     config,
+		Timestamp:  uint64(time.Now().Unix()),
+		GasLimit:   params.GenesisGasLimit,
+		Difficulty: big.NewInt(1),
+		Mixhash:    types.BFTDigest,
+		Alloc:      make(core.GenesisAlloc),
+	}
+	validators := make([]common.Address, len(n.Nodes))
+	for i, node := range n.Nodes {
+		validators[i] = node.Address
+		genesis.Alloc[node.Address] = core.GenesisAccount{Balance: validatorFunds}
+	}
+
+	switch n.Config.Engine {
+	case Sport:
+		genesis.Mixhash = types.SportDigest
+		config.Sport = &params.SportConfig{Epoch: epoch, MinFunds: minFunds}
+	case SportDAO:
+		genesis.Mixhash = types.SportDigest
+		config.SportDAO = &params.SportDAOConfig{Epoch: epoch, MinFunds: minFunds}
+	case Istanbul:
+		config.Istanbul = &params.IstanbulConfig{Epoch: epoch, BlockPeriod: blockPeriod, RequestTimeout: requestTimeout}
+	case Tendermint:
+		config.Tendermint = &params.TendermintConfig{Epoch: epoch, BlockPeriod: blockPeriod, RequestTimeout: requestTimeout}
+	}
+	// Sport fullnodes are plain accounts, the other engines are governed by the
+	// Autonity contract, operated by the first validator
+	if n.Config.Engine != Sport {
+		contract := &params.AutonityContractGenesis{
+			Operator: validators[0],
+			Deployer: params.DefaultDeployer,
+		}
+		for _, node := range n.Nodes {
+			contract.Users = append(contract.Users, params.User{
+				Address: node.Address,
+				Enode:   node.Enode,
+				Type:    params.UserValidator,
+				Stake:   stake,
+			})
+		}
+		// Validate against the default contract, which is left out of the
+		// genesis file and filled in when the genesis is committed
+		check := *contract
+		if err := check.AddDefault().Validate(); err != nil {
+			return nil, err
+		}
+		config.AutonityContractConfig = contract
+	}
+	extra, err := types.PrepareExtra(nil, validators)
+	if err != nil {
+		return nil, err
+	}
+	genesis.ExtraData = extra
+	return genesis, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package devnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/consensus/istanbul"
+	"go-smilo/src/blockchain/smilobft/consensus/sport"
+	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
+	tendermintConfig "go-smilo/src/blockchain/smilobft/consensus/tendermint/config"
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/eth"
+	"go-smilo/src/blockchain/smilobft/eth/downloader"
+	"go-smilo/src/blockchain/smilobft/node"
+	"go-smilo/src/blockchain/smilobft/p2p"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/vault"
+	"go-smilo/src/blockchain/smilobft/vault/blackbox"
+)
+
+// rpcModules are the APIs the nodes of a devnet serve over HTTP.
+const rpcModules = "admin,eth,net,web3,personal,txpool,debug,miner"
+
+// Instance is a running devnet.
+type Instance struct {
+	network *Network
+	vaults  []*blackbox.StandIn
+	nodes   []*node.Node // Nodes running in this process
+	cmds    []*exec.Cmd  // Nodes running as child processes
+}
+
+// startVaults serves a stand-in vault on the socket of each node, all sharing
+// one payload store.
+func (n *Network) startVaults(nodes []*Node) ([]*blackbox.StandIn, error) {
+	store := blackbox.NewStandInStore()
+	vaults := make([]*blackbox.StandIn, 0, len(nodes))
+	for _, node := range nodes {
+		v, err := blackbox.NewStandIn(store, n.vaultSocket(node))
+		if err != nil {
+			for _, v := range vaults {
+				v.Close()
+			}
+			return nil, err
+		}
+		log.Info("Started stand-in vault", "node", node.Name, "socket", n.vaultSocket(node), "key", v.PublicKey)
+		vaults = append(vaults, v)
+	}
+	return vaults, nil
+}
+
+// StartInProcess runs all the nodes of the devnet in this process. The vault
+// of a process is global, so the nodes share the vault of the first node.
+func (n *Network) StartInProcess() (*Instance, error) {
+	genesis, err := n.loadGenesis()
+	if err != nil {
+		return nil, err
+	}
+	instance := &Instance{network: n}
+	if n.Config.Vault {
+		if instance.vaults, err = n.startVaults(n.Nodes[:1]); err != nil {
+			return nil, err
+		}
+		vault.VaultInstance = blackbox.CreateNew(n.VaultConfig(n.Nodes[0]))
+	}
+	for _, nd := range n.Nodes {
+		stack, err := n.newNode(nd, genesis)
+		if err == nil {
+			err = stack.Start()
+		}
+		if err != nil {
+			instance.Stop()
+			return nil, fmt.Errorf("failed to start %s: %v", nd.Name, err)
+		}
+		instance.nodes = append(instance.nodes, stack)
+
+		var smilo *eth.Smilo
+		if err := stack.Service(&smilo); err != nil {
+			instance.Stop()
+			return nil, err
+		}
+		if err := smilo.StartMining(1); err != nil {
+			instance.Stop()
+			return nil, fmt.Errorf("failed to start mining on %s: %v", nd.Name, err)
+		}
+		log.Info("Started devnet node", "name", nd.Name, "enode", nd.Enode, "rpc", nd.RPCPort)
+	}
+	return instance, nil
+}
+
+func (n *Network) loadGenesis() (*core.Genesis, error) {
+	blob, err := ioutil.ReadFile(n.GenesisPath())
+	if err != nil {
+		return nil, err
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(blob, genesis); err != nil {
+		return nil, fmt.Errorf("invalid genesis file: %v", err)
+	}
+	// Fill in the contract as geth init does
+	if contract := genesis.Config.AutonityContractConfig; contract != nil {
+		if err := contract.AddDefault().Validate(); err != nil {
+			return nil, fmt.Errorf("invalid autonity contract: %v", err)
+		}
+	}
+	return genesis, nil
+}
+
+// newNode assembles the stack of a validator, as geth would from the flags
+// of startChild.
+func (n *Network) newNode(nd *Node, genesis *core.Genesis) (*node.Node, error) {
+	key, err := crypto.HexToECDSA(nd.NodeKey)
+	if err != nil {
+		return nil, err
+	}
+	stack, err := node.New(&node.Config{
+		Name:        "geth",
+		Version:     params.Version,
+		DataDir:     n.DataDir(nd),
+		HTTPHost:    "127.0.0.1",
+		HTTPPort:    nd.RPCPort,
+		HTTPModules: strings.Split(rpcModules, ","),
+		P2P: p2p.Config{
+			ListenAddr:  fmt.Sprintf("127.0.0.1:%d", nd.Port),
+			NoDiscovery: true,
+			MaxPeers:    len(n.Nodes) + 1,
+			PrivateKey:  key,
+		},
+		EnableNodePermissionFlag: n.Config.Permissioned,
+		NoUSB:                    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		config := &eth.Config{
+			Genesis:         genesis,
+			NetworkId:       n.Config.ChainID,
+			SyncMode:        downloader.FullSync,
+			DatabaseCache:   256,
+			DatabaseHandles: 256,
+			TrieCleanCache:  eth.DefaultConfig.TrieCleanCache,
+			TrieDirtyCache:  eth.DefaultConfig.TrieDirtyCache,
+			TrieTimeout:     eth.DefaultConfig.TrieTimeout,
+			Miner:           eth.DefaultConfig.Miner,
+			TxPool:          core.DefaultTxPoolConfig,
+			GPO:             eth.DefaultConfig.GPO,
+			Sport:           *sport.DefaultConfig,
+			Tendermint:      *tendermintConfig.DefaultConfig(),
+		}
+		config.Sport.DataDir = n.DataDir(nd)
+		config.Istanbul.RequestTimeout = istanbul.DefaultConfig.RequestTimeout
+		config.Istanbul.BlockPeriod = istanbul.DefaultConfig.BlockPeriod
+		config.Istanbul.Epoch = istanbul.DefaultConfig.Epoch
+		config.SportDAO.RequestTimeout = sportdao.DefaultConfig.RequestTimeout
+		config.SportDAO.BlockPeriod = sportdao.DefaultConfig.BlockPeriod
+		config.SportDAO.Epoch = sportdao.DefaultConfig.Epoch
+		config.SportDAO.MinFunds = sportdao.DefaultConfig.MinFunds
+		config.SportDAO.DataDir = n.DataDir(nd)
+		return eth.New(ctx, config, nil)
+	})
+	return stack, err
+}
+
+// StartChildren runs each node of the devnet as a child process of the geth
+// binary, initializing its chain from the genesis first. The output of the
+// nodes is written to out.
+func (n *Network) StartChildren(geth string, out io.Writer) (*Instance, error) {
+	instance := &Instance{network: n}
+	if n.Config.Vault {
+		vaults, err := n.startVaults(n.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		instance.vaults = vaults
+	}
+	for _, nd := range n.Nodes {
+		cmd, err := n.startChild(geth, nd, out)
+		if err != nil {
+			instance.Stop()
+			return nil, fmt.Errorf("failed to start %s: %v", nd.Name, err)
+		}
+		instance.cmds = append(instance.cmds, cmd)
+		log.Info("Started devnet node", "name", nd.Name, "pid", cmd.Process.Pid, "enode", nd.Enode, "rpc", nd.RPCPort)
+	}
+	return instance, nil
+}
+
+func (n *Network) startChild(geth string, nd *Node, out io.Writer) (*exec.Cmd, error) {
+	datadir := n.DataDir(nd)
+	if _, err := os.Stat(filepath.Join(datadir, instanceDir, "chaindata")); os.IsNotExist(err) {
+		init := exec.Command(geth, "--datadir", datadir, "init", n.GenesisPath())
+		if output, err := init.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("init failed: %v\n%s", err, output)
+		}
+	}
+	args := []string{
+		"--datadir", datadir,
+		"--networkid", strconv.FormatUint(n.Config.ChainID, 10),
+		"--port", strconv.Itoa(nd.Port),
+		"--nodiscover",
+		"--syncmode", "full",
+		"--mine", "--miner.threads", "1",
+		"--rpc", "--rpcaddr", "127.0.0.1", "--rpcport", strconv.Itoa(nd.RPCPort), "--rpcapi", rpcModules,
+	}
+	if n.Config.Permissioned {
+		args = append(args, "--permissioned")
+	}
+	cmd := exec.Command(geth, args...)
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.Env = os.Environ()
+	if n.Config.Vault {
+		cmd.Env = append(cmd.Env, "VAULT_IPC="+n.VaultConfig(nd))
+	}
+	return cmd, cmd.Start()
+}
+
+// Stop stops the nodes and the vaults of the devnet.
+func (i *Instance) Stop() {
+	for _, stack := range i.nodes {
+		if err := stack.Stop(); err != nil {
+			log.Warn("Failed to stop devnet node", "err", err)
+		}
+	}
+	for _, cmd := range i.cmds {
+		cmd.Process.Signal(os.Interrupt)
+	}
+	for _, cmd := range i.cmds {
+		cmd.Wait()
+	}
+	for _, v := range i.vaults {
+		v.Close()
+	}
+	log.Info("Stopped devnet", "dir", i.network.dir)
+}
@@ -181,6 +181,18 @@ func (api *PrivateMinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
 
+// SetEmptyBlockPolicy sets when the miner seals blocks without transactions,
+// overriding the chain config until the node restarts.
+func (api *PrivateMinerAPI) SetEmptyBlockPolicy(config params.EmptyBlocksConfig) bool {
+	api.e.Miner().SetEmptyBlockPolicy(&config)
+	return true
+}
+
+// EmptyBlockPolicy returns when the miner seals blocks without transactions.
+func (api *PrivateMinerAPI) EmptyBlockPolicy() params.EmptyBlocksConfig {
+	return api.e.Miner().EmptyBlockPolicy()
+}
+
 // GetHashrate returns the current hashrate of the miner.
 func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return uint64(api.e.miner.HashRate())
@@ -226,6 +226,10 @@ func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.TxPool().Content()
 }
 
+func (b *EthAPIBackend) TxPoolQuarantined() []*core.QuarantinedTx {
+	return b.eth.TxPool().Quarantined()
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
@@ -234,6 +238,10 @@ func (b *EthAPIBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
 
+func (b *EthAPIBackend) VaultRebuildProgress() core.VaultRebuildProgress {
+	return b.eth.BlockChain().VaultRebuildProgress()
+}
+
 func (b *EthAPIBackend) ProtocolVersion() int {
 	return b.eth.EthVersion()
 }
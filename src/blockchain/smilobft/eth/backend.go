@@ -34,6 +34,7 @@ import (
 	"go-smilo/src/blockchain/smilobft/consensus/sportdao"
 	tendermintBackend "go-smilo/src/blockchain/smilobft/consensus/tendermint/backend"
 	tendermintCore "go-smilo/src/blockchain/smilobft/consensus/tendermint/core"
+	"go-smilo/src/blockchain/smilobft/contracts/autonity"
 	"go-smilo/src/blockchain/smilobft/p2p/enode"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -93,7 +94,8 @@ type Smilo struct {
 	lesServer       LesServer
 
 	// DB interfaces
-	chainDb ethdb.Database // Block chain database
+	chainDb    ethdb.Database // Block chain database
+	economicDb ethdb.Database // Separate economic history database, if any
 
 	eventMux       *cmn.TypeMux
 	engine         consensus.Engine
@@ -250,6 +252,17 @@ func New(ctx *node.ServiceContext, config *Config, cons func(basic consensus.Eng
 	if err != nil {
 		return nil, err
 	}
+	eth.blockchain.SetVaultCheckpoint(config.VaultCheckpoint)
+	if contract := eth.blockchain.GetAutonityContract(); contract != nil && config.EconomicHistory {
+		historyDb := ethdb.KeyValueStore(chainDb)
+		if config.EconomicHistoryDB != "" {
+			if eth.economicDb, err = ctx.OpenDatabase(config.EconomicHistoryDB, 16, 16, "eth/db/economics/"); err != nil {
+				return nil, err
+			}
+			historyDb = eth.economicDb
+		}
+		contract.SetEconomicHistory(autonity.NewEconomicHistory(historyDb))
+	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -265,6 +278,11 @@ func New(ctx *node.ServiceContext, config *Config, cons func(basic consensus.Eng
 		config.TxPool.Blacklist = ctx.ResolvePath(config.TxPool.Blacklist)
 	}
 
+	if rules := config.TxPool.ContractAnalysis; rules != nil {
+		if _, err := core.NewContractAnalysis(*rules); err != nil {
+			return nil, err
+		}
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -671,6 +689,7 @@ func (s *Smilo) Start(srvr *p2p.Server) error {
 		log.Info("eth/backend.go, Start(), Reading Whitelist", "list", savedList.StrList)
 		go s.glienickeEventLoop(srvr)
 		srvr.UpdateWhitelist(savedList.List)
+		srvr.SetGovernancePolicies(savedList.StrList)
 	} else {
 		log.Warn("eth/backend.go, Start(), EnableNodePermissionFlag false, will not Subscribe to Autonity updates events")
 	}
@@ -701,31 +720,61 @@ func (s *Smilo) Start(srvr *p2p.Server) error {
 // Whitelist updating loop. Act as a relay between state processing logic and DevP2P
 // for updating the list of authorized enodes
 func (s *Smilo) glienickeEventLoop(server *p2p.Server) {
+	heads := make(chan core.ChainHeadEvent, 16)
+	headSub := s.blockchain.SubscribeChainHeadEvent(heads)
+	defer headSub.Unsubscribe()
+
+	blacklists := make(chan core.BlacklistEvent)
+	blacklistSub := s.blockchain.SubscribeBlacklistEvents(blacklists)
+	defer blacklistSub.Unsubscribe()
+
+	// The removed peers ahead of us are kept until we catch up with them.
+	type removal struct {
+		node  *enode.Node
+		block uint64
+	}
+	deferred := make(map[enode.ID]removal)
+	ahead := func(node *enode.Node) bool {
+		peer := s.protocolManager.peers.Peer(fmt.Sprintf("%x", node.ID().Bytes()[:8]))
+		if peer == nil {
+			return false
+		}
+		_, td := peer.Head()
+		return td.Uint64() > s.blockchain.CurrentHeader().Number.Uint64()+1
+	}
 	for {
 		select {
 		case event := <-s.glienickeCh:
-			whitelist := append([]*enode.Node{}, event.Whitelist...)
-			// Filter the list of need to be dropped peers depending on TD.
-			for _, connectedPeer := range s.protocolManager.peers.Peers() {
-				found := false
-				connectedEnode := connectedPeer.Node()
-				for _, whitelistedEnode := range whitelist {
-					if connectedEnode.ID() == whitelistedEnode.ID() {
-						found = true
-						break
-					}
+			for _, addedEnode := range event.Added.List {
+				delete(deferred, addedEnode.ID())
+			}
+			var removed []*enode.Node
+			for _, removedEnode := range event.Removed.List {
+				if ahead(removedEnode) {
+					deferred[removedEnode.ID()] = removal{removedEnode, event.Block}
+					continue
 				}
-
-				if !found { // this node is no longer in the whitelist
-					peerID := fmt.Sprintf("%x", connectedEnode.ID().Bytes()[:8])
-					peer := s.protocolManager.peers.Peer(peerID)
-					localTd := s.blockchain.CurrentHeader().Number.Uint64() + 1
-					if peer != nil && peer.td.Uint64() > localTd {
-						whitelist = append(whitelist, connectedEnode)
-					}
+				removed = append(removed, removedEnode)
+			}
+			server.ApplyWhitelistDiff(event.Block, event.Added.List, removed)
+			server.UpdateGovernancePolicies(event.Added.StrList, event.Removed.StrList)
+
+		case <-heads:
+			// Drop the removed peers we caught up with, or which disconnected
+			for id, r := range deferred {
+				if !ahead(r.node) {
+					server.ApplyWhitelistDiff(r.block, nil, []*enode.Node{r.node})
+					delete(deferred, id)
 				}
 			}
-			server.UpdateWhitelist(whitelist)
+
+		case event := <-blacklists:
+			for _, node := range event.Blacklist.List {
+				log.Info("Dropping blacklisted peer", "enode", node.String())
+				server.RemovePeer(node)
+				server.RemoveTrustedPeer(node)
+			}
+
 		// Err() channel will be closed when unsubscribing.
 		case <-s.glienickeSub.Err():
 			return
@@ -751,6 +800,9 @@ func (s *Smilo) Stop() error {
 	s.eventMux.Stop()
 
 	s.chainDb.Close()
+	if s.economicDb != nil {
+		s.economicDb.Close()
+	}
 	close(s.shutdownChan)
 	return nil
 }
@@ -157,6 +157,11 @@ type Config struct {
 	EnablePreimageRecording bool
 
 	EnableNodePermissionFlag bool
+
+	// Economic history options of the Autonity contract
+	EconomicHistory   bool   // Record an economic snapshot for every block
+	EconomicHistoryDB string // Name of a separate database in the data directory, the chain database if empty
+
 	// Sport options
 	Sport sport.Config
 
@@ -178,9 +183,13 @@ type Config struct {
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
+	// VaultCheckpoint is a trusted vault state the vault state rebuild after a
+	// fast sync starts from, instead of the genesis. It can be nil.
+	VaultCheckpoint *core.VaultCheckpoint `toml:",omitempty"`
+
 	PowMode               Mode
 	SolcPath              string
-	SmiloCodeAnalysisPath string
+	SmiloCodeAnalysisPath string // Enables the code analysis API, the analysis runs in process
 }
 
 type Mode uint
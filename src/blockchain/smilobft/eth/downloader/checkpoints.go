@@ -0,0 +1,120 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// checkpointVerified is the header check frequency of the chunks verified from
+// checkpoint to checkpoint, which skips their verification by the engine.
+const checkpointVerified = -1
+
+// checkpointVerifier verifies the header chains of BFT engines in fast and
+// light sync by skipping from epoch checkpoint to epoch checkpoint: every
+// checkpoint is verified from the previous one, and the headers in between by
+// their hash links to the next. Headers are verified by the engine until a
+// checkpoint is trusted, and after the last checkpoint of a chunk.
+type checkpointVerifier struct {
+	config  *params.ChainConfig
+	rules   *types.CheckpointRules // Seal rules of the engine
+	trusted *types.Checkpoint      // Last checkpoint verified in the current sync
+}
+
+// SetCheckpointSkipping verifies the headers of the BFT chain of config from
+// epoch checkpoint to epoch checkpoint in fast and light sync, with the seal
+// rules of its engine. It must be set before the first sync.
+func (d *Downloader) SetCheckpointSkipping(config *params.ChainConfig, rules *types.CheckpointRules) {
+	if config.CheckpointBlock == nil || config.EpochLength() == 0 {
+		return
+	}
+	log.Info("Verifying headers from checkpoint to checkpoint", "from", config.CheckpointBlock, "epoch", config.EpochLength())
+	d.checkpoints = &checkpointVerifier{config: config, rules: rules}
+}
+
+// reset forgets the checkpoint trusted in the previous sync.
+func (v *checkpointVerifier) reset() {
+	v.trusted = nil
+}
+
+// verify checks the checkpoints of chunk, a batch of contiguous headers on top
+// of the local chain, and returns the number of its first headers which can
+// skip the verification by the engine: the ones up to its last checkpoint,
+// linked to it by their hashes. The headers after it are verified by the engine.
+func (v *checkpointVerifier) verify(chain LightChain, chunk []*types.Header) (int, error) {
+	if v.trusted == nil {
+		if v.trusted = v.localCheckpoint(chain, chunk[0]); v.trusted == nil {
+			return 0, nil
+		}
+	}
+	verified := 0
+	for i, header := range chunk {
+		if !v.config.IsCheckpoint(header.Number) {
+			continue
+		}
+		checkpoint, err := types.VerifyCheckpoint(v.rules, v.trusted, header)
+		if err != nil {
+			return 0, err
+		}
+		v.trusted, verified = checkpoint, i+1
+	}
+	return verified, nil
+}
+
+// insertHeaders inserts chunk into the light chain, skipping the verification
+// by the engine of its first verified headers and checking the others at the
+// given frequency. It returns the index of the failing header, if any.
+func (d *Downloader) insertHeaders(chunk []*types.Header, verified int, frequency int) (int, error) {
+	if verified > 0 {
+		if n, err := d.lightchain.InsertHeaderChain(chunk[:verified], checkpointVerified); err != nil {
+			return n, err
+		}
+	}
+	if verified == len(chunk) {
+		return 0, nil
+	}
+	n, err := d.lightchain.InsertHeaderChain(chunk[verified:], frequency)
+	return verified + n, err
+}
+
+// localCheckpoint returns the last checkpoint of the local chain before header,
+// nil if the checkpoints are not active yet.
+func (v *checkpointVerifier) localCheckpoint(chain LightChain, header *types.Header) *types.Checkpoint {
+	epoch := new(big.Int).SetUint64(v.config.EpochLength())
+	number := new(big.Int).Sub(header.Number, big.NewInt(1))
+	number.Sub(number, new(big.Int).Mod(number, epoch))
+	if !v.config.IsCheckpoint(number) {
+		return nil
+	}
+	for parent := chain.GetHeaderByHash(header.ParentHash); parent != nil; parent = chain.GetHeaderByHash(parent.ParentHash) {
+		if parent.Number.Cmp(number) > 0 {
+			continue
+		}
+		checkpoint, err := types.ExtractCheckpoint(parent)
+		if err != nil {
+			log.Warn("Invalid local checkpoint", "number", parent.Number, "hash", parent.Hash(), "err", err)
+			return nil
+		}
+		return checkpoint
+	}
+	return nil
+}
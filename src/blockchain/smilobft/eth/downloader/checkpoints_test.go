@@ -0,0 +1,122 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	istanbulCore "go-smilo/src/blockchain/smilobft/consensus/istanbul/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// checkpointTestChain is a local chain of headers looked up by hash.
+type checkpointTestChain struct {
+	LightChain
+	headers map[common.Hash]*types.Header
+}
+
+func (c *checkpointTestChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+// makeCheckpointHeaders returns n BFT headers on top of parent, committing the
+// checkpoints of config to the validators of keys.
+func makeCheckpointHeaders(t *testing.T, config *params.ChainConfig, parent *types.Header, n int, keys []*ecdsa.PrivateKey) []*types.Header {
+	validators := make([]common.Address, len(keys))
+	for i, key := range keys {
+		validators[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		extra, err := types.PrepareExtra(nil, validators)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).Add(parent.Number, common.Big1), MixDigest: types.BFTDigest, Extra: extra}
+		if config.IsCheckpoint(h.Number) {
+			if err := types.WriteCheckpoint(h); err != nil {
+				t.Fatal(err)
+			}
+			commit := crypto.Keccak256(istanbulCore.PrepareCommittedSeal(h.Hash()))
+			seals := make([][]byte, len(keys))
+			for j, key := range keys {
+				if seals[j], err = crypto.Sign(commit, key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := types.WriteCommittedSeals(h, seals); err != nil {
+				t.Fatal(err)
+			}
+		}
+		headers[i], parent = h, h
+	}
+	return headers
+}
+
+// Tests that only the headers up to the last checkpoint of a chunk skip the
+// verification by the engine.
+func TestCheckpointVerifiedHeaders(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := &params.ChainConfig{Istanbul: &params.IstanbulConfig{Epoch: 4}, CheckpointBlock: big.NewInt(0)}
+	genesis := &types.Header{Number: big.NewInt(0)}
+	local := makeCheckpointHeaders(t, config, genesis, 4, keys)
+
+	chain := &checkpointTestChain{headers: make(map[common.Hash]*types.Header)}
+	for _, h := range local {
+		chain.headers[h.Hash()] = h
+	}
+	verifier := &checkpointVerifier{config: config, rules: istanbulCore.CheckpointRules()}
+	chunk := makeCheckpointHeaders(t, config, local[3], 10, keys)
+
+	tests := []struct {
+		chunk    []*types.Header
+		verified int
+	}{
+		{chunk[:6], 4},  // Headers 5-10, checkpoint 8: 9 and 10 left to the engine
+		{chunk[6:7], 0}, // Header 11, no checkpoint
+		{chunk[7:], 1},  // Headers 12-14, checkpoint 12
+	}
+	for i, tt := range tests {
+		verified, err := verifier.verify(chain, tt.chunk)
+		if err != nil {
+			t.Fatalf("test %d: verification failed: %v", i, err)
+		}
+		if verified != tt.verified {
+			t.Errorf("test %d: verified headers mismatch: have %d, want %d", i, verified, tt.verified)
+		}
+	}
+
+	// A checkpoint committed by untrusted validators fails the chunk
+	strangers := make([]*ecdsa.PrivateKey, 4)
+	for i := range strangers {
+		strangers[i], _ = crypto.GenerateKey()
+	}
+	verifier.reset()
+	forged := makeCheckpointHeaders(t, config, local[3], 4, strangers)
+	if _, err := verifier.verify(chain, forged); err != types.ErrUntrustedCheckpoint {
+		t.Errorf("forged checkpoint: have %v, want %v", err, types.ErrUntrustedCheckpoint)
+	}
+}
@@ -112,6 +112,8 @@ type Downloader struct {
 	queue      *queue   // Scheduler for selecting the hashes to download
 	peers      *peerSet // Set of active peers from which download can proceed
 
+	checkpoints *checkpointVerifier // Verifier of the BFT epoch checkpoints (nil = engine verification only)
+
 	stateDB    ethdb.Database  // Database to state sync into (and deduplicate via)
 	stateBloom *trie.SyncBloom // Bloom filter for fast trie node existence checks
 
@@ -1361,6 +1363,9 @@ func (d *Downloader) fetchParts(deliveryCh chan dataPack, deliver func(dataPack)
 // keeps processing and scheduling them into the header chain and downloader's
 // queue until the stream ends or a failure occurs.
 func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) error {
+	if d.checkpoints != nil {
+		d.checkpoints.reset()
+	}
 	// Keep a count of uncertain headers to roll back
 	var rollback []*types.Header
 	defer func() {
@@ -1470,7 +1475,17 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 					if chunk[len(chunk)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > pivot {
 						frequency = 1
 					}
-					if n, err := d.lightchain.InsertHeaderChain(chunk, frequency); err != nil {
+					// BFT headers skip from checkpoint to checkpoint up to the pivot, or
+					// up to the head in light sync which has none
+					verified := 0
+					if d.checkpoints != nil && (frequency != 1 || d.mode == LightSync) {
+						var err error
+						if verified, err = d.checkpoints.verify(d.lightchain, chunk); err != nil {
+							log.Debug("Invalid checkpoint encountered", "err", err)
+							return errInvalidChain
+						}
+					}
+					if n, err := d.insertHeaders(chunk, verified, frequency); err != nil {
 						// If some headers were inserted, add them too to the rollback list
 						if n > 0 {
 							rollback = append(rollback, chunk[:n]...)
@@ -52,6 +52,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		GPO                      gasprice.Config
 		EnablePreimageRecording  bool
 		EnableNodePermissionFlag bool
+		EconomicHistory          bool
+		EconomicHistoryDB        string
 		Sport                    sport.Config
 		DocRoot                  string `toml:"-"`
 		EWASMInterpreter         string
@@ -59,6 +61,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap                *big.Int                       `toml:",omitempty"`
 		Checkpoint               *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle         *params.CheckpointOracleConfig `toml:",omitempty"`
+		VaultCheckpoint          *core.VaultCheckpoint          `toml:",omitempty"`
 		PowMode                  Mode
 		SolcPath                 string
 		SmiloCodeAnalysisPath    string
@@ -93,6 +96,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.EnableNodePermissionFlag = c.EnableNodePermissionFlag
+	enc.EconomicHistory = c.EconomicHistory
+	enc.EconomicHistoryDB = c.EconomicHistoryDB
 	enc.Sport = c.Sport
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
@@ -100,6 +105,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.VaultCheckpoint = c.VaultCheckpoint
 	enc.PowMode = c.PowMode
 	enc.SolcPath = c.SolcPath
 	enc.SmiloCodeAnalysisPath = c.SmiloCodeAnalysisPath
@@ -138,6 +144,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		GPO                      *gasprice.Config
 		EnablePreimageRecording  *bool
 		EnableNodePermissionFlag *bool
+		EconomicHistory          *bool
+		EconomicHistoryDB        *string
 		Sport                    *sport.Config
 		DocRoot                  *string `toml:"-"`
 		EWASMInterpreter         *string
@@ -145,6 +153,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap                *big.Int                       `toml:",omitempty"`
 		Checkpoint               *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle         *params.CheckpointOracleConfig `toml:",omitempty"`
+		VaultCheckpoint          *core.VaultCheckpoint          `toml:",omitempty"`
 		PowMode                  *Mode
 		SolcPath                 *string
 		SmiloCodeAnalysisPath    *string
@@ -240,6 +249,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnableNodePermissionFlag != nil {
 		c.EnableNodePermissionFlag = *dec.EnableNodePermissionFlag
 	}
+	if dec.EconomicHistory != nil {
+		c.EconomicHistory = *dec.EconomicHistory
+	}
+	if dec.EconomicHistoryDB != nil {
+		c.EconomicHistoryDB = *dec.EconomicHistoryDB
+	}
 	if dec.Sport != nil {
 		c.Sport = *dec.Sport
 	}
@@ -261,6 +276,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.VaultCheckpoint != nil {
+		c.VaultCheckpoint = dec.VaultCheckpoint
+	}
 	if dec.PowMode != nil {
 		c.PowMode = *dec.PowMode
 	}
@@ -111,7 +111,7 @@ type ProtocolManager struct {
 
 	whitelistCh         chan core.WhitelistEvent
 	whitelistSub        event.Subscription
-	enodesWhitelist     []*enode.Node
+	enodesWhitelist     map[enode.ID]*enode.Node
 	enodesWhitelistLock sync.RWMutex
 	// wait group is used for graceful shutdowns during downloading
 	// and processing
@@ -227,6 +227,9 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 		stateBloom = trie.NewSyncBloom(uint64(cacheLimit), chaindb)
 	}
 	manager.downloader = downloader.New(manager.checkpointNumber, chaindb, stateBloom, manager.eventMux, blockchain, nil, manager.removePeer)
+	if checkpointer, ok := engine.(consensus.Checkpointer); ok {
+		manager.downloader.SetCheckpointSkipping(config, checkpointer.CheckpointRules(config))
+	}
 
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
@@ -263,7 +266,10 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 	}
 	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.removePeer)
 	if manager.chainconfig.Istanbul != nil || manager.chainconfig.SportDAO != nil || manager.chainconfig.Tendermint != nil {
-		manager.enodesWhitelist = rawdb.ReadEnodeWhitelist(chaindb, EnableNodePermissionFlag).List
+		manager.enodesWhitelist = make(map[enode.ID]*enode.Node)
+		for _, n := range rawdb.ReadEnodeWhitelist(chaindb, EnableNodePermissionFlag).List {
+			manager.enodesWhitelist[n.ID()] = n
+		}
 		log.Warn("eth/handler.go, rawdb.ReadEnodeWhitelist, enodesWhitelist, ", "manager.enodesWhitelist", manager.enodesWhitelist)
 	} else {
 		msg := "Wont set Istanbul Tendermint SportDAO ReadEnodeWhitelist, is this correct ? "
@@ -395,9 +401,17 @@ func (pm *ProtocolManager) glienickeEventLoop() {
 	for {
 		select {
 		case event := <-pm.whitelistCh:
-			log.Warn("glienickeEventLoop, got Whitelist, ", event.Whitelist)
+			log.Debug("glienickeEventLoop, got Whitelist change", "block", event.Block, "added", len(event.Added.List), "removed", len(event.Removed.List))
 			pm.enodesWhitelistLock.Lock()
-			pm.enodesWhitelist = event.Whitelist
+			if pm.enodesWhitelist == nil {
+				pm.enodesWhitelist = make(map[enode.ID]*enode.Node)
+			}
+			for _, n := range event.Removed.List {
+				delete(pm.enodesWhitelist, n.ID())
+			}
+			for _, n := range event.Added.List {
+				pm.enodesWhitelist[n.ID()] = n
+			}
 			pm.enodesWhitelistLock.Unlock()
 		// Err() channel will be closed when unsubscribing.
 		case err := <-pm.whitelistSub.Err():
@@ -439,16 +453,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 			log.Warn("eth/handler.go, pm.EnableNodePermissionFlag, enodesWhitelist, ", pm.enodesWhitelist)
 
 			pm.enodesWhitelistLock.RLock()
-			for _, enode := range pm.enodesWhitelist {
-				if p.Node().ID() == enode.ID() {
-					log.Warn("eth/handler.go, range pm.enodesWhitelist, ", enode)
-					whitelisted = true
-					break
-				} else {
-					log.Warn("eth/handler.go, range pm.enodesWhitelist, ELSE ", enode)
-				}
-			}
-
+			_, whitelisted = pm.enodesWhitelist[p.Node().ID()]
 			pm.enodesWhitelistLock.RUnlock()
 			if !whitelisted && p.td.Uint64() <= head.Number.Uint64()+1 {
 				p.Log().Info("dropping unauthorized peer with old TD",
@@ -553,6 +558,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			//log.Warn("eth/handler.go, handleMsg, pubKey valid, ", "msg", msg)
 		}
 		addr := crypto.PubkeyToAddress(*pubKey)
+		if msg.Code >= protocolLengths[eth63] {
+			if policy := p.Policy(); policy != nil && !policy.SendsConsensus() {
+				p.Log().Trace("Dropping consensus message of observer", "code", msg.Code)
+				return nil
+			}
+		}
 		handled, err := handler.HandleMsg(addr, msg)
 		if handled {
 			return err
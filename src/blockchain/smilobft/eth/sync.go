@@ -202,6 +202,13 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 	if atomic.LoadUint32(&pm.fastSync) == 1 {
 		log.Info("Fast sync complete, auto disabling")
 		atomic.StoreUint32(&pm.fastSync, 0)
+
+		// Fast sync skipped the vault state, rebuild it from the local vault
+		if pm.blockchain.Config().IsSmilo {
+			if err := pm.blockchain.RebuildVaultState(); err != nil {
+				log.Warn("Failed to start vault state rebuild", "err", err)
+			}
+		}
 	}
 	atomic.StoreUint32(&pm.acceptTxs, 1) // Mark initial sync done
 	if head := pm.blockchain.CurrentBlock(); head.NumberU64() > 0 {
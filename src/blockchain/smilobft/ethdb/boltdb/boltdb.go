@@ -0,0 +1,592 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build !js
+
+// Package boltdb implements the key-value database layer based on BoltDB.
+//
+// Unlike LevelDB, BoltDB is a copy-on-write B+tree in a single memory mapped
+// file: writes are applied in place when their transaction commits and there
+// is no background compaction stalling them.
+package boltdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	bolt "go.etcd.io/bbolt"
+
+	"go-smilo/src/blockchain/smilobft/ethdb"
+)
+
+const (
+	// FileName is the name of the database file in the database directory.
+	FileName = "bolt.db"
+
+	// iteratorChunk is the number of entries an iterator reads in one read
+	// transaction.
+	iteratorChunk = 1024
+
+	// compactTxSize is the amount of data copied in one write transaction when
+	// the database file is rewritten.
+	compactTxSize = 64 * 1024 * 1024
+
+	// metricsGatheringInterval specifies the interval to retrieve boltdb
+	// database stats to report to the user.
+	metricsGatheringInterval = 3 * time.Second
+)
+
+var (
+	// bucket is the single bucket all the key-value pairs are stored in.
+	bucket = []byte("ethdb")
+
+	errNotFound        = errors.New("not found")
+	errUnknownProperty = errors.New("unknown property")
+)
+
+// Database is a persistent key-value store. Apart from basic data storage
+// functionality it also supports batch writes and iterating over the keyspace in
+// binary-alphabetical order.
+type Database struct {
+	fn   string       // filename for reporting
+	lock sync.RWMutex // Mutex protecting the BoltDB instance against its rewrite by Compact
+	db   *bolt.DB     // BoltDB instance
+
+	diskSizeGauge   metrics.Gauge // Gauge for tracking the size of the database file
+	freeSizeGauge   metrics.Gauge // Gauge for tracking the size of the free pages in the database file
+	commitTimeMeter metrics.Meter // Meter for measuring the time spent writing committed pages
+	diskWriteMeter  metrics.Meter // Meter for measuring the number of page writes
+
+	quitLock sync.Mutex      // Mutex protecting the quit channel access
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// New returns a wrapped BoltDB object, storing its data in a single file in the
+// given directory. The namespace is the prefix that the metrics reporting should
+// use for surfacing internal stats.
+//
+// BoltDB has no cache of its own, it reads the pages of its memory mapped file
+// through the page cache of the operating system.
+func New(file string, namespace string) (*Database, error) {
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return nil, err
+	}
+	logger := log.New("database", file)
+
+	db, err := open(filepath.Join(file, FileName))
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Opened BoltDB database", "size", common.StorageSize(fileSize(db)))
+
+	// Assemble the wrapper with all the registered metrics
+	bdb := &Database{
+		fn:       file,
+		db:       db,
+		log:      logger,
+		quitChan: make(chan chan error),
+	}
+	bdb.diskSizeGauge = metrics.NewRegisteredGauge(namespace+"disk/size", nil)
+	bdb.freeSizeGauge = metrics.NewRegisteredGauge(namespace+"disk/free", nil)
+	bdb.commitTimeMeter = metrics.NewRegisteredMeter(namespace+"commit/time", nil)
+	bdb.diskWriteMeter = metrics.NewRegisteredMeter(namespace+"disk/write", nil)
+
+	// Start up the metrics gathering and return
+	go bdb.meter(metricsGatheringInterval)
+	return bdb, nil
+}
+
+// open opens the BoltDB database file, creating the bucket of the key-value
+// pairs if it's new.
+func open(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout:        time.Second,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// fileSize returns the size of the database file.
+func fileSize(db *bolt.DB) int64 {
+	var size int64
+	db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return size
+}
+
+// Close stops the metrics collection, flushes any pending data to disk and closes
+// all io accesses to the underlying key-value store.
+func (db *Database) Close() error {
+	db.quitLock.Lock()
+	defer db.quitLock.Unlock()
+
+	if db.quitChan != nil {
+		errc := make(chan error)
+		db.quitChan <- errc
+		if err := <-errc; err != nil {
+			db.log.Error("Metrics collection failed", "err", err)
+		}
+		db.quitChan = nil
+	}
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.db.Close()
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var has bool
+	err := db.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(bucket).Cursor().Seek(key)
+		has = k != nil && bytes.Equal(k, key)
+		return nil
+	})
+	return has, err
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var dat []byte
+	err := db.db.View(func(tx *bolt.Tx) error {
+		k, v := tx.Bucket(bucket).Cursor().Seek(key)
+		if k == nil || !bytes.Equal(k, key) {
+			return errNotFound
+		}
+		// The value is only valid during the transaction
+		dat = common.CopyBytes(v)
+		if dat == nil {
+			dat = []byte{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dat, nil
+}
+
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, value)
+	})
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete(key)
+	})
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewIterator creates a binary-alphabetical iterator over the entire keyspace
+// contained within the boltdb database.
+func (db *Database) NewIterator() ethdb.Iterator {
+	return db.newIterator(nil, nil)
+}
+
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// database content starting at a particular initial key (or after, if it does
+// not exist).
+func (db *Database) NewIteratorWithStart(start []byte) ethdb.Iterator {
+	return db.newIterator(start, nil)
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix.
+func (db *Database) NewIteratorWithPrefix(prefix []byte) ethdb.Iterator {
+	return db.newIterator(prefix, prefix)
+}
+
+func (db *Database) newIterator(start []byte, prefix []byte) *iterator {
+	return &iterator{
+		db:     db,
+		prefix: common.CopyBytes(prefix),
+		next:   common.CopyBytes(start),
+	}
+}
+
+// Stat returns a particular internal stat of the database. The supported
+// properties are boltdb.stats and boltdb.size.
+func (db *Database) Stat(property string) (string, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	switch property {
+	case "boltdb.stats":
+		stats := db.db.Stats()
+		return fmt.Sprintf("File size: %v\n"+
+			"Free pages: %d (%v), pending %d\n"+
+			"Read transactions: %d, open %d\n"+
+			"Pages allocated: %d (%v)\n"+
+			"Nodes rebalanced: %d (%v), split %d, spilled %d (%v)\n"+
+			"Page writes: %d (%v)\n",
+			common.StorageSize(fileSize(db.db)),
+			stats.FreePageN, common.StorageSize(stats.FreeAlloc), stats.PendingPageN,
+			stats.TxN, stats.OpenTxN,
+			stats.TxStats.PageCount, common.StorageSize(stats.TxStats.PageAlloc),
+			stats.TxStats.Rebalance, stats.TxStats.RebalanceTime, stats.TxStats.Split, stats.TxStats.Spill, stats.TxStats.SpillTime,
+			stats.TxStats.Write, stats.TxStats.WriteTime), nil
+
+	case "boltdb.size":
+		return fmt.Sprint(fileSize(db.db)), nil
+	}
+	return "", errUnknownProperty
+}
+
+// Compact rewrites the database file, giving back to the filesystem the pages
+// freed by deletions, if the entire data store is compacted. BoltDB reuses the
+// freed pages for the next writes and keeps its keys sorted in place, so the
+// compaction of a key range does nothing.
+//
+// The database is not accessible during the rewrite, which needs as much free
+// disk space as the live data.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	if start != nil || limit != nil {
+		return nil
+	}
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var (
+		path = db.db.Path()
+		tmp  = path + ".compact"
+		size = fileSize(db.db)
+	)
+	os.Remove(tmp)
+	if err := copyFile(db.db, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := db.db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	bdb, err := open(path)
+	if err != nil {
+		return err
+	}
+	db.db = bdb
+	db.log.Info("Compacted database", "size", common.StorageSize(size), "compacted", common.StorageSize(fileSize(bdb)))
+	return nil
+}
+
+// copyFile copies the key-value pairs of the database into a new database file,
+// filling its pages.
+func copyFile(db *bolt.DB, path string) error {
+	dst, err := open(path)
+	if err != nil {
+		return err
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		var (
+			dtx  *bolt.Tx
+			b    *bolt.Bucket
+			size int
+		)
+		c := tx.Bucket(bucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if dtx == nil {
+				var err error
+				if dtx, err = dst.Begin(true); err != nil {
+					return err
+				}
+				b = dtx.Bucket(bucket)
+				b.FillPercent = 1 // Keys are inserted in order
+			}
+			if err := b.Put(k, v); err != nil {
+				dtx.Rollback()
+				return err
+			}
+			if size += len(k) + len(v); size >= compactTxSize {
+				if err := dtx.Commit(); err != nil {
+					return err
+				}
+				dtx, size = nil, 0
+			}
+		}
+		if dtx != nil {
+			return dtx.Commit()
+		}
+		return nil
+	})
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// Path returns the path to the database directory.
+func (db *Database) Path() string {
+	return db.fn
+}
+
+// meter periodically retrieves internal boltdb counters and reports them to
+// the metrics subsystem.
+func (db *Database) meter(refresh time.Duration) {
+	var (
+		prev bolt.TxStats
+		errc chan error
+	)
+	for errc == nil {
+		db.lock.RLock()
+		stats, size := db.db.Stats(), fileSize(db.db)
+		db.lock.RUnlock()
+
+		// The transaction stats are reset when Compact reopens the file
+		if stats.TxStats.Write < prev.Write {
+			prev = bolt.TxStats{}
+		}
+		db.diskSizeGauge.Update(size)
+		db.freeSizeGauge.Update(int64(stats.FreeAlloc))
+		db.commitTimeMeter.Mark(int64(stats.TxStats.WriteTime - prev.WriteTime))
+		db.diskWriteMeter.Mark(int64(stats.TxStats.Write - prev.Write))
+		prev = stats.TxStats
+
+		// Sleep a bit, then repeat the stats collection
+		select {
+		case errc = <-db.quitChan:
+			// Quit requesting, stop hammering the database
+		case <-time.After(refresh):
+			// Timeout, gather a new set of stats
+		}
+	}
+	errc <- nil
+}
+
+// batch is a write-only boltdb batch that commits changes to its host database
+// in one transaction when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	db     *Database
+	writes []keyvalue
+	size   int
+}
+
+// keyvalue is a key-value pair written or deleted by a batch.
+type keyvalue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyvalue{common.CopyBytes(key), common.CopyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyvalue{common.CopyBytes(key), nil, true})
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	if len(b.writes) == 0 {
+		return nil
+	}
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	return b.db.db.Update(func(tx *bolt.Tx) error {
+		return b.Replay(&bucketWriter{tx.Bucket(bucket)})
+	})
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, kv := range b.writes {
+		if kv.delete {
+			if err := w.Delete(kv.key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketWriter is a small wrapper writing the replayed batch contents into the
+// bucket of a write transaction.
+type bucketWriter struct {
+	bucket *bolt.Bucket
+}
+
+// Put inserts the given value into the bucket.
+func (w *bucketWriter) Put(key, value []byte) error {
+	return w.bucket.Put(key, value)
+}
+
+// Delete removes the key from the bucket.
+func (w *bucketWriter) Delete(key []byte) error {
+	return w.bucket.Delete(key)
+}
+
+// iterator is a binary-alphabetical iterator over the keys of the database,
+// reading them by chunks in short read transactions.
+//
+// A read transaction open during the whole iteration would block the growth
+// of the memory map, and so the writes of the callers deleting or inserting
+// keys while they iterate. In exchange the iterator isn't a snapshot of the
+// database: it returns the keys written after it was created if they come
+// after its position.
+type iterator struct {
+	db     *Database
+	prefix []byte // Prefix of the keys iterated over, if any
+	next   []byte // Key the next chunk starts at
+
+	keys   [][]byte
+	values [][]byte
+	pos    int
+	done   bool // Whether the last chunk was read
+	err    error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.pos+1 < len(it.keys) {
+		it.pos++
+		return true
+	}
+	it.keys, it.values, it.pos = it.keys[:0], it.values[:0], 0
+	if it.done || it.err != nil {
+		return false
+	}
+	it.db.lock.RLock()
+	it.err = it.db.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+
+		var k, v []byte
+		if it.next == nil {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(it.next)
+		}
+		for ; k != nil && len(it.keys) < iteratorChunk; k, v = c.Next() {
+			if !bytes.HasPrefix(k, it.prefix) {
+				k = nil
+				break
+			}
+			it.keys = append(it.keys, common.CopyBytes(k))
+			it.values = append(it.values, append([]byte{}, v...))
+		}
+		if k == nil {
+			it.done = true
+		} else {
+			it.next = common.CopyBytes(k)
+		}
+		return nil
+	})
+	it.db.lock.RUnlock()
+
+	return it.err == nil && len(it.keys) > 0
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (it *iterator) Key() []byte {
+	if it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.pos]
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (it *iterator) Value() []byte {
+	if it.pos >= len(it.values) {
+		return nil
+	}
+	return it.values[it.pos]
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *iterator) Release() {
+	it.keys, it.values, it.done = nil, nil, true
+}
@@ -0,0 +1,328 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package boltdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
+)
+
+func newTestDatabase(t *testing.T) (*Database, func()) {
+	dir, err := ioutil.TempDir("", "boltdb-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := New(dir, "")
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// numberedKey returns a key sorting in the order of its number.
+func numberedKey(n int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(n))
+	return key
+}
+
+// Tests that key-value iteration on top of a bolt database works.
+func TestBoltDBIterator(t *testing.T) {
+	tests := []struct {
+		content map[string]string
+		prefix  string
+		order   []string
+	}{
+		// Empty databases should be iterable
+		{map[string]string{}, "", nil},
+		{map[string]string{}, "non-existent-prefix", nil},
+
+		// Single-item databases should be iterable
+		{map[string]string{"key": "val"}, "", []string{"key"}},
+		{map[string]string{"key": "val"}, "k", []string{"key"}},
+		{map[string]string{"key": "val"}, "l", nil},
+
+		// Multi-item databases should be fully iterable
+		{
+			map[string]string{"k1": "v1", "k5": "v5", "k2": "v2", "k4": "v4", "k3": "v3"},
+			"",
+			[]string{"k1", "k2", "k3", "k4", "k5"},
+		},
+		{
+			map[string]string{"k1": "v1", "k5": "v5", "k2": "v2", "k4": "v4", "k3": "v3"},
+			"k",
+			[]string{"k1", "k2", "k3", "k4", "k5"},
+		},
+		// Multi-item databases should be prefix-iterable
+		{
+			map[string]string{
+				"ka1": "va1", "ka5": "va5", "ka2": "va2", "ka4": "va4", "ka3": "va3",
+				"kb1": "vb1", "kb5": "vb5", "kb2": "vb2", "kb4": "vb4", "kb3": "vb3",
+			},
+			"ka",
+			[]string{"ka1", "ka2", "ka3", "ka4", "ka5"},
+		},
+		{
+			map[string]string{
+				"ka1": "va1", "ka5": "va5", "ka2": "va2", "ka4": "va4", "ka3": "va3",
+				"kb1": "vb1", "kb5": "vb5", "kb2": "vb2", "kb4": "vb4", "kb3": "vb3",
+			},
+			"kc",
+			nil,
+		},
+	}
+	for i, tt := range tests {
+		db, release := newTestDatabase(t)
+		for key, val := range tt.content {
+			if err := db.Put([]byte(key), []byte(val)); err != nil {
+				t.Fatalf("test %d: failed to insert item %s:%s into database: %v", i, key, val, err)
+			}
+		}
+		it, idx := db.NewIteratorWithPrefix([]byte(tt.prefix)), 0
+		for it.Next() {
+			if !bytes.Equal(it.Key(), []byte(tt.order[idx])) {
+				t.Errorf("test %d: item %d: key mismatch: have %s, want %s", i, idx, string(it.Key()), tt.order[idx])
+			}
+			if !bytes.Equal(it.Value(), []byte(tt.content[tt.order[idx]])) {
+				t.Errorf("test %d: item %d: value mismatch: have %s, want %s", i, idx, string(it.Value()), tt.content[tt.order[idx]])
+			}
+			idx++
+		}
+		if err := it.Error(); err != nil {
+			t.Errorf("test %d: iteration failed: %v", i, err)
+		}
+		if idx != len(tt.order) {
+			t.Errorf("test %d: iteration terminated prematurely: have %d, want %d", i, idx, len(tt.order))
+		}
+		it.Release()
+		release()
+	}
+}
+
+// Tests that iterations spanning several chunks return every key once, even if
+// the keys are deleted and inserted during the iteration.
+func TestBoltDBIteratorChunks(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	n := 3*iteratorChunk + 10
+	batch := db.NewBatch()
+	for i := 0; i < n; i++ {
+		batch.Put(numberedKey(2*i), []byte{byte(i)})
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	it := db.NewIteratorWithStart(numberedKey(2 * 5))
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		if want := numberedKey(2 * (count + 5)); !bytes.Equal(it.Key(), want) {
+			t.Fatalf("item %d: key mismatch: have %x, want %x", count, it.Key(), want)
+		}
+		// Deleting the current key and inserting keys the iterator already
+		// passed mustn't affect it
+		if err := db.Delete(it.Key()); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put(numberedKey(2*(count+5)-1), nil); err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if count != n-5 {
+		t.Errorf("iterated keys mismatch: have %d, want %d", count, n-5)
+	}
+}
+
+// Tests the reads of written, empty and deleted values.
+func TestBoltDBReadWrite(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	if _, err := db.Get([]byte("missing")); err == nil {
+		t.Error("missing key retrieved")
+	}
+	if has, err := db.Has([]byte("missing")); has || err != nil {
+		t.Errorf("missing key present: %v, %v", has, err)
+	}
+	db.Put([]byte("key"), []byte("value"))
+	db.Put([]byte("empty"), nil)
+
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Errorf("value mismatch: have %q, %v", value, err)
+	}
+	if value, err := db.Get([]byte("empty")); err != nil || value == nil || len(value) != 0 {
+		t.Errorf("empty value mismatch: have %q, %v", value, err)
+	}
+	// A key shouldn't match the next one it is a prefix of
+	if has, _ := db.Has([]byte("ke")); has {
+		t.Error("prefix of a key present")
+	}
+	db.Delete([]byte("key"))
+	if has, _ := db.Has([]byte("key")); has {
+		t.Error("deleted key present")
+	}
+}
+
+// Tests that batches apply their writes in order, and replay them.
+func TestBoltDBBatch(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	key, value := []byte("key"), []byte("value")
+
+	batch := db.NewBatch()
+	batch.Put(key, value)
+	batch.Delete([]byte("k1"))
+	batch.Put([]byte("k2"), []byte("v2"))
+	batch.Delete([]byte("k2"))
+
+	// The batch owns copies of the written data
+	key[0], value[0] = 'x', 'x'
+
+	if size := batch.ValueSize(); size != 9 {
+		t.Errorf("batch size mismatch: have %d, want 9", size)
+	}
+	if has, _ := db.Has([]byte("key")); has {
+		t.Fatal("batch written before Write")
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Errorf("value mismatch: have %q, %v", value, err)
+	}
+	if has, _ := db.Has([]byte("k2")); has {
+		t.Error("key deleted by the batch present")
+	}
+	replayed := memorydb.New()
+	replayed.Put([]byte("k1"), []byte("v1"))
+	if err := batch.Replay(replayed); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := replayed.Has([]byte("k1")); has || replayed.Len() != 1 {
+		t.Errorf("replayed batch mismatch: %d keys", replayed.Len())
+	}
+	batch.Reset()
+	if batch.ValueSize() != 0 {
+		t.Error("batch not reset")
+	}
+}
+
+// Tests that the compaction of the entire database gives back the space freed
+// by deletions and keeps the data.
+func TestBoltDBCompact(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	value := make([]byte, 1024)
+	for i := 0; i < 8; i++ {
+		batch := db.NewBatch()
+		for j := 0; j < 1024; j++ {
+			batch.Put(numberedKey(i*1024+j), value)
+		}
+		if err := batch.Write(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	batch := db.NewBatch()
+	for i := 0; i < 8*1024; i++ {
+		if i%16 != 0 {
+			batch.Delete(numberedKey(i))
+		}
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	size := func() int64 {
+		info, err := os.Stat(filepath.Join(db.Path(), FileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+	// Compacting a range does nothing
+	before := size()
+	if err := db.Compact([]byte{0}, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if after := size(); after != before {
+		t.Errorf("range compaction changed the file size: have %d, want %d", after, before)
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if after := size(); after*4 > before {
+		t.Errorf("file not compacted: have %d bytes, %d before", after, before)
+	}
+	it := db.NewIterator()
+	defer it.Release()
+
+	var count int
+	for ; it.Next(); count++ {
+		if want := numberedKey(16 * count); !bytes.Equal(it.Key(), want) {
+			t.Fatalf("item %d: key mismatch: have %x, want %x", count, it.Key(), want)
+		}
+	}
+	if count != 512 {
+		t.Errorf("keys mismatch after compaction: have %d, want 512", count)
+	}
+	if stats, err := db.Stat("boltdb.stats"); err != nil || stats == "" {
+		t.Errorf("stats unavailable after compaction: %v", err)
+	}
+}
+
+// Tests that the data survives the database being closed and reopened.
+func TestBoltDBReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltdb-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 3; i++ {
+		db, err := New(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < i; j++ {
+			if value, err := db.Get(numberedKey(j)); err != nil || !bytes.Equal(value, []byte(fmt.Sprint(j))) {
+				t.Errorf("open %d: key %d: value mismatch: have %q, %v", i, j, value, err)
+			}
+		}
+		db.Put(numberedKey(i), []byte(fmt.Sprint(i)))
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build !js
+
+// Package dbtest runs the tests of the layers built on a key-value store
+// against every database backend. It is only imported by tests.
+package dbtest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/boltdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/leveldb"
+	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
+)
+
+// Backend opens empty key-value stores of one database engine.
+type Backend struct {
+	Name string
+	Open func(dir string) (ethdb.KeyValueStore, error) // dir is an empty directory the store may use
+}
+
+// Backends are the database engines the tests are run against.
+var Backends = []Backend{
+	{"memorydb", func(string) (ethdb.KeyValueStore, error) { return memorydb.New(), nil }},
+	{"leveldb", func(dir string) (ethdb.KeyValueStore, error) { return leveldb.New(dir, 16, 16, "") }},
+	{"boltdb", func(dir string) (ethdb.KeyValueStore, error) { return boltdb.New(dir, "") }},
+}
+
+// ForEach runs test as a subtest against every backend. newDB opens an empty
+// store of the backend, which is closed and removed when the subtest ends.
+func ForEach(t *testing.T, test func(t *testing.T, newDB func() ethdb.KeyValueStore)) {
+	for _, backend := range Backends {
+		backend := backend
+		t.Run(backend.Name, func(t *testing.T) {
+			var cleanups []func()
+			defer func() {
+				for _, cleanup := range cleanups {
+					cleanup()
+				}
+			}()
+			newDB := func() ethdb.KeyValueStore {
+				dir, err := ioutil.TempDir("", "dbtest-"+backend.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				db, err := backend.Open(dir)
+				if err != nil {
+					os.RemoveAll(dir)
+					t.Fatal(err)
+				}
+				cleanups = append(cleanups, func() {
+					db.Close()
+					os.RemoveAll(dir)
+				})
+				return db
+			}
+			test(t, newDB)
+		})
+	}
+}
@@ -96,20 +96,25 @@ func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 // - highestBlock:  block number of the highest block header this node has received from peers
 // - pulledStates:  number of state entries processed until now
 // - knownStates:   number of known state entries that still need to be pulled
+// - vaultCurrentBlock: block number whose vault state was last rebuilt after a fast sync
+// - vaultHighestBlock: block number the vault state rebuild walks to
 func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	progress := s.b.Downloader().Progress()
+	vault := s.b.VaultRebuildProgress()
 
-	// Return not syncing if the synchronisation already completed
-	if progress.CurrentBlock >= progress.HighestBlock {
+	// Return not syncing if the synchronisation and the vault state rebuild already completed
+	if progress.CurrentBlock >= progress.HighestBlock && !vault.Active {
 		return false, nil
 	}
 	// Otherwise gather the block sync stats
 	return map[string]interface{}{
-		"startingBlock": hexutil.Uint64(progress.StartingBlock),
-		"currentBlock":  hexutil.Uint64(progress.CurrentBlock),
-		"highestBlock":  hexutil.Uint64(progress.HighestBlock),
-		"pulledStates":  hexutil.Uint64(progress.PulledStates),
-		"knownStates":   hexutil.Uint64(progress.KnownStates),
+		"startingBlock":     hexutil.Uint64(progress.StartingBlock),
+		"currentBlock":      hexutil.Uint64(progress.CurrentBlock),
+		"highestBlock":      hexutil.Uint64(progress.HighestBlock),
+		"pulledStates":      hexutil.Uint64(progress.PulledStates),
+		"knownStates":       hexutil.Uint64(progress.KnownStates),
+		"vaultCurrentBlock": hexutil.Uint64(vault.Current),
+		"vaultHighestBlock": hexutil.Uint64(vault.Highest),
 	}, nil
 }
 
@@ -194,6 +199,31 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// RPCQuarantinedTx is a contract creation kept aside by the code analysis.
+type RPCQuarantinedTx struct {
+	Transaction *RPCTransaction        `json:"transaction"`
+	Findings    []core.ContractFinding `json:"findings"`
+	Time        time.Time              `json:"time"`
+}
+
+// Quarantined returns the contract creations kept aside by the code analysis,
+// oldest first.
+func (s *PublicTxPoolAPI) Quarantined() []*RPCQuarantinedTx {
+	quarantined := s.b.TxPoolQuarantined()
+	txs := make([]*RPCQuarantinedTx, len(quarantined))
+	for i, qtx := range quarantined {
+		txs[i] = &RPCQuarantinedTx{
+			Transaction: newRPCPendingTransaction(qtx.Tx),
+			Findings:    qtx.Findings,
+			Time:        qtx.Time,
+		}
+		if txs[i].Findings == nil {
+			txs[i].Findings = []core.ContractFinding{}
+		}
+	}
+	return txs
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
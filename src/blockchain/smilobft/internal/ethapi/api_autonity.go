@@ -0,0 +1,249 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"go-smilo/src/blockchain/smilobft/accounts/abi"
+	"go-smilo/src/blockchain/smilobft/contracts/autonity"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+var (
+	errNoAutonityContract = errors.New("autonity contract is not available")
+	errNoEconomicHistory  = errors.New("economic history is not recorded, enable it with --autonity.economichistory")
+)
+
+// CommitteeMember is a validator of the consensus committee of the Autonity
+// contract.
+type CommitteeMember struct {
+	Address  common.Address `json:"address"`
+	UserType uint8          `json:"userType"`
+	Stake    *hexutil.Big   `json:"stake"`
+	Enode    string         `json:"enode"`
+}
+
+// PublicAutonityAPI provides an API to access the Autonity contract.
+type PublicAutonityAPI struct {
+	b Backend
+}
+
+// NewPublicAutonityAPI creates a new Autonity contract API.
+func NewPublicAutonityAPI(b Backend) *PublicAutonityAPI {
+	return &PublicAutonityAPI{b}
+}
+
+// GetUpgradeHistory returns the upgrades of the Autonity contract scheduled in
+// the chain config, and whether they were applied at the head of the chain.
+func (s *PublicAutonityAPI) GetUpgradeHistory(ctx context.Context) ([]autonity.UpgradeRecord, error) {
+	contract := s.b.AutonityContract()
+	if contract == nil {
+		return nil, errNoAutonityContract
+	}
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return contract.UpgradeHistory(state)
+}
+
+// GetEconomicHistory returns the recorded economic snapshots of the Autonity
+// contract of the blocks from and to, both included. If address is given, the
+// snapshots only hold the state of this user.
+func (s *PublicAutonityAPI) GetEconomicHistory(ctx context.Context, from, to rpc.BlockNumber, address *common.Address) ([]*autonity.EconomicSnapshot, error) {
+	contract := s.b.AutonityContract()
+	if contract == nil {
+		return nil, errNoAutonityContract
+	}
+	history := contract.EconomicHistory()
+	if history == nil {
+		return nil, errNoEconomicHistory
+	}
+	first, last := from.Int64(), to.Int64()
+	if from < 0 || to < 0 {
+		head := s.b.CurrentBlock().Number().Int64()
+		if from < 0 {
+			first = head
+		}
+		if to < 0 {
+			last = head
+		}
+	}
+	snapshots, err := history.Range(uint64(first), uint64(last))
+	if err != nil || address == nil {
+		return snapshots, err
+	}
+	for i, snapshot := range snapshots {
+		snapshots[i] = snapshot.Filter(*address)
+	}
+	return snapshots, nil
+}
+
+// GetCommittee returns the consensus committee of the Autonity contract at the
+// given block, the latest one if omitted. Contracts without a committee return
+// an error.
+func (s *PublicAutonityAPI) GetCommittee(ctx context.Context, blockNr *rpc.BlockNumber) ([]CommitteeMember, error) {
+	var committee []struct {
+		Addr     common.Address
+		UserType uint8
+		Stake    *big.Int
+		Enode    string
+	}
+	if err := s.call(ctx, blockNr, &committee, "getCommittee"); err != nil {
+		return nil, err
+	}
+	members := make([]CommitteeMember, len(committee))
+	for i, m := range committee {
+		members[i] = CommitteeMember{Address: m.Addr, UserType: m.UserType, Stake: (*hexutil.Big)(m.Stake), Enode: m.Enode}
+	}
+	return members, nil
+}
+
+// GetAccountStake returns the stake of a stakeholder at the given block, the
+// latest one if omitted.
+func (s *PublicAutonityAPI) GetAccountStake(ctx context.Context, account common.Address, blockNr *rpc.BlockNumber) (*hexutil.Big, error) {
+	stake := new(big.Int)
+	if err := s.call(ctx, blockNr, &stake, "getAccountStake", account); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(stake), nil
+}
+
+// call executes a read only method of the Autonity contract and unpacks the
+// returned values into result
+func (s *PublicAutonityAPI) call(ctx context.Context, blockNr *rpc.BlockNumber, result interface{}, method string, args ...interface{}) error {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	header, err := s.b.HeaderByNumber(ctx, number)
+	if header == nil || err != nil {
+		return fmt.Errorf("unknown block %d: %v", number, err)
+	}
+	address, contractABI, input, err := packAutonityCall(s.b, header.Number.Uint64(), method, args...)
+	if err != nil {
+		return err
+	}
+	from := s.b.ChainConfig().AutonityContractConfig.Deployer
+	ret, _, failed, err := DoCall(ctx, s.b, CallArgs{From: &from, To: &address, Data: &input}, number, nil, vm.Config{}, 5*time.Second, s.b.RPCGasCap())
+	if err != nil {
+		return err
+	}
+	if failed {
+		return fmt.Errorf("autonity contract %s reverted", method)
+	}
+	return contractABI.Unpack(result, method, ret)
+}
+
+// PrivateAutonityAPI sends the governance operator transactions of the
+// Autonity contract. The operator account must be unlocked.
+type PrivateAutonityAPI struct {
+	b   Backend
+	txs *PublicTransactionPoolAPI
+}
+
+// NewPrivateAutonityAPI creates a new Autonity contract governance API.
+func NewPrivateAutonityAPI(b Backend, nonceLock *AddrLocker) *PrivateAutonityAPI {
+	return &PrivateAutonityAPI{b, NewPublicTransactionPoolAPI(b, nonceLock)}
+}
+
+// AddValidator adds a validator with the given stake and enode.
+func (s *PrivateAutonityAPI) AddValidator(ctx context.Context, address common.Address, stake hexutil.Big, enode string) (common.Hash, error) {
+	return s.send(ctx, "addValidator", address, stake.ToInt(), enode)
+}
+
+// AddStakeholder adds a stakeholder with the given enode and stake.
+func (s *PrivateAutonityAPI) AddStakeholder(ctx context.Context, address common.Address, enode string, stake hexutil.Big) (common.Hash, error) {
+	return s.send(ctx, "addStakeholder", address, enode, stake.ToInt())
+}
+
+// RemoveUser removes a user of any type.
+func (s *PrivateAutonityAPI) RemoveUser(ctx context.Context, address common.Address) (common.Hash, error) {
+	return s.send(ctx, "removeUser", address)
+}
+
+// MintStake creates stake for a stakeholder.
+func (s *PrivateAutonityAPI) MintStake(ctx context.Context, account common.Address, amount hexutil.Big) (common.Hash, error) {
+	return s.send(ctx, "mintStake", account, amount.ToInt())
+}
+
+// RedeemStake destroys stake of a stakeholder.
+func (s *PrivateAutonityAPI) RedeemStake(ctx context.Context, account common.Address, amount hexutil.Big) (common.Hash, error) {
+	return s.send(ctx, "redeemStake", account, amount.ToInt())
+}
+
+// SetMinimumGasPrice sets the minimum gas price of the transactions.
+func (s *PrivateAutonityAPI) SetMinimumGasPrice(ctx context.Context, price hexutil.Big) (common.Hash, error) {
+	return s.send(ctx, "setMinimumGasPrice", price.ToInt())
+}
+
+// SetCommitteeSize sets the maximum size of the consensus committee. Contracts
+// without a committee return an error.
+func (s *PrivateAutonityAPI) SetCommitteeSize(ctx context.Context, size hexutil.Big) (common.Hash, error) {
+	return s.send(ctx, "setCommitteeSize", size.ToInt())
+}
+
+// send signs a call of method by the governance operator and submits it to the
+// transaction pool
+func (s *PrivateAutonityAPI) send(ctx context.Context, method string, args ...interface{}) (common.Hash, error) {
+	address, _, input, err := packAutonityCall(s.b, s.b.CurrentBlock().NumberU64(), method, args...)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// The default gas of SendTransaction is too low for most operator calls
+	operator := s.b.ChainConfig().AutonityContractConfig.Operator
+	gas, err := DoEstimateGas(ctx, s.b, CallArgs{From: &operator, To: &address, Data: &input}, rpc.PendingBlockNumber, s.b.RPCGasCap())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return s.txs.SendTransaction(ctx, SendTxArgs{
+		From: operator,
+		To:   &address,
+		Gas:  &gas,
+		Data: &input,
+	})
+}
+
+// packAutonityCall packs a call of method with the ABI of the Autonity contract
+// in the state of block number, and returns the address of the contract.
+func packAutonityCall(b Backend, number uint64, method string, args ...interface{}) (common.Address, *abi.ABI, hexutil.Bytes, error) {
+	contract := b.AutonityContract()
+	if contract == nil {
+		return common.Address{}, nil, nil, errNoAutonityContract
+	}
+	address, contractABI, err := contract.ContractAt(number)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if _, ok := contractABI.Methods[method]; !ok {
+		return common.Address{}, nil, nil, fmt.Errorf("autonity contract at block %d has no method %s", number, method)
+	}
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, contractABI, input, nil
+}
@@ -0,0 +1,449 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go-smilo/src/blockchain/smilobft/accounts"
+	"go-smilo/src/blockchain/smilobft/accounts/keystore"
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/contracts/autonity"
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/rawdb"
+	"go-smilo/src/blockchain/smilobft/core/state"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+// autonityTestChain is the chain of the Autonity contract of the test backend
+type autonityTestChain struct {
+	consensus.ChainReader
+	config *params.ChainConfig
+	head   *types.Block
+}
+
+func (c *autonityTestChain) Config() *params.ChainConfig                 { return c.config }
+func (c *autonityTestChain) GetVMConfig() *vm.Config                     { return &vm.Config{} }
+func (c *autonityTestChain) CurrentBlock() *types.Block                  { return c.head }
+func (c *autonityTestChain) Engine() consensus.Engine                    { return nil }
+func (c *autonityTestChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (c *autonityTestChain) UpdateEnodeWhitelist(*types.Nodes, uint64)   {}
+func (c *autonityTestChain) ReadEnodeWhitelist(bool) *types.Nodes        { return nil }
+func (c *autonityTestChain) UpdateBlacklist(*types.Nodes)                {}
+func (c *autonityTestChain) ReadBlacklist(bool) *types.Nodes             { return nil }
+
+// autonityTestBackend is a Backend holding the state of a single block with
+// the Autonity contract deployed. Transactions sent to it are applied to the
+// state right away.
+type autonityTestBackend struct {
+	Backend  // Methods the Autonity APIs do not use are left unimplemented
+	chain    *autonityTestChain
+	contract *autonity.Contract
+	state    *state.StateDB
+	am       *accounts.Manager
+}
+
+func (b *autonityTestBackend) AutonityContract() *autonity.Contract { return b.contract }
+func (b *autonityTestBackend) ChainConfig() *params.ChainConfig     { return b.chain.config }
+func (b *autonityTestBackend) CurrentBlock() *types.Block           { return b.chain.head }
+func (b *autonityTestBackend) AccountManager() *accounts.Manager    { return b.am }
+func (b *autonityTestBackend) RPCGasCap() *big.Int                  { return nil }
+func (b *autonityTestBackend) SuggestPrice(context.Context) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func (b *autonityTestBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.chain.head.Header(), nil
+}
+
+func (b *autonityTestBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.chain.head, nil
+}
+
+func (b *autonityTestBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (vm.SmiloAPIState, *types.Header, error) {
+	return b.state.Copy(), b.chain.head.Header(), nil
+}
+
+func (b *autonityTestBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	return b.state.GetNonce(addr), nil
+}
+
+func (b *autonityTestBackend) GetEVM(ctx context.Context, msg core.Message, st vm.SmiloAPIState, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	statedb := st.(*state.StateDB)
+	statedb.SetBalance(msg.From(), math.MaxBig256, header.Number)
+	statedb.SetSmiloPay(msg.From(), math.MaxBig256)
+	context := core.NewEVMContext(msg, header, b.chain, &common.Address{})
+	return vm.NewEVM(context, statedb, statedb, b.chain.config, vmCfg), func() error { return nil }, nil
+}
+
+func (b *autonityTestBackend) SendTx(ctx context.Context, tx *types.Transaction) error {
+	header := b.chain.head.Header()
+	msg, err := tx.AsMessage(types.MakeSigner(b.chain.config, header.Number))
+	if err != nil {
+		return err
+	}
+	context := core.NewEVMContext(msg, header, b.chain, &common.Address{})
+	evm := vm.NewEVM(context, b.state, b.state, b.chain.config, vm.Config{})
+	_, _, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		return err
+	}
+	if failed {
+		return errTestTxFailed
+	}
+	return nil
+}
+
+var errTestTxFailed = errors.New("transaction failed")
+
+func newAutonityTestHeader(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   100000000,
+		Difficulty: big.NewInt(1),
+		Time:       number,
+	}
+}
+
+func newAutonityTestUser(t *testing.T, userType params.UserType, stake uint64) params.User {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params.User{
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+		Enode:   enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 0).String(),
+		Type:    userType,
+		Stake:   stake,
+	}
+}
+
+// newAutonityTestBackend creates a backend at block 1 with the Autonity
+// contract deployed and an upgrade of the contract scheduled at block 2. The
+// operator account is unlocked in the account manager.
+func newAutonityTestBackend(t *testing.T) (*autonityTestBackend, func()) {
+	operatorKey, _ := crypto.GenerateKey()
+	config := &params.AutonityContractGenesis{
+		Operator:    crypto.PubkeyToAddress(operatorKey.PublicKey),
+		MinGasPrice: 10,
+	}
+	for _, u := range []struct {
+		userType params.UserType
+		stake    uint64
+	}{{params.UserValidator, 100}, {params.UserStakeHolder, 50}, {params.UserParticipant, 0}} {
+		config.Users = append(config.Users, newAutonityTestUser(t, u.userType, u.stake))
+	}
+	config.AddDefault()
+	upgrade := params.AutonityContractUpgrade{Block: 2, Bytecode: params.DefaultBytecode, ABI: params.DefaultABI}
+	sig, err := crypto.Sign(upgrade.SigHash().Bytes(), operatorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgrade.Signature = sig
+	config.Upgrades = append(config.Upgrades, upgrade)
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.AutonityContractConfig = config
+	chain := &autonityTestChain{config: &chainConfig, head: types.NewBlockWithHeader(newAutonityTestHeader(1))}
+	getHashFn := func(*types.Header, autonity.ChainContext) func(uint64) common.Hash {
+		return func(uint64) common.Hash { return common.Hash{} }
+	}
+	contract := autonity.NewAutonityContract(chain, core.CanTransfer, core.Transfer, getHashFn)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	if _, err := contract.DeployAutonityContract(chain, chain.head.Header(), statedb); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "autonity-api-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	operator, err := ks.ImportECDSA(operatorKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(operator, ""); err != nil {
+		t.Fatal(err)
+	}
+	statedb.SetBalance(operator.Address, big.NewInt(1e18), common.Big1)
+	statedb.SetSmiloPay(operator.Address, big.NewInt(1e18))
+
+	b := &autonityTestBackend{
+		chain:    chain,
+		contract: contract,
+		state:    statedb,
+		am:       accounts.NewManager(&accounts.Config{}, ks),
+	}
+	return b, func() { os.RemoveAll(dir) }
+}
+
+func TestAutonityUpgradeHistory(t *testing.T) {
+	b, cleanup := newAutonityTestBackend(t)
+	defer cleanup()
+	api := NewPublicAutonityAPI(b)
+
+	records, err := api.GetUpgradeHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Block != 2 || records[0].Applied {
+		t.Fatalf("upgrade history before the upgrade: have %+v", records)
+	}
+
+	b.chain.head = types.NewBlockWithHeader(newAutonityTestHeader(2))
+	if err := b.contract.ApplyUpgrade(b.chain.head.Header(), b.state); err != nil {
+		t.Fatal(err)
+	}
+	records, err = api.GetUpgradeHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].Applied || records[0].CodeHash != b.state.GetCodeHash(records[0].To) {
+		t.Fatalf("upgrade history after the upgrade: have %+v", records)
+	}
+
+	if _, err := NewPublicAutonityAPI(&autonityTestBackend{}).GetUpgradeHistory(context.Background()); err != errNoAutonityContract {
+		t.Errorf("have %v, want %v", err, errNoAutonityContract)
+	}
+}
+
+func TestAutonityEconomicHistory(t *testing.T) {
+	b, cleanup := newAutonityTestBackend(t)
+	defer cleanup()
+	api := NewPublicAutonityAPI(b)
+
+	if _, err := api.GetEconomicHistory(context.Background(), 0, 1, nil); err != errNoEconomicHistory {
+		t.Fatalf("have %v, want %v", err, errNoEconomicHistory)
+	}
+
+	history := autonity.NewEconomicHistory(rawdb.NewMemoryDatabase())
+	b.contract.SetEconomicHistory(history)
+	users := b.chain.config.AutonityContractConfig.Users
+	for number := uint64(0); number <= 1; number++ {
+		snapshot := &autonity.EconomicSnapshot{Number: number, MinGasPrice: big.NewInt(10), StakeSupply: big.NewInt(150), OperatorBalance: new(big.Int), BlockReward: new(big.Int)}
+		for _, u := range users {
+			snapshot.Users = append(snapshot.Users, autonity.EconomicUserSnapshot{Address: u.Address, Stake: new(big.Int).SetUint64(u.Stake), Balance: new(big.Int), CommissionRate: new(big.Int), Reward: new(big.Int)})
+		}
+		if err := history.Write(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		from, to rpc.BlockNumber
+		address  *common.Address
+		numbers  []uint64
+		users    int
+	}{
+		{0, 1, nil, []uint64{0, 1}, len(users)},
+		{rpc.LatestBlockNumber, rpc.LatestBlockNumber, nil, []uint64{1}, len(users)},
+		{0, 0, &users[1].Address, []uint64{0}, 1},
+	}
+	for i, tt := range tests {
+		snapshots, err := api.GetEconomicHistory(context.Background(), tt.from, tt.to, tt.address)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if len(snapshots) != len(tt.numbers) {
+			t.Fatalf("test %d: snapshots: have %d, want %d", i, len(snapshots), len(tt.numbers))
+		}
+		for j, s := range snapshots {
+			if s.Number != tt.numbers[j] || len(s.Users) != tt.users {
+				t.Errorf("test %d: snapshot %d: have number %d and %d users, want %d and %d", i, j, s.Number, len(s.Users), tt.numbers[j], tt.users)
+			}
+		}
+	}
+	if snapshots, _ := api.GetEconomicHistory(context.Background(), 0, 0, &users[1].Address); snapshots[0].Users[0].Address != users[1].Address {
+		t.Errorf("filtered user: have %v, want %v", snapshots[0].Users[0].Address, users[1].Address)
+	}
+}
+
+func TestAutonityAccountStake(t *testing.T) {
+	b, cleanup := newAutonityTestBackend(t)
+	defer cleanup()
+	api := NewPublicAutonityAPI(b)
+
+	for _, u := range b.chain.config.AutonityContractConfig.Users {
+		stake, err := api.GetAccountStake(context.Background(), u.Address, nil)
+		if u.Type == params.UserParticipant {
+			// Participants cannot hold stake, the contract reverts
+			if err == nil {
+				t.Errorf("stake of participant %v: have %v, want error", u.Address.Hex(), stake.ToInt())
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stake.ToInt().Uint64() != u.Stake {
+			t.Errorf("stake of %v: have %v, want %v", u.Address.Hex(), stake.ToInt(), u.Stake)
+		}
+	}
+}
+
+func TestAutonityGovernance(t *testing.T) {
+	// newcomer is added by the addValidator and addStakeholder tests
+	newcomer := newAutonityTestUser(t, params.UserParticipant, 0)
+	read := func(t *testing.T, api *PublicAutonityAPI, result interface{}, method string, args ...interface{}) {
+		if err := api.call(context.Background(), nil, result, method, args...); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+	stakeOf := func(t *testing.T, api *PublicAutonityAPI, address common.Address) uint64 {
+		stake, err := api.GetAccountStake(context.Background(), address, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stake.ToInt().Uint64()
+	}
+
+	tests := []struct {
+		name  string
+		send  func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error)
+		check func(t *testing.T, api *PublicAutonityAPI, users []params.User)
+	}{
+		{
+			"addValidator",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.AddValidator(context.Background(), newcomer.Address, hexutil.Big(*big.NewInt(30)), newcomer.Enode)
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				var validators []common.Address
+				read(t, api, &validators, "getValidators")
+				if validators[len(validators)-1] != newcomer.Address {
+					t.Errorf("validators: have %v, want %v last", validators, newcomer.Address.Hex())
+				}
+				if stake := stakeOf(t, api, newcomer.Address); stake != 30 {
+					t.Errorf("stake: have %d, want 30", stake)
+				}
+			},
+		},
+		{
+			"addStakeholder",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.AddStakeholder(context.Background(), newcomer.Address, newcomer.Enode, hexutil.Big(*big.NewInt(40)))
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				var stakeholders []common.Address
+				read(t, api, &stakeholders, "getStakeholders")
+				if stakeholders[len(stakeholders)-1] != newcomer.Address {
+					t.Errorf("stakeholders: have %v, want %v last", stakeholders, newcomer.Address.Hex())
+				}
+				if stake := stakeOf(t, api, newcomer.Address); stake != 40 {
+					t.Errorf("stake: have %d, want 40", stake)
+				}
+			},
+		},
+		{
+			"removeUser",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.RemoveUser(context.Background(), users[2].Address)
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				var member bool
+				read(t, api, &member, "checkMember", users[2].Address)
+				if member {
+					t.Error("removed user is still a member")
+				}
+			},
+		},
+		{
+			"mintStake",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.MintStake(context.Background(), users[1].Address, hexutil.Big(*big.NewInt(5)))
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				if stake := stakeOf(t, api, users[1].Address); stake != 55 {
+					t.Errorf("stake: have %d, want 55", stake)
+				}
+			},
+		},
+		{
+			"redeemStake",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.RedeemStake(context.Background(), users[1].Address, hexutil.Big(*big.NewInt(5)))
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				if stake := stakeOf(t, api, users[1].Address); stake != 45 {
+					t.Errorf("stake: have %d, want 45", stake)
+				}
+			},
+		},
+		{
+			"setMinimumGasPrice",
+			func(api *PrivateAutonityAPI, users []params.User) (common.Hash, error) {
+				return api.SetMinimumGasPrice(context.Background(), hexutil.Big(*big.NewInt(42)))
+			},
+			func(t *testing.T, api *PublicAutonityAPI, users []params.User) {
+				price := new(big.Int)
+				read(t, api, &price, "getMinimumGasPrice")
+				if price.Uint64() != 42 {
+					t.Errorf("minimum gas price: have %v, want 42", price)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, cleanup := newAutonityTestBackend(t)
+			defer cleanup()
+
+			users := b.chain.config.AutonityContractConfig.Users
+			hash, err := tt.send(NewPrivateAutonityAPI(b, new(AddrLocker)), users)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hash == (common.Hash{}) {
+				t.Error("no transaction hash returned")
+			}
+			tt.check(t, NewPublicAutonityAPI(b), users)
+		})
+	}
+}
+
+// The default contract has no committee, its calls fail instead of packing
+func TestAutonityCommitteeNotInContract(t *testing.T) {
+	b, cleanup := newAutonityTestBackend(t)
+	defer cleanup()
+
+	if _, err := NewPublicAutonityAPI(b).GetCommittee(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "has no method getCommittee") {
+		t.Errorf("getCommittee: have %v, want missing method error", err)
+	}
+	if _, err := NewPrivateAutonityAPI(b, new(AddrLocker)).SetCommitteeSize(context.Background(), hexutil.Big(*big.NewInt(3))); err == nil || !strings.Contains(err.Error(), "has no method setCommitteeSize") {
+		t.Errorf("setCommitteeSize: have %v, want missing method error", err)
+	}
+}
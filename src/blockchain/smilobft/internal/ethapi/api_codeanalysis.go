@@ -1,123 +1,147 @@
 package ethapi
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"go-smilo/src/blockchain/smilobft/core/codeanalysis"
 )
 
+var errCodeAnalysisDisabled = errors.New("code analysis is disabled")
+
 type codeAnalysisAPI struct {
-	// This lock guards the codeanalysis path set through the API.
-	// It also ensures that only one codeanalysis process is used at
-	// any time.
+	// This lock guards the codeanalysis path set through the API. The
+	// analysis runs in process, a path only enables it.
 	mu               sync.Mutex
 	codeanalysisPath string
 }
 
+// PublicCodeAnalysisAPI runs the static security analysis of EVM bytecode.
 type PublicCodeAnalysisAPI codeAnalysisAPI
+
+// CodeAnalysisAdminAPI enables and disables the code analysis.
 type CodeAnalysisAdminAPI codeAnalysisAPI
 
+// AnalysysResult is the result of a code analysis.
 type AnalysysResult struct {
-	Vulnerable bool
-	Output     string
+	Vulnerable  bool                   // Whether a finding is at least of medium severity
+	Incomplete  bool                   // Whether the code could not be fully analyzed
+	Findings    []codeanalysis.Finding // Findings, most severe first
+	Output      string                 `json:",omitempty"` // Findings in text format, unless silent
+	Disassembly []string               `json:",omitempty"`
+	Cfg         []*CodeAnalysisBlock   `json:",omitempty"`
+	Detectors   []CodeAnalysisDetector `json:",omitempty"`
 }
 
-type SmiloAnalysisArgs struct {
-	Code       string
-	Arguments  string
-	Abi        string
-	Hash       string
-	List       bool
-	Disassm    bool
-	SingleStep bool
-	Cfg        bool
-	CfgFull    bool
-	Decompile  bool
-	Debug      bool
-	Silent     bool
+// CodeAnalysisBlock is a basic block of the control flow graph of the code.
+type CodeAnalysisBlock struct {
+	Start        hexutil.Uint64   `json:"start"`
+	End          hexutil.Uint64   `json:"end"`
+	Successors   []hexutil.Uint64 `json:"successors"`
+	Unresolved   bool             `json:"unresolved,omitempty"`
+	Instructions []string         `json:"instructions,omitempty"`
 }
 
-func parseArgs(key, value string) string {
-	return fmt.Sprintf("--%s=%s", key, value)
+// CodeAnalysisDetector is an available detector.
+type CodeAnalysisDetector struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
-func (api PublicCodeAnalysisAPI) CodeAnalysis(args SmiloAnalysisArgs) (*AnalysysResult, error) {
-	api.mu.Lock()
-	defer api.mu.Unlock()
-
-	var (
-		stderr, stdout bytes.Buffer
-		arguments      []string
-	)
-	arguments = append(arguments, parseArgs("code", args.Code))
+// SmiloAnalysisArgs are the arguments of a code analysis.
+type SmiloAnalysisArgs struct {
+	Code      string   // Hex encoded bytecode
+	SourceMap string   // Solidity source map of the code, to locate the findings
+	Detectors []string // Detectors to run, all if empty
+	List      bool     // List the available detectors
+	Disassm   bool     // Return the disassembly
+	Cfg       bool     // Return the control flow graph
+	CfgFull   bool     // Return the control flow graph with the instructions
+	Silent    bool     // Omit the text output
+}
 
-	if args.Arguments != "" {
-		arguments = append(arguments, parseArgs("arguments", args.Arguments))
-	}
-	if args.Abi != "" {
-		arguments = append(arguments, parseArgs("abi", args.Abi))
-	}
-	if args.Hash != "" {
-		arguments = append(arguments, parseArgs("hash", args.Hash))
-	}
-	if args.List {
-		arguments = append(arguments, parseArgs("list", ""))
+// CodeAnalysis analyzes the code for insecure patterns.
+func (api *PublicCodeAnalysisAPI) CodeAnalysis(args SmiloAnalysisArgs) (*AnalysysResult, error) {
+	api.mu.Lock()
+	enabled := api.codeanalysisPath != ""
+	api.mu.Unlock()
+	if !enabled {
+		return nil, errCodeAnalysisDisabled
 	}
-	if args.Disassm {
-		arguments = append(arguments, parseArgs("disassemble", ""))
+
+	code, err := hexutil.Decode(args.Code)
+	if err != nil {
+		if code, err = hexutil.Decode("0x" + args.Code); err != nil {
+			return nil, fmt.Errorf("invalid code: %v", err)
+		}
 	}
-	if args.SingleStep {
-		arguments = append(arguments, parseArgs("single-step", ""))
+	report, err := codeanalysis.Analyze(code, &codeanalysis.Options{
+		Detectors: args.Detectors,
+		SourceMap: args.SourceMap,
+	})
+	if err != nil {
+		return nil, err
 	}
-	if args.Cfg {
-		arguments = append(arguments, parseArgs("cfg", ""))
+	res := &AnalysysResult{
+		Vulnerable: report.Vulnerable(codeanalysis.SeverityMedium),
+		Incomplete: report.Incomplete,
+		Findings:   report.Findings,
 	}
-	if args.CfgFull {
-		arguments = append(arguments, parseArgs("cfg-full", ""))
+	if res.Findings == nil {
+		res.Findings = []codeanalysis.Finding{}
 	}
-	if args.Decompile {
-		arguments = append(arguments, parseArgs("decompile", ""))
+	if !args.Silent {
+		var out strings.Builder
+		for _, f := range report.Findings {
+			fmt.Fprintln(&out, f)
+		}
+		if report.Incomplete {
+			fmt.Fprintln(&out, "Warning: the code could not be fully analyzed")
+		}
+		res.Output = out.String()
 	}
-	if args.Debug {
-		arguments = append(arguments, parseArgs("debug", ""))
+	if args.Disassm {
+		for _, in := range report.Instructions {
+			res.Disassembly = append(res.Disassembly, in.String())
+		}
 	}
-	cmd := exec.Command(api.codeanalysisPath, arguments...)
-	cmd.Stderr = &stderr
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
-		return nil, err
+	if args.Cfg || args.CfgFull {
+		for _, b := range report.CFG.Blocks {
+			block := &CodeAnalysisBlock{
+				Start:      hexutil.Uint64(b.Start),
+				End:        hexutil.Uint64(b.End),
+				Successors: make([]hexutil.Uint64, len(b.Succs)),
+				Unresolved: b.Unresolved,
+			}
+			for i, succ := range b.Succs {
+				block.Successors[i] = hexutil.Uint64(succ)
+			}
+			if args.CfgFull {
+				for _, in := range b.Instructions {
+					block.Instructions = append(block.Instructions, in.String())
+				}
+			}
+			res.Cfg = append(res.Cfg, block)
+		}
 	}
-	out := stdout.String()
-	pr := new(AnalysysResult)
-	pr.Vulnerable = strings.Contains(out, "[0;31m")
-	if !args.Silent {
-		pr.Output = out
+	if args.List {
+		for _, d := range codeanalysis.Detectors {
+			res.Detectors = append(res.Detectors, CodeAnalysisDetector{Name: d.Name, Description: d.Description})
+		}
 	}
-	return pr, nil
+	return res, nil
 }
 
-// SetSolc sets the Solidity compiler path to be used by the node.
+// SetSmiloAnalysis sets the code analysis path of the node. The analysis runs
+// in process, so the path is only kept for compatibility: an empty path
+// disables eth_codeAnalysis and any other path enables it.
 func (api *CodeAnalysisAdminAPI) SetSmiloAnalysis(path string) error {
 	api.mu.Lock()
 	defer api.mu.Unlock()
-	err := smiloAnalysisCheck(path)
-	if err != nil {
-		return err
-	}
 	api.codeanalysisPath = path
 	return nil
 }
-
-// SolidityVersion runs codeanalysisPath and parses its version output.
-func smiloAnalysisCheck(codeanalysisPath string) error {
-	if codeanalysisPath == "" {
-		codeanalysisPath = "smilo-code-analysis"
-	}
-	var out bytes.Buffer
-	cmd := exec.Command(codeanalysisPath, "--version")
-	cmd.Stdout = &out
-	err := cmd.Run()
-	return err
-}
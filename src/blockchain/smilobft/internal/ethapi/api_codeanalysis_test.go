@@ -0,0 +1,98 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/accounts"
+)
+
+// codeAnalysisTestBackend is a Backend only configuring the optional APIs.
+type codeAnalysisTestBackend struct {
+	Backend
+	codeanalysisPath string
+}
+
+func (b *codeAnalysisTestBackend) AccountManager() *accounts.Manager {
+	return accounts.NewManager(&accounts.Config{})
+}
+func (b *codeAnalysisTestBackend) GetSolcPath() string              { return "" }
+func (b *codeAnalysisTestBackend) GetSmiloCodeAnalysisPath() string { return b.codeanalysisPath }
+
+func codeAnalysisAPIs(apis []interface{}) (*PublicCodeAnalysisAPI, *CodeAnalysisAdminAPI) {
+	var (
+		public *PublicCodeAnalysisAPI
+		admin  *CodeAnalysisAdminAPI
+	)
+	for _, api := range apis {
+		switch service := api.(type) {
+		case *PublicCodeAnalysisAPI:
+			public = service
+		case *CodeAnalysisAdminAPI:
+			admin = service
+		}
+	}
+	return public, admin
+}
+
+func TestCodeAnalysisAPIs(t *testing.T) {
+	var services []interface{}
+	for _, api := range GetAPIs(&codeAnalysisTestBackend{}) {
+		services = append(services, api.Service)
+	}
+	if public, admin := codeAnalysisAPIs(services); public != nil || admin != nil {
+		t.Fatal("code analysis APIs registered without a code analysis path")
+	}
+
+	services = nil
+	for _, api := range GetAPIs(&codeAnalysisTestBackend{codeanalysisPath: "smilo-code-analysis"}) {
+		switch api.Service.(type) {
+		case *PublicCodeAnalysisAPI:
+			if api.Namespace != "eth" {
+				t.Errorf("code analysis namespace: have %s, want eth", api.Namespace)
+			}
+		case *CodeAnalysisAdminAPI:
+			if api.Namespace != "admin" {
+				t.Errorf("code analysis admin namespace: have %s, want admin", api.Namespace)
+			}
+		}
+		services = append(services, api.Service)
+	}
+	public, admin := codeAnalysisAPIs(services)
+	if public == nil || admin == nil {
+		t.Fatal("code analysis APIs not registered with a code analysis path")
+	}
+
+	// A single STOP
+	args := SmiloAnalysisArgs{Code: "0x00", Silent: true}
+	if _, err := public.CodeAnalysis(args); err != nil {
+		t.Fatalf("failed to analyze: %v", err)
+	}
+	if err := admin.SetSmiloAnalysis(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := public.CodeAnalysis(args); err != errCodeAnalysisDisabled {
+		t.Errorf("have %v, want %v", err, errCodeAnalysisDisabled)
+	}
+	if err := admin.SetSmiloAnalysis("smilo-code-analysis"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := public.CodeAnalysis(args); err != nil {
+		t.Errorf("failed to analyze once enabled again: %v", err)
+	}
+}
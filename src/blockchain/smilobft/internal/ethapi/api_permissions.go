@@ -0,0 +1,78 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/core"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/core/vm"
+	"go-smilo/src/blockchain/smilobft/params"
+	"go-smilo/src/blockchain/smilobft/rpc"
+)
+
+// AccountAccess is the transaction access of an account.
+type AccountAccess struct {
+	Account  common.Address       `json:"account"`
+	Access   params.AccountAccess `json:"access"`
+	Transact bool                 `json:"transact"` // Whether the account may send value and call contracts
+	Deploy   bool                 `json:"deploy"`   // Whether the account may deploy contracts
+}
+
+// PublicPermissionsAPI provides an API to access the account permissions.
+type PublicPermissionsAPI struct {
+	b Backend
+}
+
+// NewPublicPermissionsAPI creates a new account permissions API.
+func NewPublicPermissionsAPI(b Backend) *PublicPermissionsAPI {
+	return &PublicPermissionsAPI{b}
+}
+
+// GetAccountAccess returns the transaction access of account at the state of
+// the given block, as enforced by the transaction pool and block validation.
+func (s *PublicPermissionsAPI) GetAccountAccess(ctx context.Context, account common.Address, blockNr rpc.BlockNumber) (*AccountAccess, error) {
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	msg := types.NewMessage(account, &account, 0, new(big.Int), 0, new(big.Int), nil, false)
+	evm, _, err := s.b.GetEVM(ctx, msg, state, header, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	access, err := core.GetAccountAccess(evm, account)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountAccess{
+		Account:  account,
+		Access:   access,
+		Transact: access >= params.AccountAccessTransact,
+		Deploy:   access >= params.AccountAccessDeploy,
+	}, nil
+}
+
+// GetAccountPermissions returns the account permissions of the chain config,
+// nil if the accounts are unrestricted.
+func (s *PublicPermissionsAPI) GetAccountPermissions() *params.AccountPermissionsConfig {
+	return s.b.ChainConfig().AccountPermissions
+}
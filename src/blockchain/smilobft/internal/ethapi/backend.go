@@ -48,6 +48,7 @@ type Backend interface {
 	// General Ethereum API
 	AutonityContract() *autonity.Contract
 	Downloader() *downloader.Downloader
+	VaultRebuildProgress() core.VaultRebuildProgress
 	ProtocolVersion() int
 	SuggestPrice(ctx context.Context) (*big.Int, error)
 	ChainDb() ethdb.Database
@@ -80,6 +81,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolQuarantined() []*core.QuarantinedTx
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
 	// Filter API
@@ -137,6 +139,21 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "autonity",
+			Version:   "1.0",
+			Service:   NewPublicAutonityAPI(apiBackend),
+			Public:    true,
+		}, {
+			Namespace: "autonity",
+			Version:   "1.0",
+			Service:   NewPrivateAutonityAPI(apiBackend, nonceLock),
+			Public:    false,
+		}, {
+			Namespace: "perm",
+			Version:   "1.0",
+			Service:   NewPublicPermissionsAPI(apiBackend),
+			Public:    true,
 		},
 	}
 
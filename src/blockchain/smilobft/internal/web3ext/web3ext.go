@@ -34,9 +34,12 @@ var Modules = map[string]string{
 	"txpool":     TxpoolJs,
 	"les":        LESJs,
 	"smilobft":   SmiloBFTJS,
+	"sport":      SportJs,
 	"istanbul":   Istanbul_JS,
 	"sportdao":   SportDAO_JS,
 	"tendermint": TendermintJs,
+	"autonity":   AutonityJs,
+	"perm":       PermJs,
 }
 
 const ChequebookJs = `
@@ -168,6 +171,21 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addPermissionedNode',
+			call: 'admin_addPermissionedNode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removePermissionedNode',
+			call: 'admin_removePermissionedNode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setRateLimits',
+			call: 'admin_setRateLimits',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -218,6 +236,14 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'permissionedNodes',
+			getter: 'admin_permissionedNodes'
+		}),
+		new web3._extend.Property({
+			name: 'rateLimits',
+			getter: 'admin_rateLimits'
+		}),
 	]
 });
 `
@@ -532,6 +558,11 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'getSmiloPayload',
+			call: 'eth_getSmiloPayload',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'storageRoot',
 			call: 'eth_storageRoot',
@@ -592,6 +623,15 @@ web3._extend({
 			call: 'miner_setRecommitInterval',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'setEmptyBlockPolicy',
+			call: 'miner_setEmptyBlockPolicy',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'emptyBlockPolicy',
+			call: 'miner_emptyBlockPolicy'
+		}),
 		new web3._extend.Method({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
@@ -741,6 +781,10 @@ web3._extend({
 			name: 'inspect',
 			getter: 'txpool_inspect'
 		}),
+		new web3._extend.Property({
+			name: 'quarantined',
+			getter: 'txpool_quarantined'
+		}),
 		new web3._extend.Property({
 			name: 'status',
 			getter: 'txpool_status',
@@ -871,6 +915,21 @@ web3._extend({
 });
 `
 
+const SportJs = `
+web3._extend({
+	property: 'sport',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getRewards',
+			call: 'sport_getRewards',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	]
+});
+`
+
 const Istanbul_JS = `
 web3._extend({
 	property: 'istanbul',
@@ -1002,3 +1061,240 @@ web3._extend({
 	]
 });
 `
+
+const AutonityJs = `
+web3._extend({
+	property: 'autonity',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getEconomicHistory',
+			call: 'autonity_getEconomicHistory',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getUpgradeHistory',
+			call: 'autonity_getUpgradeHistory',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getCommittee',
+			call: 'autonity_getCommittee',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAccountStake',
+			call: 'autonity_getAccountStake',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'addValidator',
+			call: 'autonity_addValidator',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, null]
+		}),
+		new web3._extend.Method({
+			name: 'addStakeholder',
+			call: 'autonity_addStakeholder',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'removeUser',
+			call: 'autonity_removeUser',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'mintStake',
+			call: 'autonity_mintStake',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'redeemStake',
+			call: 'autonity_redeemStake',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setMinimumGasPrice',
+			call: 'autonity_setMinimumGasPrice',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setCommitteeSize',
+			call: 'autonity_setCommitteeSize',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		})
+	]
+});
+`
+
+const PermJs = `
+web3._extend({
+	property: 'perm',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getAccountAccess',
+			call: 'perm_getAccountAccess',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'accountPermissions',
+			getter: 'perm_getAccountPermissions'
+		}),
+	]
+});
+`
+
+// VaultJs are the console helpers for private contracts. They aren't an RPC
+// module, the console loads them on top of the eth module. The vault.sleep
+// function is provided by the console.
+const VaultJs = `
+web3.vault = (function() {
+	var eth = web3.eth;
+
+	function parseABI(abi) {
+		return typeof abi === 'string' ? JSON.parse(abi) : abi;
+	}
+
+	// transaction assembles a private transaction from the helper options. The
+	// transaction pool only accepts private transactions without gas price.
+	function transaction(data, to, options) {
+		if (!options || !web3._extend.utils.isArray(options.sharedWith)) {
+			throw new Error('options.sharedWith must list the vault keys of the recipients');
+		}
+		var tx = {from: options.from || eth.defaultAccount || eth.coinbase, data: data, sharedWith: options.sharedWith, gasPrice: 0};
+		['vaultFrom', 'gas', 'nonce'].forEach(function(key) {
+			if (options[key] !== undefined) {
+				tx[key] = options[key];
+			}
+		});
+		if (to) {
+			tx.to = to;
+		}
+		return tx;
+	}
+
+	// send shows the SmiloPay of the sender, sends the private transaction and
+	// waits for its receipt unless options.wait is false.
+	function send(tx, options) {
+		console.log('SmiloPay of ' + tx.from + ': ' + eth.getSmiloPay(tx.from).toString(10));
+		var hash = eth.sendTransaction(tx);
+		if (options.wait === false) {
+			return {transactionHash: hash};
+		}
+		return vault.waitForReceipt(hash, options.timeout);
+	}
+
+	var vault = {
+		// smiloPay returns the SmiloPay of an account.
+		smiloPay: function(address, block) {
+			return eth.getSmiloPay(address, block);
+		},
+
+		// payload returns the private payload of a vault digest, the input of a
+		// private transaction.
+		payload: function(digest) {
+			return eth.getSmiloPayload(digest);
+		},
+
+		// isPrivate returns whether a transaction, or its hash, is private.
+		isPrivate: function(tx) {
+			if (typeof tx === 'string') {
+				tx = eth.getTransaction(tx);
+			}
+			return tx !== null && (parseInt(tx.v, 16) === 37 || parseInt(tx.v, 16) === 38);
+		},
+
+		// waitForReceipt polls the private receipt of a transaction for up to
+		// timeout seconds (default 60).
+		waitForReceipt: function(hash, timeout) {
+			timeout = timeout || 60;
+			for (var i = 0; i <= timeout; i++) {
+				var receipt = vault.receipt(hash);
+				if (receipt !== null) {
+					return receipt;
+				}
+				if (i < timeout) {
+					vault.sleep(1);
+				}
+			}
+			throw new Error('no receipt for ' + hash + ' after ' + timeout + ' seconds');
+		},
+
+		// receipt returns the receipt of a transaction with its privacy and, if
+		// the vault holds it, its private payload.
+		receipt: function(hash) {
+			var receipt = eth.getTransactionReceipt(hash);
+			if (receipt === null) {
+				return null;
+			}
+			var tx = eth.getTransaction(hash);
+			receipt.isPrivate = vault.isPrivate(tx);
+			if (receipt.isPrivate) {
+				receipt.digest = tx.input;
+				try {
+					receipt.payload = eth.getSmiloPayload(tx.input);
+				} catch (err) {
+					receipt.payload = null;
+				}
+			}
+			return receipt;
+		},
+
+		// deploy creates a private contract from its ABI and bytecode, shared
+		// with options.sharedWith and constructed with options.args.
+		deploy: function(abi, bytecode, options) {
+			var factory = eth.contract(parseABI(abi));
+			if (bytecode.indexOf('0x') !== 0) {
+				bytecode = '0x' + bytecode;
+			}
+			var args = (options && options.args || []).concat([{data: bytecode}]);
+			var data = factory.getData.apply(factory, args);
+
+			var result = send(transaction(data, null, options), options);
+			if (result.contractAddress) {
+				result.contract = factory.at(result.contractAddress);
+			}
+			return result;
+		},
+
+		// send calls a method of a private contract in a private transaction.
+		send: function(abi, address, method, args, options) {
+			var contract = eth.contract(parseABI(abi)).at(address);
+			var data = contract[method].getData.apply(contract[method], args || []);
+			return send(transaction(data, address, options), options);
+		},
+
+		// call runs a constant method of a private contract on the private state.
+		call: function(abi, address, method, args, block) {
+			var contract = eth.contract(parseABI(abi)).at(address);
+			return contract[method].call.apply(contract[method], (args || []).concat([{}, block || 'latest']));
+		},
+
+		// storageAt returns a storage slot of a contract, private or public.
+		storageAt: function(address, slot, block) {
+			return eth.getStorageAt(address, slot, block);
+		},
+
+		// code returns the code of a contract, private or public.
+		code: function(address, block) {
+			return eth.getCode(address, block);
+		}
+	};
+	return vault;
+})();
+`
@@ -162,6 +162,10 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolQuarantined() []*core.QuarantinedTx {
+	return nil // Light clients don't analyze the contract creations
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -190,6 +194,11 @@ func (b *LesApiBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
 
+// VaultRebuildProgress returns no progress, light clients have no vault state.
+func (b *LesApiBackend) VaultRebuildProgress() core.VaultRebuildProgress {
+	return core.VaultRebuildProgress{}
+}
+
 func (b *LesApiBackend) ProtocolVersion() int {
 	return b.eth.LesVersion() + 10000
 }
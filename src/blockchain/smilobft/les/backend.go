@@ -165,6 +165,9 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	if leth.protocolManager, err = NewProtocolManager(leth.chainConfig, checkpoint, light.DefaultClientIndexerConfig, config.UltraLightServers, config.UltraLightFraction, true, config.NetworkId, leth.eventMux, leth.peers, leth.blockchain, nil, chainDb, leth.odr, leth.serverPool, registrar, quitSync, &leth.wg, nil); err != nil {
 		return nil, err
 	}
+	if checkpointer, ok := leth.engine.(consensus.Checkpointer); ok {
+		leth.protocolManager.downloader.SetCheckpointSkipping(leth.chainConfig, checkpointer.CheckpointRules(leth.chainConfig))
+	}
 	if leth.protocolManager.ulc != nil {
 		log.Warn("$$$ LES, Ultra light client is enabled", "servers", len(config.UltraLightServers), "fraction", config.UltraLightFraction)
 		leth.blockchain.DisableCheckFreq()
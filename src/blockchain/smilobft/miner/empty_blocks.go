@@ -0,0 +1,104 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+// emptyBlockPolicy decides when the BFT engines seal blocks without
+// transactions: always up to minBlocks, then only as heartbeats every
+// heartbeat, or right away when a change is pending and forcePending is set.
+type emptyBlockPolicy struct {
+	minBlocks    *big.Int
+	heartbeat    time.Duration // Zero disables the heartbeats
+	forcePending bool
+}
+
+// newEmptyBlockPolicy creates the policy of config, falling back to
+// minBlocksEmptyMining when it does not set the blocks always sealed.
+func newEmptyBlockPolicy(config *params.EmptyBlocksConfig, minBlocksEmptyMining *big.Int) emptyBlockPolicy {
+	policy := emptyBlockPolicy{minBlocks: minBlocksEmptyMining}
+	if config == nil {
+		return policy
+	}
+	if config.MinBlocks != nil {
+		policy.minBlocks = new(big.Int).Set(config.MinBlocks)
+	}
+	policy.heartbeat = time.Duration(config.Heartbeat) * time.Second
+	policy.forcePending = config.ForcePending
+	return policy
+}
+
+// config returns the policy in its chain config form.
+func (p emptyBlockPolicy) config() params.EmptyBlocksConfig {
+	config := params.EmptyBlocksConfig{
+		Heartbeat:    uint64(p.heartbeat / time.Second),
+		ForcePending: p.forcePending,
+	}
+	if p.minBlocks != nil {
+		config.MinBlocks = new(big.Int).Set(p.minBlocks)
+	}
+	return config
+}
+
+// seal returns whether an empty block on top of parent is sealed at now. If it
+// is not, it also returns how long to wait for the next heartbeat, zero to wait
+// for transactions only.
+func (p emptyBlockPolicy) seal(chainConfig *params.ChainConfig, engine consensus.Engine, parent *types.Header, now time.Time) (bool, time.Duration) {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	if p.minBlocks == nil || number.Cmp(p.minBlocks) < 0 {
+		return true, 0
+	}
+	if p.forcePending && pendingChange(chainConfig, engine, number.Uint64()) {
+		return true, 0
+	}
+	if p.heartbeat == 0 {
+		return false, 0
+	}
+	wait := time.Unix(int64(parent.Time), 0).Add(p.heartbeat).Sub(now)
+	if wait <= 0 {
+		return true, 0
+	}
+	return false, wait
+}
+
+// pendingChange returns whether the block number ends an epoch, applies an
+// upgrade of the Autonity contract, or engine has votes waiting to be sealed.
+func pendingChange(chainConfig *params.ChainConfig, engine consensus.Engine, number uint64) bool {
+	if epoch := chainConfig.EpochLength(); epoch > 0 && number%epoch == 0 {
+		return true
+	}
+	if ac := chainConfig.AutonityContractConfig; ac != nil {
+		for _, u := range ac.Upgrades {
+			if u.Block == number {
+				return true
+			}
+		}
+	}
+	if pc, ok := engine.(consensus.PendingChanges); ok && pc.HasPendingChanges() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"go-smilo/src/blockchain/smilobft/consensus"
+	"go-smilo/src/blockchain/smilobft/core/types"
+	"go-smilo/src/blockchain/smilobft/params"
+)
+
+type votingEngine struct {
+	consensus.Engine
+	pending bool
+}
+
+func (e *votingEngine) HasPendingChanges() bool {
+	return e.pending
+}
+
+func TestEmptyBlockPolicy(t *testing.T) {
+	chainConfig := &params.ChainConfig{Istanbul: &params.IstanbulConfig{Epoch: 30000}}
+	engine := new(votingEngine)
+	now := time.Unix(1000, 0)
+	parent := func(number int64, age time.Duration) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Time: uint64(now.Add(-age).Unix())}
+	}
+
+	policy := newEmptyBlockPolicy(&params.EmptyBlocksConfig{Heartbeat: 10, ForcePending: true}, big.NewInt(100))
+	tests := []struct {
+		parent  *types.Header
+		pending bool
+		seal    bool
+		wait    time.Duration
+	}{
+		{parent(50, 0), false, true, 0},                             // Below the min blocks
+		{parent(150, 4*time.Second), false, false, 6 * time.Second}, // Before the heartbeat
+		{parent(150, 10*time.Second), false, true, 0},               // Heartbeat
+		{parent(150, 0), true, true, 0},                             // Pending vote
+		{parent(29999, 0), false, true, 0},                          // Epoch checkpoint
+	}
+	for i, tt := range tests {
+		engine.pending = tt.pending
+		seal, wait := policy.seal(chainConfig, engine, tt.parent, now)
+		if seal != tt.seal || wait != tt.wait {
+			t.Errorf("test %d: have %v/%v, want %v/%v", i, seal, wait, tt.seal, tt.wait)
+		}
+	}
+
+	// Without heartbeats nor forced changes only transactions produce blocks
+	policy = newEmptyBlockPolicy(nil, big.NewInt(100))
+	engine.pending = true
+	if seal, wait := policy.seal(chainConfig, engine, parent(29999, time.Hour), now); seal || wait != 0 {
+		t.Errorf("default policy: have %v/%v, want no empty block", seal, wait)
+	}
+
+	// The min blocks of the chain config overrides the flag
+	config := newEmptyBlockPolicy(&params.EmptyBlocksConfig{MinBlocks: big.NewInt(5)}, big.NewInt(100)).config()
+	if config.MinBlocks.Int64() != 5 {
+		t.Errorf("min blocks: have %v, want 5", config.MinBlocks)
+	}
+}
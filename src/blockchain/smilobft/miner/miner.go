@@ -58,6 +58,8 @@ type Config struct {
 	GasPrice  *big.Int       // Minimum gas price for mining a transaction
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 	Noverify  bool           // Disable remote mining solution verification(only useful in ethash).
+
+	ContractAnalysis bool // Skip the contract creations refused by the pool's contract admission
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -186,6 +188,18 @@ func (self *Miner) SetRecommitInterval(interval time.Duration) {
 	self.worker.setRecommitInterval(interval)
 }
 
+// SetEmptyBlockPolicy sets when the BFT engines seal blocks without
+// transactions. A nil MinBlocks keeps the current one.
+func (self *Miner) SetEmptyBlockPolicy(config *params.EmptyBlocksConfig) {
+	self.worker.setEmptyBlockPolicy(config)
+}
+
+// EmptyBlockPolicy returns when the BFT engines seal blocks without
+// transactions.
+func (self *Miner) EmptyBlockPolicy() params.EmptyBlocksConfig {
+	return self.worker.emptyBlockPolicy()
+}
+
 // Pending returns the currently pending block and associated state.
 func (self *Miner) Pending() (*types.Block, *state.StateDB, *state.StateDB) {
 	return self.worker.pending()
@@ -89,6 +89,8 @@ type Work struct {
 
 	// Leave this publicState named state, add privateState which most code paths can just ignore
 	vaultState *state.StateDB
+
+	admission core.ContractAdmission // Admission of the contract creations, if enforced
 }
 
 type Result struct {
@@ -143,32 +145,36 @@ type worker struct {
 	unconfirmed *unconfirmedBlocks // set of locally mined blocks pending canonicalness confirmations
 
 	// atomic status counters
-	mining int32
-	atWork int32
+	mining   int32
+	atWork   int32
+	withheld int32 // Set while an empty block is not sealed, waiting for transactions or a heartbeat
 
-	minBlocksEmptyMining *big.Int // Min Blocks to mine before Stop Mining Empty Blocks
+	emptyPolicy    emptyBlockPolicy // Sealing of the blocks without transactions, guarded by mu
+	heartbeatCh    chan struct{}
+	heartbeatTimer *time.Timer
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, coinbase common.Address, eth Backend, mux *cmn.TypeMux, minBlocksEmptyMining *big.Int) *worker {
 	worker := &worker{
-		config:               config,
-		chainConfig:          chainConfig,
-		engine:               engine,
-		eth:                  eth,
-		mux:                  mux,
-		txsCh:                make(chan core.NewTxsEvent, txChanSize),
-		chainHeadCh:          make(chan core.ChainHeadEvent, chainHeadChanSize),
-		chainSideCh:          make(chan core.ChainSideEvent, chainSideChanSize),
-		chainDb:              eth.ChainDb(),
-		recv:                 make(chan *Result, resultQueueSize),
-		chain:                eth.BlockChain(),
-		proc:                 eth.BlockChain().Validator(),
-		possibleUncles:       make(map[common.Hash]*types.Block),
-		coinbase:             coinbase,
-		agents:               make(map[Agent]struct{}),
-		unconfirmed:          newUnconfirmedBlocks(eth.BlockChain(), miningLogAtDepth),
-		minBlocksEmptyMining: minBlocksEmptyMining,
-		resubmitIntervalCh:   make(chan time.Duration),
+		config:             config,
+		chainConfig:        chainConfig,
+		engine:             engine,
+		eth:                eth,
+		mux:                mux,
+		txsCh:              make(chan core.NewTxsEvent, txChanSize),
+		chainHeadCh:        make(chan core.ChainHeadEvent, chainHeadChanSize),
+		chainSideCh:        make(chan core.ChainSideEvent, chainSideChanSize),
+		chainDb:            eth.ChainDb(),
+		recv:               make(chan *Result, resultQueueSize),
+		chain:              eth.BlockChain(),
+		proc:               eth.BlockChain().Validator(),
+		possibleUncles:     make(map[common.Hash]*types.Block),
+		coinbase:           coinbase,
+		agents:             make(map[Agent]struct{}),
+		unconfirmed:        newUnconfirmedBlocks(eth.BlockChain(), miningLogAtDepth),
+		emptyPolicy:        newEmptyBlockPolicy(chainConfig.EmptyBlocks, minBlocksEmptyMining),
+		heartbeatCh:        make(chan struct{}, 1),
+		resubmitIntervalCh: make(chan time.Duration),
 	}
 
 	if _, ok := engine.(consensus.BFT); ok || !chainConfig.IsSmilo || chainConfig.Clique != nil {
@@ -207,6 +213,40 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+// setEmptyBlockPolicy replaces the policy of the blocks without transactions,
+// reconsidering a withheld empty block.
+func (self *worker) setEmptyBlockPolicy(config *params.EmptyBlocksConfig) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.emptyPolicy = newEmptyBlockPolicy(config, self.emptyPolicy.minBlocks)
+	self.wakeHeartbeat()
+}
+
+func (self *worker) emptyBlockPolicy() params.EmptyBlocksConfig {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.emptyPolicy.config()
+}
+
+// scheduleHeartbeat wakes the worker after wait to reconsider a withheld empty
+// block, replacing the previous schedule. A zero wait cancels it.
+func (self *worker) scheduleHeartbeat(wait time.Duration) {
+	if self.heartbeatTimer != nil {
+		self.heartbeatTimer.Stop()
+		self.heartbeatTimer = nil
+	}
+	if wait > 0 {
+		self.heartbeatTimer = time.AfterFunc(wait, self.wakeHeartbeat)
+	}
+}
+
+func (self *worker) wakeHeartbeat() {
+	select {
+	case self.heartbeatCh <- struct{}{}:
+	default:
+	}
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB, *state.StateDB) {
 	if atomic.LoadInt32(&self.mining) == 0 {
 		// return a snapshot to avoid contention on currentMu mutex
@@ -280,8 +320,10 @@ func (self *worker) stop() {
 		//panic("$$$ Could not stop non BFT Consensus Engine")
 		log.Warn("Could not stop non BFT Consensus Engine")
 	}
+	self.scheduleHeartbeat(0)
 	atomic.StoreInt32(&self.mining, 0)
 	atomic.StoreInt32(&self.atWork, 0)
+	atomic.StoreInt32(&self.withheld, 0)
 }
 
 func (self *worker) register(agent Agent) {
@@ -338,15 +380,20 @@ func (self *worker) update() {
 				self.updateSnapshot()
 				self.currentMu.Unlock()
 			} else if self.current.header != nil && self.current.Block != nil {
-				// If we're mining, but nothing is being processed, wake on new transactions
-				log.Trace("If we're mining, but nothing is being processed, wake on new transactions ? ", "MinBlocksMining", self.minBlocksEmptyMining, "IsSport", self.chainConfig.Sport != nil, "BlockNum Cmp MinBlocksMining", self.current.Block.Number().Cmp(self.minBlocksEmptyMining))
-				if self.chainConfig.Sport != nil && self.current.Block.Number().Cmp(self.minBlocksEmptyMining) >= 0 {
+				// If we're mining, but withholding an empty block, wake on new transactions
+				if atomic.LoadInt32(&self.withheld) == 1 {
 					self.commitNewWork(time.Now().Unix())
 				}
 			} else {
 				self.commitNewWork(time.Now().Unix())
 			}
 
+			// Handle the heartbeat of a withheld empty block
+		case <-self.heartbeatCh:
+			if atomic.LoadInt32(&self.withheld) == 1 {
+				self.commitNewWork(time.Now().Unix())
+			}
+
 			// System stopped
 		case <-self.txsSub.Err():
 			return
@@ -381,12 +428,12 @@ func (self *worker) wait() {
 				log.BlockHash = block.Hash()
 			}
 
-			// write private transacions
-			vaultStateRoot, _ := work.vaultState.Commit(self.chainConfig.IsEIP158(block.Number()))
+			// write private transacions, the vault state is committed along with the block
+			vaultStateRoot := work.vaultState.IntermediateRoot(self.chainConfig.IsEIP158(block.Number()))
 			core.WriteVaultStateRoot(self.chainDb, block.Root(), vaultStateRoot)
 			allReceipts := mergeReceipts(work.receipts, work.vaultReceipts)
 
-			stat, err := self.chain.WriteBlockWithState(block, allReceipts, work.state, nil)
+			stat, err := self.chain.WriteBlockWithState(block, allReceipts, work.state, work.vaultState)
 			if err != nil {
 				log.Error("Failed writWriteBlockAndStating block to chain", "err", err)
 				continue
@@ -461,6 +508,9 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 		createdAt:   time.Now(),
 		vaultState:  vaultState,
 	}
+	if self.config.ContractAnalysis {
+		work.admission = self.eth.TxPool().ContractAdmission()
+	}
 
 	// when 08 is processed ancestors contain 07 (quick block)
 	for _, ancestor := range self.chain.GetBlocksFromHash(parent.Hash(), 7) {
@@ -587,6 +637,10 @@ func (self *worker) commitNewWork(timestamp int64) {
 		log.Error("Failed to finalize block for sealing", "err", err)
 		return
 	}
+	if self.withholdEmpty(work, parent.Header()) {
+		self.updateSnapshot()
+		return
+	}
 	// We only care about logging if we're actually mining.
 	if atomic.LoadInt32(&self.mining) == 1 {
 		log.Info("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(time.Since(tstart)))
@@ -597,6 +651,24 @@ func (self *worker) commitNewWork(timestamp int64) {
 	self.updateSnapshot()
 }
 
+// withholdEmpty returns whether the BFT engine must not seal work yet, as it is
+// empty and the empty block policy holds it back.
+func (self *worker) withholdEmpty(work *Work, parent *types.Header) bool {
+	self.scheduleHeartbeat(0)
+	atomic.StoreInt32(&self.withheld, 0)
+	if _, ok := self.engine.(consensus.BFT); !ok || atomic.LoadInt32(&self.mining) == 0 || work.tcount > 0 {
+		return false
+	}
+	seal, wait := self.emptyPolicy.seal(self.chainConfig, self.engine, parent, time.Now())
+	if seal {
+		return false
+	}
+	log.Debug("Withholding empty block", "number", work.Block.Number(), "heartbeat", common.PrettyDuration(wait))
+	atomic.StoreInt32(&self.withheld, 1)
+	self.scheduleHeartbeat(wait)
+	return true
+}
+
 func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	hash := uncle.Hash()
 	if work.uncles.Contains(hash) {
@@ -656,6 +728,15 @@ func (env *Work) commitTransactions(mux *cmn.TypeMux, txs *types.TransactionsByP
 			txs.Pop()
 			continue
 		}
+		// Skip the contract creations refused by the admission, they may have been
+		// pooled before the current rules
+		if tx.To() == nil && env.admission != nil {
+			if err := env.admission.AdmitContract(tx, from).Err(); err != nil {
+				log.Debug("Skipping refused contract creation", "hash", tx.Hash(), "sender", from, "err", err)
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 		env.vaultState.Prepare(tx.Hash(), common.Hash{}, env.tcount)
@@ -106,6 +106,85 @@ func (api *PrivateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddPermissionedNode permissions a remote node to connect with the policy of
+// its URL parameters, and persists it in the permissioned nodes file.
+func (api *PrivateAdminAPI) AddPermissionedNode(url string) (bool, error) {
+	permissions, err := api.permissions()
+	if err != nil {
+		return false, err
+	}
+	node, err := p2p.ParseNodePolicy(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := permissions.Add(node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemovePermissionedNode revokes the permission of a remote node, disconnecting
+// it if connected, and persists it in the permissioned nodes file.
+func (api *PrivateAdminAPI) RemovePermissionedNode(url string) (bool, error) {
+	permissions, err := api.permissions()
+	if err != nil {
+		return false, err
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	return permissions.Remove(node.ID())
+}
+
+// PermissionedNodes returns the nodes permissioned to connect.
+func (api *PrivateAdminAPI) PermissionedNodes() ([]string, error) {
+	permissions, err := api.permissions()
+	if err != nil {
+		return nil, err
+	}
+	nodes := permissions.Nodes()
+	urls := make([]string, len(nodes))
+	for i, n := range nodes {
+		urls[i] = n.String()
+	}
+	return urls, nil
+}
+
+// SetRateLimits replaces the rate limits of the HTTP and WebSocket RPC
+// endpoints, removing them if config is empty.
+func (api *PrivateAdminAPI) SetRateLimits(config rpc.RateLimitConfig) (bool, error) {
+	api.node.lock.Lock()
+	defer api.node.lock.Unlock()
+
+	if err := api.node.setRPCRateLimits(&config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RateLimits returns the rate limits of the HTTP and WebSocket RPC endpoints.
+func (api *PrivateAdminAPI) RateLimits() rpc.RateLimitConfig {
+	api.node.lock.RLock()
+	defer api.node.lock.RUnlock()
+
+	if api.node.config.RPCRateLimits == nil {
+		return rpc.RateLimitConfig{Limits: []rpc.RateLimit{}}
+	}
+	return *api.node.config.RPCRateLimits
+}
+
+func (api *PrivateAdminAPI) permissions() (*p2p.Permissions, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	if server.Permissions() == nil {
+		return nil, ErrNotPermissioned
+	}
+	return server.Permissions(), nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -74,6 +74,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DatabaseEngine is the storage engine of the databases created in the data
+	// directory, "leveldb" or "boltdb". Existing databases are opened with their
+	// own engine if it is empty.
+	DatabaseEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -166,6 +171,20 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCTLS enables TLS on the HTTP and WebSocket RPC endpoints. Listing client
+	// certificate authorities enables mutual TLS.
+	RPCTLS *rpc.TLSConfig `toml:",omitempty"`
+
+	// RPCAuth requires the clients of the HTTP and WebSocket RPC endpoints to
+	// authenticate with a bearer token, a JWT or a client certificate, and limits
+	// each client to its allowed namespaces and methods.
+	RPCAuth *rpc.AuthConfig `toml:",omitempty"`
+
+	// RPCRateLimits limits the call rate and concurrency of the methods served by
+	// the HTTP and WebSocket RPC endpoints. They may be changed while running with
+	// admin_setRateLimits.
+	RPCRateLimits *rpc.RateLimitConfig `toml:",omitempty"`
+
 	// GraphQLHost is the host interface on which to start the GraphQL server. If this
 	// field is empty, no GraphQL API endpoint will be started.
 	GraphQLHost string `toml:",omitempty"`
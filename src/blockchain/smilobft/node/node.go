@@ -372,11 +372,16 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, n.config.RPCTLS, n.config.RPCAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	if err := handler.SetRateLimits(n.config.RPCRateLimits); err != nil {
+		listener.Close()
+		handler.Stop()
+		return err
+	}
+	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("%s://%s", n.rpcScheme("http"), endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "auth", n.config.RPCAuth != nil)
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -391,7 +396,7 @@ func (n *Node) stopHTTP() {
 		n.httpListener.Close()
 		n.httpListener = nil
 
-		n.log.Info("HTTP endpoint closed", "url", fmt.Sprintf("http://%s", n.httpEndpoint))
+		n.log.Info("HTTP endpoint closed", "url", fmt.Sprintf("%s://%s", n.rpcScheme("http"), n.httpEndpoint))
 	}
 	if n.httpHandler != nil {
 		n.httpHandler.Stop()
@@ -405,11 +410,16 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.config.RPCTLS, n.config.RPCAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	if err := handler.SetRateLimits(n.config.RPCRateLimits); err != nil {
+		listener.Close()
+		handler.Stop()
+		return err
+	}
+	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("%s://%s", n.rpcScheme("ws"), listener.Addr()), "auth", n.config.RPCAuth != nil)
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
@@ -424,7 +434,7 @@ func (n *Node) stopWS() {
 		n.wsListener.Close()
 		n.wsListener = nil
 
-		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.wsEndpoint))
+		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("%s://%s", n.rpcScheme("ws"), n.wsEndpoint))
 	}
 	if n.wsHandler != nil {
 		n.wsHandler.Stop()
@@ -432,6 +442,30 @@ func (n *Node) stopWS() {
 	}
 }
 
+// setRPCRateLimits replaces the rate limits of the running HTTP and WebSocket
+// endpoints, and the configured ones used when they start.
+func (n *Node) setRPCRateLimits(config *rpc.RateLimitConfig) error {
+	for _, handler := range []*rpc.Server{n.httpHandler, n.wsHandler} {
+		if handler == nil {
+			continue
+		}
+		if err := handler.SetRateLimits(config); err != nil {
+			return err
+		}
+	}
+	n.config.RPCRateLimits = config
+	return nil
+}
+
+// rpcScheme returns the URL scheme of the HTTP or WebSocket endpoint, secured
+// if TLS is enabled.
+func (n *Node) rpcScheme(scheme string) string {
+	if n.config.RPCTLS != nil {
+		return scheme + "s"
+	}
+	return scheme
+}
+
 // Stop terminates a running node along with all it's services. In the node was
 // not started, an error is returned.
 func (n *Node) Stop() error {
@@ -640,7 +674,7 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string) (
 	if n.config.DataDir == "" {
 		return rawdb.NewMemoryDatabase(), nil
 	}
-	return rawdb.NewLevelDBDatabase(n.config.ResolvePath(name), cache, handles, namespace)
+	return rawdb.NewDiskDatabase(n.config.DatabaseEngine, n.config.ResolvePath(name), cache, handles, namespace)
 }
 
 // OpenDatabaseWithFreezer opens an existing database with the given name (or
@@ -660,7 +694,7 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 	case !filepath.IsAbs(freezer):
 		freezer = n.config.ResolvePath(freezer)
 	}
-	return rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace)
+	return rawdb.NewDiskDatabaseWithFreezer(n.config.DatabaseEngine, root, cache, handles, freezer, namespace)
 }
 
 // ResolvePath returns the absolute path of a resource in the instance directory.
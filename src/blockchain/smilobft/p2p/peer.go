@@ -181,6 +181,11 @@ func (p *Peer) Inbound() bool {
 	return p.rw.is(inboundConn)
 }
 
+// Policy returns the permission policy of the peer, nil if it has none.
+func (p *Peer) Policy() *NodePolicy {
+	return p.rw.getPolicy()
+}
+
 func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	protomap := matchProtocols(protocols, conn.caps, conn)
 	p := &Peer{
@@ -441,6 +446,8 @@ type PeerInfo struct {
 		Inbound       bool   `json:"inbound"`
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
+		Role          Role   `json:"role,omitempty"`
+		Org           string `json:"org,omitempty"`
 	} `json:"network"`
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
 }
@@ -468,6 +475,9 @@ func (p *Peer) Info() *PeerInfo {
 	info.Network.Inbound = p.rw.is(inboundConn)
 	info.Network.Trusted = p.rw.is(trustedConn)
 	info.Network.Static = p.rw.is(staticDialedConn)
+	if policy := p.Policy(); policy != nil {
+		info.Network.Role, info.Network.Org = policy.Role, policy.Org
+	}
 
 	// Gather all the running protocol infos
 	for _, proto := range p.running {
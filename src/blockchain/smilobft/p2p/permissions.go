@@ -2,9 +2,12 @@ package p2p
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
@@ -14,62 +17,285 @@ import (
 const (
 	NODE_NAME_LENGTH    = 32
 	PERMISSIONED_CONFIG = "permissioned-nodes.json"
+
+	permissionsReloadInterval = 2 * time.Second // Interval between the checks of the permissioned nodes file
 )
 
-// check if a given node is permissioned to connect to the change
-func IsNodePermissioned(nodeID string, currentNode string, datadir string, direction string) bool {
+var errNotPermissioned = errors.New("node not permissioned")
+
+// Permissions is the in-memory set of the nodes permissioned to connect. It is
+// loaded from the permissioned-nodes.json file of the data directory, reloaded
+// when the file changes, and persisted when nodes are added or removed.
+//
+// The file lists the URLs of the nodes with their policy parameters, either as
+// an array or along with the organization limits:
+//
+//	{"nodes": ["enode://...?org=acme"], "organizations": {"acme": {"maxPeers": 2}}}
+type Permissions struct {
+	path string
+
+	mu      sync.RWMutex
+	nodes   []*NodePolicy        // Permissioned nodes, in the order of the file
+	orgs    map[string]OrgPolicy // Organization limits
+	modTime time.Time            // Modification time of the file when last loaded
+	size    int64                // Size of the file when last loaded
+
+	revoked func(ids []enode.ID) // Called with the nodes whose permission is revoked
+	changed func()               // Called when the policies of the nodes may have changed
+}
+
+// NewPermissions loads the permissioned nodes of datadir. A missing or invalid
+// file permissions no node until it is fixed.
+func NewPermissions(datadir string) *Permissions {
+	p := &Permissions{path: filepath.Join(datadir, PERMISSIONED_CONFIG)}
+	if err := p.Reload(); err != nil {
+		log.Error("Read Error for permissioned-nodes.json file. This is because 'permissioned' flag is specified but no permissioned-nodes.json file is present.", "err", err)
+	}
+	return p
+}
+
+// IsPermissioned returns whether the node id is permissioned to connect.
+func (p *Permissions) IsPermissioned(id enode.ID) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return indexOfNode(p.nodes, id) >= 0
+}
+
+// Policy returns the policy of node id, nil if not permissioned.
+func (p *Permissions) Policy(id enode.ID) *NodePolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if i := indexOfNode(p.nodes, id); i >= 0 {
+		return p.nodes[i]
+	}
+	return nil
+}
+
+// Organization returns the limits of the organization org.
+func (p *Permissions) Organization(org string) (OrgPolicy, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	policy, ok := p.orgs[org]
+	return policy, ok
+}
+
+// Nodes returns the permissioned nodes.
+func (p *Permissions) Nodes() []*NodePolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*NodePolicy{}, p.nodes...)
+}
+
+// Reload reads the permissioned nodes file again, revoking the permission of
+// the nodes removed from it and notifying the policy changes. The current nodes
+// are kept if it cannot be read.
+func (p *Permissions) Reload() error {
+	p.mu.Lock()
+	info, err := os.Stat(p.path)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	nodes, orgs, err := readPermissionedNodes(p.path)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	revoked := p.set(nodes)
+	p.orgs = orgs
+	p.modTime, p.size = info.ModTime(), info.Size()
+	p.mu.Unlock()
+
+	log.Info("Loaded permissioned nodes", "file", p.path, "nodes", len(nodes), "orgs", len(orgs), "revoked", len(revoked))
+	p.revoke(revoked)
+	p.notify()
+	return nil
+}
+
+// Add permissions node to connect with its policy and persists it. The policy
+// of a node already permissioned is replaced.
+func (p *Permissions) Add(node *NodePolicy) error {
+	p.mu.Lock()
+	nodes := append([]*NodePolicy{}, p.nodes...)
+	i := indexOfNode(nodes, node.Node.ID())
+	if i >= 0 {
+		nodes[i] = node
+	} else {
+		nodes = append(nodes, node)
+	}
+	err := p.persist(nodes)
+	p.mu.Unlock()
+
+	if err == nil && i >= 0 {
+		p.notify()
+	}
+	return err
+}
 
-	var permissionedList []string
-	nodes := ParsePermissionedNodes(datadir)
-	for _, v := range nodes {
-		permissionedList = append(permissionedList, v.ID().String())
+// Remove revokes the permission of node id and persists it, returning whether
+// it was permissioned.
+func (p *Permissions) Remove(id enode.ID) (bool, error) {
+	p.mu.Lock()
+	i := indexOfNode(p.nodes, id)
+	if i < 0 {
+		p.mu.Unlock()
+		return false, nil
+	}
+	nodes := append(append([]*NodePolicy{}, p.nodes[:i]...), p.nodes[i+1:]...)
+	if err := p.persist(nodes); err != nil {
+		p.mu.Unlock()
+		return false, err
 	}
+	p.mu.Unlock()
 
-	log.Trace("isNodePermissioned", "permissionedList", permissionedList)
-	for _, v := range permissionedList {
-		if v == nodeID {
-			log.Debug("isNodePermissioned", "connection", direction, "nodename", nodeID[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
-			return true
+	p.revoke([]enode.ID{id})
+	return true, nil
+}
+
+// persist atomically replaces the file with nodes, then the in-memory set. The
+// lock must be held.
+func (p *Permissions) persist(nodes []*NodePolicy) error {
+	urls := make([]string, len(nodes))
+	for i, n := range nodes {
+		urls[i] = n.String()
+	}
+	var (
+		blob []byte
+		err  error
+	)
+	if len(p.orgs) > 0 {
+		blob, err = json.MarshalIndent(permissionsFile{Nodes: urls, Orgs: p.orgs}, "", "  ")
+	} else {
+		blob, err = json.MarshalIndent(urls, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	p.set(nodes)
+	if info, err := os.Stat(p.path); err == nil {
+		p.modTime, p.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// set replaces the permissioned nodes, returning the ones no longer in. The
+// lock must be held.
+func (p *Permissions) set(nodes []*NodePolicy) []enode.ID {
+	var revoked []enode.ID
+	for _, n := range p.nodes {
+		if indexOfNode(nodes, n.Node.ID()) < 0 {
+			revoked = append(revoked, n.Node.ID())
+		}
+	}
+	p.nodes = nodes
+	return revoked
+}
+
+func (p *Permissions) revoke(ids []enode.ID) {
+	if len(ids) > 0 && p.revoked != nil {
+		p.revoked(ids)
+	}
+}
+
+func (p *Permissions) notify() {
+	if p.changed != nil {
+		p.changed()
+	}
+}
+
+// watch reloads the file whenever it changes, until quit is closed.
+func (p *Permissions) watch(quit <-chan struct{}) {
+	ticker := time.NewTicker(permissionsReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(p.path)
+			if err != nil {
+				continue
+			}
+			p.mu.RLock()
+			changed := !info.ModTime().Equal(p.modTime) || info.Size() != p.size
+			p.mu.RUnlock()
+			if changed {
+				if err := p.Reload(); err != nil {
+					log.Error("Could not reload the permissioned nodes", "file", p.path, "err", err)
+				}
+			}
+		case <-quit:
+			return
 		}
 	}
-	log.Warn("isNodePermissioned", "connection", direction, "nodename", nodeID[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
-	return false
 }
 
+func indexOfNode(nodes []*NodePolicy, id enode.ID) int {
+	for i, n := range nodes {
+		if n.Node.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParsePermissionedNodes returns the permissioned nodes of DataDir, nil if
+// they cannot be read.
 func ParsePermissionedNodes(DataDir string) []*enode.Node {
 
 	log.Trace("parsePermissionedNodes", "DataDir", DataDir, "file", PERMISSIONED_CONFIG)
 
-	path := filepath.Join(DataDir, PERMISSIONED_CONFIG)
-	if _, err := os.Stat(path); err != nil {
-		log.Error("Read Error for permissioned-nodes.json file. This is because 'permissioned' flag is specified but no permissioned-nodes.json file is present.", "err", err)
+	policies, _, err := readPermissionedNodes(filepath.Join(DataDir, PERMISSIONED_CONFIG))
+	if err != nil {
+		log.Error("parsePermissionedNodes: Failed to load nodes", "err", err)
 		return nil
 	}
+	nodes := make([]*enode.Node, len(policies))
+	for i, policy := range policies {
+		nodes[i] = policy.Node
+	}
+	return nodes
+}
+
+// permissionsFile is the format of the permissioned nodes file with
+// organization limits.
+type permissionsFile struct {
+	Nodes []string             `json:"nodes"`
+	Orgs  map[string]OrgPolicy `json:"organizations,omitempty"`
+}
+
+func readPermissionedNodes(path string) ([]*NodePolicy, map[string]OrgPolicy, error) {
 	// Load the nodes from the config file
 	blob, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Error("parsePermissionedNodes: Failed to access nodes", "err", err)
-		return nil
+		return nil, nil, err
 	}
 
-	nodelist := []string{}
-	if err := json.Unmarshal(blob, &nodelist); err != nil {
-		log.Error("parsePermissionedNodes: Failed to load nodes", "err", err)
-		return nil
+	var file permissionsFile
+	if err := json.Unmarshal(blob, &file.Nodes); err != nil {
+		if err := json.Unmarshal(blob, &file); err != nil {
+			return nil, nil, err
+		}
 	}
 	// Interpret the list as a discovery node array
-	var nodes []*enode.Node
-	for _, url := range nodelist {
+	var nodes []*NodePolicy
+	for _, url := range file.Nodes {
 		if url == "" {
 			log.Error("parsePermissionedNodes: Node URL blank")
 			continue
 		}
-		node, err := enode.ParseV4(url)
+		node, err := ParseNodePolicy(url)
 		if err != nil {
 			log.Error("parsePermissionedNodes: Node URL", "url", url, "err", err)
 			continue
 		}
 		nodes = append(nodes, node)
 	}
-	return nodes
+	return nodes, file.Orgs, nil
 }
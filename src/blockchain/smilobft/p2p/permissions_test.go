@@ -0,0 +1,90 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+func writePermissionedNodes(t *testing.T, dir string, nodes ...*enode.Node) {
+	urls := make([]string, len(nodes))
+	for i, n := range nodes {
+		urls[i] = n.String()
+	}
+	blob, _ := json.Marshal(urls)
+	if err := ioutil.WriteFile(filepath.Join(dir, PERMISSIONED_CONFIG), blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "permissions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30301, 30301)
+	b := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30302, 30302)
+	c := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303)
+	writePermissionedNodes(t, dir, a, b)
+
+	p := NewPermissions(dir)
+	var revoked []enode.ID
+	p.revoked = func(ids []enode.ID) { revoked = append(revoked, ids...) }
+	if !p.IsPermissioned(a.ID()) || !p.IsPermissioned(b.ID()) || p.IsPermissioned(c.ID()) {
+		t.Fatalf("loaded nodes: have %v", p.Nodes())
+	}
+
+	// Added and removed nodes are persisted
+	if err := p.Add(&NodePolicy{Node: c}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Remove(a.ID()); !ok || err != nil {
+		t.Fatalf("remove: have %v/%v", ok, err)
+	}
+	if ok, _ := p.Remove(a.ID()); ok {
+		t.Errorf("removed a node not permissioned")
+	}
+	persisted := ParsePermissionedNodes(dir)
+	if len(persisted) != 2 || persisted[0].ID() != b.ID() || persisted[1].ID() != c.ID() {
+		t.Errorf("persisted nodes: have %v", persisted)
+	}
+	if len(revoked) != 1 || revoked[0] != a.ID() {
+		t.Errorf("revoked on remove: have %v", revoked)
+	}
+
+	// Reloading an edited file revokes the nodes removed from it
+	revoked = nil
+	writePermissionedNodes(t, dir, c)
+	if err := p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsPermissioned(b.ID()) || !p.IsPermissioned(c.ID()) {
+		t.Errorf("reloaded nodes: have %v", p.Nodes())
+	}
+	if len(revoked) != 1 || revoked[0] != b.ID() {
+		t.Errorf("revoked on reload: have %v", revoked)
+	}
+}
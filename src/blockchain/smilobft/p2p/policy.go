@@ -0,0 +1,229 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+// Role is the part a permissioned node may take in the network.
+type Role string
+
+const (
+	RoleMember   Role = "member"   // Syncs and takes part in consensus
+	RoleObserver Role = "observer" // Syncs, but may not send consensus messages
+)
+
+var (
+	errPolicyNetwork = errors.New("node not permissioned from this address")
+	errPolicyOrgFull = errors.New("too many peers of the node organization")
+)
+
+// NodePolicy is the permission of a node to connect. It is encoded as the query
+// parameters of the node URL, so the permissioned nodes file and the governance
+// whitelist share the format:
+//
+//	enode://<id>@<ip>:<port>?role=observer&org=acme&cidr=10.0.0.0/8
+//
+// A node without parameters is a member allowed from any address.
+type NodePolicy struct {
+	Node     *enode.Node
+	Role     Role         // Role of the node, member if empty
+	Org      string       // Organization of the node, limiting its peers
+	Networks []*net.IPNet // Networks the node may connect from, any if empty
+}
+
+// OrgPolicy limits the connections to the nodes of an organization.
+type OrgPolicy struct {
+	MaxPeers int `json:"maxPeers"` // Maximum number of peers of the organization, no limit if zero
+}
+
+// ParseNodePolicy parses an enode URL and its policy parameters.
+func ParseNodePolicy(rawurl string) (*NodePolicy, error) {
+	node, err := enode.ParseV4(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	qv := u.Query()
+	policy := &NodePolicy{Node: node, Role: Role(qv.Get("role")), Org: qv.Get("org")}
+	switch policy.Role {
+	case "", RoleMember, RoleObserver:
+	default:
+		return nil, fmt.Errorf("invalid role %q", policy.Role)
+	}
+	for _, cidr := range qv["cidr"] {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %v", cidr, err)
+		}
+		policy.Networks = append(policy.Networks, network)
+	}
+	return policy, nil
+}
+
+// String returns the node URL with the policy parameters.
+func (p *NodePolicy) String() string {
+	u, err := url.Parse(p.Node.String())
+	if err != nil || u.Scheme != "enode" {
+		return p.Node.String()
+	}
+	qv := u.Query()
+	if p.Role != "" {
+		qv.Set("role", string(p.Role))
+	}
+	if p.Org != "" {
+		qv.Set("org", p.Org)
+	}
+	for _, network := range p.Networks {
+		qv.Add("cidr", network.String())
+	}
+	u.RawQuery = qv.Encode()
+	return u.String()
+}
+
+// AllowsIP returns whether the node may connect from ip.
+func (p *NodePolicy) AllowsIP(ip net.IP) bool {
+	if len(p.Networks) == 0 {
+		return true
+	}
+	for _, network := range p.Networks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendsConsensus returns whether the consensus messages of the node are
+// accepted.
+func (p *NodePolicy) SendsConsensus() bool {
+	return p.Role != RoleObserver
+}
+
+// nodePolicy returns the policy of node id, the one of the permissioned nodes
+// file if any, else the one of the governance whitelist.
+func (srv *Server) nodePolicy(id enode.ID) *NodePolicy {
+	if srv.permissions != nil {
+		if policy := srv.permissions.Policy(id); policy != nil {
+			return policy
+		}
+	}
+	srv.policyLock.RLock()
+	defer srv.policyLock.RUnlock()
+	return srv.governance[id]
+}
+
+// SetGovernancePolicies sets the policies of the nodes whitelisted by the
+// governance contract, given as enode URLs with policy parameters. Connected
+// peers no longer allowed from their address are disconnected.
+func (srv *Server) SetGovernancePolicies(urls []string) {
+	srv.policyLock.Lock()
+	srv.governance = nil
+	srv.policyLock.Unlock()
+
+	srv.UpdateGovernancePolicies(urls, nil)
+}
+
+// UpdateGovernancePolicies updates the policies of the nodes added to and
+// removed from the governance whitelist.
+func (srv *Server) UpdateGovernancePolicies(added, removed []string) {
+	srv.policyLock.Lock()
+	if srv.governance == nil {
+		srv.governance = make(map[enode.ID]*NodePolicy, len(added))
+	}
+	for _, rawurl := range removed {
+		if policy, err := ParseNodePolicy(rawurl); err == nil {
+			delete(srv.governance, policy.Node.ID())
+		}
+	}
+	for _, rawurl := range added {
+		policy, err := ParseNodePolicy(rawurl)
+		if err != nil {
+			srv.log.Warn("Invalid governance node policy", "url", rawurl, "err", err)
+			continue
+		}
+		srv.governance[policy.Node.ID()] = policy
+	}
+	srv.policyLock.Unlock()
+
+	srv.enforcePolicies()
+}
+
+// enforcePolicies disconnects the peers violating their current policy.
+func (srv *Server) enforcePolicies() {
+	select {
+	case srv.peerOp <- func(peers map[enode.ID]*Peer) {
+		for id, p := range peers {
+			policy := srv.nodePolicy(id)
+			if err := srv.checkPolicy(policy, p.rw.fd.RemoteAddr()); err != nil {
+				srv.log.Info("Disconnecting peer violating its policy", "id", id, "err", err)
+				p.Disconnect(DiscRequested)
+				continue
+			}
+			p.rw.setPolicy(policy)
+		}
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+}
+
+// checkPolicy checks that a node of policy may connect from addr.
+func (srv *Server) checkPolicy(policy *NodePolicy, addr net.Addr) error {
+	if policy == nil {
+		return nil
+	}
+	var ip net.IP
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		ip = tcp.IP
+	}
+	if !policy.AllowsIP(ip) {
+		return errPolicyNetwork
+	}
+	return nil
+}
+
+// checkOrgPeers checks that the organization of c has room for another peer.
+func (srv *Server) checkOrgPeers(peers map[enode.ID]*Peer, c *conn) error {
+	policy := c.getPolicy()
+	if policy == nil || policy.Org == "" || srv.permissions == nil {
+		return nil
+	}
+	org, ok := srv.permissions.Organization(policy.Org)
+	if !ok || org.MaxPeers == 0 {
+		return nil
+	}
+	count := 0
+	for _, p := range peers {
+		if other := p.Policy(); other != nil && other.Org == policy.Org {
+			count++
+		}
+	}
+	if count >= org.MaxPeers {
+		return errPolicyOrgFull
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+func TestNodePolicy(t *testing.T) {
+	node := enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, 1}, 30303, 30301)
+
+	policy, err := ParseNodePolicy(node.String() + "&role=observer&org=acme&cidr=10.0.0.0/8&cidr=192.168.1.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Node.ID() != node.ID() || policy.Role != RoleObserver || policy.Org != "acme" || len(policy.Networks) != 2 {
+		t.Fatalf("parsed policy: have %+v", policy)
+	}
+	if policy.SendsConsensus() {
+		t.Errorf("observer sends consensus messages")
+	}
+	for ip, allowed := range map[string]bool{"10.1.2.3": true, "192.168.1.7": true, "192.168.2.7": false} {
+		if policy.AllowsIP(net.ParseIP(ip)) != allowed {
+			t.Errorf("ip %s: have allowed %v, want %v", ip, !allowed, allowed)
+		}
+	}
+
+	// The policy survives its URL encoding
+	decoded, err := ParseNodePolicy(policy.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != policy.String() || decoded.Node.UDP() != 30301 {
+		t.Errorf("decoded policy: have %v, want %v", decoded, policy)
+	}
+
+	// Plain URLs are members allowed from anywhere
+	member, err := ParseNodePolicy(node.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !member.SendsConsensus() || !member.AllowsIP(net.ParseIP("8.8.8.8")) || member.String() != node.String() {
+		t.Errorf("plain policy: have %+v", member)
+	}
+	if _, err := ParseNodePolicy(node.String() + "&role=admin"); err == nil {
+		t.Errorf("invalid role accepted")
+	}
+}
+
+func TestOrgPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30301, 30301)
+	b := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30302, 30302)
+	file := `{"nodes": ["` + a.String() + `?org=acme", "` + b.String() + `?org=acme"], "organizations": {"acme": {"maxPeers": 1}}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, PERMISSIONED_CONFIG), []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{permissions: NewPermissions(dir)}
+	if org, ok := srv.permissions.Organization("acme"); !ok || org.MaxPeers != 1 {
+		t.Fatalf("organization: have %+v/%v", org, ok)
+	}
+
+	connA := &conn{node: a, policy: srv.nodePolicy(a.ID())}
+	connB := &conn{node: b, policy: srv.nodePolicy(b.ID())}
+	peers := make(map[enode.ID]*Peer)
+	if err := srv.checkOrgPeers(peers, connA); err != nil {
+		t.Fatalf("first peer of the organization rejected: %v", err)
+	}
+	peers[a.ID()] = &Peer{rw: connA}
+	if err := srv.checkOrgPeers(peers, connB); err != errPolicyOrgFull {
+		t.Errorf("peer over the organization limit: have %v, want %v", err, errPolicyOrgFull)
+	}
+
+	// Persisting keeps the organization limits
+	if _, err := srv.permissions.Remove(b.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.permissions.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.permissions.Organization("acme"); !ok || srv.permissions.Policy(a.ID()).Org != "acme" {
+		t.Errorf("organization lost on persist")
+	}
+}
@@ -189,6 +189,16 @@ type Server struct {
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
 	log          log.Logger
+	permissions  *Permissions // Nodes permissioned to connect, if EnableNodePermissionFlag
+
+	policyLock sync.RWMutex
+	governance map[enode.ID]*NodePolicy // Policies of the nodes whitelisted by the governance contract
+
+	whitelistLock    sync.Mutex
+	whitelist        map[enode.ID]*enode.Node      // Whitelisted nodes, dialed and trusted
+	pendingWhitelist map[enode.ID]*whitelistChange // Whitelist changes waiting for the next reconciliation
+	reconcileTimer   *time.Timer                   // Timer of the next reconciliation, nil if none scheduled
+	lastReconcile    time.Time                     // Time of the last reconciliation
 
 	// Channels into the run loop.
 	quit                    chan struct{}
@@ -234,6 +244,9 @@ type conn struct {
 	cont  chan error // The run loop uses cont to signal errors to SetupConn.
 	caps  []Cap      // valid after the protocol handshake
 	name  string     // valid after the protocol handshake
+
+	policyLock sync.RWMutex
+	policy     *NodePolicy // Permission policy of the node, nil if none
 }
 
 type transport interface {
@@ -279,6 +292,18 @@ func (f connFlag) String() string {
 	return s
 }
 
+func (c *conn) setPolicy(policy *NodePolicy) {
+	c.policyLock.Lock()
+	c.policy = policy
+	c.policyLock.Unlock()
+}
+
+func (c *conn) getPolicy() *NodePolicy {
+	c.policyLock.RLock()
+	defer c.policyLock.RUnlock()
+	return c.policy
+}
+
 func (c *conn) is(f connFlag) bool {
 	flags := connFlag(atomic.LoadInt32((*int32)(&c.flags)))
 	return flags&f != 0
@@ -322,6 +347,28 @@ func (srv *Server) Peers() []*Peer {
 	return ps
 }
 
+// Permissions returns the nodes permissioned to connect, nil if node
+// permissioning is disabled.
+func (srv *Server) Permissions() *Permissions {
+	return srv.permissions
+}
+
+// disconnectRevoked disconnects the peers whose permission is revoked.
+func (srv *Server) disconnectRevoked(ids []enode.ID) {
+	select {
+	case srv.peerOp <- func(peers map[enode.ID]*Peer) {
+		for _, id := range ids {
+			if p := peers[id]; p != nil {
+				srv.log.Info("Disconnecting peer with revoked permission", "id", id)
+				p.Disconnect(DiscRequested)
+			}
+		}
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+}
+
 // PeerCount returns the number of connected peers.
 func (srv *Server) PeerCount() int {
 	var count int
@@ -368,46 +415,6 @@ func (srv *Server) RemoveTrustedPeer(node *enode.Node) {
 	}
 }
 
-// UpdateWhitelist updates the whitelist using static peers logic
-// This function can be heavily optimized if needed
-func (srv *Server) UpdateWhitelist(enodes []*enode.Node) {
-
-	// Check for peers that needs to be disconnected
-	for _, connectedPeer := range srv.Peers() {
-		found := false
-		for _, whitelistedEnode := range enodes {
-			if connectedPeer.Node().ID() == whitelistedEnode.ID() {
-				found = true
-				break
-			}
-		}
-		if !found {
-			log.Info("Dropping no longer authorized peer", "enode", connectedPeer.Node().String())
-			srv.RemovePeer(connectedPeer.Node())
-			srv.RemoveTrustedPeer(connectedPeer.Node())
-		}
-	}
-
-	// Check for peers that needs to be connected
-	for _, whitelistedEnode := range enodes {
-		found := false
-		for _, oldEnode := range srv.TrustedNodes {
-			if oldEnode.ID() == whitelistedEnode.ID() {
-				found = true
-				break
-			}
-		}
-		if !found {
-			log.Info("Connecting to newly authorized peer", "enode", whitelistedEnode.String())
-			srv.AddPeer(whitelistedEnode)
-			srv.AddTrustedPeer(whitelistedEnode)
-		}
-	}
-
-	srv.StaticNodes = enodes
-	srv.TrustedNodes = enodes
-}
-
 // SubscribePeers subscribes the given channel to peer events
 func (srv *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -537,6 +544,15 @@ func (srv *Server) Start() (err error) {
 		srv.StaticNodes = nil
 		//srv.TrustedNodes = nil //-> breaks TestServerAtCap
 		dialer = newDialState(srv.localnode.ID(), nil, 0, &Config{NetRestrict: srv.Config.NetRestrict})
+
+		srv.permissions = NewPermissions(srv.DataDir)
+		srv.permissions.revoked = srv.disconnectRevoked
+		srv.permissions.changed = srv.enforcePolicies
+		srv.loopWG.Add(1)
+		go func() {
+			defer srv.loopWG.Done()
+			srv.permissions.watch(srv.quit)
+		}()
 	}
 
 	//dialer := newDialState(srv.localnode.ID(), srv.ntab, dynPeers, &srv.Config)
@@ -888,7 +904,7 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
 	default:
-		return nil
+		return srv.checkOrgPeers(peers, c)
 	}
 }
 
@@ -1035,33 +1051,20 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 	clog := srv.log.New("id", c.node.ID(), "addr", c.fd.RemoteAddr(), "conn", c.flags)
 
 	//START - SMILO Permissioning
-	if srv.EnableNodePermissionFlag {
-		currentNode := srv.NodeInfo().ID
-		cnodeName := srv.NodeInfo().Name
-		clog.Trace("Smilo permissioning",
-			"EnableNodePermissionFlag", srv.EnableNodePermissionFlag,
-			"DataDir", srv.DataDir,
-			"Current Node ID", currentNode,
-			"Node Name", cnodeName,
-			"Dialed Dest", dialDest,
-			"Connection ID", c.node.ID(),
-			"Connection String", c.node.ID().String())
-
-		clog.Trace("Node Permissioning is Enabled.")
-		NodeID := c.node.ID().String()
-		direction := "INCOMING"
-		if dialDest != nil {
-			NodeID = dialDest.ID().String()
-			direction = "OUTGOING"
-			log.Trace("NodeID Permissioning", "Connection Direction", direction)
-		}
-
-		if !IsNodePermissioned(NodeID, currentNode, srv.DataDir, direction) {
-			return nil
+	if srv.permissions != nil {
+		if !srv.permissions.IsPermissioned(c.node.ID()) {
+			clog.Trace("Node not permissioned, rejecting connection", "dialed", dialDest != nil)
+			return errNotPermissioned
 		}
 	} else {
 		clog.Trace("Node Permissioning is Disabled.")
 	}
+	policy := srv.nodePolicy(c.node.ID())
+	if err := srv.checkPolicy(policy, c.fd.RemoteAddr()); err != nil {
+		clog.Trace("Node policy rejected connection", "err", err)
+		return err
+	}
+	c.setPolicy(policy)
 	//END - SMILO Permissioning
 
 	if conn, ok := c.fd.(*meteredConn); ok {
@@ -0,0 +1,148 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"time"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+// whitelistReconcileInterval is the minimum interval between two reconciliations
+// of the peers with the whitelist. Changes in between are coalesced.
+const whitelistReconcileInterval = time.Second
+
+// whitelistChange is a pending change of a whitelisted node.
+type whitelistChange struct {
+	node    *enode.Node
+	removed bool
+	block   uint64 // Block of the change, zero if not from the chain
+}
+
+// UpdateWhitelist replaces the whitelist with enodes, dropping the peers it
+// added which are not in it anymore.
+func (srv *Server) UpdateWhitelist(enodes []*enode.Node) {
+	next := make(map[enode.ID]*enode.Node, len(enodes))
+	for _, n := range enodes {
+		next[n.ID()] = n
+	}
+	var added, removed []*enode.Node
+	srv.whitelistLock.Lock()
+	for id, n := range next {
+		if old, ok := srv.whitelist[id]; !ok || old.String() != n.String() {
+			added = append(added, n)
+		}
+	}
+	for id, n := range srv.whitelist {
+		if next[id] == nil {
+			removed = append(removed, n)
+		}
+	}
+	srv.whitelistLock.Unlock()
+
+	srv.ApplyWhitelistDiff(0, added, removed)
+}
+
+// ApplyWhitelistDiff schedules the whitelist changes of block, connecting to the
+// added nodes and dropping the removed ones. The changes are reconciled with the
+// peers at most once per whitelistReconcileInterval, the last change of a node
+// winning.
+func (srv *Server) ApplyWhitelistDiff(block uint64, added, removed []*enode.Node) {
+	srv.whitelistLock.Lock()
+	defer srv.whitelistLock.Unlock()
+
+	if srv.pendingWhitelist == nil {
+		srv.pendingWhitelist = make(map[enode.ID]*whitelistChange)
+	}
+	for _, n := range removed {
+		srv.pendingWhitelist[n.ID()] = &whitelistChange{node: n, removed: true, block: block}
+	}
+	for _, n := range added {
+		srv.pendingWhitelist[n.ID()] = &whitelistChange{node: n, block: block}
+	}
+	if srv.reconcileTimer == nil && len(srv.pendingWhitelist) > 0 {
+		wait := time.Until(srv.lastReconcile.Add(whitelistReconcileInterval))
+		if wait < 0 {
+			wait = 0
+		}
+		srv.reconcileTimer = time.AfterFunc(wait, srv.reconcileWhitelist)
+	}
+}
+
+// reconcileWhitelist applies the pending whitelist changes to the peers, and
+// logs them as an audit trail. The dialed and trusted nodes are changed through
+// the run loop. Only the peers added by the whitelist are dropped, the static
+// and trusted nodes of the configuration are kept.
+func (srv *Server) reconcileWhitelist() {
+	srv.whitelistLock.Lock()
+	pending := srv.pendingWhitelist
+	srv.pendingWhitelist, srv.reconcileTimer, srv.lastReconcile = nil, nil, time.Now()
+
+	if srv.whitelist == nil {
+		srv.whitelist = make(map[enode.ID]*enode.Node)
+	}
+	drop := make(map[enode.ID]bool)
+	for id, change := range pending {
+		if change.removed {
+			if _, ok := srv.whitelist[id]; ok && !srv.isConfiguredPeer(id) {
+				drop[id] = true
+			}
+			delete(srv.whitelist, id)
+		} else {
+			srv.whitelist[id] = change.node
+		}
+	}
+	nodes := make([]*enode.Node, 0, len(srv.whitelist))
+	for _, n := range srv.whitelist {
+		nodes = append(nodes, n)
+	}
+	srv.whitelistLock.Unlock()
+
+	audit := srv.log.New("audit", "whitelist")
+	for id, change := range pending {
+		if change.removed {
+			if !drop[id] {
+				audit.Info("Keeping peer not added by the whitelist", "block", change.block, "enode", change.node.String())
+				continue
+			}
+			audit.Info("Dropping no longer authorized peer", "block", change.block, "enode", change.node.String())
+			srv.RemovePeer(change.node)
+			srv.RemoveTrustedPeer(change.node)
+		} else {
+			audit.Info("Connecting to newly authorized peer", "block", change.block, "enode", change.node.String())
+			srv.AddPeer(change.node)
+			srv.AddTrustedPeer(change.node)
+		}
+	}
+	audit.Debug("Reconciled peers with the whitelist", "changes", len(pending), "whitelist", len(nodes))
+}
+
+// isConfiguredPeer returns whether id is a static or trusted node of the server
+// configuration.
+func (srv *Server) isConfiguredPeer(id enode.ID) bool {
+	for _, n := range srv.StaticNodes {
+		if n.ID() == id {
+			return true
+		}
+	}
+	for _, n := range srv.TrustedNodes {
+		if n.ID() == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"go-smilo/src/blockchain/smilobft/p2p/enode"
+)
+
+// receiveNodes returns the ids of the n nodes sent on ch.
+func receiveNodes(t *testing.T, ch chan *enode.Node, n int) []enode.ID {
+	var ids []enode.ID
+	for i := 0; i < n; i++ {
+		select {
+		case node := <-ch:
+			ids = append(ids, node.ID())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for node %d of %d", i+1, n)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// Tests that the whitelist only drops the peers it added, keeping the trusted
+// nodes of the configuration.
+func TestReconcileWhitelist(t *testing.T) {
+	var (
+		a       = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, 1}, 30303, 30303)
+		b       = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, 2}, 30303, 30303)
+		trusted = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, 3}, 30303, 30303)
+	)
+	srv := &Server{
+		Config:        Config{TrustedNodes: []*enode.Node{trusted}},
+		log:           log.Root(),
+		addstatic:     make(chan *enode.Node, 3),
+		removestatic:  make(chan *enode.Node, 3),
+		addtrusted:    make(chan *enode.Node, 3),
+		removetrusted: make(chan *enode.Node, 3),
+	}
+	srv.UpdateWhitelist([]*enode.Node{a, b, trusted})
+	receiveNodes(t, srv.addstatic, 3)
+	receiveNodes(t, srv.addtrusted, 3)
+
+	// Removing the trusted node from the whitelist keeps it connected
+	srv.UpdateWhitelist([]*enode.Node{a})
+	if have := receiveNodes(t, srv.removestatic, 1); have[0] != b.ID() {
+		t.Errorf("dropped peer: have %v, want %v", have[0], b.ID())
+	}
+	if have := receiveNodes(t, srv.removetrusted, 1); have[0] != b.ID() {
+		t.Errorf("untrusted peer: have %v, want %v", have[0], b.ID())
+	}
+	select {
+	case n := <-srv.removestatic:
+		t.Errorf("configured peer %v dropped", n.ID())
+	case n := <-srv.removetrusted:
+		t.Errorf("configured peer %v untrusted", n.ID())
+	default:
+	}
+	srv.whitelistLock.Lock()
+	defer srv.whitelistLock.Unlock()
+	if len(srv.whitelist) != 1 || srv.whitelist[a.ID()] == nil {
+		t.Errorf("whitelist: have %v, want %v", srv.whitelist, a.ID())
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccountAccess is the level of the transactions an account may send. Every
+// level includes the ones below it.
+type AccountAccess uint8
+
+const (
+	AccountAccessNone     AccountAccess = iota // May not send transactions
+	AccountAccessTransact                      // May send value and call contracts
+	AccountAccessDeploy                        // May also deploy contracts
+	AccountAccessAdmin                         // May also manage the account permissions
+)
+
+var accountAccessNames = []string{"none", "transact", "deploy", "admin"}
+
+// String implements the stringer interface.
+func (a AccountAccess) String() string {
+	if int(a) < len(accountAccessNames) {
+		return accountAccessNames[a]
+	}
+	return fmt.Sprintf("AccountAccess(%d)", uint8(a))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a AccountAccess) MarshalText() ([]byte, error) {
+	if int(a) >= len(accountAccessNames) {
+		return nil, fmt.Errorf("invalid account access %d", uint8(a))
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *AccountAccess) UnmarshalText(text []byte) error {
+	for i, name := range accountAccessNames {
+		if string(text) == name {
+			*a = AccountAccess(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown account access %q, want one of %v", text, accountAccessNames)
+}
+
+// AccountPermissionsConfig restricts the transactions of the accounts from the
+// AccountPermissionsBlock of the chain. The access of an account is the one
+// returned by the governance contract if it is deployed, else the one of the
+// genesis allow-list, else the default one.
+//
+// The contract must implement getAccountAccess(address) returns (uint8),
+// answering the levels of AccountAccess.
+type AccountPermissionsConfig struct {
+	Contract common.Address                   `json:"contract,omitempty"` // Governance contract of the account access, if any
+	Accounts map[common.Address]AccountAccess `json:"accounts,omitempty"` // Genesis allow-list
+	Default  AccountAccess                    `json:"default,omitempty"`  // Access of the accounts not listed
+}
+
+// Access returns the access of account in the genesis allow-list.
+func (c *AccountPermissionsConfig) Access(account common.Address) AccountAccess {
+	if access, ok := c.Accounts[account]; ok {
+		return access
+	}
+	return c.Default
+}
+
+// Equal reports whether both configs grant the same access.
+func (c *AccountPermissionsConfig) Equal(other *AccountPermissionsConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if c.Contract != other.Contract || c.Default != other.Default || len(c.Accounts) != len(other.Accounts) {
+		return false
+	}
+	for account, access := range c.Accounts {
+		if otherAccess, ok := other.Accounts[account]; !ok || otherAccess != access {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAccountPermissions returns whether the account permissions are enforced at
+// block num.
+func (c *ChainConfig) IsAccountPermissions(num *big.Int) bool {
+	return c.AccountPermissions != nil && isForked(c.AccountPermissionsBlock, num)
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestIsAccountPermissions(t *testing.T) {
+	permissions := &AccountPermissionsConfig{Accounts: map[common.Address]AccountAccess{common.HexToAddress("0x01"): AccountAccessDeploy}}
+	c := &ChainConfig{AccountPermissions: permissions, AccountPermissionsBlock: big.NewInt(10)}
+	for number, want := range map[int64]bool{0: false, 9: false, 10: true, 20: true} {
+		if have := c.IsAccountPermissions(big.NewInt(number)); have != want {
+			t.Errorf("block %d: have %v, want %v", number, have, want)
+		}
+	}
+	if (&ChainConfig{AccountPermissionsBlock: big.NewInt(0)}).IsAccountPermissions(big.NewInt(1)) {
+		t.Error("account permissions enforced without a config")
+	}
+
+	stored, changed := *c, *c
+	changed.AccountPermissionsBlock = big.NewInt(30)
+	if err := stored.CheckCompatible(&changed, 20, true); err == nil || err.RewindTo != 9 {
+		t.Errorf("account permissions fork moved after its activation: have %v, want rewind to 9", err)
+	}
+	changed = *c
+	changed.AccountPermissions = &AccountPermissionsConfig{Accounts: map[common.Address]AccountAccess{common.HexToAddress("0x01"): AccountAccessTransact}}
+	if err := stored.CheckCompatible(&changed, 20, true); err == nil {
+		t.Error("account permissions changed after their activation")
+	}
+	if err := stored.CheckCompatible(&changed, 5, true); err != nil {
+		t.Errorf("account permissions changed before their activation: %v", err)
+	}
+}
@@ -1,11 +1,13 @@
 package params
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
@@ -52,6 +54,35 @@ type AutonityContractGenesis struct {
 	MinGasPrice uint64         `json:"minGasPrice" toml:",omitempty"`
 	Operator    common.Address `json:"operator" toml:",omitempty"`
 	Users       []User         `json:"users" toml:",omitempty"`
+	// Upgrades scheduled by the governance operator, ordered by block
+	Upgrades []AutonityContractUpgrade `json:"upgrades,omitempty" toml:",omitempty"`
+}
+
+// AutonityContractUpgrade replaces the Autonity contract at the end of Block.
+// The users, stakes and settings of the running contract are migrated to a new
+// deployment of Bytecode, which takes over as the Autonity contract.
+type AutonityContractUpgrade struct {
+	Block    uint64 `json:"block"`
+	Bytecode string `json:"bytecode"`
+	ABI      string `json:"abi"`
+	// Signature of the governance operator over SigHash
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// SigHash returns the hash the governance operator signs to authorize the upgrade.
+func (u *AutonityContractUpgrade) SigHash() common.Hash {
+	var number [8]byte
+	binary.BigEndian.PutUint64(number[:], u.Block)
+	return crypto.Keccak256Hash(number[:], crypto.Keccak256(common.FromHex(u.Bytecode)), crypto.Keccak256([]byte(u.ABI)))
+}
+
+// Signer returns the address which signed the upgrade.
+func (u *AutonityContractUpgrade) Signer() (common.Address, error) {
+	pub, err := crypto.SigToPub(u.SigHash().Bytes(), u.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
 }
 
 func (ac *AutonityContractGenesis) AddDefault() *AutonityContractGenesis {
@@ -97,6 +128,24 @@ func (ac *AutonityContractGenesis) Validate() error {
 			return err
 		}
 	}
+	var last uint64
+	for i, u := range ac.Upgrades {
+		// The contract is deployed in block #1
+		if u.Block <= 1 || u.Block <= last {
+			return fmt.Errorf("autonity contract upgrade %d: block %d is not after block %d", i, u.Block, last)
+		}
+		if len(u.Bytecode) == 0 || len(u.ABI) == 0 {
+			return fmt.Errorf("autonity contract upgrade %d: contract is empty", i)
+		}
+		signer, err := u.Signer()
+		if err != nil {
+			return fmt.Errorf("autonity contract upgrade %d: invalid signature: %v", i, err)
+		}
+		if signer != ac.Operator {
+			return fmt.Errorf("autonity contract upgrade %d: signed by %v, not by the governance operator", i, signer.String())
+		}
+		last = u.Block
+	}
 	return nil
 }
 
@@ -107,6 +156,33 @@ func (ac *AutonityContractGenesis) GetContractAddress() (common.Address, error)
 	return crypto.CreateAddress(ac.Deployer, 0), nil
 }
 
+// GetUpgradeAddress returns the address of the contract deployed by the i-th
+// upgrade. The deployer nonce of the genesis deployment is 0.
+func (ac *AutonityContractGenesis) GetUpgradeAddress(i int) common.Address {
+	return crypto.CreateAddress(ac.Deployer, uint64(i+1))
+}
+
+// GetUpgradeIndex returns the index of the last upgrade applied by block
+// number, or -1 if the genesis contract is still in use.
+func (ac *AutonityContractGenesis) GetUpgradeIndex(number uint64) int {
+	index := -1
+	for i := range ac.Upgrades {
+		if ac.Upgrades[i].Block <= number {
+			index = i
+		}
+	}
+	return index
+}
+
+// GetContractAddressAt returns the address of the Autonity contract in the
+// state of block number.
+func (ac *AutonityContractGenesis) GetContractAddressAt(number uint64) (common.Address, error) {
+	if i := ac.GetUpgradeIndex(number); i >= 0 {
+		return ac.GetUpgradeAddress(i), nil
+	}
+	return ac.GetContractAddress()
+}
+
 //User - is used to put predefined accounts to genesis
 type User struct {
 	Address common.Address `json:"address"`
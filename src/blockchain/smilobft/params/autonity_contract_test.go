@@ -1,6 +1,7 @@
 package params
 
 import (
+	"crypto/ecdsa"
 	"net"
 	"reflect"
 	"testing"
@@ -158,3 +159,50 @@ func TestUsePartOfEnodeAsAddress(t *testing.T) {
 	}
 
 }
+
+func TestValidateAutonityContractUpgrades(t *testing.T) {
+	operatorKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	signed := func(block uint64, key *ecdsa.PrivateKey) AutonityContractUpgrade {
+		u := AutonityContractUpgrade{Block: block, Bytecode: "6080", ABI: "[]"}
+		u.Signature, _ = crypto.Sign(u.SigHash().Bytes(), key)
+		return u
+	}
+	tampered := signed(10, operatorKey)
+	tampered.Bytecode = "6081"
+
+	tests := []struct {
+		upgrades []AutonityContractUpgrade
+		ok       bool
+	}{
+		{[]AutonityContractUpgrade{signed(10, operatorKey), signed(20, operatorKey)}, true},
+		{[]AutonityContractUpgrade{signed(10, otherKey)}, false},
+		{[]AutonityContractUpgrade{tampered}, false},
+		{[]AutonityContractUpgrade{signed(1, operatorKey)}, false},
+		{[]AutonityContractUpgrade{signed(20, operatorKey), signed(10, operatorKey)}, false},
+	}
+	for i, tt := range tests {
+		contractConfig := AutonityContractGenesis{
+			Deployer: common.HexToAddress("0xff"),
+			Operator: crypto.PubkeyToAddress(operatorKey.PublicKey),
+			Bytecode: "some code",
+			ABI:      "some abi",
+			Upgrades: tt.upgrades,
+		}
+		if err := contractConfig.Validate(); (err == nil) != tt.ok {
+			t.Errorf("test %d: have err %v, want ok %v", i, err, tt.ok)
+		}
+	}
+
+	contractConfig := AutonityContractGenesis{Deployer: common.HexToAddress("0xff"), Upgrades: tests[0].upgrades}
+	genesisAddress, _ := contractConfig.GetContractAddress()
+	for number, want := range map[uint64]common.Address{
+		9:  genesisAddress,
+		10: contractConfig.GetUpgradeAddress(0),
+		25: contractConfig.GetUpgradeAddress(1),
+	} {
+		if addr, _ := contractConfig.GetContractAddressAt(number); addr != want {
+			t.Errorf("address at %d: have %v, want %v", number, addr.String(), want.String())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "math/big"
+
+// EpochLength returns the epoch of the consensus engine of the chain, zero if
+// it has none.
+func (c *ChainConfig) EpochLength() uint64 {
+	switch {
+	case c.Sport != nil:
+		return c.Sport.Epoch
+	case c.SportDAO != nil:
+		return c.SportDAO.Epoch
+	case c.Istanbul != nil:
+		return c.Istanbul.Epoch
+	case c.Tendermint != nil:
+		return c.Tendermint.Epoch
+	case c.Clique != nil:
+		return c.Clique.Epoch
+	}
+	return 0
+}
+
+// IsCheckpointFork returns whether num is past the activation of the epoch
+// checkpoint headers.
+func (c *ChainConfig) IsCheckpointFork(num *big.Int) bool {
+	return isForked(c.CheckpointBlock, num)
+}
+
+// IsCheckpoint returns whether the BFT header num is an epoch checkpoint, which
+// commits to the validator set that sealed it.
+func (c *ChainConfig) IsCheckpoint(num *big.Int) bool {
+	epoch := c.EpochLength()
+	if epoch == 0 || num.Sign() == 0 || !c.IsCheckpointFork(num) {
+		return false
+	}
+	return new(big.Int).Mod(num, new(big.Int).SetUint64(epoch)).Sign() == 0
+}
@@ -0,0 +1,37 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsCheckpoint(t *testing.T) {
+	c := &ChainConfig{Istanbul: &IstanbulConfig{Epoch: 100}, CheckpointBlock: big.NewInt(150)}
+	for number, want := range map[int64]bool{0: false, 100: false, 150: false, 199: false, 200: true, 300: true} {
+		if have := c.IsCheckpoint(big.NewInt(number)); have != want {
+			t.Errorf("block %d: have %v, want %v", number, have, want)
+		}
+	}
+
+	stored, changed := *c, *c
+	changed.CheckpointBlock = big.NewInt(250)
+	if err := stored.CheckCompatible(&changed, 180, true); err == nil || err.RewindTo != 149 {
+		t.Errorf("checkpoint fork moved after its activation: have %v, want rewind to 149", err)
+	}
+}
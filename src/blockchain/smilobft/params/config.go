@@ -270,19 +270,19 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(20080914), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, false, true, false, 0, 32, nil, nil, nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(20080914), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, false, true, false, 0, 32, nil, nil, nil, nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, false, false, 0, 32, nil, nil, nil, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, false, false, 0, 32, nil, nil, nil, nil, nil, nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, false, true, false, 0, 32, nil, nil, nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, false, true, false, 0, 32, nil, nil, nil, nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
-	SmiloTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, big.NewInt(300000), nil, nil, big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, true, true, false, 0, 32, nil, nil, nil, nil}
+	SmiloTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, big.NewInt(300000), nil, nil, big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, true, true, false, 0, 32, nil, nil, nil, nil, nil, nil, nil, nil}
 )
 
 // TrustedCheckpoint represents a set of post-processed trie roots (CHT and
@@ -372,6 +372,20 @@ type ChainConfig struct {
 	AutonityContractConfig *AutonityContractGenesis `json:"autonityContract,omitempty"`
 	Istanbul               *IstanbulConfig          `json:"istanbul,omitempty"`
 	SportDAO               *SportDAOConfig          `json:"sportdao,omitempty"`
+
+	EmptyBlocks     *EmptyBlocksConfig `json:"emptyBlocks,omitempty"`     // Production of blocks without transactions by the BFT engines
+	CheckpointBlock *big.Int           `json:"checkpointBlock,omitempty"` // Epoch headers of the BFT engines commit to the validator set (nil = no fork)
+
+	AccountPermissions      *AccountPermissionsConfig `json:"accountPermissions,omitempty"`      // Access of the accounts to transactions (nil = unrestricted)
+	AccountPermissionsBlock *big.Int                  `json:"accountPermissionsBlock,omitempty"` // Account permissions enforced from this block (nil = no fork)
+}
+
+// EmptyBlocksConfig is the policy of the miner for blocks without transactions.
+// It is a production policy, not a consensus rule, and can be changed at runtime.
+type EmptyBlocksConfig struct {
+	MinBlocks    *big.Int `json:"minBlocks,omitempty"`    // Blocks sealed even when empty (nil = --minblocksemptymining)
+	Heartbeat    uint64   `json:"heartbeat,omitempty"`    // Seconds between empty blocks past MinBlocks (0 = no empty blocks)
+	ForcePending bool     `json:"forcePending,omitempty"` // Seal empty blocks when an epoch or governance change is pending
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -398,6 +412,8 @@ type SportConfig struct {
 	Epoch         uint64 `json:"epoch"`    // Epoch length to reset votes and checkpoint
 	SpeakerPolicy uint64 `json:"policy"`   // The policy for speaker selection
 	MinFunds      int64  `json:"minfunds"` // The policy for speaker selection
+
+	Rewards *SportRewardsConfig `json:"rewards,omitempty"` // Block reward schedule, legacy rewards if nil
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -611,6 +627,21 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isSmil
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.CheckpointBlock, newcfg.CheckpointBlock, head) {
+		return newCompatError("Checkpoint fork block", c.CheckpointBlock, newcfg.CheckpointBlock)
+	}
+	if isForkIncompatible(c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock, head) {
+		return newCompatError("Account permissions fork block", c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock)
+	}
+	if c.IsAccountPermissions(head) && !c.AccountPermissions.Equal(newcfg.AccountPermissions) {
+		return newCompatError("Account permissions", c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock)
+	}
+	if isForkIncompatible(c.sportRewardsBlock(), newcfg.sportRewardsBlock(), head) {
+		return newCompatError("Sport rewards fork block", c.sportRewardsBlock(), newcfg.sportRewardsBlock())
+	}
+	if c.IsSportRewards(head) && !c.Sport.Rewards.Equal(newcfg.Sport.Rewards) {
+		return newCompatError("Sport rewards schedule", c.sportRewardsBlock(), newcfg.sportRewardsBlock())
+	}
 	return nil
 }
 
@@ -810,5 +841,8 @@ func (c *ChainConfig) IsValid() error {
 	if c.CustomTransactionSizeLimit < 32 || c.CustomTransactionSizeLimit > 128 {
 		return errors.New("custom transaction size limit must be bigger than 32 and lower than 128")
 	}
+	if c.Sport != nil && c.Sport.Rewards != nil {
+		return c.Sport.Rewards.Validate()
+	}
 	return nil
 }
@@ -0,0 +1,138 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxRewardShare is the denominator of the reward shares, in basis points.
+const MaxRewardShare = 10000
+
+// SportRewardsConfig is the block reward schedule of Sport, in effect from
+// Block on. Every block issues the reward of its stage of the schedule:
+// CommunityShare of it goes to the community address, SignersShare of the rest
+// is split equally among the committed seal signers of the parent block, and
+// the remainder goes to the coinbase.
+type SportRewardsConfig struct {
+	Block            *big.Int           `json:"block"`                      // Activation block of the schedule
+	Schedule         []SportRewardStage `json:"schedule"`                   // Reward stages, ordered by their last block
+	CommunityAddress common.Address     `json:"communityAddress,omitempty"` // Recipient of the community share
+	CommunityShare   uint64             `json:"communityShare,omitempty"`   // Basis points of the reward for the community
+	SignersShare     uint64             `json:"signersShare,omitempty"`     // Basis points of the rest for the seal signers
+}
+
+// SportRewardStage is the reward of every block up to and including Until. The
+// last stage may leave Until nil to last forever.
+type SportRewardStage struct {
+	Until  *big.Int `json:"until"`
+	Reward *big.Int `json:"reward"`
+}
+
+// Validate checks the shares and the ordering of the schedule.
+func (c *SportRewardsConfig) Validate() error {
+	if c.Block == nil {
+		return errors.New("missing sport rewards activation block")
+	}
+	if c.CommunityShare > MaxRewardShare || c.SignersShare > MaxRewardShare {
+		return fmt.Errorf("reward shares must not exceed %d basis points", MaxRewardShare)
+	}
+	if c.CommunityShare > 0 && c.CommunityAddress == (common.Address{}) {
+		return errors.New("missing community address for the community share")
+	}
+	for i, stage := range c.Schedule {
+		if stage.Reward == nil || stage.Reward.Sign() < 0 {
+			return fmt.Errorf("invalid reward of stage %d", i)
+		}
+		if stage.Until == nil {
+			if i != len(c.Schedule)-1 {
+				return fmt.Errorf("stage %d without end is not the last one", i)
+			}
+			continue
+		}
+		if i > 0 && c.Schedule[i-1].Until.Cmp(stage.Until) >= 0 {
+			return fmt.Errorf("stage %d does not end after stage %d", i, i-1)
+		}
+	}
+	return nil
+}
+
+// BlockReward returns the reward issued by the given block, zero after the end
+// of the schedule.
+func (c *SportRewardsConfig) BlockReward(number *big.Int) *big.Int {
+	for _, stage := range c.Schedule {
+		if stage.Until == nil || number.Cmp(stage.Until) <= 0 {
+			return new(big.Int).Set(stage.Reward)
+		}
+	}
+	return new(big.Int)
+}
+
+// Split divides reward into the community share, the share of every seal
+// signer out of the given number of signers, and what is left for the coinbase.
+// Without signers their share goes to the coinbase too.
+func (c *SportRewardsConfig) Split(reward *big.Int, signers int) (community, signer, coinbase *big.Int) {
+	community = share(reward, c.CommunityShare)
+	coinbase = new(big.Int).Sub(reward, community)
+
+	signer = new(big.Int)
+	if signers > 0 {
+		signer.Div(share(coinbase, c.SignersShare), big.NewInt(int64(signers)))
+		coinbase.Sub(coinbase, new(big.Int).Mul(signer, big.NewInt(int64(signers))))
+	}
+	return community, signer, coinbase
+}
+
+// Equal reports whether both configs define the same rewards.
+func (c *SportRewardsConfig) Equal(other *SportRewardsConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if !configNumEqual(c.Block, other.Block) || c.CommunityAddress != other.CommunityAddress ||
+		c.CommunityShare != other.CommunityShare || c.SignersShare != other.SignersShare ||
+		len(c.Schedule) != len(other.Schedule) {
+		return false
+	}
+	for i, stage := range c.Schedule {
+		if !configNumEqual(stage.Until, other.Schedule[i].Until) || !configNumEqual(stage.Reward, other.Schedule[i].Reward) {
+			return false
+		}
+	}
+	return true
+}
+
+func share(amount *big.Int, basisPoints uint64) *big.Int {
+	result := new(big.Int).Mul(amount, new(big.Int).SetUint64(basisPoints))
+	return result.Div(result, big.NewInt(MaxRewardShare))
+}
+
+// IsSportRewards returns whether num is past the activation of the configured
+// Sport block rewards.
+func (c *ChainConfig) IsSportRewards(num *big.Int) bool {
+	return isForked(c.sportRewardsBlock(), num)
+}
+
+func (c *ChainConfig) sportRewardsBlock() *big.Int {
+	if c.Sport == nil || c.Sport.Rewards == nil {
+		return nil
+	}
+	return c.Sport.Rewards.Block
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testSportRewards() *SportRewardsConfig {
+	return &SportRewardsConfig{
+		Block: big.NewInt(100),
+		Schedule: []SportRewardStage{
+			{Until: big.NewInt(199), Reward: big.NewInt(1000)},
+			{Until: big.NewInt(299), Reward: big.NewInt(500)},
+		},
+		CommunityAddress: common.HexToAddress("0x01"),
+		CommunityShare:   1000,
+		SignersShare:     3333,
+	}
+}
+
+func TestSportRewardsSchedule(t *testing.T) {
+	c := testSportRewards()
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for number, want := range map[int64]int64{100: 1000, 199: 1000, 200: 500, 299: 500, 300: 0} {
+		if have := c.BlockReward(big.NewInt(number)); have.Int64() != want {
+			t.Errorf("reward of block %d: have %v, want %d", number, have, want)
+		}
+	}
+
+	// The split never issues more or less than the reward
+	for _, signers := range []int{0, 1, 4, 7} {
+		community, signer, coinbase := c.Split(big.NewInt(1000), signers)
+		total := new(big.Int).Mul(signer, big.NewInt(int64(signers)))
+		total.Add(total, community).Add(total, coinbase)
+		if community.Int64() != 100 || total.Int64() != 1000 {
+			t.Errorf("%d signers: community %v, signer %v, coinbase %v", signers, community, signer, coinbase)
+		}
+	}
+}
+
+func TestSportRewardsValidate(t *testing.T) {
+	tests := []func(c *SportRewardsConfig){
+		func(c *SportRewardsConfig) { c.Block = nil },
+		func(c *SportRewardsConfig) { c.CommunityShare = MaxRewardShare + 1 },
+		func(c *SportRewardsConfig) { c.CommunityAddress = common.Address{} },
+		func(c *SportRewardsConfig) { c.Schedule[1].Until = big.NewInt(199) },
+		func(c *SportRewardsConfig) { c.Schedule[0].Until = nil },
+		func(c *SportRewardsConfig) { c.Schedule[1].Reward = nil },
+	}
+	for i, change := range tests {
+		c := testSportRewards()
+		change(c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("test %d: invalid config accepted", i)
+		}
+	}
+}
+
+func TestSportRewardsCompatible(t *testing.T) {
+	stored := *TestChainConfig
+	stored.Sport = &SportConfig{Rewards: testSportRewards()}
+	changed := stored
+	changed.Sport = &SportConfig{Rewards: testSportRewards()}
+	changed.Sport.Rewards.SignersShare = 0
+
+	if err := stored.CheckCompatible(&changed, 50, true); err != nil {
+		t.Errorf("change before the activation rejected: %v", err)
+	}
+	if err := stored.CheckCompatible(&changed, 150, true); err == nil || err.RewindTo != 99 {
+		t.Errorf("change after the activation: have %v, want rewind to 99", err)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	errAuthMissing      = errors.New("missing authentication")
+	errAuthToken        = errors.New("invalid authentication token")
+	errAuthExpired      = errors.New("authentication token expired")
+	errAuthNoClient     = errors.New("unknown client")
+	errTLSNoCertificate = errors.New("TLS certificate and key files are required")
+)
+
+// TLSConfig enables TLS on an HTTP or WebSocket endpoint. Setting ClientCAFile
+// enables mutual TLS: clients must present a certificate signed by one of the
+// listed authorities.
+type TLSConfig struct {
+	CertFile     string `toml:",omitempty"` // PEM certificate of the endpoint
+	KeyFile      string `toml:",omitempty"` // PEM private key of the endpoint
+	ClientCAFile string `toml:",omitempty"` // PEM authorities of the client certificates, if any
+}
+
+// AuthClient is a client allowed to call the endpoint. A client authenticates
+// with its static bearer token, with a JWT whose subject is its name, or with a
+// client certificate whose common name is its name.
+type AuthClient struct {
+	Name    string   `toml:",omitempty"` // Name of the client
+	Token   string   `toml:",omitempty"` // Static bearer token, if any
+	Modules []string `toml:",omitempty"` // API namespaces the client may call, all if "*"
+	Methods []string `toml:",omitempty"` // Single methods the client may call
+}
+
+// AuthConfig enables the authentication of the calls to an HTTP or WebSocket
+// endpoint. Requests are authenticated by an "Authorization: Bearer" header, or
+// by the client certificate if mutual TLS is enabled.
+//
+// JWTs must be signed with HS256 using JWTSecret. Besides the standard exp and
+// nbf claims, a JWT may carry "modules" and "methods" claims replacing the
+// allow-lists of its subject.
+type AuthConfig struct {
+	Clients   []AuthClient `toml:",omitempty"`
+	JWTSecret string       `toml:",omitempty"`
+}
+
+// ServerConfig returns the TLS configuration of an endpoint.
+func (c *TLSConfig) ServerConfig() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errTLSNoCertificate
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// authGrant is the set of the methods an authenticated client may call.
+type authGrant struct {
+	client  string
+	all     bool
+	modules map[string]bool
+	methods map[string]bool
+}
+
+type authGrantKey struct{}
+
+func newAuthGrant(client string, modules, methods []string) *authGrant {
+	g := &authGrant{client: client, modules: make(map[string]bool), methods: make(map[string]bool)}
+	for _, module := range modules {
+		if module == "*" {
+			g.all = true
+		}
+		g.modules[module] = true
+	}
+	for _, method := range methods {
+		g.methods[method] = true
+	}
+	return g
+}
+
+// allows returns whether the client may call method. The metadata methods are
+// always allowed.
+func (g *authGrant) allows(method string) bool {
+	if g.all || g.methods[method] {
+		return true
+	}
+	elem := strings.SplitN(method, serviceMethodSeparator, 2)
+	return len(elem) == 2 && (elem[0] == MetadataApi || g.modules[elem[0]])
+}
+
+// checkAuthGrant returns the error of a call of method not allowed in ctx.
+func checkAuthGrant(ctx context.Context, method string) error {
+	g, _ := ctx.Value(authGrantKey{}).(*authGrant)
+	if g == nil || g.allows(method) {
+		return nil
+	}
+	return &forbiddenError{client: g.client, method: method}
+}
+
+// authenticator authenticates the requests to an endpoint.
+type authenticator struct {
+	tokens map[string]*AuthClient
+	names  map[string]*AuthClient
+	secret []byte
+}
+
+func newAuthenticator(config *AuthConfig) *authenticator {
+	a := &authenticator{
+		tokens: make(map[string]*AuthClient),
+		names:  make(map[string]*AuthClient),
+	}
+	if config.JWTSecret != "" {
+		a.secret = []byte(config.JWTSecret)
+	}
+	for i := range config.Clients {
+		client := &config.Clients[i]
+		if client.Token != "" {
+			a.tokens[client.Token] = client
+		}
+		if client.Name != "" {
+			a.names[client.Name] = client
+		}
+	}
+	return a
+}
+
+// authenticate returns the grant of the client of r.
+func (a *authenticator) authenticate(r *http.Request) (*authGrant, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		const prefix = "Bearer "
+		if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+			return nil, errAuthToken
+		}
+		return a.authenticateToken(strings.TrimSpace(header[len(prefix):]))
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if client := a.names[name]; client != nil {
+			return newAuthGrant(client.Name, client.Modules, client.Methods), nil
+		}
+		return nil, errAuthNoClient
+	}
+	return nil, errAuthMissing
+}
+
+func (a *authenticator) authenticateToken(token string) (*authGrant, error) {
+	for known, client := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return newAuthGrant(client.Name, client.Modules, client.Methods), nil
+		}
+	}
+	if a.secret == nil || strings.Count(token, ".") != 2 {
+		return nil, errAuthToken
+	}
+	claims, err := verifyJWT(token, a.secret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Modules != nil || claims.Methods != nil {
+		return newAuthGrant(claims.Subject, claims.Modules, claims.Methods), nil
+	}
+	client := a.names[claims.Subject]
+	if client == nil {
+		return nil, errAuthNoClient
+	}
+	return newAuthGrant(client.Name, client.Modules, client.Methods), nil
+}
+
+// jwtClaims are the JWT claims understood by the endpoints.
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Modules   []string `json:"modules"`
+	Methods   []string `json:"methods"`
+}
+
+// verifyJWT checks the HS256 signature and the validity period of token.
+func verifyJWT(token string, secret []byte, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errAuthToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, errAuthToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errAuthToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errAuthToken
+	}
+	claims := new(jwtClaims)
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return nil, errAuthToken
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, errAuthExpired
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, errAuthToken
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SetAuth enables the authentication of the HTTP and WebSocket requests served
+// by s. It must be called before serving.
+func (s *Server) SetAuth(config *AuthConfig) {
+	if config == nil {
+		s.auth = nil
+		return
+	}
+	s.auth = newAuthenticator(config)
+}
+
+// authenticate authenticates r, writing the error response if it fails.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request, jsonError bool) (*authGrant, bool) {
+	if s.auth == nil {
+		return nil, true
+	}
+	grant, err := s.auth.authenticate(r)
+	if err == nil {
+		return grant, true
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	if !jsonError {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	w.Header().Set("content-type", contentType)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(errorMessage(&authError{err.Error()}))
+	return nil, false
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var testAuthConfig = &AuthConfig{
+	Clients: []AuthClient{
+		{Name: "reader", Token: "reader-token", Methods: []string{"test_noArgsRets"}},
+		{Name: "admin", Modules: []string{"*"}},
+	},
+	JWTSecret: "jwt-secret",
+}
+
+func makeTestJWT(secret string, claims interface{}) string {
+	enc := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := enc(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestHTTPAuth(t *testing.T) {
+	server := newTestServer()
+	server.SetAuth(testAuthConfig)
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	expired := time.Now().Add(-time.Hour).Unix()
+	tests := []struct {
+		token  string
+		method string
+		status int
+		code   int // JSON-RPC error code, zero if the call succeeds
+	}{
+		{"", "test_noArgsRets", http.StatusUnauthorized, -32001},
+		{"wrong-token", "test_noArgsRets", http.StatusUnauthorized, -32001},
+		{"reader-token", "test_noArgsRets", http.StatusOK, 0},
+		{"reader-token", "rpc_modules", http.StatusOK, 0},
+		{"reader-token", "test_rets", http.StatusOK, -32003},
+		{makeTestJWT("jwt-secret", map[string]interface{}{"sub": "admin"}), "test_rets", http.StatusOK, 0},
+		{makeTestJWT("jwt-secret", map[string]interface{}{"sub": "nobody", "modules": []string{"nftest"}}), "test_rets", http.StatusOK, -32003},
+		{makeTestJWT("jwt-secret", map[string]interface{}{"sub": "admin", "exp": expired}), "test_rets", http.StatusUnauthorized, -32001},
+		{makeTestJWT("other-secret", map[string]interface{}{"sub": "admin"}), "test_rets", http.StatusUnauthorized, -32001},
+	}
+	for i, test := range tests {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + test.method + `","params":[]}`
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("test %d: request failed: %v", i, err)
+		}
+		var msg jsonrpcMessage
+		err = json.NewDecoder(resp.Body).Decode(&msg)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("test %d: invalid response: %v", i, err)
+		}
+		if resp.StatusCode != test.status {
+			t.Errorf("test %d: status mismatch: got %d, want %d", i, resp.StatusCode, test.status)
+		}
+		code := 0
+		if msg.Error != nil {
+			code = msg.Error.Code
+		}
+		if code != test.code {
+			t.Errorf("test %d: error code mismatch: got %d, want %d (%v)", i, code, test.code, msg.Error)
+		}
+	}
+}
+
+func TestWebsocketAuth(t *testing.T) {
+	server := newTestServer()
+	server.SetAuth(testAuthConfig)
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	// Connections without credentials are refused.
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil {
+		t.Fatal("unauthenticated connection succeeded")
+	} else if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected handshake response: %v", err)
+	}
+
+	// Calls outside of the allow-list are rejected on authenticated connections.
+	header := http.Header{"Authorization": {"Bearer reader-token"}}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer conn.Close()
+	for _, test := range []struct {
+		method string
+		code   int
+	}{
+		{"test_noArgsRets", 0},
+		{"test_rets", -32003},
+		{"nftest_subscribe", -32003},
+	} {
+		if err := conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": test.method, "params": []interface{}{}}); err != nil {
+			t.Fatal(err)
+		}
+		var msg jsonrpcMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		code := 0
+		if msg.Error != nil {
+			code = msg.Error.Code
+		}
+		if code != test.code {
+			t.Errorf("%s: error code mismatch: got %d, want %d", test.method, code, test.code)
+		}
+	}
+}
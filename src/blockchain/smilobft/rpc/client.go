@@ -111,6 +111,9 @@ type clientConn struct {
 
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.WithValue(context.Background(), clientContextKey{}, c)
+	if wc, ok := conn.(*wsServerCodec); ok && wc.grant != nil {
+		ctx = context.WithValue(ctx, authGrantKey{}, wc.grant)
+	}
 	handler := newHandler(ctx, conn, c.idgen, c.services)
 	return &clientConn{conn, handler}
 }
@@ -17,13 +17,15 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net"
 
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules,
+// secured with TLS and authentication if tlsConfig and auth are set.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, tlsConfig *TLSConfig, auth *AuthConfig) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -31,6 +33,7 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetAuth(auth)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -44,15 +47,16 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 		listener net.Listener
 		err      error
 	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	if listener, err = listen(endpoint, tlsConfig); err != nil {
 		return nil, nil, err
 	}
 	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint, secured with TLS and authentication
+// if tlsConfig and auth are set.
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, tlsConfig *TLSConfig, auth *AuthConfig) (net.Listener, *Server, error) {
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -61,6 +65,7 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetAuth(auth)
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -74,7 +79,7 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 		listener net.Listener
 		err      error
 	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	if listener, err = listen(endpoint, tlsConfig); err != nil {
 		return nil, nil, err
 	}
 	go NewWSServer(wsOrigins, handler).Serve(listener)
@@ -100,3 +105,15 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 	go handler.ServeListener(listener)
 	return listener, handler, nil
 }
+
+// listen opens a TCP listener on endpoint, serving TLS if tlsConfig is set.
+func listen(endpoint string, tlsConfig *TLSConfig) (net.Listener, error) {
+	if tlsConfig == nil {
+		return net.Listen("tcp", endpoint)
+	}
+	config, err := tlsConfig.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Listen("tcp", endpoint, config)
+}
@@ -57,6 +57,29 @@ func (e *invalidMessageError) ErrorCode() int { return -32700 }
 
 func (e *invalidMessageError) Error() string { return e.message }
 
+// request without valid credentials
+type authError struct{ message string }
+
+func (e *authError) ErrorCode() int { return -32001 }
+
+func (e *authError) Error() string { return "unauthorized: " + e.message }
+
+// method not in the allow-list of the client
+type forbiddenError struct{ client, method string }
+
+func (e *forbiddenError) ErrorCode() int { return -32003 }
+
+func (e *forbiddenError) Error() string {
+	return fmt.Sprintf("method %s not allowed for client %q", e.method, e.client)
+}
+
+// request above a rate or concurrency limit
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
 // unable to decode supplied params, or an invalid number of parameters
 type invalidParamsError struct{ message string }
 
@@ -311,6 +311,16 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := checkAuthGrant(cp.ctx, msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
+	if limiter := h.reg.rateLimiter(); limiter != nil {
+		release, err := limiter.acquire(msg.Method, rateLimitClient(cp.ctx, h.conn.RemoteAddr()))
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
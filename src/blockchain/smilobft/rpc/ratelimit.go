@@ -0,0 +1,183 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"go-smilo/src/blockchain/smilobft/cmn/ratelimit"
+)
+
+// maxRateLimitClients is the number of client buckets of a limit above which
+// the full ones are dropped.
+const maxRateLimitClients = 1024
+
+// RateLimit limits the calls of a method, of the methods of a namespace or of
+// all methods. Clients are identified by their authenticated name if any, else
+// by their IP address.
+type RateLimit struct {
+	Match      string  `json:"match" toml:",omitempty"`                // Method ("eth_getLogs"), namespace ("debug") or "*"
+	Rate       float64 `json:"rate,omitempty" toml:",omitempty"`       // Calls per second of each client, unlimited if zero
+	Burst      int64   `json:"burst,omitempty" toml:",omitempty"`      // Calls a client may make at once, the rate if zero
+	Concurrent int     `json:"concurrent,omitempty" toml:",omitempty"` // Calls of all clients running at once, unlimited if zero
+}
+
+// RateLimitConfig is the set of the rate limits of an endpoint. A call is
+// limited by the most specific matching limit only.
+type RateLimitConfig struct {
+	Limits []RateLimit `json:"limits" toml:",omitempty"`
+}
+
+// rateLimiter applies the rate limits of a server.
+type rateLimiter struct {
+	limits map[string]*methodLimit // Limits by match
+}
+
+// methodLimit is the state of a rate limit.
+type methodLimit struct {
+	RateLimit
+	running chan struct{} // Semaphore of the running calls, nil if unlimited
+
+	lock    sync.Mutex
+	buckets map[string]*ratelimit.Bucket // Call rate buckets by client
+}
+
+func newRateLimiter(config *RateLimitConfig) (*rateLimiter, error) {
+	l := &rateLimiter{limits: make(map[string]*methodLimit)}
+	for _, limit := range config.Limits {
+		if limit.Match == "" {
+			return nil, fmt.Errorf("rate limit without match")
+		}
+		if limit.Rate < 0 || limit.Burst < 0 || limit.Concurrent < 0 {
+			return nil, fmt.Errorf("negative rate limit for %s", limit.Match)
+		}
+		if _, ok := l.limits[limit.Match]; ok {
+			return nil, fmt.Errorf("duplicate rate limit for %s", limit.Match)
+		}
+		ml := &methodLimit{RateLimit: limit, buckets: make(map[string]*ratelimit.Bucket)}
+		if limit.Concurrent > 0 {
+			ml.running = make(chan struct{}, limit.Concurrent)
+		}
+		l.limits[limit.Match] = ml
+	}
+	return l, nil
+}
+
+// limit returns the most specific limit of method, or nil.
+func (l *rateLimiter) limit(method string) *methodLimit {
+	if ml := l.limits[method]; ml != nil {
+		return ml
+	}
+	if elem := strings.SplitN(method, serviceMethodSeparator, 2); len(elem) == 2 {
+		if ml := l.limits[elem[0]]; ml != nil {
+			return ml
+		}
+	}
+	return l.limits["*"]
+}
+
+// acquire admits a call of method by client, returning the function to call
+// once it is done.
+func (l *rateLimiter) acquire(method, client string) (func(), error) {
+	ml := l.limit(method)
+	if ml == nil {
+		return func() {}, nil
+	}
+	if ml.Rate > 0 && ml.bucket(client).TakeAvailable(1) == 0 {
+		ml.rejected("rate").Inc(1)
+		return nil, &limitExceededError{fmt.Sprintf("rate limit of %s exceeded", method)}
+	}
+	if ml.running == nil {
+		return func() {}, nil
+	}
+	select {
+	case ml.running <- struct{}{}:
+		return func() { <-ml.running }, nil
+	default:
+		ml.rejected("concurrency").Inc(1)
+		return nil, &limitExceededError{fmt.Sprintf("too many concurrent calls of %s", method)}
+	}
+}
+
+// rejected returns the counter of the calls rejected by the limit for reason.
+func (ml *methodLimit) rejected(reason string) metrics.Counter {
+	match := ml.Match
+	if match == "*" {
+		match = "all"
+	}
+	return metrics.GetOrRegisterCounter("rpc/ratelimit/"+match+"/rejected/"+reason, nil)
+}
+
+// bucket returns the call rate bucket of client.
+func (ml *methodLimit) bucket(client string) *ratelimit.Bucket {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	if b := ml.buckets[client]; b != nil {
+		return b
+	}
+	if len(ml.buckets) >= maxRateLimitClients {
+		for id, b := range ml.buckets {
+			if b.Available() >= b.Capacity() {
+				delete(ml.buckets, id)
+			}
+		}
+	}
+	burst := ml.Burst
+	if burst == 0 {
+		burst = int64(ml.Rate)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	b := ratelimit.NewBucketWithRate(ml.Rate, burst)
+	ml.buckets[client] = b
+	return b
+}
+
+// rateLimitClient returns the identity of the client of a call, its
+// authenticated name if any, else its IP address.
+func rateLimitClient(ctx context.Context, remote string) string {
+	if g, _ := ctx.Value(authGrantKey{}).(*authGrant); g != nil && g.client != "" {
+		return "client:" + g.client
+	}
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+// SetRateLimits replaces the rate limits of the calls served by s. It may be
+// called while serving, the running calls keeping their former limits.
+func (s *Server) SetRateLimits(config *RateLimitConfig) error {
+	if config == nil || len(config.Limits) == 0 {
+		s.services.limiter.Store((*rateLimiter)(nil))
+		return nil
+	}
+	limiter, err := newRateLimiter(config)
+	if err != nil {
+		return err
+	}
+	s.services.limiter.Store(limiter)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func rateLimitErrorCode(err error) int {
+	if ec, ok := err.(Error); ok {
+		return ec.ErrorCode()
+	}
+	return 0
+}
+
+func TestRateLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	err := server.SetRateLimits(&RateLimitConfig{Limits: []RateLimit{
+		{Match: "test", Rate: 0.001, Burst: 2},
+		{Match: "test_echo", Rate: 1000},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The namespace limit admits the burst, then rejects.
+	for i := 0; i < 3; i++ {
+		err := client.Call(nil, "test_noArgsRets")
+		if i < 2 && err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+		if i == 2 && rateLimitErrorCode(err) != -32005 {
+			t.Fatalf("call %d: want limit exceeded error, got %v", i, err)
+		}
+	}
+	// The method limit takes precedence over the namespace one.
+	var result Result
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("method limit not applied: %v", err)
+	}
+	// Reloading the limits resets them.
+	if err := server.SetRateLimits(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("call failed after removing the limits: %v", err)
+	}
+	// Invalid limits are refused.
+	if err := server.SetRateLimits(&RateLimitConfig{Limits: []RateLimit{{Rate: 1}}}); err == nil {
+		t.Fatal("limit without match accepted")
+	}
+}
+
+func TestRateLimitConcurrency(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.SetRateLimits(&RateLimitConfig{Limits: []RateLimit{{Match: "test_sleep", Concurrent: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Hold the only slot, as a running call would.
+	release, err := server.services.rateLimiter().acquire("test_sleep", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_sleep", time.Duration(0)); rateLimitErrorCode(err) != -32005 {
+		t.Fatalf("want limit exceeded error, got %v", err)
+	}
+	release()
+	if err := client.Call(nil, "test_sleep", time.Duration(0)); err != nil {
+		t.Fatalf("call failed once the slot is free: %v", err)
+	}
+}
@@ -46,6 +46,7 @@ type Server struct {
 	idgen    func() ID
 	run      int32
 	codecs   mapset.Set
+	auth     *authenticator // Authenticator of the HTTP and WebSocket requests, if any
 }
 
 // NewServer creates a new server instance with no registered handlers.
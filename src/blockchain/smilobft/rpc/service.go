@@ -24,6 +24,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 
@@ -40,6 +41,7 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	limiter  atomic.Value // *rateLimiter of the calls, if any
 }
 
 // service represents a registered object.
@@ -104,6 +106,12 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[elem[0]].callbacks[elem[1]]
 }
 
+// rateLimiter returns the rate limiter of the calls, or nil.
+func (r *serviceRegistry) rateLimiter() *rateLimiter {
+	limiter, _ := r.limiter.Load().(*rateLimiter)
+	return limiter
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
@@ -57,12 +57,16 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 		CheckOrigin:     wsHandshakeValidator(allowedOrigins),
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grant, ok := s.authenticate(w, r, false)
+		if !ok {
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn)
+		codec := &wsServerCodec{newWebsocketCodec(conn), grant, r.RemoteAddr}
 		s.ServeCodec(codec, OptionMethodInvocation|OptionSubscriptions)
 	})
 }
@@ -169,6 +173,17 @@ func wsClientHeaders(endpoint, origin string) (string, http.Header, error) {
 	return endpointURL.String(), header, nil
 }
 
+// wsServerCodec carries the remote address and the grant of the client of a
+// served websocket connection.
+type wsServerCodec struct {
+	ServerCodec
+	grant  *authGrant
+	remote string
+}
+
+// RemoteAddr returns the peer address of the connection.
+func (c *wsServerCodec) RemoteAddr() string { return c.remote }
+
 func newWebsocketCodec(conn *websocket.Conn) ServerCodec {
 	conn.SetReadLimit(maxRequestContentLength)
 	return newCodec(conn, conn.WriteJSON, conn.ReadJSON)
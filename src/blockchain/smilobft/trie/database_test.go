@@ -19,15 +19,18 @@ package trie
 import (
 	"testing"
 
-	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 // Tests that the trie database returns a missing trie node error if attempting
 // to retrieve the meta root.
-func TestDatabaseMetarootFetch(t *testing.T) {
-	db := NewDatabase(memorydb.New())
+func TestDatabaseMetarootFetch(t *testing.T) { dbtest.ForEach(t, testDatabaseMetarootFetch) }
+
+func testDatabaseMetarootFetch(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	db := NewDatabase(newDB())
 	if _, err := db.Node(common.Hash{}); err == nil {
 		t.Fatalf("metaroot retrieval succeeded")
 	}
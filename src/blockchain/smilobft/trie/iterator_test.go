@@ -22,6 +22,8 @@ import (
 	"math/rand"
 	"testing"
 
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -97,9 +99,11 @@ func TestIteratorLargeData(t *testing.T) {
 }
 
 // Tests that the node iterator indeed walks over the entire database contents.
-func TestNodeIteratorCoverage(t *testing.T) {
+func TestNodeIteratorCoverage(t *testing.T) { dbtest.ForEach(t, testNodeIteratorCoverage) }
+
+func testNodeIteratorCoverage(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	// Create some arbitrary test trie to iterate
-	db, trie, _ := makeTestTrie()
+	db, trie, _ := makeTestTrie(newDB())
 
 	// Gather all the node hashes found by the iterator
 	hashes := make(map[common.Hash]struct{})
@@ -289,11 +293,16 @@ func TestIteratorNoDups(t *testing.T) {
 }
 
 // This test checks that nodeIterator.Next can be retried after inserting missing trie nodes.
-func TestIteratorContinueAfterErrorDisk(t *testing.T)    { testIteratorContinueAfterError(t, false) }
-func TestIteratorContinueAfterErrorMemonly(t *testing.T) { testIteratorContinueAfterError(t, true) }
+func TestIteratorContinueAfterErrorDisk(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) {
+		testIteratorContinueAfterError(t, newDB(), false)
+	})
+}
+func TestIteratorContinueAfterErrorMemonly(t *testing.T) {
+	testIteratorContinueAfterError(t, memorydb.New(), true)
+}
 
-func testIteratorContinueAfterError(t *testing.T, memonly bool) {
-	diskdb := memorydb.New()
+func testIteratorContinueAfterError(t *testing.T, diskdb ethdb.KeyValueStore, memonly bool) {
 	triedb := NewDatabase(diskdb)
 
 	tr, _ := New(common.Hash{}, triedb)
@@ -376,15 +385,16 @@ func testIteratorContinueAfterError(t *testing.T, memonly bool) {
 // certain key prefix behaves correctly when Next is called. The expectation is that Next
 // should retry seeking before returning true for the first time.
 func TestIteratorContinueAfterSeekErrorDisk(t *testing.T) {
-	testIteratorContinueAfterSeekError(t, false)
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) {
+		testIteratorContinueAfterSeekError(t, newDB(), false)
+	})
 }
 func TestIteratorContinueAfterSeekErrorMemonly(t *testing.T) {
-	testIteratorContinueAfterSeekError(t, true)
+	testIteratorContinueAfterSeekError(t, memorydb.New(), true)
 }
 
-func testIteratorContinueAfterSeekError(t *testing.T, memonly bool) {
+func testIteratorContinueAfterSeekError(t *testing.T, diskdb ethdb.KeyValueStore, memonly bool) {
 	// Commit test trie to db, then remove the node containing "bars".
-	diskdb := memorydb.New()
 	triedb := NewDatabase(diskdb)
 
 	ctr, _ := New(common.Hash{}, triedb)
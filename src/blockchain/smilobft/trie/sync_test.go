@@ -20,15 +20,16 @@ import (
 	"bytes"
 	"testing"
 
-	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
+	"go-smilo/src/blockchain/smilobft/ethdb"
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 // makeTestTrie create a sample test trie to test node-wise reconstruction.
-func makeTestTrie() (*Database, *Trie, map[string][]byte) {
+func makeTestTrie(diskdb ethdb.KeyValueStore) (*Database, *Trie, map[string][]byte) {
 	// Create an empty trie
-	triedb := NewDatabase(memorydb.New())
+	triedb := NewDatabase(diskdb)
 	trie, _ := New(common.Hash{}, triedb)
 
 	// Fill it with some arbitrary data
@@ -88,14 +89,16 @@ func checkTrieConsistency(db *Database, root common.Hash) error {
 }
 
 // Tests that an empty trie is not scheduled for syncing.
-func TestEmptySync(t *testing.T) {
-	dbA := NewDatabase(memorydb.New())
-	dbB := NewDatabase(memorydb.New())
+func TestEmptySync(t *testing.T) { dbtest.ForEach(t, testEmptySync) }
+
+func testEmptySync(t *testing.T, newDB func() ethdb.KeyValueStore) {
+	dbA := NewDatabase(newDB())
+	dbB := NewDatabase(newDB())
 	emptyA, _ := New(common.Hash{}, dbA)
 	emptyB, _ := New(emptyRoot, dbB)
 
 	for i, trie := range []*Trie{emptyA, emptyB} {
-		if req := NewSync(trie.Hash(), memorydb.New(), nil, NewSyncBloom(1, memorydb.New())).Missing(1); len(req) != 0 {
+		if req := NewSync(trie.Hash(), newDB(), nil, NewSyncBloom(1, newDB())).Missing(1); len(req) != 0 {
 			t.Errorf("test %d: content requested for empty trie: %v", i, req)
 		}
 	}
@@ -103,15 +106,19 @@ func TestEmptySync(t *testing.T) {
 
 // Tests that given a root hash, a trie can sync iteratively on a single thread,
 // requesting retrieval tasks and returning all of them in one go.
-func TestIterativeSyncIndividual(t *testing.T) { testIterativeSync(t, 1) }
-func TestIterativeSyncBatched(t *testing.T)    { testIterativeSync(t, 100) }
+func TestIterativeSyncIndividual(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) { testIterativeSync(t, newDB, 1) })
+}
+func TestIterativeSyncBatched(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) { testIterativeSync(t, newDB, 100) })
+}
 
-func testIterativeSync(t *testing.T, batch int) {
+func testIterativeSync(t *testing.T, newDB func() ethdb.KeyValueStore, batch int) {
 	// Create a random trie to copy
-	srcDb, srcTrie, srcData := makeTestTrie()
+	srcDb, srcTrie, srcData := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -139,12 +146,14 @@ func testIterativeSync(t *testing.T, batch int) {
 
 // Tests that the trie scheduler can correctly reconstruct the state even if only
 // partial results are returned, and the others sent only later.
-func TestIterativeDelayedSync(t *testing.T) {
+func TestIterativeDelayedSync(t *testing.T) { dbtest.ForEach(t, testIterativeDelayedSync) }
+
+func testIterativeDelayedSync(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	// Create a random trie to copy
-	srcDb, srcTrie, srcData := makeTestTrie()
+	srcDb, srcTrie, srcData := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -174,15 +183,19 @@ func TestIterativeDelayedSync(t *testing.T) {
 // Tests that given a root hash, a trie can sync iteratively on a single thread,
 // requesting retrieval tasks and returning all of them in one go, however in a
 // random order.
-func TestIterativeRandomSyncIndividual(t *testing.T) { testIterativeRandomSync(t, 1) }
-func TestIterativeRandomSyncBatched(t *testing.T)    { testIterativeRandomSync(t, 100) }
+func TestIterativeRandomSyncIndividual(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) { testIterativeRandomSync(t, newDB, 1) })
+}
+func TestIterativeRandomSyncBatched(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) { testIterativeRandomSync(t, newDB, 100) })
+}
 
-func testIterativeRandomSync(t *testing.T, batch int) {
+func testIterativeRandomSync(t *testing.T, newDB func() ethdb.KeyValueStore, batch int) {
 	// Create a random trie to copy
-	srcDb, srcTrie, srcData := makeTestTrie()
+	srcDb, srcTrie, srcData := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -218,12 +231,14 @@ func testIterativeRandomSync(t *testing.T, batch int) {
 
 // Tests that the trie scheduler can correctly reconstruct the state even if only
 // partial results are returned (Even those randomly), others sent only later.
-func TestIterativeRandomDelayedSync(t *testing.T) {
+func TestIterativeRandomDelayedSync(t *testing.T) { dbtest.ForEach(t, testIterativeRandomDelayedSync) }
+
+func testIterativeRandomDelayedSync(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	// Create a random trie to copy
-	srcDb, srcTrie, srcData := makeTestTrie()
+	srcDb, srcTrie, srcData := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -265,12 +280,14 @@ func TestIterativeRandomDelayedSync(t *testing.T) {
 
 // Tests that a trie sync will not request nodes multiple times, even if they
 // have such references.
-func TestDuplicateAvoidanceSync(t *testing.T) {
+func TestDuplicateAvoidanceSync(t *testing.T) { dbtest.ForEach(t, testDuplicateAvoidanceSync) }
+
+func testDuplicateAvoidanceSync(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	// Create a random trie to copy
-	srcDb, srcTrie, srcData := makeTestTrie()
+	srcDb, srcTrie, srcData := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -305,12 +322,14 @@ func TestDuplicateAvoidanceSync(t *testing.T) {
 
 // Tests that at any point in time during a sync, only complete sub-tries are in
 // the database.
-func TestIncompleteSync(t *testing.T) {
+func TestIncompleteSync(t *testing.T) { dbtest.ForEach(t, testIncompleteSync) }
+
+func testIncompleteSync(t *testing.T, newDB func() ethdb.KeyValueStore) {
 	// Create a random trie to copy
-	srcDb, srcTrie, _ := makeTestTrie()
+	srcDb, srcTrie, _ := makeTestTrie(newDB())
 
 	// Create a destination trie and sync with the scheduler
-	diskdb := memorydb.New()
+	diskdb := newDB()
 	triedb := NewDatabase(diskdb)
 	sched := NewSync(srcTrie.Hash(), diskdb, nil, NewSyncBloom(1, diskdb))
 
@@ -28,6 +28,7 @@ import (
 	"testing"
 	"testing/quick"
 
+	"go-smilo/src/blockchain/smilobft/ethdb/dbtest"
 	"go-smilo/src/blockchain/smilobft/ethdb/leveldb"
 	"go-smilo/src/blockchain/smilobft/ethdb/memorydb"
 
@@ -79,11 +80,14 @@ func TestMissingRoot(t *testing.T) {
 	}
 }
 
-func TestMissingNodeDisk(t *testing.T)    { testMissingNode(t, false) }
-func TestMissingNodeMemonly(t *testing.T) { testMissingNode(t, true) }
+func TestMissingNodeDisk(t *testing.T) {
+	dbtest.ForEach(t, func(t *testing.T, newDB func() ethdb.KeyValueStore) {
+		testMissingNode(t, newDB(), false)
+	})
+}
+func TestMissingNodeMemonly(t *testing.T) { testMissingNode(t, memorydb.New(), true) }
 
-func testMissingNode(t *testing.T, memonly bool) {
-	diskdb := memorydb.New()
+func testMissingNode(t *testing.T, diskdb ethdb.KeyValueStore, memonly bool) {
 	triedb := NewDatabase(diskdb)
 
 	trie, _ := New(common.Hash{}, triedb)
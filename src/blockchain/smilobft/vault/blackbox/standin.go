@@ -0,0 +1,195 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package blackbox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/crypto/sha3"
+)
+
+// StandInStore holds the payloads of a group of stand-in vaults, the way the
+// Blackbox nodes of a network share them with their recipients.
+type StandInStore struct {
+	mu       sync.RWMutex
+	payloads map[string][]byte          // Payloads by digest
+	parties  map[string]map[string]bool // Public keys allowed to read a payload, by digest
+}
+
+// NewStandInStore creates an empty payload store.
+func NewStandInStore() *StandInStore {
+	return &StandInStore{
+		payloads: make(map[string][]byte),
+		parties:  make(map[string]map[string]bool),
+	}
+}
+
+// store keeps the payload for the parties and returns its digest.
+func (s *StandInStore) store(payload []byte, parties []string) []byte {
+	digest := sha3.Sum512(payload)
+	key := string(digest[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payloads[key] = append([]byte{}, payload...)
+	s.share(key, parties)
+	return digest[:]
+}
+
+// shareDigest allows the parties to read a stored payload, returning false if
+// the digest is unknown.
+func (s *StandInStore) shareDigest(digest []byte, parties []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.payloads[string(digest)]; !ok {
+		return false
+	}
+	s.share(string(digest), parties)
+	return true
+}
+
+func (s *StandInStore) share(key string, parties []string) {
+	if s.parties[key] == nil {
+		s.parties[key] = make(map[string]bool)
+	}
+	for _, p := range parties {
+		if p != "" {
+			s.parties[key][p] = true
+		}
+	}
+}
+
+// load returns the payload of the digest if the party may read it.
+func (s *StandInStore) load(digest []byte, party string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	payload, ok := s.payloads[string(digest)]
+	if !ok || !s.parties[string(digest)][party] {
+		return nil, false
+	}
+	return payload, true
+}
+
+// StandIn serves the Blackbox node API on a unix socket from a payload store,
+// so that private transactions can be tested without running Blackbox. It
+// doesn't encrypt nor distribute anything, the payloads never leave the store.
+type StandIn struct {
+	PublicKey string // Base64 public key of the vault
+
+	store    *StandInStore
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewStandIn starts a stand-in vault on the socket, with a random public key.
+func NewStandIn(store *StandInStore, socket string) (*StandIn, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	os.Remove(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	v := &StandIn{
+		PublicKey: base64.StdEncoding.EncodeToString(key),
+		store:     store,
+		listener:  listener,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upcheck", v.upcheck)
+	mux.HandleFunc("/sendraw", v.sendRaw)
+	mux.HandleFunc("/sendsignedtx", v.sendSignedTx)
+	mux.HandleFunc("/receiveraw", v.receiveRaw)
+	v.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := v.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error("Stand-in vault failed", "socket", socket, "err", err)
+		}
+	}()
+	return v, nil
+}
+
+// Close stops serving the socket.
+func (v *StandIn) Close() error {
+	return v.server.Close()
+}
+
+func (v *StandIn) upcheck(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("I'm up!"))
+}
+
+// parties returns the sender and the recipients of a request, the sender
+// defaulting to the vault's own key.
+func (v *StandIn) parties(r *http.Request, from string) []string {
+	if from == "" {
+		from = v.PublicKey
+	}
+	return append([]string{from, v.PublicKey}, strings.Split(r.Header.Get("bb0x-to"), ",")...)
+}
+
+// sendRaw stores a base64 payload and returns its base64 digest.
+func (v *StandIn) sendRaw(w http.ResponseWriter, r *http.Request) {
+	payload, err := ioutil.ReadAll(base64.NewDecoder(base64.StdEncoding, r.Body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digest := v.store.store(payload, v.parties(r, r.Header.Get("bb0x-from")))
+	w.Write([]byte(base64.StdEncoding.EncodeToString(digest)))
+}
+
+// sendSignedTx shares the payload of a digest with the recipients, storing the
+// body as a new payload if it isn't a known digest.
+func (v *StandIn) sendSignedTx(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digest := body
+	if !v.store.shareDigest(body, v.parties(r, "")) {
+		digest = v.store.store(body, v.parties(r, ""))
+	}
+	w.Write([]byte(base64.StdEncoding.EncodeToString(digest)))
+}
+
+// receiveRaw returns the base64 payload of the digest of the bb0x-key header,
+// if the vault is one of its parties.
+func (v *StandIn) receiveRaw(w http.ResponseWriter, r *http.Request) {
+	digest, err := base64.StdEncoding.DecodeString(r.Header.Get("bb0x-key"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload, ok := v.store.load(digest, v.PublicKey)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write([]byte(base64.StdEncoding.EncodeToString(payload)))
+}
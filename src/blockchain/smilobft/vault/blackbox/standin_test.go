@@ -0,0 +1,79 @@
+// Copyright 2019 The go-smilo Authors
+// This file is part of the go-smilo library.
+//
+// The go-smilo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-smilo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-smilo library. If not, see <http://www.gnu.org/licenses/>.
+
+package blackbox
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that the payloads posted to a stand-in vault can only be read from the
+// stand-ins of their parties.
+func TestStandIn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "standin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewStandInStore()
+	vaults := make([]*Blackbox, 3)
+	keys := make([]string, 3)
+	for i := range vaults {
+		socket := filepath.Join(dir, fmt.Sprintf("%d.ipc", i))
+		standin, err := NewStandIn(store, socket)
+		if err != nil {
+			t.Fatalf("failed to start stand-in: %v", err)
+		}
+		defer standin.Close()
+
+		if vaults[i], err = New(socket); err != nil {
+			t.Fatalf("failed to connect to stand-in: %v", err)
+		}
+		keys[i] = standin.PublicKey
+	}
+	payload := []byte("private payload")
+	digest, err := vaults[0].Post(payload, "", []string{keys[1]})
+	if err != nil {
+		t.Fatalf("failed to post: %v", err)
+	}
+	if len(digest) != 64 {
+		t.Fatalf("digest length mismatch: have %d, want 64", len(digest))
+	}
+	for i, want := range [][]byte{payload, payload, nil} {
+		have, err := vaults[i].Get(digest)
+		if err != nil {
+			t.Fatalf("vault %d: failed to get: %v", i, err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Errorf("vault %d: payload mismatch: have %q, want %q", i, have, want)
+		}
+	}
+
+	// Sharing the digest of a raw transaction makes it readable by the recipients
+	if _, err := vaults[1].PostRawTransaction(digest, []string{keys[2]}); err != nil {
+		t.Fatalf("failed to post raw transaction: %v", err)
+	}
+	vaults[2].cache.Flush()
+	if have, _ := vaults[2].Get(digest); !bytes.Equal(have, payload) {
+		t.Errorf("shared payload mismatch: have %q, want %q", have, payload)
+	}
+}